@@ -0,0 +1,9 @@
+package analyticserrors
+
+import "errors"
+
+var (
+	ErrInvalidPeriod    = errors.New("period must be day, week or month")
+	ErrInvalidGroupBy   = errors.New("group_by must be item, category, supplier or staff")
+	ErrInvalidDateRange = errors.New("start date must be before end date")
+)
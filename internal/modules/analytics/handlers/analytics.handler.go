@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	analyticserrors "github.com/hsrvms/fixparts/internal/modules/analytics/errors"
+	"github.com/hsrvms/fixparts/internal/modules/analytics/models"
+	"github.com/hsrvms/fixparts/internal/modules/analytics/services"
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+type AnalyticsHandler struct {
+	service services.AnalyticsService
+}
+
+func NewAnalyticsHandler(service services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		service: service,
+	}
+}
+
+// GetMargins handles the revenue, COGS and gross margin report
+func (h *AnalyticsHandler) GetMargins(c echo.Context) error {
+	filter, err := parseMarginFilter(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetMargins(ctx, filter)
+	if err != nil {
+		return analyticsError(err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// ExportMargins handles downloading the margin report as CSV
+func (h *AnalyticsHandler) ExportMargins(c echo.Context) error {
+	if !authMiddleware.FullAccess(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "the margin export needs full access")
+	}
+
+	filter, err := parseMarginFilter(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetMargins(ctx, filter)
+	if err != nil {
+		return analyticsError(err)
+	}
+
+	filename := fmt.Sprintf("kar-marji-%s-%s.csv",
+		report.StartDate.Format("20060102"), report.EndDate.Format("20060102"))
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Response().WriteHeader(http.StatusOK)
+
+	return writeMarginCSV(c.Response(), report)
+}
+
+// groupHeaders are the CSV column headers of each grouping
+var groupHeaders = map[string]string{
+	models.GroupByItem:     "Parça No",
+	models.GroupByCategory: "Kategori",
+	models.GroupBySupplier: "Tedarikçi",
+	models.GroupByStaff:    "Satış Personeli",
+}
+
+// writeMarginCSV writes one row per period and group and a totals row. The
+// byte order mark lets spreadsheet programs detect UTF-8 for the Turkish
+// headers.
+func writeMarginCSV(w io.Writer, report *models.MarginReport) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+
+	var header []string
+	if report.Period != "" {
+		header = append(header, "Dönem")
+	}
+	if report.GroupBy != "" {
+		header = append(header, groupHeaders[report.GroupBy])
+	}
+	keyColumns := len(header)
+	header = append(header, "Adet", "Ciro", "SMM", "Brüt Kâr", "Kâr Marjı %")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, margin := range report.Rows {
+		var row []string
+		if report.Period != "" {
+			row = append(row, formatPeriod(margin.Period, report.Period))
+		}
+		if report.GroupBy != "" {
+			row = append(row, margin.GroupName)
+		}
+		if err := writer.Write(append(row, marginColumns(margin)...)); err != nil {
+			return err
+		}
+	}
+
+	// The totals row is labelled in the first key column, if there is one
+	total := make([]string, keyColumns)
+	if len(total) > 0 {
+		total[0] = "Toplam"
+	}
+	if err := writer.Write(append(total, marginColumns(report.Totals)...)); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func marginColumns(margin *models.MarginRow) []string {
+	return []string{
+		strconv.Itoa(margin.Quantity),
+		formatAmount(margin.Revenue),
+		formatAmount(margin.CostOfGoods),
+		formatAmount(margin.GrossMargin),
+		formatAmount(margin.MarginPercent),
+	}
+}
+
+func formatPeriod(period *time.Time, unit string) string {
+	if period == nil {
+		return ""
+	}
+	if unit == models.PeriodMonth {
+		return period.Format("2006-01")
+	}
+	return period.Format("2006-01-02")
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+func parseMarginFilter(c echo.Context) (*models.MarginFilter, error) {
+	filter := &models.MarginFilter{
+		Period:  c.QueryParam("period"),
+		GroupBy: c.QueryParam("group_by"),
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		date, err := time.Parse(time.RFC3339, startDate)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "start_date must be an RFC 3339 date")
+		}
+		filter.StartDate = &date
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		date, err := time.Parse(time.RFC3339, endDate)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "end_date must be an RFC 3339 date")
+		}
+		filter.EndDate = &date
+	}
+
+	return filter, nil
+}
+
+// analyticsError maps analytics errors to HTTP errors
+func analyticsError(err error) error {
+	switch err {
+	case analyticserrors.ErrInvalidPeriod, analyticserrors.ErrInvalidGroupBy, analyticserrors.ErrInvalidDateRange:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
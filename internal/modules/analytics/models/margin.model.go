@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Periods the margins can be split by
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// Dimensions the margins can be grouped by. Categories roll up their
+// subcategories; staff is the sold_by of the sale.
+const (
+	GroupByItem     = "item"
+	GroupByCategory = "category"
+	GroupBySupplier = "supplier"
+	GroupByStaff    = "staff"
+)
+
+// DefaultRangeDays is the length of the range reported when no start date
+// is given
+const DefaultRangeDays = 30
+
+func IsValidPeriod(period string) bool {
+	switch period {
+	case "", PeriodDay, PeriodWeek, PeriodMonth:
+		return true
+	}
+	return false
+}
+
+func IsValidGroupBy(groupBy string) bool {
+	switch groupBy {
+	case "", GroupByItem, GroupByCategory, GroupBySupplier, GroupByStaff:
+		return true
+	}
+	return false
+}
+
+// MarginRow is the revenue and cost of the goods sold in a period by a
+// group. Revenue is net of the ticket discount and excludes tax; returns
+// made in the period are taken off the revenue, and off the cost when the
+// parts went back on the shelf.
+type MarginRow struct {
+	// Start of the period; nil when the range is not split by period
+	Period *time.Time `json:"period,omitempty"`
+	// Item, category or supplier ID; nil for staff and for sales the group
+	// does not apply to, such as items without a category
+	GroupID   *int   `json:"group_id,omitempty"`
+	GroupName string `json:"group_name,omitempty"`
+	// Parent of a category, so the roll-up can be shown as a tree
+	ParentID *int `json:"parent_id,omitempty"`
+
+	Quantity    int     `json:"quantity"`
+	Revenue     float64 `json:"revenue"`
+	CostOfGoods float64 `json:"cost_of_goods" access:"full"`
+
+	// Computed by the analytics service
+	GrossMargin   float64 `json:"gross_margin" access:"full"`
+	MarginPercent float64 `json:"margin_percent" access:"full"`
+}
+
+// MarginReport is the gross margin over a date range
+type MarginReport struct {
+	StartDate time.Time    `json:"start_date"`
+	EndDate   time.Time    `json:"end_date"`
+	Period    string       `json:"period,omitempty"`
+	GroupBy   string       `json:"group_by,omitempty"`
+	Totals    *MarginRow   `json:"totals"`
+	Rows      []*MarginRow `json:"rows"`
+}
+
+type MarginFilter struct {
+	StartDate *time.Time `query:"start_date"`
+	EndDate   *time.Time `query:"end_date"`
+	Period    string     `query:"period"`
+	GroupBy   string     `query:"group_by"`
+}
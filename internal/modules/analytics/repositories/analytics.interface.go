@@ -0,0 +1,11 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/analytics/models"
+)
+
+type AnalyticsRepository interface {
+	GetMargins(ctx context.Context, filter *models.MarginFilter) ([]*models.MarginRow, error)
+}
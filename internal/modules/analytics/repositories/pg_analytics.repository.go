@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/analytics/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+)
+
+type PostgresAnalyticsRepository struct {
+	db *db.Database
+}
+
+func NewPostgresAnalyticsRepository(database *db.Database) AnalyticsRepository {
+	return &PostgresAnalyticsRepository{
+		db: database,
+	}
+}
+
+// marginGroups are the group ID, name and parent columns, and the joins they
+// need, of each grouping
+var marginGroups = map[string]struct {
+	id, name, parent, joins string
+}{
+	"": {
+		id: "NULL::INTEGER", name: "''", parent: "NULL::INTEGER",
+	},
+	models.GroupByItem: {
+		id: "i.item_id", name: "i.part_number", parent: "NULL::INTEGER",
+	},
+	models.GroupByCategory: {
+		id: "ct.ancestor_id", name: "COALESCE(c.category_name, '')", parent: "c.parent_category_id",
+		joins: `
+        LEFT JOIN category_tree ct ON ct.category_id = i.category_id
+        LEFT JOIN categories c ON c.category_id = ct.ancestor_id`,
+	},
+	models.GroupBySupplier: {
+		id: "i.supplier_id", name: "COALESCE(sup.name, '')", parent: "NULL::INTEGER",
+		joins: `
+        LEFT JOIN suppliers sup ON sup.supplier_id = i.supplier_id`,
+	},
+	models.GroupByStaff: {
+		id: "NULL::INTEGER", name: "COALESCE(l.sold_by, '')", parent: "NULL::INTEGER",
+	},
+}
+
+// GetMargins returns the quantity, revenue and cost of goods sold between
+// the filter's dates, per period and group. Sales count on their date and
+// returns on theirs. A category's row includes the sales of all of its
+// subcategories, so the rows of nested categories overlap.
+func (r *PostgresAnalyticsRepository) GetMargins(ctx context.Context, filter *models.MarginFilter) ([]*models.MarginRow, error) {
+	group := marginGroups[filter.GroupBy]
+
+	period := "NULL::TIMESTAMPTZ"
+	if filter.Period != "" {
+		period = "date_trunc('" + filter.Period + "', l.date)"
+	}
+
+	query := `
+        WITH RECURSIVE category_tree AS (
+            SELECT category_id, category_id as ancestor_id FROM categories
+            UNION ALL
+            SELECT ct.category_id, c.parent_category_id
+            FROM category_tree ct
+            JOIN categories c ON c.category_id = ct.ancestor_id
+            WHERE c.parent_category_id IS NOT NULL
+        ),
+        lines AS (
+            SELECT
+                s.date, sl.item_id, s.sold_by, sl.quantity,
+                sl.total_price * CASE WHEN s.subtotal > 0
+                    THEN (s.subtotal - s.discount_amount) / s.subtotal ELSE 1 END as revenue,
+                sl.cost_of_goods
+            FROM sale_lines sl
+            JOIN sales s ON sl.sale_id = s.sale_id
+            WHERE s.date >= $1 AND s.date < $2
+            UNION ALL
+            SELECT
+                sr.date, rl.item_id, s.sold_by, -rl.quantity,
+                -rl.refund_amount * CASE WHEN s.total_amount > 0
+                    THEN (s.subtotal - s.discount_amount) / s.total_amount ELSE 1 END,
+                -COALESCE((
+                    SELECT SUM(m.cost) FROM stock_movements m
+                    WHERE m.reference_type = 'sale_return'
+                    AND m.reference_line_id = rl.return_line_id
+                ), 0)
+            FROM sale_return_lines rl
+            JOIN sale_returns sr ON rl.return_id = sr.return_id
+            JOIN sales s ON sr.sale_id = s.sale_id
+            WHERE sr.date >= $1 AND sr.date < $2
+        )
+        SELECT
+            ` + period + ` as period,
+            ` + group.id + ` as group_id,
+            ` + group.name + ` as group_name,
+            ` + group.parent + ` as parent_id,
+            SUM(l.quantity)::INTEGER as quantity,
+            ROUND(SUM(l.revenue), 2) as revenue,
+            SUM(l.cost_of_goods) as cost_of_goods
+        FROM lines l
+        JOIN items i ON l.item_id = i.item_id` + group.joins + `
+        GROUP BY 1, 2, 3, 4
+        ORDER BY 1, 6 DESC, 3
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, *filter.StartDate, *filter.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var margins []*models.MarginRow
+	for rows.Next() {
+		row := &models.MarginRow{}
+		err := rows.Scan(
+			&row.Period,
+			&row.GroupID,
+			&row.GroupName,
+			&row.ParentID,
+			&row.Quantity,
+			&row.Revenue,
+			&row.CostOfGoods,
+		)
+		if err != nil {
+			return nil, err
+		}
+		margins = append(margins, row)
+	}
+
+	return margins, rows.Err()
+}
@@ -0,0 +1,21 @@
+package analytics
+
+import (
+	"github.com/hsrvms/fixparts/internal/modules/analytics/handlers"
+	"github.com/hsrvms/fixparts/internal/modules/analytics/repositories"
+	"github.com/hsrvms/fixparts/internal/modules/analytics/services"
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database) {
+	repo := repositories.NewPostgresAnalyticsRepository(database)
+	service := services.NewAnalyticsService(repo)
+	handler := handlers.NewAnalyticsHandler(service)
+
+	analytics := api.Group("/analytics", authMiddleware.Require(authModels.PermReportsView))
+	analytics.GET("/margins", handler.GetMargins)
+	analytics.GET("/margins/export", handler.ExportMargins)
+}
@@ -0,0 +1,11 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/analytics/models"
+)
+
+type AnalyticsService interface {
+	GetMargins(ctx context.Context, filter *models.MarginFilter) (*models.MarginReport, error)
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"math"
+	"time"
+
+	analyticserrors "github.com/hsrvms/fixparts/internal/modules/analytics/errors"
+	"github.com/hsrvms/fixparts/internal/modules/analytics/models"
+	"github.com/hsrvms/fixparts/internal/modules/analytics/repositories"
+)
+
+type analyticsService struct {
+	repo repositories.AnalyticsRepository
+}
+
+func NewAnalyticsService(repo repositories.AnalyticsRepository) AnalyticsService {
+	return &analyticsService{
+		repo: repo,
+	}
+}
+
+// GetMargins returns the revenue, cost of goods sold and gross margin over
+// the filter's range, by period and group, with the totals of the range
+func (s *analyticsService) GetMargins(ctx context.Context, filter *models.MarginFilter) (*models.MarginReport, error) {
+	if filter == nil {
+		filter = &models.MarginFilter{}
+	}
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.GetMargins(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.MarginReport{
+		StartDate: *filter.StartDate,
+		EndDate:   *filter.EndDate,
+		Period:    filter.Period,
+		GroupBy:   filter.GroupBy,
+		Totals:    &models.MarginRow{},
+		Rows:      []*models.MarginRow{},
+	}
+
+	for _, row := range rows {
+		computeMargin(row)
+		report.Rows = append(report.Rows, row)
+
+		// Subcategories are already rolled up into their parents
+		if filter.GroupBy == models.GroupByCategory && row.ParentID != nil {
+			continue
+		}
+		report.Totals.Quantity += row.Quantity
+		report.Totals.Revenue = roundCurrency(report.Totals.Revenue + row.Revenue)
+		report.Totals.CostOfGoods = roundCurrency(report.Totals.CostOfGoods + row.CostOfGoods)
+	}
+	computeMargin(report.Totals)
+
+	return report, nil
+}
+
+// validateFilter checks the period and grouping and defaults the range to
+// the last DefaultRangeDays days
+func validateFilter(filter *models.MarginFilter) error {
+	if !models.IsValidPeriod(filter.Period) {
+		return analyticserrors.ErrInvalidPeriod
+	}
+	if !models.IsValidGroupBy(filter.GroupBy) {
+		return analyticserrors.ErrInvalidGroupBy
+	}
+
+	if filter.EndDate == nil {
+		now := time.Now()
+		filter.EndDate = &now
+	}
+	if filter.StartDate == nil {
+		start := filter.EndDate.AddDate(0, 0, -models.DefaultRangeDays)
+		filter.StartDate = &start
+	}
+	if !filter.StartDate.Before(*filter.EndDate) {
+		return analyticserrors.ErrInvalidDateRange
+	}
+
+	return nil
+}
+
+func computeMargin(row *models.MarginRow) {
+	row.GrossMargin = roundCurrency(row.Revenue - row.CostOfGoods)
+	row.MarginPercent = 0
+	if row.Revenue != 0 {
+		row.MarginPercent = roundCurrency(row.GrossMargin / row.Revenue * 100)
+	}
+}
+
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,10 @@
+package auditerrors
+
+import "errors"
+
+var (
+	ErrInvalidEntityType = errors.New("invalid entity type")
+	ErrInvalidEntityID   = errors.New("invalid entity ID")
+	ErrInvalidAction     = errors.New("action must be create, update or delete")
+	ErrInvalidRange      = errors.New("start date must not be after end date")
+)
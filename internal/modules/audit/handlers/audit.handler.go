@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	auditerrors "github.com/hsrvms/fixparts/internal/modules/audit/errors"
+	"github.com/hsrvms/fixparts/internal/modules/audit/models"
+	"github.com/hsrvms/fixparts/internal/modules/audit/services"
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	layouts "github.com/hsrvms/fixparts/web/templates/layouts/base"
+	auditview "github.com/hsrvms/fixparts/web/templates/pages/audit"
+	"github.com/labstack/echo/v4"
+)
+
+// dateLayout is the format date inputs send; RFC 3339 times are accepted
+// as well
+const dateLayout = "2006-01-02"
+
+type AuditHandler struct {
+	service services.AuditService
+}
+
+func NewAuditHandler(service services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+// Page renders the audit log with its history tabs
+func (h *AuditHandler) Page(c echo.Context) error {
+	entityTypes := make([]string, len(models.EntityTypes))
+	for i, entityType := range models.EntityTypes {
+		entityTypes[i] = string(entityType)
+	}
+
+	component := layouts.Layout(authMiddleware.CurrentUser(c).FullName, auditview.Audit(entityTypes))
+	return component.Render(c.Request().Context(), c.Response().Writer)
+}
+
+// GetEntries handles retrieval of audit entries filtered by entity, user,
+// action and date. HTMX requests get the history table.
+func (h *AuditHandler) GetEntries(c echo.Context) error {
+	filter, err := parseFilter(c)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, filter)
+}
+
+// GetHistory handles retrieval of the history of a single record
+func (h *AuditHandler) GetHistory(c echo.Context) error {
+	filter, err := parseFilter(c)
+	if err != nil {
+		return err
+	}
+
+	entityType := models.EntityType(c.Param("entityType"))
+	filter.EntityType = &entityType
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, auditerrors.ErrInvalidEntityID.Error())
+	}
+	filter.EntityID = &id
+
+	return h.respond(c, filter)
+}
+
+func (h *AuditHandler) respond(c echo.Context, filter *models.EntryFilter) error {
+	entries, err := h.service.GetAll(c.Request().Context(), filter)
+	if err != nil {
+		switch err {
+		case auditerrors.ErrInvalidEntityType, auditerrors.ErrInvalidEntityID,
+			auditerrors.ErrInvalidAction, auditerrors.ErrInvalidRange:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+		return auditview.History(historyRows(entries)).Render(c.Request().Context(), c.Response().Writer)
+	}
+	return c.JSON(http.StatusOK, entries)
+}
+
+func parseFilter(c echo.Context) (*models.EntryFilter, error) {
+	filter := new(models.EntryFilter)
+
+	if entityType := c.QueryParam("entity_type"); entityType != "" {
+		value := models.EntityType(entityType)
+		filter.EntityType = &value
+	}
+
+	if entityID := c.QueryParam("entity_id"); entityID != "" {
+		id, err := strconv.Atoi(entityID)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, auditerrors.ErrInvalidEntityID.Error())
+		}
+		filter.EntityID = &id
+	}
+
+	if actor := c.QueryParam("actor"); actor != "" {
+		filter.Actor = &actor
+	}
+
+	if action := c.QueryParam("action"); action != "" {
+		value := models.Action(action)
+		filter.Action = &value
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		date, err := parseDate(startDate, false)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid start_date")
+		}
+		filter.StartDate = &date
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		date, err := parseDate(endDate, true)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid end_date")
+		}
+		filter.EndDate = &date
+	}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		if value, err := strconv.Atoi(limit); err == nil {
+			filter.Limit = value
+		}
+	}
+
+	return filter, nil
+}
+
+// parseDate reads an RFC 3339 time or a date. An end date covers the whole
+// day, so it becomes the start of the next one.
+func parseDate(value string, end bool) (time.Time, error) {
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+
+	date, err := time.ParseInLocation(dateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date, nil
+}
+
+func historyRows(entries []*models.Entry) []*auditview.Entry {
+	rows := make([]*auditview.Entry, 0, len(entries))
+	for _, entry := range entries {
+		row := &auditview.Entry{
+			Time:       entry.CreatedAt.Local().Format("02.01.2006 15:04"),
+			EntityType: string(entry.EntityType),
+			EntityID:   entry.EntityID,
+			Action:     string(entry.Action),
+		}
+		if entry.Actor != nil {
+			row.Actor = *entry.Actor
+		}
+		if entry.AuthorizedBy != nil {
+			row.AuthorizedBy = *entry.AuthorizedBy
+		}
+
+		fields := make([]string, 0, len(entry.Changes))
+		for field := range entry.Changes {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			change := entry.Changes[field]
+			row.Changes = append(row.Changes, auditview.Change{
+				Field: field,
+				Old:   formatValue(change.Old),
+				New:   formatValue(change.New),
+			})
+		}
+
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "—"
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return fmt.Sprint(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
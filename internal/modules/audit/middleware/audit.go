@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/hsrvms/fixparts/internal/modules/audit/models"
+	"github.com/hsrvms/fixparts/internal/modules/audit/services"
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+const serviceKey = "audit.service"
+
+// Recorder lets handlers write audit entries with RecordCreate,
+// RecordUpdate and RecordDelete
+func Recorder(service services.AuditService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(serviceKey, service)
+			return next(c)
+		}
+	}
+}
+
+// RecordCreate records a created entity as loaded after the write
+func RecordCreate(c echo.Context, entityType models.EntityType, entityID int, after any) {
+	record(c, entityType, entityID, models.ActionCreate, nil, after)
+}
+
+// RecordUpdate records the fields an update changed, from the entity as
+// loaded before and after the write
+func RecordUpdate(c echo.Context, entityType models.EntityType, entityID int, before, after any) {
+	record(c, entityType, entityID, models.ActionUpdate, before, after)
+}
+
+// RecordDelete records a deleted entity as loaded before the write
+func RecordDelete(c echo.Context, entityType models.EntityType, entityID int, before any) {
+	record(c, entityType, entityID, models.ActionDelete, before, nil)
+}
+
+// record writes the entry right away, as the change is already made. A
+// failure is logged rather than failing the request that made it.
+func record(c echo.Context, entityType models.EntityType, entityID int, action models.Action, before, after any) {
+	service, ok := c.Get(serviceKey).(services.AuditService)
+	if !ok {
+		log.Printf("Audit log is not set up; %s of %s %d not recorded", action, entityType, entityID)
+		return
+	}
+
+	ip := c.RealIP()
+	entry := &models.Entry{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Actor:      authMiddleware.Actor(c),
+		IPAddress:  &ip,
+	}
+	if authorization := authMiddleware.Approval(c); authorization != nil {
+		entry.AuthorizedBy = &authorization.AuthorizedBy
+	}
+
+	if err := service.Record(c.Request().Context(), entry, before, after); err != nil {
+		log.Printf("Failed to record the %s of %s %d: %v", action, entityType, entityID, err)
+	}
+}
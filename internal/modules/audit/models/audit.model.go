@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// EntityType names what an audit entry is about
+type EntityType string
+
+const (
+	EntityItem            EntityType = "item"
+	EntityCategory        EntityType = "category"
+	EntitySupplier        EntityType = "supplier"
+	EntityVehicleMake     EntityType = "vehicle_make"
+	EntityVehicleModel    EntityType = "vehicle_model"
+	EntityVehicleSubmodel EntityType = "vehicle_submodel"
+	// EntityCompatibility entries are kept under the item ID, so an item's
+	// fitments have one history
+	EntityCompatibility EntityType = "compatibility"
+	EntitySale          EntityType = "sale"
+	EntityPurchase      EntityType = "purchase"
+)
+
+// EntityTypes are the audited entities in the order the history tabs show
+// them
+var EntityTypes = []EntityType{
+	EntityItem,
+	EntityCategory,
+	EntitySupplier,
+	EntityVehicleMake,
+	EntityVehicleModel,
+	EntityVehicleSubmodel,
+	EntityCompatibility,
+	EntitySale,
+	EntityPurchase,
+}
+
+func IsValidEntityType(entityType EntityType) bool {
+	for _, valid := range EntityTypes {
+		if entityType == valid {
+			return true
+		}
+	}
+	return false
+}
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+func IsValidAction(action Action) bool {
+	return action == ActionCreate || action == ActionUpdate || action == ActionDelete
+}
+
+const (
+	DefaultEntryLimit = 100
+	MaxEntryLimit     = 500
+)
+
+// Change is the value of a field before and after. Old is null for a
+// creation and New for a deletion.
+type Change struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// Entry records one create, update or delete with the fields it changed
+type Entry struct {
+	AuditID      int64             `json:"audit_id" db:"audit_id"`
+	EntityType   EntityType        `json:"entity_type" db:"entity_type"`
+	EntityID     int               `json:"entity_id" db:"entity_id"`
+	Action       Action            `json:"action" db:"action"`
+	Actor        *string           `json:"actor,omitempty" db:"actor"`
+	AuthorizedBy *string           `json:"authorized_by,omitempty" db:"authorized_by"`
+	Changes      map[string]Change `json:"changes" db:"changes"`
+	IPAddress    *string           `json:"ip_address,omitempty" db:"ip_address"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+}
+
+// EntryFilter represents the search criteria for audit entries. Entries
+// come newest first.
+type EntryFilter struct {
+	EntityType *EntityType `query:"entity_type"`
+	EntityID   *int        `query:"entity_id"`
+	Actor      *string     `query:"actor"`
+	Action     *Action     `query:"action"`
+	StartDate  *time.Time  `query:"start_date"`
+	EndDate    *time.Time  `query:"end_date"`
+	Limit      int         `query:"limit"`
+}
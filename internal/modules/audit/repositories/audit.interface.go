@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/audit/models"
+)
+
+type AuditRepository interface {
+	GetAll(ctx context.Context, filter *models.EntryFilter) ([]*models.Entry, error)
+	Create(ctx context.Context, entry *models.Entry) (int64, error)
+}
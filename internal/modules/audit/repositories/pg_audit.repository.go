@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hsrvms/fixparts/internal/modules/audit/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+)
+
+type PostgresAuditRepository struct {
+	db *db.Database
+}
+
+func NewPostgresAuditRepository(database *db.Database) AuditRepository {
+	return &PostgresAuditRepository{
+		db: database,
+	}
+}
+
+func (r *PostgresAuditRepository) GetAll(ctx context.Context, filter *models.EntryFilter) ([]*models.Entry, error) {
+	query := `
+        SELECT audit_id, entity_type, entity_id, action, actor, authorized_by,
+               changes, ip_address, created_at
+        FROM audit_log
+    `
+	conditions := []string{}
+	params := []interface{}{}
+	paramCount := 1
+
+	if filter.EntityType != nil {
+		conditions = append(conditions, fmt.Sprintf("entity_type = $%d", paramCount))
+		params = append(params, *filter.EntityType)
+		paramCount++
+	}
+
+	if filter.EntityID != nil {
+		conditions = append(conditions, fmt.Sprintf("entity_id = $%d", paramCount))
+		params = append(params, *filter.EntityID)
+		paramCount++
+	}
+
+	if filter.Actor != nil {
+		conditions = append(conditions, fmt.Sprintf("LOWER(actor) = LOWER($%d)", paramCount))
+		params = append(params, *filter.Actor)
+		paramCount++
+	}
+
+	if filter.Action != nil {
+		conditions = append(conditions, fmt.Sprintf("action = $%d", paramCount))
+		params = append(params, *filter.Action)
+		paramCount++
+	}
+
+	if filter.StartDate != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", paramCount))
+		params = append(params, *filter.StartDate)
+		paramCount++
+	}
+
+	if filter.EndDate != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", paramCount))
+		params = append(params, *filter.EndDate)
+		paramCount++
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += fmt.Sprintf(" ORDER BY created_at DESC, audit_id DESC LIMIT $%d", paramCount)
+	params = append(params, filter.Limit)
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.Entry
+	for rows.Next() {
+		entry := &models.Entry{}
+		err := rows.Scan(
+			&entry.AuditID,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.Action,
+			&entry.Actor,
+			&entry.AuthorizedBy,
+			&entry.Changes,
+			&entry.IPAddress,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *PostgresAuditRepository) Create(ctx context.Context, entry *models.Entry) (int64, error) {
+	query := `
+        INSERT INTO audit_log (
+            entity_type, entity_id, action, actor, authorized_by, changes, ip_address
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING audit_id, created_at
+    `
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		entry.EntityType,
+		entry.EntityID,
+		entry.Action,
+		entry.Actor,
+		entry.AuthorizedBy,
+		entry.Changes,
+		entry.IPAddress,
+	).Scan(&entry.AuditID, &entry.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return entry.AuditID, nil
+}
@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"github.com/hsrvms/fixparts/internal/modules/audit/handlers"
+	auditMiddleware "github.com/hsrvms/fixparts/internal/modules/audit/middleware"
+	"github.com/hsrvms/fixparts/internal/modules/audit/repositories"
+	"github.com/hsrvms/fixparts/internal/modules/audit/services"
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRoutes adds the audit log and installs the middleware the other
+// modules record their changes through. It must run before the routes of
+// the audited modules.
+func RegisterRoutes(e *echo.Echo, api *echo.Group, database *db.Database) {
+	service := services.NewAuditService(repositories.NewPostgresAuditRepository(database))
+	e.Use(auditMiddleware.Recorder(service))
+
+	handler := handlers.NewAuditHandler(service)
+	view := authMiddleware.Require(authModels.PermAuditView)
+	e.GET("/audit", handler.Page, view)
+
+	entries := api.Group("/audit", view)
+	entries.GET("", handler.GetEntries)
+	entries.GET("/:entityType/:id", handler.GetHistory)
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/audit/models"
+)
+
+type AuditService interface {
+	GetAll(ctx context.Context, filter *models.EntryFilter) ([]*models.Entry, error)
+	// Record writes the entry with the fields that differ between before
+	// and after, either of which may be nil. An update that changes nothing
+	// is not recorded.
+	Record(ctx context.Context, entry *models.Entry, before, after any) error
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+
+	auditerrors "github.com/hsrvms/fixparts/internal/modules/audit/errors"
+	"github.com/hsrvms/fixparts/internal/modules/audit/models"
+	"github.com/hsrvms/fixparts/internal/modules/audit/repositories"
+)
+
+type auditService struct {
+	repo repositories.AuditRepository
+}
+
+func NewAuditService(repo repositories.AuditRepository) AuditService {
+	return &auditService{
+		repo: repo,
+	}
+}
+
+func (s *auditService) GetAll(ctx context.Context, filter *models.EntryFilter) ([]*models.Entry, error) {
+	if filter.EntityType != nil && !models.IsValidEntityType(*filter.EntityType) {
+		return nil, auditerrors.ErrInvalidEntityType
+	}
+	if filter.EntityID != nil && *filter.EntityID <= 0 {
+		return nil, auditerrors.ErrInvalidEntityID
+	}
+	if filter.Action != nil && !models.IsValidAction(*filter.Action) {
+		return nil, auditerrors.ErrInvalidAction
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && filter.StartDate.After(*filter.EndDate) {
+		return nil, auditerrors.ErrInvalidRange
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = models.DefaultEntryLimit
+	}
+	if filter.Limit > models.MaxEntryLimit {
+		filter.Limit = models.MaxEntryLimit
+	}
+
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *auditService) Record(ctx context.Context, entry *models.Entry, before, after any) error {
+	changes, err := diff(before, after)
+	if err != nil {
+		return err
+	}
+	if entry.Action == models.ActionUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	entry.Changes = changes
+	_, err = s.repo.Create(ctx, entry)
+	return err
+}
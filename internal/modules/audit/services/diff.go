@@ -0,0 +1,64 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/hsrvms/fixparts/internal/modules/audit/models"
+)
+
+// ignoredFields change on every write and say nothing about the change
+var ignoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+// diff compares the JSON forms of before and after field by field
+func diff(before, after any) (map[string]models.Change, error) {
+	old, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	current, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]models.Change{}
+	for name, value := range old {
+		if ignoredFields[name] || reflect.DeepEqual(value, current[name]) {
+			continue
+		}
+		changes[name] = models.Change{Old: value, New: current[name]}
+	}
+	for name, value := range current {
+		if _, seen := old[name]; seen || ignoredFields[name] || value == nil {
+			continue
+		}
+		changes[name] = models.Change{New: value}
+	}
+
+	return changes, nil
+}
+
+// fields decodes the JSON object of v, keeping numbers as written
+func fields(v any) (map[string]any, error) {
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil() {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
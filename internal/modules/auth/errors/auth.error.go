@@ -0,0 +1,33 @@
+package autherrors
+
+import "errors"
+
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrInvalidUserID      = errors.New("invalid user ID")
+	ErrInvalidUsername    = errors.New("username is required and cannot contain spaces")
+	ErrInvalidFullName    = errors.New("full name is required")
+	ErrInvalidRole        = errors.New("role must be admin, manager, cashier or warehouse")
+	ErrDuplicateUsername  = errors.New("username already exists")
+	ErrPasswordTooShort   = errors.New("password must be at least 8 characters")
+	ErrWrongPassword      = errors.New("current password is incorrect")
+	ErrLastAdmin          = errors.New("at least one active admin must remain")
+	ErrNoUsers            = errors.New("no users exist; set ADMIN_PASSWORD to create the first admin")
+	ErrAPIKeyNotFound     = errors.New("API key not found")
+	ErrInvalidAPIKeyID    = errors.New("invalid API key ID")
+	ErrInvalidAPIKeyName  = errors.New("API key name is required and cannot exceed 90 characters")
+	ErrNoScopes           = errors.New("API key needs at least one scope")
+	ErrInvalidScope       = errors.New("invalid API key scope")
+	ErrInvalidExpiry      = errors.New("expiry must be in the future")
+	ErrInvalidGracePeriod = errors.New("grace period cannot be negative")
+	ErrAPIKeyRevoked      = errors.New("API key is already revoked")
+	ErrTOTPNotAllowed     = errors.New("only managers and admins can enrol a TOTP authenticator")
+	ErrTOTPAlreadyEnabled = errors.New("TOTP is already enabled; ask an admin to reset it")
+	ErrTOTPNotEnrolled    = errors.New("no TOTP enrolment is pending")
+	ErrTOTPNotEnabled     = errors.New("TOTP is not enabled")
+	ErrInvalidTOTPCode    = errors.New("invalid TOTP code")
+	ErrTOTPLocked         = errors.New("too many wrong codes; try again in a few minutes")
+	ErrApprovalRequired   = errors.New("this action needs a manager's TOTP code")
+	ErrInvalidApprover    = errors.New("the approving user is not a manager with TOTP enabled")
+)
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	autherrors "github.com/hsrvms/fixparts/internal/modules/auth/errors"
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/auth/services"
+	"github.com/labstack/echo/v4"
+)
+
+type APIKeyHandler struct {
+	service services.APIKeyService
+}
+
+func NewAPIKeyHandler(service services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+	}
+}
+
+// GetAPIKeys handles retrieval of the API keys, without their secrets
+func (h *APIKeyHandler) GetAPIKeys(c echo.Context) error {
+	filter := new(models.APIKeyFilter)
+	if err := c.Bind(filter); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	keys, err := h.service.GetAll(ctx, filter)
+	if err != nil {
+		return apiKeyError(err)
+	}
+
+	return c.JSON(http.StatusOK, keys)
+}
+
+// GetAPIKeyByID handles retrieval of a single API key
+func (h *APIKeyHandler) GetAPIKeyByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid API key ID")
+	}
+
+	ctx := c.Request().Context()
+	key, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return apiKeyError(err)
+	}
+
+	return c.JSON(http.StatusOK, key)
+}
+
+// CreateAPIKey handles issuing a key. The secret is only in this response.
+func (h *APIKeyHandler) CreateAPIKey(c echo.Context) error {
+	key := new(models.APIKey)
+	if err := c.Bind(key); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	issued, err := h.service.Create(ctx, key, authMiddleware.Actor(c))
+	if err != nil {
+		return apiKeyError(err)
+	}
+
+	return c.JSON(http.StatusCreated, issued)
+}
+
+// RotateAPIKey handles replacing a key with a new secret
+func (h *APIKeyHandler) RotateAPIKey(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid API key ID")
+	}
+
+	rotation := new(models.APIKeyRotation)
+	if err := c.Bind(rotation); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	issued, err := h.service.Rotate(ctx, id, rotation, authMiddleware.Actor(c))
+	if err != nil {
+		return apiKeyError(err)
+	}
+
+	return c.JSON(http.StatusCreated, issued)
+}
+
+// RevokeAPIKey handles disabling a key for good
+func (h *APIKeyHandler) RevokeAPIKey(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid API key ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Revoke(ctx, id); err != nil {
+		return apiKeyError(err)
+	}
+
+	return h.GetAPIKeyByID(c)
+}
+
+func apiKeyError(err error) error {
+	switch err {
+	case autherrors.ErrAPIKeyNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case autherrors.ErrInvalidAPIKeyID, autherrors.ErrInvalidAPIKeyName,
+		autherrors.ErrNoScopes, autherrors.ErrInvalidScope,
+		autherrors.ErrInvalidExpiry, autherrors.ErrInvalidGracePeriod:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case autherrors.ErrAPIKeyRevoked:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
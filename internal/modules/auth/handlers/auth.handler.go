@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	autherrors "github.com/hsrvms/fixparts/internal/modules/auth/errors"
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/auth/services"
+	loginview "github.com/hsrvms/fixparts/web/templates/pages/login"
+	"github.com/labstack/echo/v4"
+)
+
+type AuthHandler struct {
+	service      services.AuthService
+	sessionTTL   time.Duration
+	secureCookie bool
+}
+
+func NewAuthHandler(service services.AuthService, sessionTTL time.Duration, secureCookie bool) *AuthHandler {
+	return &AuthHandler{
+		service:      service,
+		sessionTTL:   sessionTTL,
+		secureCookie: secureCookie,
+	}
+}
+
+// LoginPage renders the login form
+func (h *AuthHandler) LoginPage(c echo.Context) error {
+	return render(c, http.StatusOK, loginview.Login("", ""))
+}
+
+// Login checks the credentials and sets the session cookie. The login form
+// is redirected to the dashboard; JSON clients get the user back.
+func (h *AuthHandler) Login(c echo.Context) error {
+	request := new(models.LoginRequest)
+	if err := c.Bind(request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	token, user, err := h.service.Login(ctx, request, c.RealIP(), c.Request().UserAgent())
+	if err != nil {
+		if err == autherrors.ErrInvalidCredentials && !wantsJSON(c) {
+			return render(c, http.StatusUnauthorized,
+				loginview.Login(request.Username, "Kullanıcı adı veya şifre hatalı"))
+		}
+		return authError(err)
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     models.SessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  time.Now().Add(h.sessionTTL),
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if wantsJSON(c) {
+		return c.JSON(http.StatusOK, user)
+	}
+	return c.Redirect(http.StatusSeeOther, "/")
+}
+
+// Logout ends the session and clears its cookie
+func (h *AuthHandler) Logout(c echo.Context) error {
+	if cookie, err := c.Cookie(models.SessionCookie); err == nil {
+		if err := h.service.Logout(c.Request().Context(), cookie.Value); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     models.SessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		c.Response().Header().Set("HX-Redirect", "/login")
+		return c.NoContent(http.StatusNoContent)
+	}
+	return c.Redirect(http.StatusSeeOther, "/login")
+}
+
+// GetCurrentUser handles retrieval of the signed in user
+func (h *AuthHandler) GetCurrentUser(c echo.Context) error {
+	return c.JSON(http.StatusOK, authMiddleware.CurrentUser(c))
+}
+
+// ChangePassword handles a user changing their own password
+func (h *AuthHandler) ChangePassword(c echo.Context) error {
+	change := new(models.PasswordChange)
+	if err := c.Bind(change); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	token := ""
+	if cookie, err := c.Cookie(models.SessionCookie); err == nil {
+		token = cookie.Value
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.ChangePassword(ctx, authMiddleware.CurrentUser(c), token, change); err != nil {
+		return authError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func wantsJSON(c echo.Context) bool {
+	return strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+}
+
+func render(c echo.Context, status int, component templ.Component) error {
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+	c.Response().WriteHeader(status)
+	return component.Render(c.Request().Context(), c.Response().Writer)
+}
+
+func authError(err error) error {
+	switch err {
+	case autherrors.ErrUserNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case autherrors.ErrInvalidCredentials:
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	case autherrors.ErrInvalidUserID, autherrors.ErrInvalidUsername,
+		autherrors.ErrInvalidFullName, autherrors.ErrInvalidRole,
+		autherrors.ErrPasswordTooShort, autherrors.ErrWrongPassword:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case autherrors.ErrDuplicateUsername, autherrors.ErrLastAdmin:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/a-h/templ"
+	autherrors "github.com/hsrvms/fixparts/internal/modules/auth/errors"
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/auth/services"
+	layouts "github.com/hsrvms/fixparts/web/templates/layouts/base"
+	accountview "github.com/hsrvms/fixparts/web/templates/pages/account"
+	"github.com/labstack/echo/v4"
+)
+
+type TOTPHandler struct {
+	service services.TOTPService
+}
+
+func NewTOTPHandler(service services.TOTPService) *TOTPHandler {
+	return &TOTPHandler{
+		service: service,
+	}
+}
+
+// Page renders the authenticator settings of the signed in user
+func (h *TOTPHandler) Page(c echo.Context) error {
+	user := authMiddleware.CurrentUser(c)
+	status, err := h.service.Status(c.Request().Context(), user)
+	if err != nil {
+		return totpError(err)
+	}
+
+	return render(c, http.StatusOK, layouts.Layout(user.FullName, h.statusView(user, status, "")))
+}
+
+// GetStatus handles retrieval of the signed in user's authenticator status
+func (h *TOTPHandler) GetStatus(c echo.Context) error {
+	status, err := h.service.Status(c.Request().Context(), authMiddleware.CurrentUser(c))
+	if err != nil {
+		return totpError(err)
+	}
+
+	return c.JSON(http.StatusOK, status)
+}
+
+// Enroll handles starting enrolment with a new secret
+func (h *TOTPHandler) Enroll(c echo.Context) error {
+	enrollment, err := h.service.Enroll(c.Request().Context(), authMiddleware.CurrentUser(c))
+	if err != nil {
+		return totpError(err)
+	}
+
+	if isHTMX(c) {
+		return render(c, http.StatusOK, accountview.TOTPSetup(enrollment.Secret, ""))
+	}
+	return c.JSON(http.StatusCreated, enrollment)
+}
+
+// GetQRCode handles the QR code image of the pending enrolment
+func (h *TOTPHandler) GetQRCode(c echo.Context) error {
+	image, err := h.service.QRCode(c.Request().Context(), authMiddleware.CurrentUser(c))
+	if err != nil {
+		return totpError(err)
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.Blob(http.StatusOK, "image/png", image)
+}
+
+// Confirm handles enabling the pending authenticator with its first code.
+// The recovery codes are in the response, and nowhere else.
+func (h *TOTPHandler) Confirm(c echo.Context) error {
+	request := new(models.TOTPCode)
+	if err := c.Bind(request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	user := authMiddleware.CurrentUser(c)
+	codes, err := h.service.Confirm(ctx, user, request.Code)
+	if err != nil {
+		if isHTMX(c) && isCodeError(err) {
+			enrollment, enrollmentErr := h.service.Enrollment(ctx, user)
+			if enrollmentErr != nil {
+				return totpError(enrollmentErr)
+			}
+			return render(c, http.StatusOK, accountview.TOTPSetup(enrollment.Secret, codeMessage(err)))
+		}
+		return totpError(err)
+	}
+
+	if isHTMX(c) {
+		return render(c, http.StatusOK, accountview.RecoveryCodes(codes.Codes))
+	}
+	return c.JSON(http.StatusOK, codes)
+}
+
+// RegenerateRecoveryCodes handles replacing the recovery codes, confirmed
+// with a code from the authenticator
+func (h *TOTPHandler) RegenerateRecoveryCodes(c echo.Context) error {
+	request := new(models.TOTPCode)
+	if err := c.Bind(request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	user := authMiddleware.CurrentUser(c)
+	codes, err := h.service.RegenerateRecoveryCodes(ctx, user, request.Code)
+	if err != nil {
+		if isHTMX(c) && isCodeError(err) {
+			status, statusErr := h.service.Status(ctx, user)
+			if statusErr != nil {
+				return totpError(statusErr)
+			}
+			return render(c, http.StatusOK, h.statusView(user, status, codeMessage(err)))
+		}
+		return totpError(err)
+	}
+
+	if isHTMX(c) {
+		return render(c, http.StatusOK, accountview.RecoveryCodes(codes.Codes))
+	}
+	return c.JSON(http.StatusOK, codes)
+}
+
+// ResetTOTP handles an admin removing a user's authenticator, for a lost
+// phone without recovery codes
+func (h *TOTPHandler) ResetTOTP(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	if err := h.service.Reset(c.Request().Context(), id); err != nil {
+		return totpError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetAuthorizations handles retrieval of the recorded manager approvals
+func (h *TOTPHandler) GetAuthorizations(c echo.Context) error {
+	filter := new(models.AuthorizationFilter)
+	if action := c.QueryParam("action"); action != "" {
+		filter.Action = &action
+	}
+
+	if resourceID := c.QueryParam("resource_id"); resourceID != "" {
+		if id, err := strconv.Atoi(resourceID); err == nil {
+			filter.ResourceID = &id
+		}
+	}
+
+	if authorizedBy := c.QueryParam("authorized_by"); authorizedBy != "" {
+		filter.AuthorizedBy = &authorizedBy
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		if date, err := time.Parse(time.RFC3339, startDate); err == nil {
+			filter.StartDate = &date
+		}
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		if date, err := time.Parse(time.RFC3339, endDate); err == nil {
+			filter.EndDate = &date
+		}
+	}
+
+	authorizations, err := h.service.GetAuthorizations(c.Request().Context(), filter)
+	if err != nil {
+		return totpError(err)
+	}
+
+	return c.JSON(http.StatusOK, authorizations)
+}
+
+func (h *TOTPHandler) statusView(user *models.User, status *models.TOTPStatus, message string) templ.Component {
+	return accountview.TOTP(user.Role.Can(models.PermAuthorize), status.Enabled, status.RecoveryCodesLeft, message)
+}
+
+func isHTMX(c echo.Context) bool {
+	return c.Request().Header.Get("HX-Request") == "true"
+}
+
+func isCodeError(err error) bool {
+	return err == autherrors.ErrInvalidTOTPCode || err == autherrors.ErrTOTPLocked
+}
+
+func codeMessage(err error) string {
+	if err == autherrors.ErrTOTPLocked {
+		return "Çok fazla hatalı kod girildi, birkaç dakika sonra tekrar deneyin"
+	}
+	return "Kod hatalı"
+}
+
+func totpError(err error) error {
+	switch err {
+	case autherrors.ErrUserNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case autherrors.ErrInvalidUserID, autherrors.ErrInvalidTOTPCode:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case autherrors.ErrTOTPNotAllowed:
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case autherrors.ErrTOTPAlreadyEnabled, autherrors.ErrTOTPNotEnrolled, autherrors.ErrTOTPNotEnabled:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case autherrors.ErrTOTPLocked:
+		return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
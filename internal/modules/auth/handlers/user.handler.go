@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/auth/services"
+	"github.com/labstack/echo/v4"
+)
+
+type UserHandler struct {
+	service services.UserService
+}
+
+func NewUserHandler(service services.UserService) *UserHandler {
+	return &UserHandler{
+		service: service,
+	}
+}
+
+// GetUsers handles retrieval of all users with optional filtering
+func (h *UserHandler) GetUsers(c echo.Context) error {
+	filter := new(models.UserFilter)
+	if err := c.Bind(filter); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	users, err := h.service.GetAll(ctx, filter)
+	if err != nil {
+		return authError(err)
+	}
+
+	return c.JSON(http.StatusOK, users)
+}
+
+// GetUserByID handles retrieval of a single user
+func (h *UserHandler) GetUserByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	ctx := c.Request().Context()
+	user, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return authError(err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// CreateUser handles creation of a new user with an initial password
+func (h *UserHandler) CreateUser(c echo.Context) error {
+	user := new(models.User)
+	if err := c.Bind(user); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.Create(ctx, user)
+	if err != nil {
+		return authError(err)
+	}
+
+	user.UserID = id
+	return c.JSON(http.StatusCreated, user)
+}
+
+// UpdateUser handles changing the name, role and active flag of a user
+func (h *UserHandler) UpdateUser(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	user := new(models.User)
+	if err := c.Bind(user); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	user.UserID = id
+
+	ctx := c.Request().Context()
+	if err := h.service.Update(ctx, user); err != nil {
+		return authError(err)
+	}
+
+	return h.GetUserByID(c)
+}
+
+// ResetPassword handles an admin setting a new password for a user
+func (h *UserHandler) ResetPassword(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	reset := new(models.PasswordReset)
+	if err := c.Bind(reset); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.ResetPassword(ctx, id, reset); err != nil {
+		return authError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
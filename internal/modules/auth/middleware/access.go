@@ -0,0 +1,253 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/labstack/echo/v4"
+)
+
+// AccessLevel returns the access level of the request. Requests without a
+// user or API key get counter access.
+func AccessLevel(c echo.Context) models.AccessLevel {
+	if user := CurrentUser(c); user != nil {
+		return user.Role.AccessLevel()
+	}
+	if key := CurrentAPIKey(c); key != nil {
+		return key.AccessLevel()
+	}
+	return models.AccessCounter
+}
+
+// FullAccess reports whether the request may see costs and margins
+func FullAccess(c echo.Context) bool {
+	return AccessLevel(c) == models.AccessFull
+}
+
+// Serializer writes JSON responses, leaving out the fields tagged
+// access:"full" for counter access
+type Serializer struct {
+	echo.DefaultJSONSerializer
+}
+
+func (s Serializer) Serialize(c echo.Context, i interface{}, indent string) error {
+	if FullAccess(c) {
+		return s.DefaultJSONSerializer.Serialize(c, i, indent)
+	}
+
+	redacted, err := Redact(i)
+	if err != nil {
+		return err
+	}
+	return s.DefaultJSONSerializer.Serialize(c, redacted, indent)
+}
+
+// Binder rejects JSON bodies that set a field tagged access:"full" when the
+// request only has counter access
+type Binder struct {
+	echo.DefaultBinder
+}
+
+func (b *Binder) Bind(i interface{}, c echo.Context) error {
+	req := c.Request()
+	if FullAccess(c) || req.Body == nil ||
+		!strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		return b.DefaultBinder.Bind(i, c)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	var node any
+	if json.Unmarshal(body, &node) == nil {
+		if field := restrictedField(reflect.TypeOf(i), node); field != "" {
+			return echo.NewHTTPError(http.StatusForbidden, field+" cannot be set with counter access")
+		}
+	}
+
+	return b.DefaultBinder.Bind(i, c)
+}
+
+// Redact returns the JSON form of v without the fields tagged access:"full"
+func Redact(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var node any
+	if err := decoder.Decode(&node); err != nil {
+		return nil, err
+	}
+
+	redactValue(reflect.ValueOf(v), node)
+	return node, nil
+}
+
+// redactValue walks v alongside node, its decoded JSON, deleting the keys of
+// restricted fields
+func redactValue(v reflect.Value, node any) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		object, ok := node.(map[string]any)
+		if !ok {
+			return
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, inline, ok := jsonField(field)
+			if !ok {
+				continue
+			}
+			if inline {
+				redactValue(v.Field(i), object)
+				continue
+			}
+			if isRestricted(field) {
+				delete(object, name)
+				continue
+			}
+			redactValue(v.Field(i), object[name])
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := node.([]any)
+		if !ok {
+			return
+		}
+		for i := 0; i < v.Len() && i < len(items); i++ {
+			redactValue(v.Index(i), items[i])
+		}
+	case reflect.Map:
+		object, ok := node.(map[string]any)
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			redactValue(iter.Value(), object[iter.Key().String()])
+		}
+	}
+}
+
+// restrictedField returns the JSON name of the first restricted field of t
+// set in node, or ""
+func restrictedField(t reflect.Type, node any) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := node.(map[string]any)
+		if !ok {
+			return ""
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, inline, ok := jsonField(field)
+			if !ok {
+				continue
+			}
+			if inline {
+				if found := restrictedField(field.Type, object); found != "" {
+					return found
+				}
+				continue
+			}
+			value, present := lookupKey(object, name)
+			if !present {
+				continue
+			}
+			if isRestricted(field) {
+				return name
+			}
+			if found := restrictedField(field.Type, value); found != "" {
+				return found
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := node.([]any)
+		if !ok {
+			return ""
+		}
+		for _, item := range items {
+			if found := restrictedField(t.Elem(), item); found != "" {
+				return found
+			}
+		}
+	case reflect.Map:
+		object, ok := node.(map[string]any)
+		if !ok {
+			return ""
+		}
+		for _, value := range object {
+			if found := restrictedField(t.Elem(), value); found != "" {
+				return found
+			}
+		}
+	}
+	return ""
+}
+
+// jsonField returns the JSON name of a struct field as encoding/json sees
+// it, whether it is an embedded struct whose fields are inlined, and false
+// when the field is not encoded at all
+func jsonField(field reflect.StructField) (string, bool, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if field.Anonymous && name == "" {
+		t := field.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			return "", true, true
+		}
+	}
+	if !field.IsExported() {
+		return "", false, false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, false, true
+}
+
+// lookupKey finds a key the way encoding/json matches it to a field,
+// preferring an exact match over a case-insensitive one
+func lookupKey(object map[string]any, name string) (any, bool) {
+	if value, ok := object[name]; ok {
+		return value, true
+	}
+	for key, value := range object {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func isRestricted(field reflect.StructField) bool {
+	return field.Tag.Get(models.AccessTag) == string(models.AccessFull)
+}
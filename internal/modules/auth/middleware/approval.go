@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	autherrors "github.com/hsrvms/fixparts/internal/modules/auth/errors"
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/auth/services"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	approvalsKey = "auth.approvals"
+	approvalKey  = "auth.approval"
+)
+
+// Approvals lets handlers ask for a manager's approval with Approve, and
+// records the approval once the request has succeeded
+func Approvals(service services.TOTPService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(approvalsKey, service)
+
+			err := next(c)
+
+			authorization := Approval(c)
+			if authorization != nil && err == nil && c.Response().Status < http.StatusBadRequest {
+				if err := service.RecordAuthorization(c.Request().Context(), authorization); err != nil {
+					log.Printf("Failed to record the %s authorization of %d by %s: %v",
+						authorization.Action, authorization.ResourceID, authorization.AuthorizedBy, err)
+				}
+			}
+			return err
+		}
+	}
+}
+
+// Approve checks the approval of a manager for a destructive action and
+// spends the code, so handlers call it once the action has been validated,
+// right before carrying it out. API clients send the code in the
+// X-Manager-TOTP header and the manager's username in X-Manager, which a
+// manager approving their own request may leave out. HTMX buttons can ask
+// for them with hx-prompt instead, as "username code" or just the code.
+func Approve(c echo.Context, action string, resourceID int, details string) error {
+	service, ok := c.Get(approvalsKey).(services.TOTPService)
+	if !ok {
+		return echo.NewHTTPError(http.StatusInternalServerError, "approvals are not set up")
+	}
+
+	username, code := approvalCredentials(c)
+	if username == "" {
+		if user := CurrentUser(c); user != nil {
+			username = user.Username
+		}
+	}
+	if code == "" || username == "" {
+		return echo.NewHTTPError(http.StatusPreconditionRequired, autherrors.ErrApprovalRequired.Error())
+	}
+
+	ctx := c.Request().Context()
+	authorization, err := service.Verify(ctx, username, code)
+	if err == nil {
+		err = service.Consume(ctx, authorization)
+	}
+	if err != nil {
+		switch err {
+		case autherrors.ErrInvalidApprover, autherrors.ErrInvalidTOTPCode:
+			return echo.NewHTTPError(http.StatusForbidden, err.Error())
+		case autherrors.ErrTOTPLocked:
+			return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	authorization.Action = action
+	authorization.ResourceID = resourceID
+	if details != "" {
+		authorization.Details = &details
+	}
+	authorization.RequestedBy = Actor(c)
+	ip := c.RealIP()
+	authorization.IPAddress = &ip
+
+	c.Set(approvalKey, authorization)
+	return nil
+}
+
+func approvalCredentials(c echo.Context) (string, string) {
+	header := c.Request().Header
+	if code := header.Get(models.ManagerCodeHeader); code != "" {
+		return strings.TrimSpace(header.Get(models.ManagerHeader)), code
+	}
+
+	fields := strings.Fields(header.Get("HX-Prompt"))
+	switch len(fields) {
+	case 1:
+		return "", fields[0]
+	case 2:
+		return fields[0], fields[1]
+	}
+	return "", ""
+}
+
+// Approval returns the manager approval given to the request, or nil
+func Approval(c echo.Context) *models.Authorization {
+	authorization, _ := c.Get(approvalKey).(*models.Authorization)
+	return authorization
+}
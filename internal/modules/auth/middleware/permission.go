@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/labstack/echo/v4"
+)
+
+// Require lets the request through only when the user's role, or the scopes
+// of the API key, grant the permission
+func Require(permission models.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := authorize(c, permission); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// Guard protects a resource group: GET and HEAD requests need the read
+// permission and every other method the write permission
+func Guard(read, write models.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			permission := write
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead:
+				permission = read
+			}
+			if err := authorize(c, permission); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+func authorize(c echo.Context, permission models.Permission) error {
+	if user := CurrentUser(c); user != nil {
+		if !user.Role.Can(permission) {
+			return echo.NewHTTPError(http.StatusForbidden, "your role does not allow this action")
+		}
+		return nil
+	}
+	if key := CurrentAPIKey(c); key != nil {
+		if !key.HasScope(permission) {
+			return echo.NewHTTPError(http.StatusForbidden, "API key is missing the "+string(permission)+" scope")
+		}
+		return nil
+	}
+	return echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+}
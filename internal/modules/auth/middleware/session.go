@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/auth/services"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	userKey   = "auth.user"
+	apiKeyKey = "auth.api_key"
+	loginPath = "/login"
+)
+
+// publicPaths are served without a session
+var publicPaths = map[string]bool{
+	loginPath:     true,
+	"/api/health": true,
+}
+
+var publicPrefixes = []string{"/static/", "/js/"}
+
+// Authenticate resolves the session cookie to a user for every request
+// other than the login page, static files and the health check. API calls
+// may instead send an API key header. Pages without a session are
+// redirected to the login page and API calls get a 401, with an
+// HX-Redirect header when they come from HTMX.
+func Authenticate(service services.AuthService, keys services.APIKeyService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			path := c.Request().URL.Path
+			if isPublic(path) {
+				return next(c)
+			}
+
+			if secret := c.Request().Header.Get(models.APIKeyHeader); secret != "" && strings.HasPrefix(path, "/api/") {
+				key, err := keys.Authenticate(c.Request().Context(), secret, c.RealIP())
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+				}
+				if key == nil {
+					return echo.NewHTTPError(http.StatusUnauthorized, "invalid API key")
+				}
+				c.Set(apiKeyKey, key)
+				return next(c)
+			}
+
+			var user *models.User
+			if cookie, err := c.Cookie(models.SessionCookie); err == nil {
+				user, err = service.Authenticate(c.Request().Context(), cookie.Value)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+				}
+			}
+
+			if user == nil {
+				if strings.HasPrefix(path, "/api/") {
+					if c.Request().Header.Get("HX-Request") == "true" {
+						c.Response().Header().Set("HX-Redirect", loginPath)
+					}
+					return echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+				}
+				return c.Redirect(http.StatusSeeOther, loginPath)
+			}
+
+			c.Set(userKey, user)
+			return next(c)
+		}
+	}
+}
+
+func isPublic(path string) bool {
+	if publicPaths[path] {
+		return true
+	}
+	for _, prefix := range publicPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentUser returns the signed in user of the request, or nil
+func CurrentUser(c echo.Context) *models.User {
+	user, _ := c.Get(userKey).(*models.User)
+	return user
+}
+
+// CurrentAPIKey returns the API key the request was made with, or nil
+func CurrentAPIKey(c echo.Context) *models.APIKey {
+	key, _ := c.Get(apiKeyKey).(*models.APIKey)
+	return key
+}
+
+// Actor returns the name recorded as sold_by, received_by and the like on
+// documents created by the request: the username, or "api:" and the key
+// name for machine clients
+func Actor(c echo.Context) *string {
+	if user := CurrentUser(c); user != nil {
+		username := user.Username
+		return &username
+	}
+	if key := CurrentAPIKey(c); key != nil {
+		actor := "api:" + key.Name
+		return &actor
+	}
+	return nil
+}
+
+// RequireUser rejects requests made with an API key, for routes that act on
+// the signed in user's own account
+func RequireUser() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if CurrentUser(c) == nil {
+				return echo.NewHTTPError(http.StatusForbidden, "this action needs a signed in user")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+// AccessLevel decides which fields a request may see and write. Counter
+// access is for staff at the sales counter and hides what parts cost us.
+type AccessLevel string
+
+const (
+	AccessFull    AccessLevel = "full"
+	AccessCounter AccessLevel = "counter"
+)
+
+// AccessTag marks a struct field that is only shown to, and only accepted
+// from, full access requests: `access:"full"`
+const AccessTag = "access"
+
+// AccessLevel of a role: full when it may see costs, counter otherwise
+func (r Role) AccessLevel() AccessLevel {
+	if r.Can(PermCostsView) {
+		return AccessFull
+	}
+	return AccessCounter
+}
+
+// AccessLevel of an API key: full when it carries the costs:view scope
+func (k *APIKey) AccessLevel() AccessLevel {
+	if k.HasScope(PermCostsView) {
+		return AccessFull
+	}
+	return AccessCounter
+}
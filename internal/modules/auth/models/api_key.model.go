@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+const (
+	// APIKeyHeader carries the key of a machine client
+	APIKeyHeader = "X-API-Key"
+	// APIKeyPrefix starts every issued key so a leaked key is easy to spot
+	APIKeyPrefix = "fpk_"
+	// MaxAPIKeyNameLength leaves room for the "api:" actor prefix in the
+	// sold_by and received_by columns
+	MaxAPIKeyNameLength = 90
+)
+
+type APIKey struct {
+	APIKeyID   int          `json:"api_key_id" db:"api_key_id"`
+	Name       string       `json:"name" db:"name"`
+	KeyPrefix  string       `json:"key_prefix" db:"key_prefix"`
+	Scopes     []Permission `json:"scopes" db:"scopes"`
+	CreatedBy  *string      `json:"created_by,omitempty" db:"created_by"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty" db:"expires_at"`
+	RevokedAt  *time.Time   `json:"revoked_at,omitempty" db:"revoked_at"`
+	ReplacedBy *int         `json:"replaced_by,omitempty" db:"replaced_by"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty" db:"last_used_at"`
+	LastUsedIP *string      `json:"last_used_ip,omitempty" db:"last_used_ip"`
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at" db:"updated_at"`
+	Key        string       `json:"key,omitempty" db:"-"` // only returned when the key is issued
+	KeyHash    string       `json:"-" db:"key_hash"`
+}
+
+// HasScope reports whether the key grants the permission
+func (k *APIKey) HasScope(permission Permission) bool {
+	for _, scope := range k.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyFilter represents the search criteria for API keys
+type APIKeyFilter struct {
+	IncludeRevoked bool `query:"include_revoked"`
+}
+
+// APIKeyRotation issues a replacement key. The old key keeps working for
+// the grace period so clients can be switched over; without one it is
+// revoked at once.
+type APIKeyRotation struct {
+	GracePeriodHours int `json:"grace_period_hours"`
+}
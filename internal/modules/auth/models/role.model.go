@@ -0,0 +1,128 @@
+package models
+
+type Role string
+
+const (
+	RoleAdmin     Role = "admin"
+	RoleManager   Role = "manager"
+	RoleCashier   Role = "cashier"
+	RoleWarehouse Role = "warehouse"
+)
+
+// Permission is checked by the route guards. Read permissions cover GET
+// requests of a resource and write permissions everything else. API keys
+// carry permissions directly as their scopes.
+type Permission string
+
+const (
+	PermDashboardView  Permission = "dashboard:view"
+	PermItemsRead      Permission = "items:read"
+	PermItemsWrite     Permission = "items:write"
+	PermInventoryRead  Permission = "inventory:read"
+	PermInventoryWrite Permission = "inventory:write"
+	PermVehiclesRead   Permission = "vehicles:read"
+	PermVehiclesWrite  Permission = "vehicles:write"
+	PermSuppliersRead  Permission = "suppliers:read"
+	PermSuppliersWrite Permission = "suppliers:write"
+	PermPurchasesRead  Permission = "purchases:read"
+	PermPurchasesWrite Permission = "purchases:write"
+	PermSalesRead      Permission = "sales:read"
+	PermSalesWrite     Permission = "sales:write"
+	PermCustomersRead  Permission = "customers:read"
+	PermCustomersWrite Permission = "customers:write"
+	PermReportsView    Permission = "reports:view"
+	PermCostsView      Permission = "costs:view"
+	PermSettingsWrite  Permission = "settings:write"
+	PermUsersManage    Permission = "users:manage"
+	PermAPIKeysManage  Permission = "api_keys:manage"
+	PermAuditView      Permission = "audit:view"
+	// PermAuthorize lets a user approve deletions and price decreases with
+	// their TOTP code
+	PermAuthorize Permission = "actions:authorize"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermDashboardView,
+		PermItemsRead, PermItemsWrite,
+		PermInventoryRead, PermInventoryWrite,
+		PermVehiclesRead, PermVehiclesWrite,
+		PermSuppliersRead, PermSuppliersWrite,
+		PermPurchasesRead, PermPurchasesWrite,
+		PermSalesRead, PermSalesWrite,
+		PermCustomersRead, PermCustomersWrite,
+		PermReportsView, PermCostsView, PermSettingsWrite, PermUsersManage, PermAPIKeysManage,
+		PermAuthorize, PermAuditView,
+	},
+	RoleManager: {
+		PermDashboardView,
+		PermItemsRead, PermItemsWrite,
+		PermInventoryRead, PermInventoryWrite,
+		PermVehiclesRead, PermVehiclesWrite,
+		PermSuppliersRead, PermSuppliersWrite,
+		PermPurchasesRead, PermPurchasesWrite,
+		PermSalesRead, PermSalesWrite,
+		PermCustomersRead, PermCustomersWrite,
+		PermReportsView, PermCostsView, PermSettingsWrite,
+		PermAuthorize, PermAuditView,
+	},
+	RoleCashier: {
+		PermDashboardView,
+		PermItemsRead,
+		PermInventoryRead,
+		PermVehiclesRead,
+		PermSalesRead, PermSalesWrite,
+		PermCustomersRead, PermCustomersWrite,
+	},
+	RoleWarehouse: {
+		PermDashboardView,
+		PermItemsRead, PermItemsWrite,
+		PermInventoryRead, PermInventoryWrite,
+		PermVehiclesRead,
+		PermSuppliersRead,
+		PermPurchasesRead, PermPurchasesWrite,
+		PermCostsView,
+	},
+}
+
+func IsValidRole(role Role) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Can reports whether the role grants the permission
+func (r Role) Can(permission Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// apiKeyScopes are the permissions an API key may be issued with. Managing
+// users, keys and settings, approving actions and reading the audit log,
+// whose diffs hold costs, stays with signed in users.
+var apiKeyScopes = map[Permission]bool{
+	PermDashboardView:  true,
+	PermItemsRead:      true,
+	PermItemsWrite:     true,
+	PermInventoryRead:  true,
+	PermInventoryWrite: true,
+	PermVehiclesRead:   true,
+	PermVehiclesWrite:  true,
+	PermSuppliersRead:  true,
+	PermSuppliersWrite: true,
+	PermPurchasesRead:  true,
+	PermPurchasesWrite: true,
+	PermSalesRead:      true,
+	PermSalesWrite:     true,
+	PermCustomersRead:  true,
+	PermCustomersWrite: true,
+	PermReportsView:    true,
+	PermCostsView:      true,
+}
+
+func IsValidScope(scope Permission) bool {
+	return apiKeyScopes[scope]
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// SessionCookie holds the session token of a signed in browser
+const SessionCookie = "fixparts_session"
+
+// Session is a login of a user. The token itself is only handed to the
+// client; the database keeps its SHA-256 hash.
+type Session struct {
+	TokenHash string    `json:"-" db:"token_hash"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	IPAddress *string   `json:"ip_address,omitempty" db:"ip_address"`
+	UserAgent *string   `json:"user_agent,omitempty" db:"user_agent"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
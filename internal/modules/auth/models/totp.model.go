@@ -0,0 +1,106 @@
+package models
+
+import "time"
+
+const (
+	// ManagerHeader names the manager approving an action. It may be left
+	// out when the signed in user is the approving manager.
+	ManagerHeader = "X-Manager"
+	// ManagerCodeHeader carries the manager's TOTP or recovery code
+	ManagerCodeHeader = "X-Manager-TOTP"
+	// TOTPIssuer is shown next to the account in authenticator apps
+	TOTPIssuer = "FixParts"
+	// RecoveryCodeCount is the number of recovery codes issued at a time
+	RecoveryCodeCount = 10
+	// MaxTOTPAttempts wrong codes in a row lock the authenticator for
+	// TOTPLockout
+	MaxTOTPAttempts = 5
+	TOTPLockout     = 5 * time.Minute
+)
+
+// Actions needing a manager's approval
+const (
+	ActionSaleDelete     = "sale.delete"
+	ActionPurchaseDelete = "purchase.delete"
+	ActionItemDelete     = "item.delete"
+	ActionPriceDecrease  = "item.price_decrease"
+)
+
+type AuthorizationMethod string
+
+const (
+	MethodTOTP         AuthorizationMethod = "totp"
+	MethodRecoveryCode AuthorizationMethod = "recovery_code"
+)
+
+// TOTP is a user's authenticator. It is pending until the first code
+// confirms it.
+type TOTP struct {
+	UserID         int        `json:"user_id" db:"user_id"`
+	Secret         string     `json:"-" db:"secret"`
+	ConfirmedAt    *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	LastStep       *int64     `json:"-" db:"last_step"`
+	FailedAttempts int        `json:"-" db:"failed_attempts"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Enabled reports whether the authenticator is confirmed
+func (t *TOTP) Enabled() bool {
+	return t != nil && t.ConfirmedAt != nil
+}
+
+// TOTPStatus describes the signed in user's authenticator
+type TOTPStatus struct {
+	Enabled           bool       `json:"enabled"`
+	Pending           bool       `json:"pending"`
+	ConfirmedAt       *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+}
+
+// TOTPEnrollment is returned when enrolment starts. The QR code of the URL
+// is served at QRCodeURL until the enrolment is confirmed.
+type TOTPEnrollment struct {
+	Secret    string `json:"secret"`
+	URL       string `json:"otpauth_url"`
+	QRCodeURL string `json:"qr_code_url"`
+}
+
+// TOTPCode confirms an enrolment or new recovery codes
+type TOTPCode struct {
+	Code string `json:"code" form:"code"`
+}
+
+// RecoveryCodes are shown once, when they are issued
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+// Authorization records a manager approving a destructive action
+type Authorization struct {
+	AuthorizationID int                 `json:"authorization_id" db:"authorization_id"`
+	Action          string              `json:"action" db:"action"`
+	ResourceID      int                 `json:"resource_id" db:"resource_id"`
+	Details         *string             `json:"details,omitempty" db:"details"`
+	RequestedBy     *string             `json:"requested_by,omitempty" db:"requested_by"`
+	AuthorizedBy    string              `json:"authorized_by" db:"authorized_by"`
+	AuthorizerID    *int                `json:"authorizer_id,omitempty" db:"authorizer_id"`
+	Method          AuthorizationMethod `json:"method" db:"method"`
+	IPAddress       *string             `json:"ip_address,omitempty" db:"ip_address"`
+	CreatedAt       time.Time           `json:"created_at" db:"created_at"`
+
+	// The TOTP step or recovery code the authorization was verified with,
+	// spent once the action is about to be carried out
+	Step             *int64 `json:"-" db:"-"`
+	RecoveryCodeHash string `json:"-" db:"-"`
+}
+
+// AuthorizationFilter represents the search criteria for authorizations
+type AuthorizationFilter struct {
+	Action       *string    `query:"action"`
+	ResourceID   *int       `query:"resource_id"`
+	AuthorizedBy *string    `query:"authorized_by"`
+	StartDate    *time.Time `query:"start_date"`
+	EndDate      *time.Time `query:"end_date"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// MinPasswordLength is the shortest password accepted for an account
+const MinPasswordLength = 8
+
+type User struct {
+	UserID       int        `json:"user_id" db:"user_id"`
+	Username     string     `json:"username" db:"username"`
+	FullName     string     `json:"full_name" db:"full_name"`
+	Role         Role       `json:"role" db:"role"`
+	IsActive     bool       `json:"is_active" db:"is_active"`
+	LastLoginAt  *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	Password     string     `json:"password,omitempty" db:"-"` // only read when creating a user
+	PasswordHash string     `json:"-" db:"password_hash"`
+}
+
+// UserFilter represents the search criteria for users
+type UserFilter struct {
+	Role     *Role `query:"role"`
+	IsActive *bool `query:"is_active"`
+}
+
+// LoginRequest is posted by the login form or an API client
+type LoginRequest struct {
+	Username string `json:"username" form:"username"`
+	Password string `json:"password" form:"password"`
+}
+
+// PasswordChange is sent by a user changing their own password
+type PasswordChange struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// PasswordReset is sent by an admin setting another user's password
+type PasswordReset struct {
+	Password string `json:"password"`
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+)
+
+type APIKeyRepository interface {
+	GetAll(ctx context.Context, filter *models.APIKeyFilter) ([]*models.APIKey, error)
+	GetByID(ctx context.Context, id int) (*models.APIKey, error)
+	// GetActiveByHash returns an unrevoked, unexpired key, or nil
+	GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	Create(ctx context.Context, key *models.APIKey) (int, error)
+	// Rotate stores the replacement key and retires the old one, at once or
+	// when graceUntil passes
+	Rotate(ctx context.Context, id int, replacement *models.APIKey, graceUntil *time.Time) (int, error)
+	Revoke(ctx context.Context, id int) error
+	RecordUse(ctx context.Context, id int, ipAddress string) error
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+)
+
+type AuthorizationRepository interface {
+	GetAll(ctx context.Context, filter *models.AuthorizationFilter) ([]*models.Authorization, error)
+	Create(ctx context.Context, authorization *models.Authorization) (int, error)
+}
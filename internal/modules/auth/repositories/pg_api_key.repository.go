@@ -0,0 +1,257 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	autherrors "github.com/hsrvms/fixparts/internal/modules/auth/errors"
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+const apiKeyColumns = `
+            k.api_key_id, k.name, k.key_prefix, k.scopes, k.created_by,
+            k.expires_at, k.revoked_at, k.replaced_by, k.last_used_at,
+            k.last_used_ip, k.created_at, k.updated_at, k.key_hash`
+
+// recordUseInterval limits how often a busy key rewrites its last use
+const recordUseInterval = "1 minute"
+
+type PostgresAPIKeyRepository struct {
+	db *db.Database
+}
+
+func NewPostgresAPIKeyRepository(database *db.Database) APIKeyRepository {
+	return &PostgresAPIKeyRepository{
+		db: database,
+	}
+}
+
+func scanAPIKey(row pgx.Row) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	var scopes []string
+	err := row.Scan(
+		&key.APIKeyID,
+		&key.Name,
+		&key.KeyPrefix,
+		&scopes,
+		&key.CreatedBy,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.ReplacedBy,
+		&key.LastUsedAt,
+		&key.LastUsedIP,
+		&key.CreatedAt,
+		&key.UpdatedAt,
+		&key.KeyHash,
+	)
+	if err != nil {
+		return nil, err
+	}
+	key.Scopes = make([]models.Permission, 0, len(scopes))
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, models.Permission(scope))
+	}
+	return key, nil
+}
+
+func scopeStrings(scopes []models.Permission) []string {
+	values := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		values = append(values, string(scope))
+	}
+	return values
+}
+
+func (r *PostgresAPIKeyRepository) GetAll(ctx context.Context, filter *models.APIKeyFilter) ([]*models.APIKey, error) {
+	query := `SELECT` + apiKeyColumns + `
+        FROM api_keys k
+    `
+	if filter == nil || !filter.IncludeRevoked {
+		query += " WHERE k.revoked_at IS NULL"
+	}
+	query += " ORDER BY k.name, k.api_key_id"
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *PostgresAPIKeyRepository) GetByID(ctx context.Context, id int) (*models.APIKey, error) {
+	query := `SELECT` + apiKeyColumns + `
+        FROM api_keys k
+        WHERE k.api_key_id = $1
+    `
+
+	key, err := scanAPIKey(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (r *PostgresAPIKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := `SELECT` + apiKeyColumns + `
+        FROM api_keys k
+        WHERE k.key_hash = $1
+          AND k.revoked_at IS NULL
+          AND (k.expires_at IS NULL OR k.expires_at > CURRENT_TIMESTAMP)
+    `
+
+	key, err := scanAPIKey(r.db.Pool.QueryRow(ctx, query, keyHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (r *PostgresAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	id, err := insertAPIKey(ctx, tx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func insertAPIKey(ctx context.Context, tx pgx.Tx, key *models.APIKey) (int, error) {
+	query := `
+        INSERT INTO api_keys (name, key_prefix, key_hash, scopes, created_by, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING api_key_id, created_at, updated_at
+    `
+
+	err := tx.QueryRow(ctx, query,
+		key.Name,
+		key.KeyPrefix,
+		key.KeyHash,
+		scopeStrings(key.Scopes),
+		key.CreatedBy,
+		key.ExpiresAt,
+	).Scan(&key.APIKeyID, &key.CreatedAt, &key.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return key.APIKeyID, nil
+}
+
+func (r *PostgresAPIKeyRepository) Rotate(ctx context.Context, id int, replacement *models.APIKey, graceUntil *time.Time) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var revokedAt *time.Time
+	err = tx.QueryRow(ctx,
+		`SELECT revoked_at FROM api_keys WHERE api_key_id = $1 FOR UPDATE`, id,
+	).Scan(&revokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, autherrors.ErrAPIKeyNotFound
+		}
+		return 0, err
+	}
+	if revokedAt != nil {
+		return 0, autherrors.ErrAPIKeyRevoked
+	}
+
+	newID, err := insertAPIKey(ctx, tx, replacement)
+	if err != nil {
+		return 0, err
+	}
+
+	if graceUntil == nil {
+		_, err = tx.Exec(ctx, `
+            UPDATE api_keys SET
+                replaced_by = $2,
+                revoked_at = CURRENT_TIMESTAMP
+            WHERE api_key_id = $1
+        `, id, newID)
+	} else {
+		_, err = tx.Exec(ctx, `
+            UPDATE api_keys SET
+                replaced_by = $2,
+                expires_at = LEAST(COALESCE(expires_at, $3), $3)
+            WHERE api_key_id = $1
+        `, id, newID, *graceUntil)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return newID, nil
+}
+
+func (r *PostgresAPIKeyRepository) Revoke(ctx context.Context, id int) error {
+	result, err := r.db.Pool.Exec(ctx,
+		`UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE api_key_id = $1 AND revoked_at IS NULL`,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return autherrors.ErrAPIKeyRevoked
+	}
+
+	return nil
+}
+
+func (r *PostgresAPIKeyRepository) RecordUse(ctx context.Context, id int, ipAddress string) error {
+	query := `
+        UPDATE api_keys SET
+            last_used_at = CURRENT_TIMESTAMP,
+            last_used_ip = $2
+        WHERE api_key_id = $1
+          AND (last_used_at IS NULL
+               OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '` + recordUseInterval + `'
+               OR last_used_ip IS DISTINCT FROM $2)
+    `
+
+	_, err := r.db.Pool.Exec(ctx, query, id, ipAddress)
+	return err
+}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+)
+
+type PostgresAuthorizationRepository struct {
+	db *db.Database
+}
+
+func NewPostgresAuthorizationRepository(database *db.Database) AuthorizationRepository {
+	return &PostgresAuthorizationRepository{
+		db: database,
+	}
+}
+
+func (r *PostgresAuthorizationRepository) GetAll(ctx context.Context, filter *models.AuthorizationFilter) ([]*models.Authorization, error) {
+	query := `
+        SELECT authorization_id, action, resource_id, details, requested_by,
+               authorized_by, authorizer_id, method, ip_address, created_at
+        FROM manager_authorizations
+    `
+	conditions := []string{}
+	params := []interface{}{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.Action != nil {
+			conditions = append(conditions, fmt.Sprintf("action = $%d", paramCount))
+			params = append(params, *filter.Action)
+			paramCount++
+		}
+
+		if filter.ResourceID != nil {
+			conditions = append(conditions, fmt.Sprintf("resource_id = $%d", paramCount))
+			params = append(params, *filter.ResourceID)
+			paramCount++
+		}
+
+		if filter.AuthorizedBy != nil {
+			conditions = append(conditions, fmt.Sprintf("LOWER(authorized_by) = LOWER($%d)", paramCount))
+			params = append(params, *filter.AuthorizedBy)
+			paramCount++
+		}
+
+		if filter.StartDate != nil {
+			conditions = append(conditions, fmt.Sprintf("created_at >= $%d", paramCount))
+			params = append(params, *filter.StartDate)
+			paramCount++
+		}
+
+		if filter.EndDate != nil {
+			conditions = append(conditions, fmt.Sprintf("created_at <= $%d", paramCount))
+			params = append(params, *filter.EndDate)
+			paramCount++
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY created_at DESC, authorization_id DESC"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var authorizations []*models.Authorization
+	for rows.Next() {
+		authorization := &models.Authorization{}
+		err := rows.Scan(
+			&authorization.AuthorizationID,
+			&authorization.Action,
+			&authorization.ResourceID,
+			&authorization.Details,
+			&authorization.RequestedBy,
+			&authorization.AuthorizedBy,
+			&authorization.AuthorizerID,
+			&authorization.Method,
+			&authorization.IPAddress,
+			&authorization.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		authorizations = append(authorizations, authorization)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return authorizations, nil
+}
+
+func (r *PostgresAuthorizationRepository) Create(ctx context.Context, authorization *models.Authorization) (int, error) {
+	query := `
+        INSERT INTO manager_authorizations (
+            action, resource_id, details, requested_by, authorized_by,
+            authorizer_id, method, ip_address
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING authorization_id, created_at
+    `
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		authorization.Action,
+		authorization.ResourceID,
+		authorization.Details,
+		authorization.RequestedBy,
+		authorization.AuthorizedBy,
+		authorization.AuthorizerID,
+		authorization.Method,
+		authorization.IPAddress,
+	).Scan(&authorization.AuthorizationID, &authorization.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return authorization.AuthorizationID, nil
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresSessionRepository struct {
+	db *db.Database
+}
+
+func NewPostgresSessionRepository(database *db.Database) SessionRepository {
+	return &PostgresSessionRepository{
+		db: database,
+	}
+}
+
+func (r *PostgresSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	query := `
+        INSERT INTO sessions (token_hash, user_id, ip_address, user_agent, expires_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING created_at
+    `
+
+	return r.db.Pool.QueryRow(ctx, query,
+		session.TokenHash,
+		session.UserID,
+		session.IPAddress,
+		session.UserAgent,
+		session.ExpiresAt,
+	).Scan(&session.CreatedAt)
+}
+
+func (r *PostgresSessionRepository) GetUser(ctx context.Context, tokenHash string) (*models.User, error) {
+	query := `SELECT` + userColumns + `
+        FROM sessions s
+        JOIN users u ON s.user_id = u.user_id
+        WHERE s.token_hash = $1
+          AND s.expires_at > CURRENT_TIMESTAMP
+          AND u.is_active
+    `
+
+	user, err := scanUser(r.db.Pool.QueryRow(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *PostgresSessionRepository) Delete(ctx context.Context, tokenHash string) error {
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM sessions WHERE token_hash = $1`, tokenHash)
+	return err
+}
+
+func (r *PostgresSessionRepository) DeleteByUser(ctx context.Context, userID int, keepHash string) error {
+	_, err := r.db.Pool.Exec(ctx,
+		`DELETE FROM sessions WHERE user_id = $1 AND token_hash <> $2`,
+		userID, keepHash,
+	)
+	return err
+}
+
+func (r *PostgresSessionRepository) DeleteExpired(ctx context.Context) error {
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM sessions WHERE expires_at <= CURRENT_TIMESTAMP`)
+	return err
+}
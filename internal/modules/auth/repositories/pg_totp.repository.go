@@ -0,0 +1,260 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	autherrors "github.com/hsrvms/fixparts/internal/modules/auth/errors"
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresTOTPRepository struct {
+	db *db.Database
+}
+
+func NewPostgresTOTPRepository(database *db.Database) TOTPRepository {
+	return &PostgresTOTPRepository{
+		db: database,
+	}
+}
+
+func (r *PostgresTOTPRepository) Get(ctx context.Context, userID int) (*models.TOTP, error) {
+	query := `
+        SELECT user_id, secret, confirmed_at, last_step, failed_attempts,
+               locked_until, created_at, updated_at
+        FROM user_totp
+        WHERE user_id = $1
+    `
+
+	totp := &models.TOTP{}
+	err := r.db.Pool.QueryRow(ctx, query, userID).Scan(
+		&totp.UserID,
+		&totp.Secret,
+		&totp.ConfirmedAt,
+		&totp.LastStep,
+		&totp.FailedAttempts,
+		&totp.LockedUntil,
+		&totp.CreatedAt,
+		&totp.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return totp, nil
+}
+
+// SavePending stores a new secret awaiting confirmation, replacing an
+// earlier pending one. A confirmed authenticator is left alone.
+func (r *PostgresTOTPRepository) SavePending(ctx context.Context, userID int, secret string) error {
+	query := `
+        INSERT INTO user_totp (user_id, secret)
+        VALUES ($1, $2)
+        ON CONFLICT (user_id) DO UPDATE SET
+            secret = EXCLUDED.secret,
+            last_step = NULL,
+            failed_attempts = 0,
+            locked_until = NULL
+        WHERE user_totp.confirmed_at IS NULL
+    `
+
+	result, err := r.db.Pool.Exec(ctx, query, userID, secret)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return autherrors.ErrTOTPAlreadyEnabled
+	}
+
+	return nil
+}
+
+// Confirm enables the pending authenticator, marking the step of the code
+// that confirmed it as used, and issues its recovery codes
+func (r *PostgresTOTPRepository) Confirm(ctx context.Context, userID int, step int64, codeHashes []string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+        UPDATE user_totp SET
+            confirmed_at = CURRENT_TIMESTAMP,
+            last_step = $2,
+            failed_attempts = 0
+        WHERE user_id = $1 AND confirmed_at IS NULL
+    `, userID, step)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return autherrors.ErrTOTPNotEnrolled
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseStep marks a time step as used and clears the failed attempts. It
+// returns false when the step, or a later one, was already used.
+func (r *PostgresTOTPRepository) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	query := `
+        UPDATE user_totp SET
+            last_step = $2,
+            failed_attempts = 0
+        WHERE user_id = $1
+          AND confirmed_at IS NOT NULL
+          AND (last_step IS NULL OR last_step < $2)
+    `
+
+	result, err := r.db.Pool.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// RecordFailure counts a wrong code. Reaching maxAttempts locks the
+// authenticator for the lockout and starts the count again.
+func (r *PostgresTOTPRepository) RecordFailure(ctx context.Context, userID int, maxAttempts int, lockout time.Duration) error {
+	query := `
+        UPDATE user_totp SET
+            failed_attempts = CASE
+                WHEN failed_attempts + 1 >= $2 THEN 0
+                ELSE failed_attempts + 1
+            END,
+            locked_until = CASE
+                WHEN failed_attempts + 1 >= $2 THEN CURRENT_TIMESTAMP + $3 * INTERVAL '1 second'
+                ELSE locked_until
+            END
+        WHERE user_id = $1
+    `
+
+	_, err := r.db.Pool.Exec(ctx, query, userID, maxAttempts, int(lockout.Seconds()))
+	return err
+}
+
+func (r *PostgresTOTPRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID int, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO totp_recovery_codes (user_id, code_hash) VALUES ($1, $2)`,
+			userID, codeHash,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HasRecoveryCode reports whether the user has the recovery code unused
+func (r *PostgresTOTPRepository) HasRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	var exists bool
+	err := r.db.Pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM totp_recovery_codes WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL)`,
+		userID, codeHash,
+	).Scan(&exists)
+	return exists, err
+}
+
+// UseRecoveryCode spends an unused recovery code and clears the failed
+// attempts. It returns false when no such code is left.
+func (r *PostgresTOTPRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+        UPDATE totp_recovery_codes SET used_at = CURRENT_TIMESTAMP
+        WHERE recovery_code_id = (
+            SELECT recovery_code_id FROM totp_recovery_codes
+            WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+            LIMIT 1
+        )
+    `, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE user_totp SET failed_attempts = 0 WHERE user_id = $1`, userID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *PostgresTOTPRepository) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := r.db.Pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM totp_recovery_codes WHERE user_id = $1 AND used_at IS NULL`,
+		userID,
+	).Scan(&count)
+	return count, err
+}
+
+// Delete removes the authenticator and its recovery codes
+func (r *PostgresTOTPRepository) Delete(ctx context.Context, userID int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return autherrors.ErrTOTPNotEnabled
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	autherrors "github.com/hsrvms/fixparts/internal/modules/auth/errors"
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+const userColumns = `
+            u.user_id, u.username, u.full_name, u.role, u.is_active,
+            u.last_login_at, u.created_at, u.updated_at, u.password_hash`
+
+type PostgresUserRepository struct {
+	db *db.Database
+}
+
+func NewPostgresUserRepository(database *db.Database) UserRepository {
+	return &PostgresUserRepository{
+		db: database,
+	}
+}
+
+func scanUser(row pgx.Row) (*models.User, error) {
+	user := &models.User{}
+	err := row.Scan(
+		&user.UserID,
+		&user.Username,
+		&user.FullName,
+		&user.Role,
+		&user.IsActive,
+		&user.LastLoginAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.PasswordHash,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *PostgresUserRepository) GetAll(ctx context.Context, filter *models.UserFilter) ([]*models.User, error) {
+	query := `SELECT` + userColumns + `
+        FROM users u
+    `
+	conditions := []string{}
+	params := []interface{}{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.Role != nil {
+			conditions = append(conditions, fmt.Sprintf("u.role = $%d", paramCount))
+			params = append(params, *filter.Role)
+			paramCount++
+		}
+
+		if filter.IsActive != nil {
+			conditions = append(conditions, fmt.Sprintf("u.is_active = $%d", paramCount))
+			params = append(params, *filter.IsActive)
+			paramCount++
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY u.username"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *PostgresUserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	query := `SELECT` + userColumns + `
+        FROM users u
+        WHERE u.user_id = $1
+    `
+
+	user, err := scanUser(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	query := `SELECT` + userColumns + `
+        FROM users u
+        WHERE LOWER(u.username) = LOWER($1)
+    `
+
+	user, err := scanUser(r.db.Pool.QueryRow(ctx, query, username))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *PostgresUserRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM users`).Scan(&count)
+	return count, err
+}
+
+func (r *PostgresUserRepository) CountActiveAdmins(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.Pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM users WHERE role = $1 AND is_active`,
+		models.RoleAdmin,
+	).Scan(&count)
+	return count, err
+}
+
+func (r *PostgresUserRepository) Create(ctx context.Context, user *models.User) (int, error) {
+	query := `
+        INSERT INTO users (username, full_name, password_hash, role, is_active)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING user_id, created_at, updated_at
+    `
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		user.Username,
+		user.FullName,
+		user.PasswordHash,
+		user.Role,
+		user.IsActive,
+	).Scan(&user.UserID, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return user.UserID, nil
+}
+
+func (r *PostgresUserRepository) Update(ctx context.Context, user *models.User) error {
+	query := `
+        UPDATE users SET
+            full_name = $1,
+            role = $2,
+            is_active = $3
+        WHERE user_id = $4
+    `
+
+	result, err := r.db.Pool.Exec(ctx, query,
+		user.FullName,
+		user.Role,
+		user.IsActive,
+		user.UserID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return autherrors.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresUserRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	result, err := r.db.Pool.Exec(ctx,
+		`UPDATE users SET password_hash = $1 WHERE user_id = $2`,
+		passwordHash, id,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return autherrors.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresUserRepository) RecordLogin(ctx context.Context, id int) error {
+	_, err := r.db.Pool.Exec(ctx,
+		`UPDATE users SET last_login_at = CURRENT_TIMESTAMP WHERE user_id = $1`,
+		id,
+	)
+	return err
+}
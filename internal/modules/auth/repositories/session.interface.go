@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	// GetUser returns the active user of an unexpired session, or nil
+	GetUser(ctx context.Context, tokenHash string) (*models.User, error)
+	Delete(ctx context.Context, tokenHash string) error
+	// DeleteByUser ends every session of the user except keepHash
+	DeleteByUser(ctx context.Context, userID int, keepHash string) error
+	DeleteExpired(ctx context.Context) error
+}
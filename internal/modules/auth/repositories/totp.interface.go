@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+)
+
+type TOTPRepository interface {
+	Get(ctx context.Context, userID int) (*models.TOTP, error)
+	SavePending(ctx context.Context, userID int, secret string) error
+	Confirm(ctx context.Context, userID int, step int64, codeHashes []string) error
+	UseStep(ctx context.Context, userID int, step int64) (bool, error)
+	RecordFailure(ctx context.Context, userID int, maxAttempts int, lockout time.Duration) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error
+	HasRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID int) (int, error)
+	Delete(ctx context.Context, userID int) error
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+)
+
+type UserRepository interface {
+	GetAll(ctx context.Context, filter *models.UserFilter) ([]*models.User, error)
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	Count(ctx context.Context) (int, error)
+	CountActiveAdmins(ctx context.Context) (int, error)
+	Create(ctx context.Context, user *models.User) (int, error)
+	Update(ctx context.Context, user *models.User) error
+	UpdatePassword(ctx context.Context, id int, passwordHash string) error
+	RecordLogin(ctx context.Context, id int) error
+}
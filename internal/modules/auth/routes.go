@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"log"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/handlers"
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/auth/repositories"
+	"github.com/hsrvms/fixparts/internal/modules/auth/services"
+	"github.com/hsrvms/fixparts/pkg/config"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRoutes adds the login pages, user management and manager TOTP
+// enrolment, and installs the session and approval middleware every other
+// route relies on. It must run before the routes of the other modules.
+func RegisterRoutes(e *echo.Echo, api *echo.Group, database *db.Database, cfg *config.AuthConfig) {
+	userRepo := repositories.NewPostgresUserRepository(database)
+	sessionRepo := repositories.NewPostgresSessionRepository(database)
+	authService := services.NewAuthService(userRepo, sessionRepo, cfg.SessionTTL)
+	apiKeyService := services.NewAPIKeyService(repositories.NewPostgresAPIKeyRepository(database))
+	totpService := services.NewTOTPService(
+		userRepo,
+		repositories.NewPostgresTOTPRepository(database),
+		repositories.NewPostgresAuthorizationRepository(database),
+	)
+
+	if err := authService.EnsureAdmin(context.Background(), cfg.AdminUsername, cfg.AdminPassword); err != nil {
+		log.Printf("Failed to create the first admin: %v", err)
+	}
+
+	e.Use(authMiddleware.Authenticate(authService, apiKeyService))
+	// Cost and margin fields are hidden from, and cannot be written by,
+	// counter level users and keys
+	e.JSONSerializer = authMiddleware.Serializer{}
+	e.Binder = &authMiddleware.Binder{}
+	// Deletions and price decreases ask for a manager's TOTP code
+	e.Use(authMiddleware.Approvals(totpService))
+
+	authHandler := handlers.NewAuthHandler(authService, cfg.SessionTTL, cfg.SecureCookie)
+	e.GET("/login", authHandler.LoginPage)
+	e.POST("/login", authHandler.Login)
+	e.POST("/logout", authHandler.Logout)
+
+	// Every signed in user may see and change their own account
+	account := api.Group("/auth", authMiddleware.RequireUser())
+	account.GET("/me", authHandler.GetCurrentUser)
+	account.PUT("/password", authHandler.ChangePassword)
+
+	totpHandler := handlers.NewTOTPHandler(totpService)
+	e.GET("/account/totp", totpHandler.Page)
+	account.GET("/totp", totpHandler.GetStatus)
+	account.POST("/totp", totpHandler.Enroll)
+	account.GET("/totp/qr", totpHandler.GetQRCode)
+	account.POST("/totp/confirm", totpHandler.Confirm)
+	account.POST("/totp/recovery-codes", totpHandler.RegenerateRecoveryCodes)
+
+	userService := services.NewUserService(userRepo, sessionRepo)
+	userHandler := handlers.NewUserHandler(userService)
+	users := api.Group("/users", authMiddleware.Require(models.PermUsersManage))
+	users.GET("", userHandler.GetUsers)
+	users.GET("/:id", userHandler.GetUserByID)
+	users.POST("", userHandler.CreateUser)
+	users.PUT("/:id", userHandler.UpdateUser)
+	users.PUT("/:id/password", userHandler.ResetPassword)
+	users.DELETE("/:id/totp", totpHandler.ResetTOTP)
+
+	authorizations := api.Group("/authorizations", authMiddleware.Require(models.PermAuthorize))
+	authorizations.GET("", totpHandler.GetAuthorizations)
+
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	apiKeys := api.Group("/api-keys", authMiddleware.Require(models.PermAPIKeysManage))
+	apiKeys.GET("", apiKeyHandler.GetAPIKeys)
+	apiKeys.GET("/:id", apiKeyHandler.GetAPIKeyByID)
+	apiKeys.POST("", apiKeyHandler.CreateAPIKey)
+	apiKeys.POST("/:id/rotate", apiKeyHandler.RotateAPIKey)
+	apiKeys.POST("/:id/revoke", apiKeyHandler.RevokeAPIKey)
+}
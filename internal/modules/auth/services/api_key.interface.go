@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+)
+
+type APIKeyService interface {
+	GetAll(ctx context.Context, filter *models.APIKeyFilter) ([]*models.APIKey, error)
+	GetByID(ctx context.Context, id int) (*models.APIKey, error)
+	// Create issues a key; the returned key holds the secret this one time
+	Create(ctx context.Context, key *models.APIKey, createdBy *string) (*models.APIKey, error)
+	// Rotate issues a replacement with the same name, scopes and expiry
+	Rotate(ctx context.Context, id int, rotation *models.APIKeyRotation, createdBy *string) (*models.APIKey, error)
+	Revoke(ctx context.Context, id int) error
+	// Authenticate returns the active key matching the secret, or nil, and
+	// records where it was used from
+	Authenticate(ctx context.Context, secret, ipAddress string) (*models.APIKey, error)
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	autherrors "github.com/hsrvms/fixparts/internal/modules/auth/errors"
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/auth/repositories"
+)
+
+// keyPrefixLength is how much of a key is kept readable to tell keys apart
+const keyPrefixLength = 12
+
+type apiKeyService struct {
+	repo repositories.APIKeyRepository
+}
+
+func NewAPIKeyService(repo repositories.APIKeyRepository) APIKeyService {
+	return &apiKeyService{
+		repo: repo,
+	}
+}
+
+func (s *apiKeyService) GetAll(ctx context.Context, filter *models.APIKeyFilter) ([]*models.APIKey, error) {
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *apiKeyService) GetByID(ctx context.Context, id int) (*models.APIKey, error) {
+	if id <= 0 {
+		return nil, autherrors.ErrInvalidAPIKeyID
+	}
+
+	key, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, autherrors.ErrAPIKeyNotFound
+	}
+
+	return key, nil
+}
+
+func (s *apiKeyService) Create(ctx context.Context, key *models.APIKey, createdBy *string) (*models.APIKey, error) {
+	key.Name = strings.TrimSpace(key.Name)
+	if key.Name == "" || len(key.Name) > models.MaxAPIKeyNameLength {
+		return nil, autherrors.ErrInvalidAPIKeyName
+	}
+
+	scopes, err := normalizeScopes(key.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		return nil, autherrors.ErrInvalidExpiry
+	}
+
+	issued := &models.APIKey{
+		Name:      key.Name,
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+		ExpiresAt: key.ExpiresAt,
+	}
+	if err := issueSecret(issued); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.Create(ctx, issued); err != nil {
+		return nil, err
+	}
+
+	return issued, nil
+}
+
+func (s *apiKeyService) Rotate(ctx context.Context, id int, rotation *models.APIKeyRotation, createdBy *string) (*models.APIKey, error) {
+	if rotation.GracePeriodHours < 0 {
+		return nil, autherrors.ErrInvalidGracePeriod
+	}
+
+	existing, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.RevokedAt != nil {
+		return nil, autherrors.ErrAPIKeyRevoked
+	}
+
+	replacement := &models.APIKey{
+		Name:      existing.Name,
+		Scopes:    existing.Scopes,
+		CreatedBy: createdBy,
+		ExpiresAt: existing.ExpiresAt,
+	}
+	if err := issueSecret(replacement); err != nil {
+		return nil, err
+	}
+
+	var graceUntil *time.Time
+	if rotation.GracePeriodHours > 0 {
+		until := time.Now().Add(time.Duration(rotation.GracePeriodHours) * time.Hour)
+		graceUntil = &until
+	}
+
+	if _, err := s.repo.Rotate(ctx, id, replacement, graceUntil); err != nil {
+		return nil, err
+	}
+
+	return replacement, nil
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, id int) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Revoke(ctx, id)
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, secret, ipAddress string) (*models.APIKey, error) {
+	if !strings.HasPrefix(secret, models.APIKeyPrefix) {
+		return nil, nil
+	}
+
+	key, err := s.repo.GetActiveByHash(ctx, hashToken(secret))
+	if err != nil || key == nil {
+		return nil, err
+	}
+
+	if err := s.repo.RecordUse(ctx, key.APIKeyID, ipAddress); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// issueSecret generates the secret of a new key and the prefix and hash
+// stored for it
+func issueSecret(key *models.APIKey) error {
+	token, _, err := newToken()
+	if err != nil {
+		return err
+	}
+	key.Key = models.APIKeyPrefix + token
+	key.KeyPrefix = key.Key[:keyPrefixLength]
+	key.KeyHash = hashToken(key.Key)
+	return nil
+}
+
+func normalizeScopes(scopes []models.Permission) ([]models.Permission, error) {
+	if len(scopes) == 0 {
+		return nil, autherrors.ErrNoScopes
+	}
+
+	seen := make(map[models.Permission]bool, len(scopes))
+	normalized := make([]models.Permission, 0, len(scopes))
+	for _, scope := range scopes {
+		if !models.IsValidScope(scope) {
+			return nil, autherrors.ErrInvalidScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+
+	return normalized, nil
+}
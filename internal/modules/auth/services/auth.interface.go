@@ -0,0 +1,19 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+)
+
+type AuthService interface {
+	// Login checks the credentials and opens a session, returning its token
+	Login(ctx context.Context, request *models.LoginRequest, ipAddress, userAgent string) (string, *models.User, error)
+	Logout(ctx context.Context, token string) error
+	// Authenticate returns the user of a session token, or nil
+	Authenticate(ctx context.Context, token string) (*models.User, error)
+	// ChangePassword sets the user's own password and ends their other sessions
+	ChangePassword(ctx context.Context, user *models.User, token string, change *models.PasswordChange) error
+	// EnsureAdmin creates the first admin while there are no users
+	EnsureAdmin(ctx context.Context, username, password string) error
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	autherrors "github.com/hsrvms/fixparts/internal/modules/auth/errors"
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/auth/repositories"
+)
+
+type authService struct {
+	users      repositories.UserRepository
+	sessions   repositories.SessionRepository
+	sessionTTL time.Duration
+	// dummyHash is compared against when the username is unknown so a
+	// failed login takes as long whether or not the user exists
+	dummyHash string
+}
+
+func NewAuthService(
+	users repositories.UserRepository,
+	sessions repositories.SessionRepository,
+	sessionTTL time.Duration,
+) AuthService {
+	dummyHash, _ := hashPassword("not-a-real-password")
+	return &authService{
+		users:      users,
+		sessions:   sessions,
+		sessionTTL: sessionTTL,
+		dummyHash:  dummyHash,
+	}
+}
+
+func (s *authService) Login(ctx context.Context, request *models.LoginRequest, ipAddress, userAgent string) (string, *models.User, error) {
+	username := strings.TrimSpace(request.Username)
+	if username == "" || request.Password == "" {
+		return "", nil, autherrors.ErrInvalidCredentials
+	}
+
+	user, err := s.users.GetByUsername(ctx, username)
+	if err != nil {
+		return "", nil, err
+	}
+	if user == nil {
+		checkPassword(s.dummyHash, request.Password)
+		return "", nil, autherrors.ErrInvalidCredentials
+	}
+	if !checkPassword(user.PasswordHash, request.Password) || !user.IsActive {
+		return "", nil, autherrors.ErrInvalidCredentials
+	}
+
+	if err := s.sessions.DeleteExpired(ctx); err != nil {
+		return "", nil, err
+	}
+
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	session := &models.Session{
+		TokenHash: tokenHash,
+		UserID:    user.UserID,
+		ExpiresAt: time.Now().Add(s.sessionTTL),
+	}
+	if ipAddress != "" {
+		session.IPAddress = &ipAddress
+	}
+	if userAgent != "" {
+		session.UserAgent = &userAgent
+	}
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return "", nil, err
+	}
+
+	if err := s.users.RecordLogin(ctx, user.UserID); err != nil {
+		return "", nil, err
+	}
+
+	return token, user, nil
+}
+
+func (s *authService) Logout(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	return s.sessions.Delete(ctx, hashToken(token))
+}
+
+func (s *authService) Authenticate(ctx context.Context, token string) (*models.User, error) {
+	if token == "" {
+		return nil, nil
+	}
+	return s.sessions.GetUser(ctx, hashToken(token))
+}
+
+func (s *authService) ChangePassword(ctx context.Context, user *models.User, token string, change *models.PasswordChange) error {
+	if !checkPassword(user.PasswordHash, change.CurrentPassword) {
+		return autherrors.ErrWrongPassword
+	}
+
+	passwordHash, err := hashPassword(change.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.users.UpdatePassword(ctx, user.UserID, passwordHash); err != nil {
+		return err
+	}
+
+	return s.sessions.DeleteByUser(ctx, user.UserID, hashToken(token))
+}
+
+func (s *authService) EnsureAdmin(ctx context.Context, username, password string) error {
+	count, err := s.users.Count(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if password == "" {
+		return autherrors.ErrNoUsers
+	}
+
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.users.Create(ctx, &models.User{
+		Username:     username,
+		FullName:     username,
+		PasswordHash: passwordHash,
+		Role:         models.RoleAdmin,
+		IsActive:     true,
+	})
+	return err
+}
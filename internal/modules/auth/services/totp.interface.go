@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+)
+
+type TOTPService interface {
+	Status(ctx context.Context, user *models.User) (*models.TOTPStatus, error)
+	// Enroll starts enrolment with a new secret, replacing a pending one
+	Enroll(ctx context.Context, user *models.User) (*models.TOTPEnrollment, error)
+	// Enrollment returns the pending enrolment
+	Enrollment(ctx context.Context, user *models.User) (*models.TOTPEnrollment, error)
+	QRCode(ctx context.Context, user *models.User) ([]byte, error)
+	// Confirm enables the pending authenticator with its first code and
+	// issues the recovery codes
+	Confirm(ctx context.Context, user *models.User, code string) (*models.RecoveryCodes, error)
+	RegenerateRecoveryCodes(ctx context.Context, user *models.User, code string) (*models.RecoveryCodes, error)
+	// Reset removes a user's authenticator so they can enrol again
+	Reset(ctx context.Context, userID int) error
+	// Verify checks the TOTP or recovery code of the approving manager
+	// without spending it and returns the authorization to record once the
+	// action is done
+	Verify(ctx context.Context, username, code string) (*models.Authorization, error)
+	// Consume spends the code a verified authorization was given with, so
+	// it cannot approve anything else
+	Consume(ctx context.Context, authorization *models.Authorization) error
+	RecordAuthorization(ctx context.Context, authorization *models.Authorization) error
+	GetAuthorizations(ctx context.Context, filter *models.AuthorizationFilter) ([]*models.Authorization, error)
+}
@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	autherrors "github.com/hsrvms/fixparts/internal/modules/auth/errors"
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/auth/repositories"
+)
+
+// totpQRCodePath serves the QR code of the pending enrolment
+const totpQRCodePath = "/api/auth/totp/qr"
+
+type totpService struct {
+	users          repositories.UserRepository
+	totps          repositories.TOTPRepository
+	authorizations repositories.AuthorizationRepository
+}
+
+func NewTOTPService(
+	users repositories.UserRepository,
+	totps repositories.TOTPRepository,
+	authorizations repositories.AuthorizationRepository,
+) TOTPService {
+	return &totpService{
+		users:          users,
+		totps:          totps,
+		authorizations: authorizations,
+	}
+}
+
+func (s *totpService) Status(ctx context.Context, user *models.User) (*models.TOTPStatus, error) {
+	totp, err := s.totps.Get(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.TOTPStatus{}
+	if totp == nil {
+		return status, nil
+	}
+
+	status.Enabled = totp.Enabled()
+	status.Pending = !totp.Enabled()
+	status.ConfirmedAt = totp.ConfirmedAt
+	if status.Enabled {
+		status.RecoveryCodesLeft, err = s.totps.CountRecoveryCodes(ctx, user.UserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
+
+func (s *totpService) Enroll(ctx context.Context, user *models.User) (*models.TOTPEnrollment, error) {
+	if !user.Role.Can(models.PermAuthorize) {
+		return nil, autherrors.ErrTOTPNotAllowed
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.totps.SavePending(ctx, user.UserID, secret); err != nil {
+		return nil, err
+	}
+
+	return enrollment(user, secret), nil
+}
+
+func (s *totpService) Enrollment(ctx context.Context, user *models.User) (*models.TOTPEnrollment, error) {
+	totp, err := s.pending(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return enrollment(user, totp.Secret), nil
+}
+
+func (s *totpService) QRCode(ctx context.Context, user *models.User) ([]byte, error) {
+	totp, err := s.pending(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return qrCodeImage(totpURL(user.Username, totp.Secret))
+}
+
+func (s *totpService) Confirm(ctx context.Context, user *models.User, code string) (*models.RecoveryCodes, error) {
+	totp, err := s.pending(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLock(totp); err != nil {
+		return nil, err
+	}
+
+	step, ok := matchTOTP(totp.Secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return nil, s.fail(ctx, totp)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.totps.Confirm(ctx, user.UserID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return &models.RecoveryCodes{Codes: codes}, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes. It takes a code from
+// the authenticator only, so a stolen recovery code cannot renew the set.
+func (s *totpService) RegenerateRecoveryCodes(ctx context.Context, user *models.User, code string) (*models.RecoveryCodes, error) {
+	totp, err := s.enabled(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.verify(ctx, totp, code, false); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.totps.ReplaceRecoveryCodes(ctx, user.UserID, hashes); err != nil {
+		return nil, err
+	}
+
+	return &models.RecoveryCodes{Codes: codes}, nil
+}
+
+func (s *totpService) Reset(ctx context.Context, userID int) error {
+	if userID <= 0 {
+		return autherrors.ErrInvalidUserID
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return autherrors.ErrUserNotFound
+	}
+
+	return s.totps.Delete(ctx, userID)
+}
+
+func (s *totpService) Verify(ctx context.Context, username, code string) (*models.Authorization, error) {
+	manager, err := s.users.GetByUsername(ctx, strings.TrimSpace(username))
+	if err != nil {
+		return nil, err
+	}
+	if manager == nil || !manager.IsActive || !manager.Role.Can(models.PermAuthorize) {
+		return nil, autherrors.ErrInvalidApprover
+	}
+
+	totp, err := s.totps.Get(ctx, manager.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !totp.Enabled() {
+		return nil, autherrors.ErrInvalidApprover
+	}
+
+	authorization, err := s.check(ctx, totp, code, true)
+	if err != nil {
+		return nil, err
+	}
+
+	authorization.AuthorizedBy = manager.Username
+	authorization.AuthorizerID = &manager.UserID
+	return authorization, nil
+}
+
+func (s *totpService) Consume(ctx context.Context, authorization *models.Authorization) error {
+	if authorization.AuthorizerID == nil {
+		return autherrors.ErrInvalidApprover
+	}
+	return s.spend(ctx, *authorization.AuthorizerID, authorization)
+}
+
+func (s *totpService) RecordAuthorization(ctx context.Context, authorization *models.Authorization) error {
+	_, err := s.authorizations.Create(ctx, authorization)
+	return err
+}
+
+func (s *totpService) GetAuthorizations(ctx context.Context, filter *models.AuthorizationFilter) ([]*models.Authorization, error) {
+	return s.authorizations.GetAll(ctx, filter)
+}
+
+func (s *totpService) pending(ctx context.Context, user *models.User) (*models.TOTP, error) {
+	totp, err := s.totps.Get(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if totp == nil {
+		return nil, autherrors.ErrTOTPNotEnrolled
+	}
+	if totp.Enabled() {
+		return nil, autherrors.ErrTOTPAlreadyEnabled
+	}
+	return totp, nil
+}
+
+func (s *totpService) enabled(ctx context.Context, userID int) (*models.TOTP, error) {
+	totp, err := s.totps.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !totp.Enabled() {
+		return nil, autherrors.ErrTOTPNotEnabled
+	}
+	return totp, nil
+}
+
+// verify checks a code against an enabled authenticator and spends it
+func (s *totpService) verify(ctx context.Context, totp *models.TOTP, code string, allowRecovery bool) (models.AuthorizationMethod, error) {
+	authorization, err := s.check(ctx, totp, code, allowRecovery)
+	if err != nil {
+		return "", err
+	}
+	if err := s.spend(ctx, totp.UserID, authorization); err != nil {
+		return "", err
+	}
+	return authorization.Method, nil
+}
+
+// check matches a code against an enabled authenticator without spending
+// it. A six digit code is taken as a TOTP code, which cannot be used twice;
+// anything else as a recovery code when those are allowed.
+func (s *totpService) check(ctx context.Context, totp *models.TOTP, code string, allowRecovery bool) (*models.Authorization, error) {
+	if err := checkLock(totp); err != nil {
+		return nil, err
+	}
+
+	code = strings.TrimSpace(code)
+	if isTOTPCode(code) {
+		if step, ok := matchTOTP(totp.Secret, code, time.Now()); ok && stepUnused(totp, step) {
+			return &models.Authorization{Method: models.MethodTOTP, Step: &step}, nil
+		}
+	} else if allowRecovery && code != "" {
+		codeHash := hashToken(normalizeRecoveryCode(code))
+		unused, err := s.totps.HasRecoveryCode(ctx, totp.UserID, codeHash)
+		if err != nil {
+			return nil, err
+		}
+		if unused {
+			return &models.Authorization{Method: models.MethodRecoveryCode, RecoveryCodeHash: codeHash}, nil
+		}
+	}
+
+	return nil, s.fail(ctx, totp)
+}
+
+// spend uses up the step or recovery code a code was checked against. One
+// spent by a concurrent request in the meantime counts as a wrong code.
+func (s *totpService) spend(ctx context.Context, userID int, authorization *models.Authorization) error {
+	var used bool
+	var err error
+	switch authorization.Method {
+	case models.MethodTOTP:
+		if authorization.Step != nil {
+			used, err = s.totps.UseStep(ctx, userID, *authorization.Step)
+		}
+	case models.MethodRecoveryCode:
+		used, err = s.totps.UseRecoveryCode(ctx, userID, authorization.RecoveryCodeHash)
+	}
+	if err != nil {
+		return err
+	}
+	if !used {
+		return autherrors.ErrInvalidTOTPCode
+	}
+	return nil
+}
+
+// fail counts a wrong code and reports it
+func (s *totpService) fail(ctx context.Context, totp *models.TOTP) error {
+	if err := s.totps.RecordFailure(ctx, totp.UserID, models.MaxTOTPAttempts, models.TOTPLockout); err != nil {
+		return err
+	}
+	return autherrors.ErrInvalidTOTPCode
+}
+
+func checkLock(totp *models.TOTP) error {
+	if totp.LockedUntil != nil && totp.LockedUntil.After(time.Now()) {
+		return autherrors.ErrTOTPLocked
+	}
+	return nil
+}
+
+func enrollment(user *models.User, secret string) *models.TOTPEnrollment {
+	return &models.TOTPEnrollment{
+		Secret:    secret,
+		URL:       totpURL(user.Username, secret),
+		QRCodeURL: totpQRCodePath,
+	}
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	autherrors "github.com/hsrvms/fixparts/internal/modules/auth/errors"
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/auth/repositories"
+)
+
+// rfcSecret is the SHA-1 seed of the RFC 6238 test vectors
+var rfcSecret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// The RFC lists eight digit codes; six digit codes are their last six
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := totpCode(rfcSecret, totpStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("totpCode(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("totpCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestMatchTOTPSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := totpStep(now)
+
+	tests := []struct {
+		name   string
+		offset int64
+		ok     bool
+	}{
+		{"two steps behind", -2, false},
+		{"one step behind", -1, true},
+		{"current step", 0, true},
+		{"one step ahead", 1, true},
+		{"two steps ahead", 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := totpCode(rfcSecret, current+tt.offset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			step, ok := matchTOTP(rfcSecret, code, now)
+			if ok != tt.ok {
+				t.Fatalf("matchTOTP ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && step != current+tt.offset {
+				t.Errorf("matchTOTP step = %d, want %d", step, current+tt.offset)
+			}
+		})
+	}
+}
+
+func TestMatchTOTPRejectsMalformedCodes(t *testing.T) {
+	for _, code := range []string{"", "12345", "1234567", "12a456"} {
+		if _, ok := matchTOTP(rfcSecret, code, time.Unix(59, 0)); ok {
+			t.Errorf("matchTOTP(%q) accepted", code)
+		}
+	}
+}
+
+func TestStepUnused(t *testing.T) {
+	last := int64(100)
+
+	tests := []struct {
+		name     string
+		lastStep *int64
+		step     int64
+		want     bool
+	}{
+		{"nothing used yet", nil, 100, true},
+		{"later step", &last, 101, true},
+		{"same step", &last, 100, false},
+		{"earlier step", &last, 99, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			totp := &models.TOTP{LastStep: tt.lastStep}
+			if got := stepUnused(totp, tt.step); got != tt.want {
+				t.Errorf("stepUnused = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeTOTPRepository keeps the last used step the way the Postgres
+// repository does
+type fakeTOTPRepository struct {
+	repositories.TOTPRepository
+	totp     *models.TOTP
+	failures int
+}
+
+func (r *fakeTOTPRepository) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	if !stepUnused(r.totp, step) {
+		return false, nil
+	}
+	r.totp.LastStep = &step
+	return true, nil
+}
+
+func (r *fakeTOTPRepository) RecordFailure(ctx context.Context, userID int, maxAttempts int, lockout time.Duration) error {
+	r.failures++
+	return nil
+}
+
+func TestVerifySpendsStepOnce(t *testing.T) {
+	ctx := context.Background()
+	totp := &models.TOTP{UserID: 1, Secret: rfcSecret}
+	repo := &fakeTOTPRepository{totp: totp}
+	s := &totpService{totps: repo}
+
+	code, err := totpCode(rfcSecret, totpStep(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	method, err := s.verify(ctx, totp, code, false)
+	if err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if method != models.MethodTOTP {
+		t.Errorf("method = %s, want %s", method, models.MethodTOTP)
+	}
+
+	if _, err := s.verify(ctx, totp, code, false); !errors.Is(err, autherrors.ErrInvalidTOTPCode) {
+		t.Fatalf("second use: err = %v, want %v", err, autherrors.ErrInvalidTOTPCode)
+	}
+	if repo.failures != 1 {
+		t.Errorf("failures = %d, want 1", repo.failures)
+	}
+}
+
+func TestSpendRejectsStepSpentAfterCheck(t *testing.T) {
+	ctx := context.Background()
+	totp := &models.TOTP{UserID: 1, Secret: rfcSecret}
+	s := &totpService{totps: &fakeTOTPRepository{totp: totp}}
+
+	code, err := totpCode(rfcSecret, totpStep(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two requests check the same code before either spends it
+	first, err := s.check(ctx, totp, code, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.check(ctx, totp, code, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.spend(ctx, totp.UserID, first); err != nil {
+		t.Fatalf("first spend: %v", err)
+	}
+	if err := s.spend(ctx, totp.UserID, second); !errors.Is(err, autherrors.ErrInvalidTOTPCode) {
+		t.Fatalf("second spend: err = %v, want %v", err, autherrors.ErrInvalidTOTPCode)
+	}
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+)
+
+type UserService interface {
+	GetAll(ctx context.Context, filter *models.UserFilter) ([]*models.User, error)
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	Create(ctx context.Context, user *models.User) (int, error)
+	Update(ctx context.Context, user *models.User) error
+	ResetPassword(ctx context.Context, id int, reset *models.PasswordReset) error
+}
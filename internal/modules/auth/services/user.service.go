@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	autherrors "github.com/hsrvms/fixparts/internal/modules/auth/errors"
+	"github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/auth/repositories"
+)
+
+type userService struct {
+	users    repositories.UserRepository
+	sessions repositories.SessionRepository
+}
+
+func NewUserService(users repositories.UserRepository, sessions repositories.SessionRepository) UserService {
+	return &userService{
+		users:    users,
+		sessions: sessions,
+	}
+}
+
+func (s *userService) GetAll(ctx context.Context, filter *models.UserFilter) ([]*models.User, error) {
+	return s.users.GetAll(ctx, filter)
+}
+
+func (s *userService) GetByID(ctx context.Context, id int) (*models.User, error) {
+	if id <= 0 {
+		return nil, autherrors.ErrInvalidUserID
+	}
+
+	user, err := s.users.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, autherrors.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+func (s *userService) Create(ctx context.Context, user *models.User) (int, error) {
+	user.Username = strings.TrimSpace(user.Username)
+	if user.Username == "" || strings.ContainsAny(user.Username, " \t\n") || len(user.Username) > 100 {
+		return 0, autherrors.ErrInvalidUsername
+	}
+	if err := s.validateUser(user); err != nil {
+		return 0, err
+	}
+
+	existing, err := s.users.GetByUsername(ctx, user.Username)
+	if err != nil {
+		return 0, err
+	}
+	if existing != nil {
+		return 0, autherrors.ErrDuplicateUsername
+	}
+
+	passwordHash, err := hashPassword(user.Password)
+	if err != nil {
+		return 0, err
+	}
+	user.PasswordHash = passwordHash
+	user.Password = ""
+	user.IsActive = true
+
+	return s.users.Create(ctx, user)
+}
+
+// Update changes the name, role and active flag of a user. The username
+// stays as it is recorded on the documents the user handled.
+func (s *userService) Update(ctx context.Context, user *models.User) error {
+	if err := s.validateUser(user); err != nil {
+		return err
+	}
+
+	existing, err := s.GetByID(ctx, user.UserID)
+	if err != nil {
+		return err
+	}
+
+	stillAdmin := user.Role == models.RoleAdmin && user.IsActive
+	if existing.Role == models.RoleAdmin && existing.IsActive && !stillAdmin {
+		admins, err := s.users.CountActiveAdmins(ctx)
+		if err != nil {
+			return err
+		}
+		if admins <= 1 {
+			return autherrors.ErrLastAdmin
+		}
+	}
+
+	user.Username = existing.Username
+	if err := s.users.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if !user.IsActive {
+		return s.sessions.DeleteByUser(ctx, user.UserID, "")
+	}
+	return nil
+}
+
+// ResetPassword sets a new password for a user and signs them out everywhere
+func (s *userService) ResetPassword(ctx context.Context, id int, reset *models.PasswordReset) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	passwordHash, err := hashPassword(reset.Password)
+	if err != nil {
+		return err
+	}
+
+	if err := s.users.UpdatePassword(ctx, id, passwordHash); err != nil {
+		return err
+	}
+
+	return s.sessions.DeleteByUser(ctx, id, "")
+}
+
+func (s *userService) validateUser(user *models.User) error {
+	user.FullName = strings.TrimSpace(user.FullName)
+	if user.FullName == "" {
+		return autherrors.ErrInvalidFullName
+	}
+	if !models.IsValidRole(user.Role) {
+		return autherrors.ErrInvalidRole
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package customerErrors
+
+import "errors"
+
+var (
+	ErrCustomerNotFound   = errors.New("customer not found")
+	ErrInvalidCustomerID  = errors.New("invalid customer ID")
+	ErrCustomerNameNeeded = errors.New("customer name is required")
+	ErrInvalidEmail       = errors.New("invalid customer email format")
+	ErrDuplicateCustomer  = errors.New("a customer with this phone or email already exists")
+	ErrNothingToMerge     = errors.New("at least one other customer must be given to merge")
+	ErrMergeIntoSelf      = errors.New("a customer cannot be merged into itself")
+	ErrVehicleNotFound    = errors.New("customer vehicle not found")
+	ErrInvalidVehicleID   = errors.New("invalid vehicle ID")
+	ErrInvalidSubmodelID  = errors.New("invalid submodel ID")
+	ErrSubmodelNotFound   = errors.New("submodel not found")
+	ErrInvalidYear        = errors.New("year is outside the production years of the submodel")
+	ErrInvalidVIN         = errors.New("VIN must be 17 characters without I, O or Q")
+)
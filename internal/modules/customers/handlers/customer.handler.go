@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	customerErrors "github.com/hsrvms/fixparts/internal/modules/customers/errors"
+	"github.com/hsrvms/fixparts/internal/modules/customers/models"
+	"github.com/hsrvms/fixparts/internal/modules/customers/services"
+	"github.com/labstack/echo/v4"
+)
+
+type CustomerHandler struct {
+	service services.CustomerService
+}
+
+func NewCustomerHandler(service services.CustomerService) *CustomerHandler {
+	return &CustomerHandler{
+		service: service,
+	}
+}
+
+// GetCustomers handles searching customers by name, phone or email
+func (h *CustomerHandler) GetCustomers(c echo.Context) error {
+	filter := &models.CustomerFilter{}
+
+	// Parse query parameters
+	if search := c.QueryParam("search"); search != "" {
+		filter.SearchTerm = &search
+	}
+
+	if phone := c.QueryParam("phone"); phone != "" {
+		filter.Phone = &phone
+	}
+
+	if email := c.QueryParam("email"); email != "" {
+		filter.Email = &email
+	}
+
+	ctx := c.Request().Context()
+	customers, err := h.service.GetAll(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, customers)
+}
+
+// GetCustomerByID handles retrieval of a single customer with their sales summary
+func (h *CustomerHandler) GetCustomerByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	ctx := c.Request().Context()
+	customer, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return customerError(err)
+	}
+
+	return c.JSON(http.StatusOK, customer)
+}
+
+// CreateCustomer handles creation of a new customer
+func (h *CustomerHandler) CreateCustomer(c echo.Context) error {
+	customer := new(models.Customer)
+	if err := c.Bind(customer); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.Create(ctx, customer)
+	if err != nil {
+		return customerError(err)
+	}
+
+	customer.CustomerID = id
+	return c.JSON(http.StatusCreated, customer)
+}
+
+// UpdateCustomer handles updating an existing customer
+func (h *CustomerHandler) UpdateCustomer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	customer := new(models.Customer)
+	if err := c.Bind(customer); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	customer.CustomerID = id
+
+	ctx := c.Request().Context()
+	if err := h.service.Update(ctx, customer); err != nil {
+		return customerError(err)
+	}
+
+	return c.JSON(http.StatusOK, customer)
+}
+
+// DeleteCustomer handles deletion of a customer
+func (h *CustomerHandler) DeleteCustomer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Delete(ctx, id); err != nil {
+		return customerError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetDuplicates handles listing customers that share a phone number or email
+func (h *CustomerHandler) GetDuplicates(c echo.Context) error {
+	ctx := c.Request().Context()
+	groups, err := h.service.GetDuplicates(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, groups)
+}
+
+// MergeCustomers handles folding duplicate customers into the given customer
+func (h *CustomerHandler) MergeCustomers(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	request := new(models.MergeRequest)
+	if err := c.Bind(request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	customer, err := h.service.Merge(ctx, id, request)
+	if err != nil {
+		return customerError(err)
+	}
+
+	return c.JSON(http.StatusOK, customer)
+}
+
+// customerError maps customer errors to HTTP errors
+func customerError(err error) error {
+	switch err {
+	case customerErrors.ErrCustomerNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case customerErrors.ErrInvalidCustomerID, customerErrors.ErrCustomerNameNeeded,
+		customerErrors.ErrInvalidEmail, customerErrors.ErrNothingToMerge,
+		customerErrors.ErrMergeIntoSelf:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case customerErrors.ErrDuplicateCustomer:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	customerErrors "github.com/hsrvms/fixparts/internal/modules/customers/errors"
+	"github.com/hsrvms/fixparts/internal/modules/customers/models"
+	"github.com/hsrvms/fixparts/internal/modules/customers/services"
+	"github.com/labstack/echo/v4"
+)
+
+type CustomerVehicleHandler struct {
+	service services.CustomerVehicleService
+}
+
+func NewCustomerVehicleHandler(service services.CustomerVehicleService) *CustomerVehicleHandler {
+	return &CustomerVehicleHandler{
+		service: service,
+	}
+}
+
+// GetVehicles handles retrieval of a customer's saved vehicles
+func (h *CustomerVehicleHandler) GetVehicles(c echo.Context) error {
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	ctx := c.Request().Context()
+	vehicles, err := h.service.GetByCustomer(ctx, customerID)
+	if err != nil {
+		return vehicleError(err)
+	}
+
+	return c.JSON(http.StatusOK, vehicles)
+}
+
+// AddVehicle handles saving a vehicle for a customer
+func (h *CustomerVehicleHandler) AddVehicle(c echo.Context) error {
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	vehicle := new(models.CustomerVehicle)
+	if err := c.Bind(vehicle); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	vehicle.CustomerID = customerID
+
+	ctx := c.Request().Context()
+	created, err := h.service.Create(ctx, vehicle)
+	if err != nil {
+		return vehicleError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateVehicle handles changing a customer's saved vehicle
+func (h *CustomerVehicleHandler) UpdateVehicle(c echo.Context) error {
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	vehicleID, err := strconv.Atoi(c.Param("vehicleId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid vehicle ID")
+	}
+
+	vehicle := new(models.CustomerVehicle)
+	if err := c.Bind(vehicle); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	vehicle.CustomerID = customerID
+	vehicle.VehicleID = vehicleID
+
+	ctx := c.Request().Context()
+	updated, err := h.service.Update(ctx, vehicle)
+	if err != nil {
+		return vehicleError(err)
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
+
+// RemoveVehicle handles deleting a customer's saved vehicle
+func (h *CustomerVehicleHandler) RemoveVehicle(c echo.Context) error {
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	vehicleID, err := strconv.Atoi(c.Param("vehicleId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid vehicle ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Delete(ctx, customerID, vehicleID); err != nil {
+		return vehicleError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetCompatibleItems handles listing the in-stock items that fit each of a
+// customer's saved vehicles
+func (h *CustomerVehicleHandler) GetCompatibleItems(c echo.Context) error {
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.service.GetCompatibleItems(ctx, customerID)
+	if err != nil {
+		return vehicleError(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// vehicleError maps customer vehicle errors to HTTP errors
+func vehicleError(err error) error {
+	switch err {
+	case customerErrors.ErrCustomerNotFound, customerErrors.ErrVehicleNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case customerErrors.ErrInvalidCustomerID, customerErrors.ErrInvalidVehicleID,
+		customerErrors.ErrInvalidSubmodelID, customerErrors.ErrSubmodelNotFound,
+		customerErrors.ErrInvalidYear, customerErrors.ErrInvalidVIN:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
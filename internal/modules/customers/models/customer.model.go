@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+type Customer struct {
+	CustomerID int       `json:"customer_id" db:"customer_id"`
+	Name       string    `json:"name" db:"name"`
+	Phone      *string   `json:"phone,omitempty" db:"phone"`
+	Email      *string   `json:"email,omitempty" db:"email"`
+	Address    *string   `json:"address,omitempty" db:"address"`
+	TaxID      *string   `json:"tax_id,omitempty" db:"tax_id"`
+	Notes      *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+
+	// Purchase history summary (not from the customers table)
+	SalesCount   int        `json:"sales_count" db:"-"`
+	TotalSpent   float64    `json:"total_spent" db:"-"`
+	LastSaleDate *time.Time `json:"last_sale_date,omitempty" db:"-"`
+}
+
+// Filter represents the search criteria for customers
+type CustomerFilter struct {
+	SearchTerm *string `query:"search"`
+	Phone      *string `query:"phone"`
+	Email      *string `query:"email"`
+}
+
+// DuplicateGroup is a set of customers that look like the same person
+type DuplicateGroup struct {
+	MatchedOn string      `json:"matched_on"`
+	Value     string      `json:"value"`
+	Customers []*Customer `json:"customers"`
+}
+
+// MergeRequest folds the source customers into the target customer
+type MergeRequest struct {
+	SourceIDs []int `json:"source_ids"`
+}
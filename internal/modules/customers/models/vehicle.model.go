@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	itemmodels "github.com/hsrvms/fixparts/internal/modules/inventory/items/models"
+)
+
+// CustomerVehicle is a car a customer drives, saved so the counter can look
+// up parts for it without asking again
+type CustomerVehicle struct {
+	VehicleID  int       `json:"vehicle_id" db:"vehicle_id"`
+	CustomerID int       `json:"customer_id" db:"customer_id"`
+	SubmodelID int       `json:"submodel_id" db:"submodel_id"`
+	Year       int       `json:"year" db:"year"`
+	VIN        *string   `json:"vin,omitempty" db:"vin"`
+	Plate      *string   `json:"plate,omitempty" db:"plate"`
+	Notes      *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	MakeName     string `json:"make_name,omitempty" db:"-"`
+	ModelName    string `json:"model_name,omitempty" db:"-"`
+	SubmodelName string `json:"submodel_name,omitempty" db:"-"`
+}
+
+// VehicleItems lists the in-stock items that fit one of a customer's vehicles
+type VehicleItems struct {
+	Vehicle *CustomerVehicle   `json:"vehicle"`
+	Items   []*itemmodels.Item `json:"items"`
+}
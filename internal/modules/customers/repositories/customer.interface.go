@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/customers/models"
+)
+
+type CustomerRepository interface {
+	GetAll(ctx context.Context, filter *models.CustomerFilter) ([]*models.Customer, error)
+	GetByID(ctx context.Context, id int) (*models.Customer, error)
+	Create(ctx context.Context, customer *models.Customer) (int, error)
+	Update(ctx context.Context, customer *models.Customer) error
+	Delete(ctx context.Context, id int) error
+	GetDuplicates(ctx context.Context) ([]*models.DuplicateGroup, error)
+	Merge(ctx context.Context, targetID int, sourceIDs []int) error
+}
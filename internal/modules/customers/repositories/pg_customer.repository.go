@@ -0,0 +1,356 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	customerErrors "github.com/hsrvms/fixparts/internal/modules/customers/errors"
+	"github.com/hsrvms/fixparts/internal/modules/customers/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresCustomerRepository struct {
+	db *db.Database
+}
+
+func NewPostgresCustomerRepository(database *db.Database) CustomerRepository {
+	return &PostgresCustomerRepository{
+		db: database,
+	}
+}
+
+// customerSelect returns customers with a summary of their sales history.
+// Total spent is net of refunds.
+const customerSelect = `
+    SELECT
+        c.customer_id, c.name, c.phone, c.email, c.address,
+        c.tax_id, c.notes, c.created_at, c.updated_at,
+        COALESCE(h.sales_count, 0), COALESCE(h.total_spent, 0), h.last_sale_date
+    FROM customers c
+    LEFT JOIN LATERAL (
+        SELECT
+            COUNT(*) as sales_count,
+            SUM(s.total_amount) - COALESCE(SUM((
+                SELECT SUM(sr.refund_amount) FROM sale_returns sr
+                WHERE sr.sale_id = s.sale_id
+            )), 0) as total_spent,
+            MAX(s.date) as last_sale_date
+        FROM sales s
+        WHERE s.customer_id = c.customer_id
+    ) h ON true
+`
+
+func (r *PostgresCustomerRepository) GetAll(ctx context.Context, filter *models.CustomerFilter) ([]*models.Customer, error) {
+	query := customerSelect + " WHERE 1=1"
+
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.SearchTerm != nil {
+			conditions = append(conditions, fmt.Sprintf(
+				"(c.name ILIKE $%d OR c.email ILIKE $%d OR c.phone ILIKE $%d)",
+				paramCount, paramCount, paramCount))
+			params = append(params, "%"+*filter.SearchTerm+"%")
+			paramCount++
+		}
+
+		// Phone numbers are compared on their digits only so that
+		// "0532 123 45 67" and "05321234567" are the same number
+		if filter.Phone != nil {
+			conditions = append(conditions, fmt.Sprintf(
+				"regexp_replace(c.phone, '\\D', '', 'g') = regexp_replace($%d, '\\D', '', 'g')", paramCount))
+			params = append(params, *filter.Phone)
+			paramCount++
+		}
+
+		if filter.Email != nil {
+			conditions = append(conditions, fmt.Sprintf("LOWER(c.email) = LOWER($%d)", paramCount))
+			params = append(params, *filter.Email)
+			paramCount++
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY c.name, c.customer_id"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCustomers(rows)
+}
+
+func (r *PostgresCustomerRepository) GetByID(ctx context.Context, id int) (*models.Customer, error) {
+	rows, err := r.db.Pool.Query(ctx, customerSelect+" WHERE c.customer_id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers, err := scanCustomers(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(customers) == 0 {
+		return nil, nil
+	}
+
+	return customers[0], nil
+}
+
+func (r *PostgresCustomerRepository) Create(ctx context.Context, customer *models.Customer) (int, error) {
+	query := `
+        INSERT INTO customers (
+            name, phone, email, address, tax_id, notes
+        ) VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING customer_id
+    `
+
+	var id int
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		customer.Name,
+		customer.Phone,
+		customer.Email,
+		customer.Address,
+		customer.TaxID,
+		customer.Notes,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *PostgresCustomerRepository) Update(ctx context.Context, customer *models.Customer) error {
+	query := `
+        UPDATE customers SET
+            name = $2,
+            phone = $3,
+            email = $4,
+            address = $5,
+            tax_id = $6,
+            notes = $7
+        WHERE customer_id = $1
+    `
+
+	result, err := r.db.Pool.Exec(
+		ctx, query,
+		customer.CustomerID,
+		customer.Name,
+		customer.Phone,
+		customer.Email,
+		customer.Address,
+		customer.TaxID,
+		customer.Notes,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return customerErrors.ErrCustomerNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresCustomerRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM customers WHERE customer_id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return customerErrors.ErrCustomerNotFound
+	}
+
+	return nil
+}
+
+// GetDuplicates groups customers sharing an email address (case-insensitive)
+// or a phone number (digits only)
+func (r *PostgresCustomerRepository) GetDuplicates(ctx context.Context) ([]*models.DuplicateGroup, error) {
+	query := `
+        WITH keys AS (
+            SELECT 'email' as matched_on, LOWER(email) as value, customer_id
+            FROM customers
+            WHERE email IS NOT NULL AND email <> ''
+            UNION ALL
+            SELECT 'phone', regexp_replace(phone, '\D', '', 'g'), customer_id
+            FROM customers
+            WHERE regexp_replace(COALESCE(phone, ''), '\D', '', 'g') <> ''
+        )
+        SELECT matched_on, value, ARRAY_AGG(customer_id ORDER BY customer_id)
+        FROM keys
+        GROUP BY matched_on, value
+        HAVING COUNT(*) > 1
+        ORDER BY matched_on, value
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type group struct {
+		group *models.DuplicateGroup
+		ids   []int
+	}
+	var groups []group
+	var allIDs []int
+	for rows.Next() {
+		g := group{group: &models.DuplicateGroup{}}
+		if err := rows.Scan(&g.group.MatchedOn, &g.group.Value, &g.ids); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+		allIDs = append(allIDs, g.ids...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := []*models.DuplicateGroup{}
+	if len(groups) == 0 {
+		return result, nil
+	}
+
+	customerRows, err := r.db.Pool.Query(ctx, customerSelect+" WHERE c.customer_id = ANY($1)", allIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer customerRows.Close()
+
+	customers, err := scanCustomers(customerRows)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*models.Customer, len(customers))
+	for _, customer := range customers {
+		byID[customer.CustomerID] = customer
+	}
+
+	for _, g := range groups {
+		for _, id := range g.ids {
+			if customer, ok := byID[id]; ok {
+				g.group.Customers = append(g.group.Customers, customer)
+			}
+		}
+		result = append(result, g.group)
+	}
+
+	return result, nil
+}
+
+// Merge moves the sales and saved vehicles of the source customers to the
+// target, fills the target's missing contact details from the sources and
+// deletes the sources, all in one transaction
+func (r *PostgresCustomerRepository) Merge(ctx context.Context, targetID int, sourceIDs []int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the target and the sources in a stable order
+	ids := append([]int{targetID}, sourceIDs...)
+	result, err := tx.Exec(ctx,
+		`SELECT 1 FROM customers WHERE customer_id = ANY($1) ORDER BY customer_id FOR UPDATE`,
+		ids,
+	)
+	if err != nil {
+		return err
+	}
+	if int(result.RowsAffected()) != len(ids) {
+		return customerErrors.ErrCustomerNotFound
+	}
+
+	query := `
+        UPDATE customers t SET
+            phone = COALESCE(NULLIF(t.phone, ''), src.phone),
+            email = COALESCE(NULLIF(t.email, ''), src.email),
+            address = COALESCE(NULLIF(t.address, ''), src.address),
+            tax_id = COALESCE(NULLIF(t.tax_id, ''), src.tax_id),
+            notes = COALESCE(NULLIF(t.notes, ''), src.notes)
+        FROM (
+            SELECT
+                (ARRAY_AGG(phone ORDER BY customer_id) FILTER (WHERE phone <> ''))[1] as phone,
+                (ARRAY_AGG(email ORDER BY customer_id) FILTER (WHERE email <> ''))[1] as email,
+                (ARRAY_AGG(address ORDER BY customer_id) FILTER (WHERE address <> ''))[1] as address,
+                (ARRAY_AGG(tax_id ORDER BY customer_id) FILTER (WHERE tax_id <> ''))[1] as tax_id,
+                (ARRAY_AGG(notes ORDER BY customer_id) FILTER (WHERE notes <> ''))[1] as notes
+            FROM customers
+            WHERE customer_id = ANY($2)
+        ) src
+        WHERE t.customer_id = $1
+    `
+	if _, err := tx.Exec(ctx, query, targetID, sourceIDs); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE sales SET customer_id = $1 WHERE customer_id = ANY($2)`,
+		targetID, sourceIDs,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE customer_vehicles SET customer_id = $1 WHERE customer_id = ANY($2)`,
+		targetID, sourceIDs,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM customers WHERE customer_id = ANY($1)`, sourceIDs)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func scanCustomers(rows pgx.Rows) ([]*models.Customer, error) {
+	customers := []*models.Customer{}
+	for rows.Next() {
+		customer := &models.Customer{}
+		err := rows.Scan(
+			&customer.CustomerID,
+			&customer.Name,
+			&customer.Phone,
+			&customer.Email,
+			&customer.Address,
+			&customer.TaxID,
+			&customer.Notes,
+			&customer.CreatedAt,
+			&customer.UpdatedAt,
+			&customer.SalesCount,
+			&customer.TotalSpent,
+			&customer.LastSaleDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+
+	return customers, rows.Err()
+}
@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	customerErrors "github.com/hsrvms/fixparts/internal/modules/customers/errors"
+	"github.com/hsrvms/fixparts/internal/modules/customers/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresCustomerVehicleRepository struct {
+	db *db.Database
+}
+
+func NewPostgresCustomerVehicleRepository(database *db.Database) CustomerVehicleRepository {
+	return &PostgresCustomerVehicleRepository{
+		db: database,
+	}
+}
+
+const vehicleSelect = `
+    SELECT
+        v.vehicle_id, v.customer_id, v.submodel_id, v.year, v.vin,
+        v.plate, v.notes, v.created_at, v.updated_at,
+        mk.make_name, m.model_name, s.submodel_name
+    FROM customer_vehicles v
+    JOIN vehicle_submodels s ON v.submodel_id = s.submodel_id
+    JOIN vehicle_models m ON s.model_id = m.model_id
+    JOIN vehicle_makes mk ON m.make_id = mk.make_id
+`
+
+func (r *PostgresCustomerVehicleRepository) GetByCustomer(ctx context.Context, customerID int) ([]*models.CustomerVehicle, error) {
+	query := vehicleSelect + `
+        WHERE v.customer_id = $1
+        ORDER BY v.created_at, v.vehicle_id
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	vehicles := []*models.CustomerVehicle{}
+	for rows.Next() {
+		vehicle, err := scanVehicle(rows)
+		if err != nil {
+			return nil, err
+		}
+		vehicles = append(vehicles, vehicle)
+	}
+
+	return vehicles, rows.Err()
+}
+
+func (r *PostgresCustomerVehicleRepository) GetByID(ctx context.Context, id int) (*models.CustomerVehicle, error) {
+	vehicle, err := scanVehicle(r.db.Pool.QueryRow(ctx, vehicleSelect+" WHERE v.vehicle_id = $1", id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return vehicle, nil
+}
+
+func (r *PostgresCustomerVehicleRepository) Create(ctx context.Context, vehicle *models.CustomerVehicle) (int, error) {
+	query := `
+        INSERT INTO customer_vehicles (
+            customer_id, submodel_id, year, vin, plate, notes
+        ) VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING vehicle_id
+    `
+
+	var id int
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		vehicle.CustomerID,
+		vehicle.SubmodelID,
+		vehicle.Year,
+		vehicle.VIN,
+		vehicle.Plate,
+		vehicle.Notes,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *PostgresCustomerVehicleRepository) Update(ctx context.Context, vehicle *models.CustomerVehicle) error {
+	query := `
+        UPDATE customer_vehicles SET
+            submodel_id = $2,
+            year = $3,
+            vin = $4,
+            plate = $5,
+            notes = $6
+        WHERE vehicle_id = $1
+    `
+
+	result, err := r.db.Pool.Exec(
+		ctx, query,
+		vehicle.VehicleID,
+		vehicle.SubmodelID,
+		vehicle.Year,
+		vehicle.VIN,
+		vehicle.Plate,
+		vehicle.Notes,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return customerErrors.ErrVehicleNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresCustomerVehicleRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM customer_vehicles WHERE vehicle_id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return customerErrors.ErrVehicleNotFound
+	}
+
+	return nil
+}
+
+func scanVehicle(row pgx.Row) (*models.CustomerVehicle, error) {
+	vehicle := &models.CustomerVehicle{}
+	err := row.Scan(
+		&vehicle.VehicleID,
+		&vehicle.CustomerID,
+		&vehicle.SubmodelID,
+		&vehicle.Year,
+		&vehicle.VIN,
+		&vehicle.Plate,
+		&vehicle.Notes,
+		&vehicle.CreatedAt,
+		&vehicle.UpdatedAt,
+		&vehicle.MakeName,
+		&vehicle.ModelName,
+		&vehicle.SubmodelName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return vehicle, nil
+}
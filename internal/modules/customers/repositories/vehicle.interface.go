@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/customers/models"
+)
+
+type CustomerVehicleRepository interface {
+	GetByCustomer(ctx context.Context, customerID int) ([]*models.CustomerVehicle, error)
+	GetByID(ctx context.Context, id int) (*models.CustomerVehicle, error)
+	Create(ctx context.Context, vehicle *models.CustomerVehicle) (int, error)
+	Update(ctx context.Context, vehicle *models.CustomerVehicle) error
+	Delete(ctx context.Context, id int) error
+}
@@ -0,0 +1,43 @@
+package customers
+
+import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/customers/handlers"
+	"github.com/hsrvms/fixparts/internal/modules/customers/repositories"
+	"github.com/hsrvms/fixparts/internal/modules/customers/services"
+	compatibility "github.com/hsrvms/fixparts/internal/modules/inventory/compatibility/repositories"
+	submodels "github.com/hsrvms/fixparts/internal/modules/vehicles/submodels/repositories"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database) {
+	repo := repositories.NewPostgresCustomerRepository(database)
+	service := services.NewCustomerService(repo)
+	handler := handlers.NewCustomerHandler(service)
+
+	customers := api.Group("/customers", authMiddleware.Guard(authModels.PermCustomersRead, authModels.PermCustomersWrite))
+	customers.GET("", handler.GetCustomers)
+	customers.GET("/duplicates", handler.GetDuplicates)
+	customers.GET("/:id", handler.GetCustomerByID)
+	customers.POST("", handler.CreateCustomer)
+	customers.PUT("/:id", handler.UpdateCustomer)
+	customers.DELETE("/:id", handler.DeleteCustomer)
+	customers.POST("/:id/merge", handler.MergeCustomers)
+
+	vehicleRepo := repositories.NewPostgresCustomerVehicleRepository(database)
+	vehicleService := services.NewCustomerVehicleService(
+		vehicleRepo,
+		repo,
+		submodels.NewPostgresVehicleSubmodelRepository(database),
+		compatibility.NewPostgresCompatibilityRepository(database),
+	)
+	vehicleHandler := handlers.NewCustomerVehicleHandler(vehicleService)
+
+	customers.GET("/:id/vehicles", vehicleHandler.GetVehicles)
+	customers.POST("/:id/vehicles", vehicleHandler.AddVehicle)
+	customers.GET("/:id/vehicles/compatible-items", vehicleHandler.GetCompatibleItems)
+	customers.PUT("/:id/vehicles/:vehicleId", vehicleHandler.UpdateVehicle)
+	customers.DELETE("/:id/vehicles/:vehicleId", vehicleHandler.RemoveVehicle)
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+
+	customerErrors "github.com/hsrvms/fixparts/internal/modules/customers/errors"
+	"github.com/hsrvms/fixparts/internal/modules/customers/models"
+	"github.com/hsrvms/fixparts/internal/modules/customers/repositories"
+)
+
+type customerService struct {
+	repo repositories.CustomerRepository
+}
+
+func NewCustomerService(repo repositories.CustomerRepository) CustomerService {
+	return &customerService{
+		repo: repo,
+	}
+}
+
+func (s *customerService) GetAll(ctx context.Context, filter *models.CustomerFilter) ([]*models.Customer, error) {
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *customerService) GetByID(ctx context.Context, id int) (*models.Customer, error) {
+	if id <= 0 {
+		return nil, customerErrors.ErrInvalidCustomerID
+	}
+
+	customer, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, customerErrors.ErrCustomerNotFound
+	}
+
+	return customer, nil
+}
+
+func (s *customerService) Create(ctx context.Context, customer *models.Customer) (int, error) {
+	if err := s.validateCustomer(customer); err != nil {
+		return 0, err
+	}
+
+	if err := s.checkDuplicate(ctx, customer); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Create(ctx, customer)
+}
+
+func (s *customerService) Update(ctx context.Context, customer *models.Customer) error {
+	if customer.CustomerID <= 0 {
+		return customerErrors.ErrInvalidCustomerID
+	}
+
+	if err := s.validateCustomer(customer); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetByID(ctx, customer.CustomerID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return customerErrors.ErrCustomerNotFound
+	}
+
+	if err := s.checkDuplicate(ctx, customer); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, customer)
+}
+
+// Delete removes a customer. Their sales are kept and become walk-in sales.
+func (s *customerService) Delete(ctx context.Context, id int) error {
+	if id <= 0 {
+		return customerErrors.ErrInvalidCustomerID
+	}
+
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return customerErrors.ErrCustomerNotFound
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *customerService) GetDuplicates(ctx context.Context) ([]*models.DuplicateGroup, error) {
+	return s.repo.GetDuplicates(ctx)
+}
+
+// Merge folds the source customers into the target and returns the merged
+// customer with its combined sales history
+func (s *customerService) Merge(ctx context.Context, targetID int, request *models.MergeRequest) (*models.Customer, error) {
+	if targetID <= 0 {
+		return nil, customerErrors.ErrInvalidCustomerID
+	}
+	if request == nil || len(request.SourceIDs) == 0 {
+		return nil, customerErrors.ErrNothingToMerge
+	}
+
+	seen := make(map[int]bool, len(request.SourceIDs))
+	sourceIDs := make([]int, 0, len(request.SourceIDs))
+	for _, id := range request.SourceIDs {
+		if id <= 0 {
+			return nil, customerErrors.ErrInvalidCustomerID
+		}
+		if id == targetID {
+			return nil, customerErrors.ErrMergeIntoSelf
+		}
+		if !seen[id] {
+			seen[id] = true
+			sourceIDs = append(sourceIDs, id)
+		}
+	}
+
+	for _, id := range append([]int{targetID}, sourceIDs...) {
+		existing, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, customerErrors.ErrCustomerNotFound
+		}
+	}
+
+	if err := s.repo.Merge(ctx, targetID, sourceIDs); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, targetID)
+}
+
+// Helper functions
+func (s *customerService) validateCustomer(customer *models.Customer) error {
+	customer.Name = strings.TrimSpace(customer.Name)
+	if customer.Name == "" {
+		return customerErrors.ErrCustomerNameNeeded
+	}
+
+	if customer.Email != nil && *customer.Email != "" {
+		if _, err := mail.ParseAddress(*customer.Email); err != nil {
+			return customerErrors.ErrInvalidEmail
+		}
+	}
+
+	return nil
+}
+
+// checkDuplicate refuses a customer whose phone or email already belongs to
+// another customer
+func (s *customerService) checkDuplicate(ctx context.Context, customer *models.Customer) error {
+	filters := []*models.CustomerFilter{}
+	if customer.Phone != nil && *customer.Phone != "" {
+		filters = append(filters, &models.CustomerFilter{Phone: customer.Phone})
+	}
+	if customer.Email != nil && *customer.Email != "" {
+		filters = append(filters, &models.CustomerFilter{Email: customer.Email})
+	}
+
+	for _, filter := range filters {
+		matches, err := s.repo.GetAll(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, match := range matches {
+			if match.CustomerID != customer.CustomerID {
+				return customerErrors.ErrDuplicateCustomer
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/customers/models"
+)
+
+type CustomerVehicleService interface {
+	GetByCustomer(ctx context.Context, customerID int) ([]*models.CustomerVehicle, error)
+	Create(ctx context.Context, vehicle *models.CustomerVehicle) (*models.CustomerVehicle, error)
+	Update(ctx context.Context, vehicle *models.CustomerVehicle) (*models.CustomerVehicle, error)
+	Delete(ctx context.Context, customerID, vehicleID int) error
+	GetCompatibleItems(ctx context.Context, customerID int) ([]*models.VehicleItems, error)
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	customerErrors "github.com/hsrvms/fixparts/internal/modules/customers/errors"
+	"github.com/hsrvms/fixparts/internal/modules/customers/models"
+	"github.com/hsrvms/fixparts/internal/modules/customers/repositories"
+	compatibility "github.com/hsrvms/fixparts/internal/modules/inventory/compatibility/repositories"
+	itemmodels "github.com/hsrvms/fixparts/internal/modules/inventory/items/models"
+	submodels "github.com/hsrvms/fixparts/internal/modules/vehicles/submodels/repositories"
+)
+
+type customerVehicleService struct {
+	repo              repositories.CustomerVehicleRepository
+	customerRepo      repositories.CustomerRepository
+	submodelRepo      submodels.VehicleSubmodelRepository
+	compatibilityRepo compatibility.CompatibilityRepository
+}
+
+func NewCustomerVehicleService(
+	repo repositories.CustomerVehicleRepository,
+	customerRepo repositories.CustomerRepository,
+	submodelRepo submodels.VehicleSubmodelRepository,
+	compatibilityRepo compatibility.CompatibilityRepository,
+) CustomerVehicleService {
+	return &customerVehicleService{
+		repo:              repo,
+		customerRepo:      customerRepo,
+		submodelRepo:      submodelRepo,
+		compatibilityRepo: compatibilityRepo,
+	}
+}
+
+func (s *customerVehicleService) GetByCustomer(ctx context.Context, customerID int) ([]*models.CustomerVehicle, error) {
+	if err := s.checkCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByCustomer(ctx, customerID)
+}
+
+func (s *customerVehicleService) Create(ctx context.Context, vehicle *models.CustomerVehicle) (*models.CustomerVehicle, error) {
+	if err := s.checkCustomer(ctx, vehicle.CustomerID); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateVehicle(ctx, vehicle); err != nil {
+		return nil, err
+	}
+
+	id, err := s.repo.Create(ctx, vehicle)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *customerVehicleService) Update(ctx context.Context, vehicle *models.CustomerVehicle) (*models.CustomerVehicle, error) {
+	if _, err := s.getOwned(ctx, vehicle.CustomerID, vehicle.VehicleID); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateVehicle(ctx, vehicle); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, vehicle); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, vehicle.VehicleID)
+}
+
+func (s *customerVehicleService) Delete(ctx context.Context, customerID, vehicleID int) error {
+	if _, err := s.getOwned(ctx, customerID, vehicleID); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, vehicleID)
+}
+
+// GetCompatibleItems lists, for every saved vehicle of the customer, the
+// active items in stock that fit it
+func (s *customerVehicleService) GetCompatibleItems(ctx context.Context, customerID int) ([]*models.VehicleItems, error) {
+	vehicles, err := s.GetByCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.VehicleItems, 0, len(vehicles))
+	for _, vehicle := range vehicles {
+		items, err := s.compatibilityRepo.GetCompatibleItemsInStock(ctx, vehicle.SubmodelID)
+		if err != nil {
+			return nil, err
+		}
+		if items == nil {
+			items = []*itemmodels.Item{}
+		}
+		result = append(result, &models.VehicleItems{
+			Vehicle: vehicle,
+			Items:   items,
+		})
+	}
+
+	return result, nil
+}
+
+// Helper functions
+func (s *customerVehicleService) checkCustomer(ctx context.Context, customerID int) error {
+	if customerID <= 0 {
+		return customerErrors.ErrInvalidCustomerID
+	}
+
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	if customer == nil {
+		return customerErrors.ErrCustomerNotFound
+	}
+
+	return nil
+}
+
+// getOwned loads a vehicle and makes sure it belongs to the given customer
+func (s *customerVehicleService) getOwned(ctx context.Context, customerID, vehicleID int) (*models.CustomerVehicle, error) {
+	if customerID <= 0 {
+		return nil, customerErrors.ErrInvalidCustomerID
+	}
+	if vehicleID <= 0 {
+		return nil, customerErrors.ErrInvalidVehicleID
+	}
+
+	vehicle, err := s.repo.GetByID(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil || vehicle.CustomerID != customerID {
+		return nil, customerErrors.ErrVehicleNotFound
+	}
+
+	return vehicle, nil
+}
+
+func (s *customerVehicleService) validateVehicle(ctx context.Context, vehicle *models.CustomerVehicle) error {
+	if vehicle.SubmodelID <= 0 {
+		return customerErrors.ErrInvalidSubmodelID
+	}
+
+	submodel, err := s.submodelRepo.GetSubmodelByID(ctx, vehicle.SubmodelID)
+	if err != nil {
+		return err
+	}
+	if submodel == nil {
+		return customerErrors.ErrSubmodelNotFound
+	}
+
+	// The year must fall within the production run of the submodel
+	if vehicle.Year < submodel.YearFrom || (submodel.YearTo != nil && vehicle.Year > *submodel.YearTo) {
+		return customerErrors.ErrInvalidYear
+	}
+
+	if vehicle.VIN != nil {
+		vin := strings.ToUpper(strings.TrimSpace(*vehicle.VIN))
+		if vin == "" {
+			vehicle.VIN = nil
+		} else {
+			if !isValidVIN(vin) {
+				return customerErrors.ErrInvalidVIN
+			}
+			vehicle.VIN = &vin
+		}
+	}
+
+	if vehicle.Plate != nil {
+		plate := strings.ToUpper(strings.Join(strings.Fields(*vehicle.Plate), " "))
+		if plate == "" {
+			vehicle.Plate = nil
+		} else {
+			vehicle.Plate = &plate
+		}
+	}
+
+	return nil
+}
+
+// isValidVIN checks the shape of a 17 character VIN. The letters I, O and Q
+// are never used so they cannot be confused with 1 and 0.
+func isValidVIN(vin string) bool {
+	if len(vin) != 17 {
+		return false
+	}
+	for _, r := range vin {
+		switch {
+		case r >= '0' && r <= '9':
+		case r >= 'A' && r <= 'Z' && r != 'I' && r != 'O' && r != 'Q':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,10 @@
+package dashboarderrors
+
+import "errors"
+
+var (
+	ErrInvalidMetric   = errors.New("metric must be revenue, units, purchases or stock_value")
+	ErrInvalidInterval = errors.New("interval must be day or month")
+	ErrInvalidRange    = errors.New("start date must not be after end date")
+	ErrRangeTooLong    = errors.New("daily series cannot span more than 366 days")
+)
@@ -1,12 +1,25 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"time"
 
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	dashboarderrors "github.com/hsrvms/fixparts/internal/modules/dashboard/errors"
+	"github.com/hsrvms/fixparts/internal/modules/dashboard/models"
 	"github.com/hsrvms/fixparts/internal/modules/dashboard/services"
+	"github.com/hsrvms/fixparts/web/templates/components/charts"
 	"github.com/labstack/echo/v4"
 )
 
+// dateLayout is the format of the range's start and end query parameters,
+// as sent by date inputs
+const dateLayout = "2006-01-02"
+
+const invalidRangeHTML = "<div class='text-red-600'>Geçersiz tarih aralığı</div>"
+
 type DashboardAPIHandler struct {
 	service services.DashboardService
 }
@@ -24,9 +37,28 @@ func (h *DashboardAPIHandler) GetLowStockCount(c echo.Context) error {
 	return c.HTML(200, fmt.Sprintf("<div>%d</div>", count))
 }
 
+func (h *DashboardAPIHandler) GetRevenue(c echo.Context) error {
+	rng, err := parseRange(c)
+	if err != nil {
+		return c.HTML(400, invalidRangeHTML)
+	}
+
+	ctx := c.Request().Context()
+	sales, err := h.service.GetRevenue(ctx, rng)
+	if err != nil {
+		return c.HTML(500, "<div class='text-red-600'>Dönem geliri alınamadı</div>")
+	}
+	return c.HTML(200, fmt.Sprintf("<div>%.2f</div>", sales))
+}
+
+// GetTodaySales is the revenue of today, kept for the clients of the
+// endpoint that predates the date range
 func (h *DashboardAPIHandler) GetTodaySales(c echo.Context) error {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
 	ctx := c.Request().Context()
-	sales, err := h.service.GetTodaySales(ctx)
+	sales, err := h.service.GetRevenue(ctx, &models.DateRange{Start: today, End: today.AddDate(0, 0, 1)})
 	if err != nil {
 		return c.HTML(500, "<div class='text-red-600'>Bugünkü satışlar alınamadı</div>")
 	}
@@ -68,8 +100,36 @@ func (h *DashboardAPIHandler) GetLowStockItems(c echo.Context) error {
 		"</tr></thead><tbody>"
 
 	for _, item := range items {
-		html += fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%d</td><td>%d</td></tr>",
-			item.PartNumber, item.Name, item.Current, item.Minimum)
+		current := fmt.Sprintf("%d", item.Current)
+		if item.Warning != "" {
+			current += fmt.Sprintf(" <span class='text-xs text-red-600'>%s</span>", item.Warning)
+		}
+		html += fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%s</td><td>%d</td></tr>",
+			item.PartNumber, item.Name, current, item.Minimum)
+	}
+	html += "</tbody></table>"
+
+	return c.HTML(200, html)
+}
+
+func (h *DashboardAPIHandler) GetStockoutForecast(c echo.Context) error {
+	ctx := c.Request().Context()
+	items, err := h.service.GetStockoutForecast(ctx)
+	if err != nil {
+		return c.HTML(500, "<div class='text-red-600'>Stok tükenme tahmini alınamadı</div>")
+	}
+
+	if len(items) == 0 {
+		return c.HTML(200, "<div class='text-gray-500'>Yakında tükenecek ürün bulunamadı</div>")
+	}
+
+	html := "<table class='min-w-full'><thead><tr>" +
+		"<th>Parça Numarası</th><th>İsim</th><th>Mevcut Stok</th><th>Günlük Satış</th><th>Kalan Gün</th>" +
+		"</tr></thead><tbody>"
+
+	for _, item := range items {
+		html += fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%d</td><td>%.2f</td><td>%d</td></tr>",
+			item.PartNumber, item.Name, item.Current, item.DailyRate, item.DaysLeft)
 	}
 	html += "</tbody></table>"
 
@@ -77,8 +137,13 @@ func (h *DashboardAPIHandler) GetLowStockItems(c echo.Context) error {
 }
 
 func (h *DashboardAPIHandler) GetRecentSales(c echo.Context) error {
+	rng, err := parseRange(c)
+	if err != nil {
+		return c.HTML(400, invalidRangeHTML)
+	}
+
 	ctx := c.Request().Context()
-	sales, err := h.service.GetRecentSales(ctx)
+	sales, err := h.service.GetRecentSales(ctx, rng)
 	if err != nil {
 		return c.HTML(500, "<div class='text-red-600'>Son satışlar alınamadı</div>")
 	}
@@ -101,8 +166,13 @@ func (h *DashboardAPIHandler) GetRecentSales(c echo.Context) error {
 }
 
 func (h *DashboardAPIHandler) GetTopSellers(c echo.Context) error {
+	rng, err := parseRange(c)
+	if err != nil {
+		return c.HTML(400, invalidRangeHTML)
+	}
+
 	ctx := c.Request().Context()
-	sellers, err := h.service.GetTopSellers(ctx)
+	sellers, err := h.service.GetTopSellers(ctx, rng)
 	if err != nil {
 		return c.HTML(500, "<div class='text-red-600'>En çok satanlar alınamadı</div>")
 	}
@@ -125,8 +195,13 @@ func (h *DashboardAPIHandler) GetTopSellers(c echo.Context) error {
 }
 
 func (h *DashboardAPIHandler) GetRecentPurchases(c echo.Context) error {
+	rng, err := parseRange(c)
+	if err != nil {
+		return c.HTML(400, invalidRangeHTML)
+	}
+
 	ctx := c.Request().Context()
-	purchases, err := h.service.GetRecentPurchases(ctx)
+	purchases, err := h.service.GetRecentPurchases(ctx, rng)
 	if err != nil {
 		return c.HTML(500, "<div class='text-red-600'>Son alımlar alınamadı</div>")
 	}
@@ -135,15 +210,138 @@ func (h *DashboardAPIHandler) GetRecentPurchases(c echo.Context) error {
 		return c.HTML(200, "<div class='text-gray-500'>Son alım bulunamadı</div>")
 	}
 
+	// Counter access sees what was bought but not what it cost
+	showCost := authMiddleware.FullAccess(c)
+
 	html := "<table class='min-w-full'><thead><tr>" +
-		"<th>Tarih</th><th>Parça Numarası</th><th>Tedarikçi</th><th>Maliyet</th>" +
-		"</tr></thead><tbody>"
+		"<th>Tarih</th><th>Parça Numarası</th><th>Tedarikçi</th>"
+	if showCost {
+		html += "<th>Maliyet</th>"
+	}
+	html += "</tr></thead><tbody>"
 
 	for _, purchase := range purchases {
-		html += fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%s</td><td>%.2f</td></tr>",
-			purchase.Date, purchase.PartNumber, purchase.Supplier, purchase.Cost)
+		html += fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%s</td>",
+			purchase.Date, purchase.PartNumber, purchase.Supplier)
+		if showCost {
+			html += fmt.Sprintf("<td>%.2f</td>", purchase.Cost)
+		}
+		html += "</tr>"
 	}
 	html += "</tbody></table>"
 
 	return c.HTML(200, html)
 }
+
+// GetSeries handles retrieval of a metric's time series over the selected
+// range
+func (h *DashboardAPIHandler) GetSeries(c echo.Context) error {
+	rng, err := parseRange(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	metric := c.Param("metric")
+	if models.IsCostMetric(metric) && !authMiddleware.FullAccess(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "the "+metric+" metric needs full access")
+	}
+
+	ctx := c.Request().Context()
+	series, err := h.service.GetSeries(ctx, metric, c.QueryParam("interval"), rng)
+	if err != nil {
+		return seriesError(err)
+	}
+
+	return c.JSON(http.StatusOK, series)
+}
+
+// chartTitles are the dashboard chart titles of each metric
+var chartTitles = map[string]string{
+	models.MetricRevenue:    "Gelir",
+	models.MetricUnits:      "Satılan Adet",
+	models.MetricPurchases:  "Alım Harcaması",
+	models.MetricStockValue: "Stok Değeri",
+}
+
+// GetChart renders a metric's time series over the selected range as an
+// SVG chart
+func (h *DashboardAPIHandler) GetChart(c echo.Context) error {
+	rng, err := parseRange(c)
+	if err != nil {
+		return c.HTML(400, invalidRangeHTML)
+	}
+
+	metric := c.Param("metric")
+	if models.IsCostMetric(metric) && !authMiddleware.FullAccess(c) {
+		return c.HTML(403, "<div class='text-red-600'>Bu grafik için yetkiniz yok</div>")
+	}
+
+	ctx := c.Request().Context()
+	series, err := h.service.GetSeries(ctx, metric, c.QueryParam("interval"), rng)
+	if err != nil {
+		if errors.Is(err, dashboarderrors.ErrRangeTooLong) {
+			return c.HTML(400, "<div class='text-red-600'>Günlük grafik en fazla 366 gün gösterebilir</div>")
+		}
+		return c.HTML(500, "<div class='text-red-600'>Grafik verisi alınamadı</div>")
+	}
+
+	labelLayout := "02.01"
+	if series.Interval == models.IntervalMonth {
+		labelLayout = "01.2006"
+	}
+	decimals := 2
+	if series.Metric == models.MetricUnits {
+		decimals = 0
+	}
+
+	labels := make([]string, 0, len(series.Points))
+	values := make([]float64, 0, len(series.Points))
+	for _, point := range series.Points {
+		labels = append(labels, point.Date.Format(labelLayout))
+		values = append(values, point.Value)
+	}
+
+	chart := charts.NewLineChart(chartTitles[series.Metric], labels, values, decimals)
+	return charts.LineChart(chart).Render(ctx, c.Response().Writer)
+}
+
+// parseRange reads the selected range; both dates are inclusive. Without a
+// start the range covers the DefaultRangeDays days up to the end, which
+// defaults to today.
+func parseRange(c echo.Context) (*models.DateRange, error) {
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if value := c.QueryParam("end"); value != "" {
+		date, err := time.ParseInLocation(dateLayout, value, time.Local)
+		if err != nil {
+			return nil, dashboarderrors.ErrInvalidRange
+		}
+		end = date
+	}
+
+	start := end.AddDate(0, 0, 1-models.DefaultRangeDays)
+	if value := c.QueryParam("start"); value != "" {
+		date, err := time.ParseInLocation(dateLayout, value, time.Local)
+		if err != nil {
+			return nil, dashboarderrors.ErrInvalidRange
+		}
+		start = date
+	}
+
+	if start.After(end) {
+		return nil, dashboarderrors.ErrInvalidRange
+	}
+
+	return &models.DateRange{Start: start, End: end.AddDate(0, 0, 1)}, nil
+}
+
+// seriesError maps series errors to HTTP errors
+func seriesError(err error) error {
+	switch err {
+	case dashboarderrors.ErrInvalidMetric, dashboarderrors.ErrInvalidInterval,
+		dashboarderrors.ErrInvalidRange, dashboarderrors.ErrRangeTooLong:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -1,12 +1,20 @@
 package handlers
 
 import (
+	"time"
+
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	"github.com/hsrvms/fixparts/internal/modules/dashboard/models"
 	layouts "github.com/hsrvms/fixparts/web/templates/layouts/base"
 	dashboardview "github.com/hsrvms/fixparts/web/templates/pages/dashboard"
 	"github.com/labstack/echo/v4"
 )
 
 func ViewHandler(c echo.Context) error {
-	component := layouts.Layout(dashboardview.Dashboard())
+	end := time.Now()
+	start := end.AddDate(0, 0, 1-models.DefaultRangeDays)
+	metrics := models.ChartMetrics(authMiddleware.FullAccess(c))
+	component := layouts.Layout(authMiddleware.CurrentUser(c).FullName,
+		dashboardview.Dashboard(start.Format(dateLayout), end.Format(dateLayout), metrics))
 	return component.Render(c.Request().Context(), c.Response().Writer)
 }
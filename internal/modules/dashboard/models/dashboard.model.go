@@ -5,6 +5,15 @@ type LowStockItem struct {
 	Name       string `json:"name"`
 	Current    int    `json:"current"`
 	Minimum    int    `json:"minimum"`
+	Warning    string `json:"warning,omitempty"`
+}
+
+type StockoutForecast struct {
+	PartNumber string  `json:"part_number"`
+	Name       string  `json:"name"`
+	Current    int     `json:"current"`
+	DailyRate  float64 `json:"daily_rate"`
+	DaysLeft   int     `json:"days_left"`
 }
 
 type RecentSale struct {
@@ -25,5 +34,5 @@ type RecentPurchase struct {
 	Date       string  `json:"date"`
 	PartNumber string  `json:"part_number"`
 	Supplier   string  `json:"supplier"`
-	Cost       float64 `json:"cost"`
+	Cost       float64 `json:"cost" access:"full"`
 }
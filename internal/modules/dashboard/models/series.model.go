@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Metrics the dashboard charts
+const (
+	MetricRevenue    = "revenue"
+	MetricUnits      = "units"
+	MetricPurchases  = "purchases"
+	MetricStockValue = "stock_value"
+)
+
+// Intervals a series is bucketed by
+const (
+	IntervalDay   = "day"
+	IntervalMonth = "month"
+)
+
+// DefaultRangeDays is the length of the range shown when none is selected
+const DefaultRangeDays = 30
+
+// MaxDailyPoints is the longest range a daily series can cover; ranges
+// longer than MaxAutoDailyDays are bucketed by month unless asked otherwise
+const (
+	MaxDailyPoints   = 366
+	MaxAutoDailyDays = 92
+)
+
+func IsValidMetric(metric string) bool {
+	switch metric {
+	case MetricRevenue, MetricUnits, MetricPurchases, MetricStockValue:
+		return true
+	}
+	return false
+}
+
+// IsCostMetric reports whether the metric is made of purchase costs, which
+// counter access does not see
+func IsCostMetric(metric string) bool {
+	return metric == MetricPurchases || metric == MetricStockValue
+}
+
+// ChartMetrics are the metrics charted on the dashboard, in order
+func ChartMetrics(fullAccess bool) []string {
+	metrics := []string{MetricRevenue, MetricUnits}
+	if fullAccess {
+		metrics = append(metrics, MetricPurchases, MetricStockValue)
+	}
+	return metrics
+}
+
+// DateRange is the period the dashboard widgets show, from Start up to but
+// not including End
+type DateRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Days is the number of days in the range
+func (r *DateRange) Days() int {
+	return int(r.End.Sub(r.Start).Hours()/24 + 0.5)
+}
+
+// SeriesPoint is the value of a metric in the bucket starting at Date. Flows
+// (revenue, units, purchases) are totals over the bucket; stock value is the
+// value at its end.
+type SeriesPoint struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
+}
+
+type Series struct {
+	Metric   string         `json:"metric"`
+	Interval string         `json:"interval"`
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Points   []*SeriesPoint `json:"points"`
+}
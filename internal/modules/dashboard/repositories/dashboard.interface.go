@@ -8,11 +8,12 @@ import (
 
 type DashboardRepository interface {
     GetLowStockCount(ctx context.Context) (int, error)
-    GetTodaySales(ctx context.Context) (float64, error)
+    GetRevenue(ctx context.Context, rng *models.DateRange) (float64, error)
     GetTotalInventoryCount(ctx context.Context) (int, error)
     GetVehicleCount(ctx context.Context) (int, error)
     GetLowStockItems(ctx context.Context) ([]*models.LowStockItem, error)
-    GetRecentSales(ctx context.Context) ([]*models.RecentSale, error)
-    GetTopSellers(ctx context.Context) ([]*models.TopSeller, error)
-    GetRecentPurchases(ctx context.Context) ([]*models.RecentPurchase, error)
+    GetRecentSales(ctx context.Context, rng *models.DateRange) ([]*models.RecentSale, error)
+    GetTopSellers(ctx context.Context, rng *models.DateRange) ([]*models.TopSeller, error)
+    GetRecentPurchases(ctx context.Context, rng *models.DateRange) ([]*models.RecentPurchase, error)
+    GetSeries(ctx context.Context, metric, interval string, rng *models.DateRange) ([]*models.SeriesPoint, error)
 }
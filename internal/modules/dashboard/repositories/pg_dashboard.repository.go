@@ -28,14 +28,20 @@ func (r *PostgresDashboardRepository) GetLowStockCount(ctx context.Context) (int
 	return count, err
 }
 
-func (r *PostgresDashboardRepository) GetTodaySales(ctx context.Context) (float64, error) {
+// GetRevenue returns the revenue of the range net of the refunds given in
+// it, whichever day the returned parts were sold
+func (r *PostgresDashboardRepository) GetRevenue(ctx context.Context, rng *models.DateRange) (float64, error) {
 	query := `
-        SELECT COALESCE(SUM(total_price), 0)
-        FROM sales
-        WHERE DATE(created_at) = CURRENT_DATE
+        SELECT
+            (SELECT COALESCE(SUM(total_amount), 0)
+             FROM sales
+             WHERE date >= $1 AND date < $2)
+          - (SELECT COALESCE(SUM(refund_amount), 0)
+             FROM sale_returns
+             WHERE date >= $1 AND date < $2)
     `
 	var total float64
-	err := r.db.Pool.QueryRow(ctx, query).Scan(&total)
+	err := r.db.Pool.QueryRow(ctx, query, rng.Start, rng.End).Scan(&total)
 	return total, err
 }
 
@@ -86,24 +92,30 @@ func (r *PostgresDashboardRepository) GetLowStockItems(ctx context.Context) ([]*
 		if err != nil {
 			return nil, err
 		}
+		if item.Current < 0 {
+			item.Warning = "Negatif stok"
+		}
 		items = append(items, item)
 	}
 	return items, rows.Err()
 }
 
-func (r *PostgresDashboardRepository) GetRecentSales(ctx context.Context) ([]*models.RecentSale, error) {
+func (r *PostgresDashboardRepository) GetRecentSales(ctx context.Context, rng *models.DateRange) ([]*models.RecentSale, error) {
 	query := `
 		SELECT
             TO_CHAR(s.date, 'DD/MM/YYYY') as date,
-            i.item_name as part,
-            s.customer_name as customer,
-            s.total_price as total
+            STRING_AGG(i.item_name, ', ' ORDER BY sl.sale_line_id) as part,
+            COALESCE(s.customer_name, '') as customer,
+            s.total_amount as total
         FROM sales s
-        JOIN items i ON s.item_id = i.item_id
+        JOIN sale_lines sl ON s.sale_id = sl.sale_id
+        JOIN items i ON sl.item_id = i.item_id
+        WHERE s.date >= $1 AND s.date < $2
+        GROUP BY s.sale_id, s.date, s.customer_name, s.total_amount
         ORDER BY s.date DESC
         LIMIT 10
     `
-	rows, err := r.db.Pool.Query(ctx, query)
+	rows, err := r.db.Pool.Query(ctx, query, rng.Start, rng.End)
 	if err != nil {
 		return nil, err
 	}
@@ -126,21 +138,22 @@ func (r *PostgresDashboardRepository) GetRecentSales(ctx context.Context) ([]*mo
 	return sales, rows.Err()
 }
 
-func (r *PostgresDashboardRepository) GetTopSellers(ctx context.Context) ([]*models.TopSeller, error) {
+func (r *PostgresDashboardRepository) GetTopSellers(ctx context.Context, rng *models.DateRange) ([]*models.TopSeller, error) {
 	query := `
 		SELECT
             i.part_number,
             i.item_name as name,
-            COUNT(*) as sold,
-            SUM(s.total_price) as revenue
-        FROM sales s
-        JOIN items i ON s.item_id = i.item_id
-        WHERE s.date >= CURRENT_DATE - INTERVAL '30 days'
+            SUM(sl.quantity) as sold,
+            SUM(sl.total_price) as revenue
+        FROM sale_lines sl
+        JOIN sales s ON sl.sale_id = s.sale_id
+        JOIN items i ON sl.item_id = i.item_id
+        WHERE s.date >= $1 AND s.date < $2
         GROUP BY i.part_number, i.item_name
         ORDER BY sold DESC
         LIMIT 10
     `
-	rows, err := r.db.Pool.Query(ctx, query)
+	rows, err := r.db.Pool.Query(ctx, query, rng.Start, rng.End)
 	if err != nil {
 		return nil, err
 	}
@@ -163,7 +176,7 @@ func (r *PostgresDashboardRepository) GetTopSellers(ctx context.Context) ([]*mod
 	return items, rows.Err()
 }
 
-func (r *PostgresDashboardRepository) GetRecentPurchases(ctx context.Context) ([]*models.RecentPurchase, error) {
+func (r *PostgresDashboardRepository) GetRecentPurchases(ctx context.Context, rng *models.DateRange) ([]*models.RecentPurchase, error) {
 	query := `
 		SELECT
             TO_CHAR(p.date, 'DD/MM/YYYY') as date,
@@ -173,10 +186,11 @@ func (r *PostgresDashboardRepository) GetRecentPurchases(ctx context.Context) ([
         FROM purchases p
         JOIN items i ON p.item_id = i.item_id
         JOIN suppliers s ON p.supplier_id = s.supplier_id
+        WHERE p.date >= $1 AND p.date < $2
         ORDER BY p.date DESC
         LIMIT 10
     `
-	rows, err := r.db.Pool.Query(ctx, query)
+	rows, err := r.db.Pool.Query(ctx, query, rng.Start, rng.End)
 	if err != nil {
 		return nil, err
 	}
@@ -198,3 +212,91 @@ func (r *PostgresDashboardRepository) GetRecentPurchases(ctx context.Context) ([
 	}
 	return purchases, rows.Err()
 }
+
+// seriesValues are the dated values each flow metric sums per bucket. Only
+// values in the range ($1 to $2) are read.
+var seriesValues = map[string]string{
+	models.MetricRevenue: `
+            SELECT date, total_amount as value FROM sales
+            WHERE date >= $1 AND date < $2
+            UNION ALL
+            SELECT date, -refund_amount FROM sale_returns
+            WHERE date >= $1 AND date < $2`,
+	models.MetricUnits: `
+            SELECT s.date, sl.quantity as value
+            FROM sale_lines sl
+            JOIN sales s ON sl.sale_id = s.sale_id
+            WHERE s.date >= $1 AND s.date < $2
+            UNION ALL
+            SELECT sr.date, -rl.quantity
+            FROM sale_return_lines rl
+            JOIN sale_returns sr ON rl.return_id = sr.return_id
+            WHERE sr.date >= $1 AND sr.date < $2`,
+	models.MetricPurchases: `
+            SELECT date, total_cost as value FROM purchases
+            WHERE date >= $1 AND date < $2`,
+}
+
+// GetSeries returns one point per day or month of the range, zero filled.
+// Flows are summed per bucket; stock value is the ledger value, under the
+// costing method setting, at the end of each bucket.
+func (r *PostgresDashboardRepository) GetSeries(ctx context.Context, metric, interval string, rng *models.DateRange) ([]*models.SeriesPoint, error) {
+	buckets := `
+        buckets AS (
+            SELECT generate_series(
+                date_trunc($3, $1::TIMESTAMPTZ),
+                date_trunc($3, $2::TIMESTAMPTZ - INTERVAL '1 second'),
+                ('1 ' || $3)::INTERVAL
+            ) as bucket
+        )`
+
+	var query string
+	if metric == models.MetricStockValue {
+		query = `
+        WITH` + buckets + `,
+        ledger AS (
+            SELECT
+                m.created_at,
+                CASE WHEN (SELECT value FROM settings WHERE key = 'costing_method') = 'average'
+                    THEN m.cost_average ELSE m.cost_fifo END as value
+            FROM stock_movements m
+            WHERE m.created_at < $2
+        )
+        SELECT
+            b.bucket,
+            (SELECT COALESCE(SUM(value), 0) FROM ledger WHERE created_at < $1)
+              + SUM(COALESCE(SUM(l.value), 0)) OVER (ORDER BY b.bucket)
+        FROM buckets b
+        LEFT JOIN ledger l ON l.created_at >= $1 AND date_trunc($3, l.created_at) = b.bucket
+        GROUP BY b.bucket
+        ORDER BY b.bucket
+    `
+	} else {
+		query = `
+        WITH` + buckets + `,
+        series_values AS (` + seriesValues[metric] + `
+        )
+        SELECT b.bucket, COALESCE(SUM(v.value), 0)::FLOAT8
+        FROM buckets b
+        LEFT JOIN series_values v ON date_trunc($3, v.date) = b.bucket
+        GROUP BY b.bucket
+        ORDER BY b.bucket
+    `
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, rng.Start, rng.End, interval)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []*models.SeriesPoint
+	for rows.Next() {
+		point := &models.SeriesPoint{}
+		if err := rows.Scan(&point.Date, &point.Value); err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+	return points, rows.Err()
+}
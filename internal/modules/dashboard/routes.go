@@ -1,9 +1,13 @@
 package dashboard
 
 import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
 	"github.com/hsrvms/fixparts/internal/modules/dashboard/handlers"
 	"github.com/hsrvms/fixparts/internal/modules/dashboard/repositories"
 	"github.com/hsrvms/fixparts/internal/modules/dashboard/services"
+	forecasts "github.com/hsrvms/fixparts/internal/modules/forecasting/repositories"
+	forecasting "github.com/hsrvms/fixparts/internal/modules/forecasting/services"
 	"github.com/hsrvms/fixparts/pkg/db"
 	"github.com/labstack/echo/v4"
 )
@@ -11,18 +15,26 @@ import (
 func RegisterRoutes(e *echo.Echo, api *echo.Group, database *db.Database) {
 
 	repo := repositories.NewPostgresDashboardRepository(database)
-	service := services.NewDashboardService(repo)
+	service := services.NewDashboardService(
+		repo,
+		forecasting.NewForecastService(forecasts.NewPostgresForecastRepository(database)),
+	)
 	apiHandler := handlers.NewDashboardAPIHandler(service)
 
 	e.GET("/", handlers.ViewHandler)
 
 	// API routes for HTMX requests
-	api.GET("/inventory/low-stock-count", apiHandler.GetLowStockCount)
-	api.GET("/sales/today", apiHandler.GetTodaySales)
-	api.GET("/inventory/total-count", apiHandler.GetTotalInventoryCount)
-	api.GET("/compatibility/vehicle-count", apiHandler.GetVehicleCount)
-	api.GET("/inventory/low-stock", apiHandler.GetLowStockItems)
-	api.GET("/sales/recent", apiHandler.GetRecentSales)
-	api.GET("/sales/top-sellers", apiHandler.GetTopSellers)
-	api.GET("/purchases/recent", apiHandler.GetRecentPurchases)
+	view := authMiddleware.Require(authModels.PermDashboardView)
+	api.GET("/inventory/low-stock-count", apiHandler.GetLowStockCount, view)
+	api.GET("/sales/today", apiHandler.GetTodaySales, view)
+	api.GET("/sales/revenue", apiHandler.GetRevenue, view)
+	api.GET("/inventory/total-count", apiHandler.GetTotalInventoryCount, view)
+	api.GET("/compatibility/vehicle-count", apiHandler.GetVehicleCount, view)
+	api.GET("/inventory/low-stock", apiHandler.GetLowStockItems, view)
+	api.GET("/inventory/stockout-forecast", apiHandler.GetStockoutForecast, view)
+	api.GET("/sales/recent", apiHandler.GetRecentSales, view)
+	api.GET("/sales/top-sellers", apiHandler.GetTopSellers, view)
+	api.GET("/purchases/recent", apiHandler.GetRecentPurchases, view)
+	api.GET("/dashboard/series/:metric", apiHandler.GetSeries, view)
+	api.GET("/dashboard/charts/:metric", apiHandler.GetChart, view)
 }
@@ -3,17 +3,26 @@ package services
 import (
 	"context"
 
+	dashboarderrors "github.com/hsrvms/fixparts/internal/modules/dashboard/errors"
 	"github.com/hsrvms/fixparts/internal/modules/dashboard/models"
 	"github.com/hsrvms/fixparts/internal/modules/dashboard/repositories"
+	forecastModels "github.com/hsrvms/fixparts/internal/modules/forecasting/models"
+	forecasting "github.com/hsrvms/fixparts/internal/modules/forecasting/services"
 )
 
+// stockoutHorizon is how many days ahead the dashboard warns about items
+// running out
+const stockoutHorizon = 30
+
 type dashboardService struct {
-	repo repositories.DashboardRepository
+	repo      repositories.DashboardRepository
+	forecasts forecasting.ForecastService
 }
 
-func NewDashboardService(repo repositories.DashboardRepository) DashboardService {
+func NewDashboardService(repo repositories.DashboardRepository, forecasts forecasting.ForecastService) DashboardService {
 	return &dashboardService{
-		repo: repo,
+		repo:      repo,
+		forecasts: forecasts,
 	}
 }
 
@@ -21,8 +30,8 @@ func (s *dashboardService) GetLowStockCount(ctx context.Context) (int, error) {
 	return s.repo.GetLowStockCount(ctx)
 }
 
-func (s *dashboardService) GetTodaySales(ctx context.Context) (float64, error) {
-	return s.repo.GetTodaySales(ctx)
+func (s *dashboardService) GetRevenue(ctx context.Context, rng *models.DateRange) (float64, error) {
+	return s.repo.GetRevenue(ctx, rng)
 }
 
 func (s *dashboardService) GetTotalInventoryCount(ctx context.Context) (int, error) {
@@ -37,14 +46,82 @@ func (s *dashboardService) GetLowStockItems(ctx context.Context) ([]*models.LowS
 	return s.repo.GetLowStockItems(ctx)
 }
 
-func (s *dashboardService) GetRecentSales(ctx context.Context) ([]*models.RecentSale, error) {
-	return s.repo.GetRecentSales(ctx)
+// GetStockoutForecast returns the items expected to run out within the
+// horizon, soonest first
+func (s *dashboardService) GetStockoutForecast(ctx context.Context) ([]*models.StockoutForecast, error) {
+	horizon := stockoutHorizon
+	forecasts, err := s.forecasts.GetForecasts(ctx, &forecastModels.ForecastFilter{
+		Window:  forecastModels.DefaultWindow,
+		Horizon: &horizon,
+		Limit:   10,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*models.StockoutForecast, 0, len(forecasts))
+	for _, forecast := range forecasts {
+		items = append(items, &models.StockoutForecast{
+			PartNumber: forecast.PartNumber,
+			Name:       forecast.ItemName,
+			Current:    forecast.CurrentStock,
+			DailyRate:  forecast.DailyForecast,
+			DaysLeft:   *forecast.DaysUntilStockout,
+		})
+	}
+	return items, nil
+}
+
+func (s *dashboardService) GetRecentSales(ctx context.Context, rng *models.DateRange) ([]*models.RecentSale, error) {
+	return s.repo.GetRecentSales(ctx, rng)
+}
+
+func (s *dashboardService) GetTopSellers(ctx context.Context, rng *models.DateRange) ([]*models.TopSeller, error) {
+	return s.repo.GetTopSellers(ctx, rng)
 }
 
-func (s *dashboardService) GetTopSellers(ctx context.Context) ([]*models.TopSeller, error) {
-	return s.repo.GetTopSellers(ctx)
+func (s *dashboardService) GetRecentPurchases(ctx context.Context, rng *models.DateRange) ([]*models.RecentPurchase, error) {
+	return s.repo.GetRecentPurchases(ctx, rng)
 }
 
-func (s *dashboardService) GetRecentPurchases(ctx context.Context) ([]*models.RecentPurchase, error) {
-	return s.repo.GetRecentPurchases(ctx)
+// GetSeries returns a metric over the range by day or month. Without an
+// interval, long ranges are bucketed by month and short ones by day.
+func (s *dashboardService) GetSeries(ctx context.Context, metric, interval string, rng *models.DateRange) (*models.Series, error) {
+	if !models.IsValidMetric(metric) {
+		return nil, dashboarderrors.ErrInvalidMetric
+	}
+	if !rng.Start.Before(rng.End) {
+		return nil, dashboarderrors.ErrInvalidRange
+	}
+
+	switch interval {
+	case "":
+		interval = models.IntervalDay
+		if rng.Days() > models.MaxAutoDailyDays {
+			interval = models.IntervalMonth
+		}
+	case models.IntervalDay:
+		if rng.Days() > models.MaxDailyPoints {
+			return nil, dashboarderrors.ErrRangeTooLong
+		}
+	case models.IntervalMonth:
+	default:
+		return nil, dashboarderrors.ErrInvalidInterval
+	}
+
+	points, err := s.repo.GetSeries(ctx, metric, interval, rng)
+	if err != nil {
+		return nil, err
+	}
+	if points == nil {
+		points = []*models.SeriesPoint{}
+	}
+
+	return &models.Series{
+		Metric:   metric,
+		Interval: interval,
+		Start:    rng.Start,
+		End:      rng.End,
+		Points:   points,
+	}, nil
 }
@@ -0,0 +1,10 @@
+package forecasterrors
+
+import "errors"
+
+var (
+	ErrInvalidWindow  = errors.New("forecast window must be 7, 30 or 90 days")
+	ErrInvalidHorizon = errors.New("forecast horizon cannot be negative")
+	ErrInvalidItemID  = errors.New("invalid item ID")
+	ErrItemNotFound   = errors.New("item not found")
+)
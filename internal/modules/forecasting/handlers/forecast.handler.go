@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	forecasterrors "github.com/hsrvms/fixparts/internal/modules/forecasting/errors"
+	"github.com/hsrvms/fixparts/internal/modules/forecasting/models"
+	"github.com/hsrvms/fixparts/internal/modules/forecasting/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ForecastHandler struct {
+	service services.ForecastService
+}
+
+func NewForecastHandler(service services.ForecastService) *ForecastHandler {
+	return &ForecastHandler{
+		service: service,
+	}
+}
+
+// GetForecasts handles retrieval of the demand forecasts of the active items,
+// those running out soonest first
+func (h *ForecastHandler) GetForecasts(c echo.Context) error {
+	filter, err := parseFilter(c)
+	if err != nil {
+		return err
+	}
+
+	if search := c.QueryParam("search"); search != "" {
+		filter.SearchTerm = &search
+	}
+
+	if horizon := c.QueryParam("horizon"); horizon != "" {
+		days, err := strconv.Atoi(horizon)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, forecasterrors.ErrInvalidHorizon.Error())
+		}
+		filter.Horizon = &days
+	}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		if n, err := strconv.Atoi(limit); err == nil {
+			filter.Limit = n
+		}
+	}
+
+	ctx := c.Request().Context()
+	forecasts, err := h.service.GetForecasts(ctx, filter)
+	if err != nil {
+		return forecastError(err)
+	}
+
+	return c.JSON(http.StatusOK, forecasts)
+}
+
+// GetItemForecast handles retrieval of the demand forecast of an item
+func (h *ForecastHandler) GetItemForecast(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	filter, err := parseFilter(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	forecast, err := h.service.GetItemForecast(ctx, itemID, filter)
+	if err != nil {
+		return forecastError(err)
+	}
+
+	return c.JSON(http.StatusOK, forecast)
+}
+
+// parseFilter reads the forecast window and the seasonality flag
+func parseFilter(c echo.Context) (*models.ForecastFilter, error) {
+	filter := &models.ForecastFilter{}
+
+	if window := c.QueryParam("window"); window != "" {
+		days, err := strconv.Atoi(window)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, forecasterrors.ErrInvalidWindow.Error())
+		}
+		filter.Window = days
+	}
+
+	if seasonality := c.QueryParam("seasonality"); seasonality != "" {
+		enabled, err := strconv.ParseBool(seasonality)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid seasonality flag")
+		}
+		filter.Seasonality = enabled
+	}
+
+	return filter, nil
+}
+
+// forecastError maps forecast errors to HTTP errors
+func forecastError(err error) error {
+	switch err {
+	case forecasterrors.ErrItemNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case forecasterrors.ErrInvalidWindow, forecasterrors.ErrInvalidHorizon, forecasterrors.ErrInvalidItemID:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
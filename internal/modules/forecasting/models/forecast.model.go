@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Forecast windows, in days
+const (
+	Window7       = 7
+	Window30      = 30
+	Window90      = 90
+	DefaultWindow = Window30
+)
+
+// Windows are the sales windows a forecast reports the velocity over
+var Windows = []int{Window7, Window30, Window90}
+
+// SeasonalityThreshold is how far, as a ratio in either direction, the sales
+// of the coming month last year must be from the average month of that year
+// for an item to be flagged as seasonal
+const SeasonalityThreshold = 1.5
+
+// SeasonalityDays is the sales history needed to look for seasonality
+const SeasonalityDays = 365
+
+// WindowVelocity is the average daily quantity sold over a window
+type WindowVelocity struct {
+	Days         int     `json:"days"`
+	QuantitySold int     `json:"quantity_sold"`
+	DailyRate    float64 `json:"daily_rate"`
+}
+
+// ItemForecast is the expected demand of an item. Sales are counted net of
+// returns, and days without sales count as zero, so items that have not
+// sold are still forecast. The days until stockout divide the stock on hand
+// by the exponential moving average of the daily sales over the selected
+// window.
+type ItemForecast struct {
+	ItemID       int    `json:"item_id" db:"item_id"`
+	PartNumber   string `json:"part_number" db:"part_number"`
+	ItemName     string `json:"item_name" db:"item_name"`
+	CurrentStock int    `json:"current_stock" db:"current_stock"`
+	// Days since the first sale of the item, nil if it never sold
+	HistoryDays *int `json:"history_days,omitempty" db:"history_days"`
+
+	// Computed by the forecast service
+	Window            int               `json:"window" db:"-"`
+	Velocities        []*WindowVelocity `json:"velocities" db:"-"`
+	DailyForecast     float64           `json:"daily_forecast" db:"-"`
+	DaysUntilStockout *int              `json:"days_until_stockout" db:"-"`
+	StockoutDate      *time.Time        `json:"stockout_date,omitempty" db:"-"`
+	SeasonalIndex     *float64          `json:"seasonal_index,omitempty" db:"-"`
+	Seasonal          bool              `json:"seasonal" db:"-"`
+}
+
+// DailySales is the net quantity of an item sold on a day, counted in days
+// before today
+type DailySales struct {
+	ItemID   int `db:"item_id"`
+	DaysAgo  int `db:"days_ago"`
+	Quantity int `db:"quantity"`
+}
+
+type ForecastFilter struct {
+	ItemID      *int    `query:"item_id"`
+	SearchTerm  *string `query:"search"`
+	Window      int     `query:"window"`
+	Seasonality bool    `query:"seasonality"`
+	// Only items expected to run out within this many days
+	Horizon *int `query:"horizon"`
+	Limit   int  `query:"limit"`
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/forecasting/models"
+)
+
+type ForecastRepository interface {
+	GetItems(ctx context.Context, filter *models.ForecastFilter) ([]*models.ItemForecast, error)
+	GetDailySales(ctx context.Context, itemIDs []int, days int) ([]*models.DailySales, error)
+}
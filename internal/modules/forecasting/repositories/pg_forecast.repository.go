@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hsrvms/fixparts/internal/modules/forecasting/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+)
+
+type PostgresForecastRepository struct {
+	db *db.Database
+}
+
+func NewPostgresForecastRepository(database *db.Database) ForecastRepository {
+	return &PostgresForecastRepository{
+		db: database,
+	}
+}
+
+// GetItems returns the items to forecast: the given item, or every active
+// item matching the search term
+func (r *PostgresForecastRepository) GetItems(ctx context.Context, filter *models.ForecastFilter) ([]*models.ItemForecast, error) {
+	query := `
+        SELECT
+            i.item_id, i.part_number, i.item_name, i.current_stock,
+            (
+                SELECT CURRENT_DATE - MIN(s.date)::date
+                FROM sale_lines sl
+                JOIN sales s ON sl.sale_id = s.sale_id
+                WHERE sl.item_id = i.item_id
+            ) as history_days
+        FROM items i
+    `
+	params := []interface{}{}
+	paramCount := 1
+
+	if filter.ItemID != nil {
+		query += fmt.Sprintf(" WHERE i.item_id = $%d", paramCount)
+		params = append(params, *filter.ItemID)
+		paramCount++
+	} else {
+		query += " WHERE i.is_active = true"
+	}
+
+	if filter.SearchTerm != nil {
+		query += fmt.Sprintf(" AND (i.part_number ILIKE $%d OR i.item_name ILIKE $%d)", paramCount, paramCount)
+		params = append(params, "%"+*filter.SearchTerm+"%")
+		paramCount++
+	}
+
+	query += " ORDER BY i.part_number"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.ItemForecast
+	for rows.Next() {
+		item := &models.ItemForecast{}
+		err := rows.Scan(
+			&item.ItemID,
+			&item.PartNumber,
+			&item.ItemName,
+			&item.CurrentStock,
+			&item.HistoryDays,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// GetDailySales returns the quantity of the given items sold per day over
+// the last days days, today included. Returns are taken off the day they
+// were processed, so a day can be negative.
+func (r *PostgresForecastRepository) GetDailySales(ctx context.Context, itemIDs []int, days int) ([]*models.DailySales, error) {
+	if len(itemIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+        SELECT item_id, days_ago, SUM(quantity)::integer as quantity
+        FROM (
+            SELECT sl.item_id, CURRENT_DATE - s.date::date as days_ago, sl.quantity
+            FROM sale_lines sl
+            JOIN sales s ON sl.sale_id = s.sale_id
+            WHERE sl.item_id = ANY($1)
+            AND s.date >= CURRENT_DATE - ($2::integer - 1)
+            UNION ALL
+            SELECT rl.item_id, CURRENT_DATE - sr.date::date as days_ago, -rl.quantity
+            FROM sale_return_lines rl
+            JOIN sale_returns sr ON rl.return_id = sr.return_id
+            WHERE rl.item_id = ANY($1)
+            AND sr.date >= CURRENT_DATE - ($2::integer - 1)
+        ) movements
+        WHERE days_ago BETWEEN 0 AND $2::integer - 1
+        GROUP BY item_id, days_ago
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, itemIDs, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sales []*models.DailySales
+	for rows.Next() {
+		day := &models.DailySales{}
+		if err := rows.Scan(&day.ItemID, &day.DaysAgo, &day.Quantity); err != nil {
+			return nil, err
+		}
+		sales = append(sales, day)
+	}
+
+	return sales, rows.Err()
+}
@@ -0,0 +1,21 @@
+package forecasting
+
+import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/forecasting/handlers"
+	"github.com/hsrvms/fixparts/internal/modules/forecasting/repositories"
+	"github.com/hsrvms/fixparts/internal/modules/forecasting/services"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database) {
+	repo := repositories.NewPostgresForecastRepository(database)
+	service := services.NewForecastService(repo)
+	handler := handlers.NewForecastHandler(service)
+
+	forecasts := api.Group("/forecasts", authMiddleware.Require(authModels.PermReportsView))
+	forecasts.GET("", handler.GetForecasts)
+	api.GET("/items/:itemId/forecast", handler.GetItemForecast, authMiddleware.Require(authModels.PermReportsView))
+}
@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/forecasting/models"
+)
+
+type ForecastService interface {
+	GetForecasts(ctx context.Context, filter *models.ForecastFilter) ([]*models.ItemForecast, error)
+	GetItemForecast(ctx context.Context, itemID int, filter *models.ForecastFilter) (*models.ItemForecast, error)
+}
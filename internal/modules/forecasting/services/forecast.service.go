@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	forecasterrors "github.com/hsrvms/fixparts/internal/modules/forecasting/errors"
+	"github.com/hsrvms/fixparts/internal/modules/forecasting/models"
+	"github.com/hsrvms/fixparts/internal/modules/forecasting/repositories"
+)
+
+type forecastService struct {
+	repo repositories.ForecastRepository
+}
+
+func NewForecastService(repo repositories.ForecastRepository) ForecastService {
+	return &forecastService{
+		repo: repo,
+	}
+}
+
+// GetForecasts forecasts the matching items, those running out soonest
+// first. Items without demand are listed last.
+func (s *forecastService) GetForecasts(ctx context.Context, filter *models.ForecastFilter) ([]*models.ItemForecast, error) {
+	if filter == nil {
+		filter = &models.ForecastFilter{}
+	}
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+
+	items, err := s.forecast(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Horizon != nil {
+		items = slices.DeleteFunc(items, func(item *models.ItemForecast) bool {
+			return item.DaysUntilStockout == nil || *item.DaysUntilStockout > *filter.Horizon
+		})
+	}
+
+	sort.SliceStable(items, func(a, b int) bool {
+		left, right := items[a].DaysUntilStockout, items[b].DaysUntilStockout
+		if left == nil || right == nil {
+			return right == nil && left != nil
+		}
+		return *left < *right
+	})
+
+	if filter.Limit > 0 && len(items) > filter.Limit {
+		items = items[:filter.Limit]
+	}
+
+	return items, nil
+}
+
+func (s *forecastService) GetItemForecast(ctx context.Context, itemID int, filter *models.ForecastFilter) (*models.ItemForecast, error) {
+	if itemID <= 0 {
+		return nil, forecasterrors.ErrInvalidItemID
+	}
+	if filter == nil {
+		filter = &models.ForecastFilter{}
+	}
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+
+	filter.ItemID = &itemID
+	filter.SearchTerm = nil
+
+	items, err := s.forecast(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, forecasterrors.ErrItemNotFound
+	}
+
+	return items[0], nil
+}
+
+// forecast loads the items and their daily sales and computes the forecast
+// of each
+func (s *forecastService) forecast(ctx context.Context, filter *models.ForecastFilter) ([]*models.ItemForecast, error) {
+	items, err := s.repo.GetItems(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	days := models.Window90
+	if filter.Seasonality {
+		days = models.SeasonalityDays
+	}
+
+	itemIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.ItemID)
+	}
+
+	sales, err := s.repo.GetDailySales(ctx, itemIDs, days)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make(map[int][]float64, len(items))
+	for _, item := range items {
+		series[item.ItemID] = make([]float64, days)
+	}
+	for _, day := range sales {
+		if daily, ok := series[day.ItemID]; ok && day.DaysAgo >= 0 && day.DaysAgo < days {
+			daily[day.DaysAgo] = float64(day.Quantity)
+		}
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, item := range items {
+		forecastItem(item, series[item.ItemID], filter, today)
+	}
+
+	return items, nil
+}
+
+// forecastItem fills in the forecast of an item from its daily sales, where
+// daily[n] is the quantity sold n days ago.
+//
+// The daily forecast is an exponential moving average over the selected
+// window with the usual smoothing factor of 2/(window+1), seeded with the
+// window's plain average so a quiet start of the window does not drag it
+// down. Seasonality compares the sales of the 30 days that followed today's
+// date last year with the average 30 days of the past year; it is only
+// computed for items with a full year of sales history.
+func forecastItem(item *models.ItemForecast, daily []float64, filter *models.ForecastFilter, today time.Time) {
+	item.Window = filter.Window
+	item.Velocities = make([]*models.WindowVelocity, 0, len(models.Windows))
+	for _, window := range models.Windows {
+		sold := sum(daily[:window])
+		item.Velocities = append(item.Velocities, &models.WindowVelocity{
+			Days:         window,
+			QuantitySold: int(sold),
+			DailyRate:    round(math.Max(sold, 0) / float64(window)),
+		})
+	}
+
+	alpha := 2 / float64(filter.Window+1)
+	ema := sum(daily[:filter.Window]) / float64(filter.Window)
+	for n := filter.Window - 1; n >= 0; n-- {
+		ema = alpha*daily[n] + (1-alpha)*ema
+	}
+	item.DailyForecast = round(math.Max(ema, 0))
+
+	switch {
+	case item.CurrentStock <= 0:
+		days := 0
+		item.DaysUntilStockout = &days
+	case item.DailyForecast > 0:
+		days := int(float64(item.CurrentStock) / item.DailyForecast)
+		item.DaysUntilStockout = &days
+	}
+	if item.DaysUntilStockout != nil {
+		date := today.AddDate(0, 0, *item.DaysUntilStockout)
+		item.StockoutDate = &date
+	}
+
+	if !filter.Seasonality || item.HistoryDays == nil || *item.HistoryDays < models.SeasonalityDays {
+		return
+	}
+
+	month := float64(models.Window30)
+	yearly := sum(daily[:models.SeasonalityDays]) / float64(models.SeasonalityDays) * month
+	if yearly <= 0 {
+		return
+	}
+
+	lastYear := sum(daily[models.SeasonalityDays-models.Window30 : models.SeasonalityDays])
+	index := round(math.Max(lastYear, 0) / yearly)
+	item.SeasonalIndex = &index
+	item.Seasonal = index >= models.SeasonalityThreshold || index <= 1/models.SeasonalityThreshold
+}
+
+func validateFilter(filter *models.ForecastFilter) error {
+	if filter.Window == 0 {
+		filter.Window = models.DefaultWindow
+	}
+	if !slices.Contains(models.Windows, filter.Window) {
+		return forecasterrors.ErrInvalidWindow
+	}
+	if filter.Horizon != nil && *filter.Horizon < 0 {
+		return forecasterrors.ErrInvalidHorizon
+	}
+	return nil
+}
+
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -7,4 +7,5 @@ var (
 	ErrParentCategoryNotFound   = errors.New("parent category not found")
 	ErrCategoryHasSubcategories = errors.New("category has subcategories and cannot be deleted")
 	ErrCircularReference        = errors.New("circular reference detected: a category cannot be its own parent")
+	ErrInvalidStockPolicy       = errors.New("negative stock policy must be disallow, allow_with_warning or allow")
 )
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"strconv"
 
+	auditMiddleware "github.com/hsrvms/fixparts/internal/modules/audit/middleware"
+	auditModels "github.com/hsrvms/fixparts/internal/modules/audit/models"
 	categoryerrors "github.com/hsrvms/fixparts/internal/modules/inventory/categories/errors"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/categories/models"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/categories/services"
@@ -74,7 +76,7 @@ func (h *CategoryHandler) CreateCategory(c echo.Context) error {
 	id, err := h.service.CreateCategory(ctx, category)
 	if err != nil {
 		switch err {
-		case categoryerrors.ErrParentCategoryNotFound:
+		case categoryerrors.ErrParentCategoryNotFound, categoryerrors.ErrInvalidStockPolicy:
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		default:
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
@@ -82,6 +84,13 @@ func (h *CategoryHandler) CreateCategory(c echo.Context) error {
 	}
 
 	category.CategoryID = id
+
+	created, err := h.service.GetCategoryByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordCreate(c, auditModels.EntityCategory, id, created)
+
 	return c.JSON(http.StatusCreated, category)
 }
 
@@ -99,18 +108,33 @@ func (h *CategoryHandler) UpdateCategory(c echo.Context) error {
 	category.CategoryID = id
 
 	ctx := c.Request().Context()
+	existing, err := h.service.GetCategoryByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if existing == nil {
+		return echo.NewHTTPError(http.StatusNotFound, categoryerrors.ErrCategoryNotFound.Error())
+	}
+
 	err = h.service.UpdateCategory(ctx, category)
 	if err != nil {
 		switch err {
 		case categoryerrors.ErrCategoryNotFound:
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
-		case categoryerrors.ErrParentCategoryNotFound, categoryerrors.ErrCircularReference:
+		case categoryerrors.ErrParentCategoryNotFound, categoryerrors.ErrCircularReference,
+			categoryerrors.ErrInvalidStockPolicy:
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		default:
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 	}
 
+	updated, err := h.service.GetCategoryByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordUpdate(c, auditModels.EntityCategory, id, existing, updated)
+
 	return c.JSON(http.StatusOK, category)
 }
 
@@ -121,6 +145,14 @@ func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
+	existing, err := h.service.GetCategoryByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if existing == nil {
+		return echo.NewHTTPError(http.StatusNotFound, categoryerrors.ErrCategoryNotFound.Error())
+	}
+
 	err = h.service.DeleteCategory(ctx, id)
 	if err != nil {
 		switch err {
@@ -132,6 +164,7 @@ func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 	}
+	auditMiddleware.RecordDelete(c, auditModels.EntityCategory, id, existing)
 
 	return c.NoContent(http.StatusNoContent)
 }
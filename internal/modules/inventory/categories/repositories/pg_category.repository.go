@@ -24,7 +24,8 @@ func NewPostgresCategoryRepository(database *db.Database) CategoryRepository {
 // GetAll retrieves all categories from the database
 func (r *PostgresCategoryRepository) GetAll(ctx context.Context) ([]*models.Category, error) {
 	query := `
-		SELECT category_id, category_name, description, parent_category_id, created_at, updated_at
+		SELECT category_id, category_name, description, parent_category_id,
+			negative_stock_policy, created_at, updated_at
 		FROM categories
 		ORDER BY category_name
 	`
@@ -43,6 +44,7 @@ func (r *PostgresCategoryRepository) GetAll(ctx context.Context) ([]*models.Cate
 			&category.CategoryName,
 			&category.Description,
 			&category.ParentCategoryID,
+			&category.NegativeStockPolicy,
 			&category.CreatedAt,
 			&category.UpdatedAt,
 		)
@@ -62,7 +64,8 @@ func (r *PostgresCategoryRepository) GetAll(ctx context.Context) ([]*models.Cate
 // GetByID retrieves a category by its ID
 func (r *PostgresCategoryRepository) GetByID(ctx context.Context, id int) (*models.Category, error) {
 	query := `
-		SELECT category_id, category_name, description, parent_category_id, created_at, updated_at
+		SELECT category_id, category_name, description, parent_category_id,
+			negative_stock_policy, created_at, updated_at
 		FROM categories
 		WHERE category_id = $1
 	`
@@ -73,6 +76,7 @@ func (r *PostgresCategoryRepository) GetByID(ctx context.Context, id int) (*mode
 		&category.CategoryName,
 		&category.Description,
 		&category.ParentCategoryID,
+		&category.NegativeStockPolicy,
 		&category.CreatedAt,
 		&category.UpdatedAt,
 	)
@@ -90,7 +94,8 @@ func (r *PostgresCategoryRepository) GetByID(ctx context.Context, id int) (*mode
 // GetSubcategories retrieves all subcategories for a parent category
 func (r *PostgresCategoryRepository) GetSubcategories(ctx context.Context, parentID int) ([]*models.Category, error) {
 	query := `
-		SELECT category_id, category_name, description, parent_category_id, created_at, updated_at
+		SELECT category_id, category_name, description, parent_category_id,
+			negative_stock_policy, created_at, updated_at
 		FROM categories
 		WHERE parent_category_id = $1
 		ORDER BY category_name
@@ -110,6 +115,7 @@ func (r *PostgresCategoryRepository) GetSubcategories(ctx context.Context, paren
 			&category.CategoryName,
 			&category.Description,
 			&category.ParentCategoryID,
+			&category.NegativeStockPolicy,
 			&category.CreatedAt,
 			&category.UpdatedAt,
 		)
@@ -129,8 +135,8 @@ func (r *PostgresCategoryRepository) GetSubcategories(ctx context.Context, paren
 // Create adds a new category to the database
 func (r *PostgresCategoryRepository) Create(ctx context.Context, category *models.Category) (int, error) {
 	query := `
-		INSERT INTO categories (category_name, description, parent_category_id)
-		VALUES ($1, $2, $3)
+		INSERT INTO categories (category_name, description, parent_category_id, negative_stock_policy)
+		VALUES ($1, $2, $3, $4)
 		RETURNING category_id
 	`
 
@@ -141,6 +147,7 @@ func (r *PostgresCategoryRepository) Create(ctx context.Context, category *model
 		category.CategoryName,
 		category.Description,
 		category.ParentCategoryID,
+		category.NegativeStockPolicy,
 	).Scan(&id)
 
 	if err != nil {
@@ -154,7 +161,8 @@ func (r *PostgresCategoryRepository) Create(ctx context.Context, category *model
 func (r *PostgresCategoryRepository) Update(ctx context.Context, category *models.Category) error {
 	query := `
 		UPDATE categories
-		SET category_name = $2, description = $3, parent_category_id = $4
+		SET category_name = $2, description = $3, parent_category_id = $4,
+			negative_stock_policy = $5
 		WHERE category_id = $1
 	`
 
@@ -165,6 +173,7 @@ func (r *PostgresCategoryRepository) Update(ctx context.Context, category *model
 		category.CategoryName,
 		category.Description,
 		category.ParentCategoryID,
+		category.NegativeStockPolicy,
 	)
 
 	return err
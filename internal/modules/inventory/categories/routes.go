@@ -1,6 +1,8 @@
 package categories
 
 import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/categories/handlers"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/categories/repositories"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/categories/services"
@@ -13,7 +15,7 @@ func RegisterRoutes(e *echo.Echo, api *echo.Group, database *db.Database) {
 	service := services.NewCategoryService(repo)
 	handler := handlers.NewCategoryHandler(service)
 
-	categories := api.Group("/categories")
+	categories := api.Group("/categories", authMiddleware.Guard(authModels.PermItemsRead, authModels.PermItemsWrite))
 	categories.GET("", handler.GetAllCategories)
 	categories.GET("/:id", handler.GetCategoryByID)
 	categories.GET("/:id/subcategories", handler.GetSubcategories)
@@ -6,6 +6,7 @@ import (
 	categoryerrors "github.com/hsrvms/fixparts/internal/modules/inventory/categories/errors"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/categories/models"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/categories/repositories"
+	stockModels "github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
 )
 
 type categoryService struct {
@@ -45,6 +46,9 @@ func (s *categoryService) GetSubcategories(ctx context.Context, parentID int) ([
 
 func (s *categoryService) CreateCategory(ctx context.Context, category *models.Category) (int, error) {
 	// Add any business logic/validation here before calling repository
+	if category.NegativeStockPolicy != nil && !stockModels.IsValidNegativeStockPolicy(*category.NegativeStockPolicy) {
+		return 0, categoryerrors.ErrInvalidStockPolicy
+	}
 
 	// For example, we might want to check if the parent category exists
 	if category.ParentCategoryID != nil {
@@ -71,6 +75,10 @@ func (s *categoryService) UpdateCategory(ctx context.Context, category *models.C
 		return categoryerrors.ErrCategoryNotFound
 	}
 
+	if category.NegativeStockPolicy != nil && !stockModels.IsValidNegativeStockPolicy(*category.NegativeStockPolicy) {
+		return categoryerrors.ErrInvalidStockPolicy
+	}
+
 	// Check if parent category exists (if one is specified)
 	if category.ParentCategoryID != nil {
 		parent, err := s.repo.GetByID(ctx, *category.ParentCategoryID)
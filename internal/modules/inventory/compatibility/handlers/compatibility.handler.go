@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
 
+	auditMiddleware "github.com/hsrvms/fixparts/internal/modules/audit/middleware"
+	auditModels "github.com/hsrvms/fixparts/internal/modules/audit/models"
 	compatibilityerrors "github.com/hsrvms/fixparts/internal/modules/inventory/compatibility/errors"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/compatibility/models"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/compatibility/services"
@@ -57,6 +60,16 @@ func (h *CompatibilityHandler) AddCompatibility(c echo.Context) error {
 	}
 
 	compatibility.CompatID = id
+
+	created, err := h.compatibilityOf(ctx, compatibility.ItemID, compatibility.SubmodelID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if created == nil {
+		created = compatibility
+	}
+	auditMiddleware.RecordCreate(c, auditModels.EntityCompatibility, compatibility.ItemID, created)
+
 	return c.JSON(http.StatusCreated, compatibility)
 }
 
@@ -72,6 +85,11 @@ func (h *CompatibilityHandler) RemoveCompatibility(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
+	existing, err := h.compatibilityOf(ctx, itemID, submodelID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	err = h.service.RemoveCompatibility(ctx, itemID, submodelID)
 	if err != nil {
 		if err.Error() == "compatibility not found" {
@@ -79,6 +97,9 @@ func (h *CompatibilityHandler) RemoveCompatibility(c echo.Context) error {
 		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	if existing != nil {
+		auditMiddleware.RecordDelete(c, auditModels.EntityCompatibility, itemID, existing)
+	}
 
 	return c.NoContent(http.StatusNoContent)
 }
@@ -97,3 +118,19 @@ func (h *CompatibilityHandler) GetCompatibleItems(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, items)
 }
+
+// compatibilityOf returns the item's compatibility with the submodel, with
+// the vehicle names filled in, or nil
+func (h *CompatibilityHandler) compatibilityOf(ctx context.Context, itemID, submodelID int) (*models.Compatibility, error) {
+	compatibilities, err := h.service.GetCompatibilities(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, compatibility := range compatibilities {
+		if compatibility.SubmodelID == submodelID {
+			return compatibility, nil
+		}
+	}
+	return nil, nil
+}
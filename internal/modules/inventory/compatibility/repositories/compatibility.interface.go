@@ -12,4 +12,5 @@ type CompatibilityRepository interface {
 	AddCompatibility(ctx context.Context, compatibility *models.Compatibility) (int, error)
 	RemoveCompatibility(ctx context.Context, itemID, submodelID int) error
 	GetCompatibleItems(ctx context.Context, submodelID int) ([]*itemmodels.Item, error)
+	GetCompatibleItemsInStock(ctx context.Context, submodelID int) ([]*itemmodels.Item, error)
 }
@@ -100,13 +100,15 @@ func (r *PostgresCompatibilityRepository) RemoveCompatibility(ctx context.Contex
 	return nil
 }
 
-func (r *PostgresCompatibilityRepository) GetCompatibleItems(ctx context.Context, submodelID int) ([]*itemmodels.Item, error) {
-	query := `
+// compatibleItemsQuery selects the active items fitting the submodel given
+// as $1. Callers append their own conditions and ordering.
+const compatibleItemsQuery = `
         SELECT
             i.item_id, i.part_number, i.description, i.category_id, i.buy_price,
-            i.sell_price, i.current_stock, i.minimum_stock, i.barcode, i.supplier_id,
+            i.sell_price, i.current_stock, i.minimum_stock, i.reorder_up_to, i.barcode, i.supplier_id,
             i.location_aisle, i.location_shelf, i.location_bin, i.weight_kg,
             i.dimensions_cm, i.warranty_period, i.image_url, i.is_active, i.notes,
+            i.negative_stock_policy, i.abc_class, i.xyz_class, i.classified_at,
             i.created_at, i.updated_at,
             c.category_name, s.name as supplier_name
         FROM items i
@@ -114,9 +116,20 @@ func (r *PostgresCompatibilityRepository) GetCompatibleItems(ctx context.Context
         LEFT JOIN suppliers s ON i.supplier_id = s.supplier_id
         JOIN compatibility comp ON i.item_id = comp.item_id
         WHERE comp.submodel_id = $1 AND i.is_active = true
-        ORDER BY i.part_number
     `
 
+func (r *PostgresCompatibilityRepository) GetCompatibleItems(ctx context.Context, submodelID int) ([]*itemmodels.Item, error) {
+	return r.queryCompatibleItems(ctx, compatibleItemsQuery+" ORDER BY i.part_number", submodelID)
+}
+
+// GetCompatibleItemsInStock returns the compatible items that can be sold
+// right away
+func (r *PostgresCompatibilityRepository) GetCompatibleItemsInStock(ctx context.Context, submodelID int) ([]*itemmodels.Item, error) {
+	query := compatibleItemsQuery + " AND i.current_stock > 0 ORDER BY i.part_number"
+	return r.queryCompatibleItems(ctx, query, submodelID)
+}
+
+func (r *PostgresCompatibilityRepository) queryCompatibleItems(ctx context.Context, query string, submodelID int) ([]*itemmodels.Item, error) {
 	rows, err := r.db.Pool.Query(ctx, query, submodelID)
 	if err != nil {
 		return nil, err
@@ -128,10 +141,11 @@ func (r *PostgresCompatibilityRepository) GetCompatibleItems(ctx context.Context
 		item := &itemmodels.Item{}
 		err := rows.Scan(
 			&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-			&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
+			&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock, &item.ReorderUpTo,
 			&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
 			&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-			&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
+			&item.ImageURL, &item.IsActive, &item.Notes, &item.NegativeStockPolicy,
+			&item.AbcClass, &item.XyzClass, &item.ClassifiedAt, &item.CreatedAt, &item.UpdatedAt,
 			&item.CategoryName, &item.SupplierName,
 		)
 		if err != nil {
@@ -147,9 +161,10 @@ func (r *PostgresCompatibilityRepository) GetLowStockItems(ctx context.Context)
 	query := `
         SELECT
             i.item_id, i.part_number, i.description, i.category_id, i.buy_price,
-            i.sell_price, i.current_stock, i.minimum_stock, i.barcode, i.supplier_id,
+            i.sell_price, i.current_stock, i.minimum_stock, i.reorder_up_to, i.barcode, i.supplier_id,
             i.location_aisle, i.location_shelf, i.location_bin, i.weight_kg,
             i.dimensions_cm, i.warranty_period, i.image_url, i.is_active, i.notes,
+            i.negative_stock_policy, i.abc_class, i.xyz_class, i.classified_at,
             i.created_at, i.updated_at,
             c.category_name, s.name as supplier_name
         FROM items i
@@ -170,15 +185,17 @@ func (r *PostgresCompatibilityRepository) GetLowStockItems(ctx context.Context)
 		item := &itemmodels.Item{}
 		err := rows.Scan(
 			&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-			&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
+			&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock, &item.ReorderUpTo,
 			&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
 			&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-			&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
+			&item.ImageURL, &item.IsActive, &item.Notes, &item.NegativeStockPolicy,
+			&item.AbcClass, &item.XyzClass, &item.ClassifiedAt, &item.CreatedAt, &item.UpdatedAt,
 			&item.CategoryName, &item.SupplierName,
 		)
 		if err != nil {
 			return nil, err
 		}
+		item.FlagNegativeStock()
 		items = append(items, item)
 	}
 
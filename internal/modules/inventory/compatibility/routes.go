@@ -1,6 +1,8 @@
 package compatibility
 
 import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/compatibility/handlers"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/compatibility/repositories"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/compatibility/services"
@@ -13,9 +15,9 @@ func RegisterRoutes(e *echo.Echo, api *echo.Group, database *db.Database) {
 	service := services.NewCompatibilityService(repo)
 	handler := handlers.NewCompatibilityHandler(service)
 
-	items := api.Group("/items")
+	items := api.Group("/items", authMiddleware.Guard(authModels.PermItemsRead, authModels.PermItemsWrite))
 	items.GET("/:itemId/compatibilities", handler.GetCompatibilities)
 	items.POST("/:itemId/compatibilities", handler.AddCompatibility)
 	items.DELETE("/:itemId/compatibilities/:submodelId", handler.RemoveCompatibility)
-	api.GET("/submodels/:submodelId/compatible-items", handler.GetCompatibleItems)
+	api.GET("/submodels/:submodelId/compatible-items", handler.GetCompatibleItems, authMiddleware.Require(authModels.PermItemsRead))
 }
@@ -11,4 +11,6 @@ var (
 	ErrCompatibilityExists = errors.New("compatibility already exists")
 	ErrInvalidPrice        = errors.New("price must be greater than 0")
 	ErrInvalidStock        = errors.New("stock cannot be negative")
+	ErrInvalidStockPolicy  = errors.New("negative stock policy must be disallow, allow_with_warning or allow")
+	ErrItemHasHistory      = errors.New("item has stock or document history and cannot be deleted; deactivate it instead")
 )
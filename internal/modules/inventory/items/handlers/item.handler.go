@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
+	auditMiddleware "github.com/hsrvms/fixparts/internal/modules/audit/middleware"
+	auditModels "github.com/hsrvms/fixparts/internal/modules/audit/models"
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
 	itemerrors "github.com/hsrvms/fixparts/internal/modules/inventory/items/errors"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/items/models"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/items/services"
@@ -41,6 +47,13 @@ func (h *ItemHandler) GetItems(c echo.Context) error {
 		}
 	}
 
+	if warehouseID := c.QueryParam("warehouse_id"); warehouseID != "" {
+		id, err := strconv.Atoi(warehouseID)
+		if err == nil {
+			filter.WarehouseID = &id
+		}
+	}
+
 	if partNumber := c.QueryParam("part_number"); partNumber != "" {
 		filter.PartNumber = &partNumber
 	}
@@ -59,6 +72,16 @@ func (h *ItemHandler) GetItems(c echo.Context) error {
 		filter.IsActive = &active
 	}
 
+	if abcClass := c.QueryParam("abc_class"); abcClass != "" {
+		class := strings.ToUpper(abcClass)
+		filter.AbcClass = &class
+	}
+
+	if xyzClass := c.QueryParam("xyz_class"); xyzClass != "" {
+		class := strings.ToUpper(xyzClass)
+		filter.XyzClass = &class
+	}
+
 	ctx := c.Request().Context()
 	items, err := h.service.GetItems(ctx, filter)
 	if err != nil {
@@ -119,6 +142,11 @@ func (h *ItemHandler) GetItemByBarcode(c echo.Context) error {
 
 // CreateItem handles the creation of a new item
 func (h *ItemHandler) CreateItem(c echo.Context) error {
+	// A new item needs its buy price, which counter access cannot set
+	if !authMiddleware.FullAccess(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "creating items needs full access")
+	}
+
 	item := new(models.Item)
 	if err := c.Bind(item); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
@@ -130,12 +158,21 @@ func (h *ItemHandler) CreateItem(c echo.Context) error {
 		switch err {
 		case itemerrors.ErrDuplicatePartNumber, itemerrors.ErrDuplicateBarcode:
 			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		case itemerrors.ErrInvalidStock, itemerrors.ErrInvalidStockPolicy:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		default:
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 	}
 
 	item.ItemID = id
+
+	created, err := h.service.GetItemByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordCreate(c, auditModels.EntityItem, id, created)
+
 	return c.JSON(http.StatusCreated, item)
 }
 
@@ -153,18 +190,42 @@ func (h *ItemHandler) UpdateItem(c echo.Context) error {
 	item.ItemID = id
 
 	ctx := c.Request().Context()
-	err = h.service.UpdateItem(ctx, item)
+	existing, err := h.service.GetItemByID(ctx, id)
 	if err != nil {
-		switch err {
-		case itemerrors.ErrItemNotFound:
+		if err == itemerrors.ErrItemNotFound {
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
-		case itemerrors.ErrDuplicatePartNumber, itemerrors.ErrDuplicateBarcode:
-			return echo.NewHTTPError(http.StatusConflict, err.Error())
-		default:
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	// Counter access never sees the buy price, so it keeps its stored value
+	if !authMiddleware.FullAccess(c) {
+		item.BuyPrice = existing.BuyPrice
+	}
+
+	if item.SellPrice < existing.SellPrice {
+		// A change that would be rejected must not spend the manager's code
+		if err := h.service.ValidateUpdate(ctx, item); err != nil {
+			return updateItemError(err)
+		}
+
+		details := fmt.Sprintf("sell_price %.2f -> %.2f", existing.SellPrice, item.SellPrice)
+		if err := authMiddleware.Approve(c, authModels.ActionPriceDecrease, id, details); err != nil {
+			return err
+		}
+	}
+
+	err = h.service.UpdateItem(ctx, item)
+	if err != nil {
+		return updateItemError(err)
+	}
+
+	updated, err := h.service.GetItemByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordUpdate(c, auditModels.EntityItem, id, existing, updated)
+
 	return c.JSON(http.StatusOK, item)
 }
 
@@ -176,19 +237,58 @@ func (h *ItemHandler) DeleteItem(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
-	err = h.service.DeleteItem(ctx, id)
+	existing, err := h.service.GetItemByID(ctx, id)
 	if err != nil {
-		switch err {
-		case itemerrors.ErrItemNotFound:
+		if err == itemerrors.ErrItemNotFound {
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
-		default:
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// A deletion that would be refused must not spend the manager's code
+	if err := h.service.CanDeleteItem(ctx, id); err != nil {
+		return deleteItemError(err)
 	}
 
+	if err := authMiddleware.Approve(c, authModels.ActionItemDelete, id, ""); err != nil {
+		return err
+	}
+
+	err = h.service.DeleteItem(ctx, id)
+	if err != nil {
+		return deleteItemError(err)
+	}
+	auditMiddleware.RecordDelete(c, auditModels.EntityItem, id, existing)
+
 	return c.NoContent(http.StatusNoContent)
 }
 
+// updateItemError maps item update errors to HTTP errors
+func updateItemError(err error) error {
+	switch err {
+	case itemerrors.ErrItemNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case itemerrors.ErrDuplicatePartNumber, itemerrors.ErrDuplicateBarcode:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case itemerrors.ErrInvalidStock, itemerrors.ErrInvalidStockPolicy:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// deleteItemError maps item deletion errors to HTTP errors
+func deleteItemError(err error) error {
+	switch err {
+	case itemerrors.ErrItemNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case itemerrors.ErrItemHasHistory:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
 func (h *ItemHandler) GetBarcodeImage(c echo.Context) error {
 	barcode := c.Param("barcode")
 	if barcode == "" {
@@ -1,32 +1,153 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"time"
+)
 
 type Item struct {
-	ItemID         int       `json:"item_id" db:"item_id"`
-	ItemName       string    `json:"item_name" db:"item_name"`
-	PartNumber     string    `json:"part_number" db:"part_number"`
-	Description    string    `json:"description" db:"description"`
-	CategoryID     *int      `json:"category_id,omitempty" db:"category_id"`
-	BuyPrice       float64   `json:"buy_price" db:"buy_price"`
-	SellPrice      float64   `json:"sell_price" db:"sell_price"`
-	CurrentStock   int       `json:"current_stock" db:"current_stock"`
-	MinimumStock   int       `json:"minimum_stock" db:"minimum_stock"`
-	Barcode        *string   `json:"barcode,omitempty" db:"barcode"`
-	SupplierID     *int      `json:"supplier_id,omitempty" db:"supplier_id"`
-	LocationAisle  *string   `json:"location_aisle,omitempty" db:"location_aisle"`
-	LocationShelf  *string   `json:"location_shelf,omitempty" db:"location_shelf"`
-	LocationBin    *string   `json:"location_bin,omitempty" db:"location_bin"`
-	WeightKg       *float64  `json:"weight_kg,omitempty" db:"weight_kg"`
-	DimensionsCm   *string   `json:"dimensions_cm,omitempty" db:"dimensions_cm"`
-	WarrantyPeriod *string   `json:"warranty_period,omitempty" db:"warranty_period"`
-	ImageURL       *string   `json:"image_url,omitempty" db:"image_url"`
-	IsActive       bool      `json:"is_active" db:"is_active"`
-	Notes          *string   `json:"notes,omitempty" db:"notes"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	ItemID         int      `json:"item_id" db:"item_id"`
+	ItemName       string   `json:"item_name" db:"item_name"`
+	PartNumber     string   `json:"part_number" db:"part_number"`
+	Description    string   `json:"description" db:"description"`
+	CategoryID     *int     `json:"category_id,omitempty" db:"category_id"`
+	BuyPrice       float64  `json:"buy_price" db:"buy_price" access:"full"`
+	SellPrice      float64  `json:"sell_price" db:"sell_price"`
+	CurrentStock   int      `json:"current_stock" db:"current_stock"`
+	MinimumStock   int      `json:"minimum_stock" db:"minimum_stock"`
+	ReorderUpTo    *int     `json:"reorder_up_to,omitempty" db:"reorder_up_to"`
+	Barcode        *string  `json:"barcode,omitempty" db:"barcode"`
+	SupplierID     *int     `json:"supplier_id,omitempty" db:"supplier_id"`
+	LocationAisle  *string  `json:"location_aisle,omitempty" db:"location_aisle"`
+	LocationShelf  *string  `json:"location_shelf,omitempty" db:"location_shelf"`
+	LocationBin    *string  `json:"location_bin,omitempty" db:"location_bin"`
+	WeightKg       *float64 `json:"weight_kg,omitempty" db:"weight_kg"`
+	DimensionsCm   *string  `json:"dimensions_cm,omitempty" db:"dimensions_cm"`
+	WarrantyPeriod *string  `json:"warranty_period,omitempty" db:"warranty_period"`
+	ImageURL       *string  `json:"image_url,omitempty" db:"image_url"`
+	IsActive       bool     `json:"is_active" db:"is_active"`
+	Notes          *string  `json:"notes,omitempty" db:"notes"`
+	// Overrides the category and global negative stock policy
+	NegativeStockPolicy *string `json:"negative_stock_policy,omitempty" db:"negative_stock_policy"`
+	// Set by the ABC/XYZ classification report
+	AbcClass     *string    `json:"abc_class,omitempty" db:"abc_class"`
+	XyzClass     *string    `json:"xyz_class,omitempty" db:"xyz_class"`
+	ClassifiedAt *time.Time `json:"classified_at,omitempty" db:"classified_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
 
 	// Additional fields for API responses
 	CategoryName *string `json:"category_name,omitempty" db:"-"`
 	SupplierName *string `json:"supplier_name,omitempty" db:"-"`
+	StockWarning *string `json:"stock_warning,omitempty" db:"-"`
+
+	// Stock held in each warehouse; CurrentStock is their total
+	Stock []*ItemStock `json:"stock,omitempty" db:"-"`
+
+	// How to bring the warehouses that are short back to minimum stock
+	Suggestions []*RestockSuggestion `json:"suggestions,omitempty" db:"-"`
+}
+
+// ItemStock is the quantity and bin location of an item in one warehouse
+type ItemStock struct {
+	WarehouseID   int     `json:"warehouse_id" db:"warehouse_id"`
+	WarehouseCode string  `json:"warehouse_code" db:"warehouse_code"`
+	WarehouseName string  `json:"warehouse_name" db:"warehouse_name"`
+	Quantity      int     `json:"quantity" db:"quantity"`
+	LocationAisle *string `json:"location_aisle,omitempty" db:"location_aisle"`
+	LocationShelf *string `json:"location_shelf,omitempty" db:"location_shelf"`
+	LocationBin   *string `json:"location_bin,omitempty" db:"location_bin"`
+
+	// Quantities on open transfers: Incoming is still to arrive here,
+	// Outgoing is requested from here but not shipped yet
+	Incoming int `json:"incoming" db:"incoming"`
+	Outgoing int `json:"outgoing" db:"outgoing"`
+}
+
+// Restock actions
+const (
+	RestockTransfer = "transfer"
+	RestockPurchase = "purchase"
+)
+
+// RestockSuggestion is one way to cover the shortage of a warehouse: a
+// transfer from another warehouse, or a purchase for what no warehouse can
+// spare
+type RestockSuggestion struct {
+	Action            string  `json:"action"`
+	WarehouseID       int     `json:"warehouse_id"`
+	WarehouseCode     string  `json:"warehouse_code"`
+	FromWarehouseID   *int    `json:"from_warehouse_id,omitempty"`
+	FromWarehouseCode *string `json:"from_warehouse_code,omitempty"`
+	Quantity          int     `json:"quantity"`
+}
+
+// SuggestRestock fills Suggestions from the per-warehouse stock. Every
+// warehouse holding the item should keep its minimum stock; a warehouse that
+// is short, counting what is already on its way, is topped up from the
+// warehouses with the most to spare first and the rest is suggested as a
+// purchase.
+func (i *Item) SuggestRestock() {
+	i.Suggestions = nil
+
+	surplus := make(map[int]int, len(i.Stock))
+	for _, stock := range i.Stock {
+		if spare := stock.Quantity - stock.Outgoing - i.MinimumStock; spare > 0 {
+			surplus[stock.WarehouseID] = spare
+		}
+	}
+
+	for _, short := range i.Stock {
+		need := i.MinimumStock - short.Quantity - short.Incoming
+		if need <= 0 {
+			continue
+		}
+
+		sources := make([]*ItemStock, 0, len(i.Stock))
+		for _, stock := range i.Stock {
+			if stock.WarehouseID != short.WarehouseID && surplus[stock.WarehouseID] > 0 {
+				sources = append(sources, stock)
+			}
+		}
+		sort.SliceStable(sources, func(a, b int) bool {
+			return surplus[sources[a].WarehouseID] > surplus[sources[b].WarehouseID]
+		})
+
+		for _, source := range sources {
+			if need == 0 {
+				break
+			}
+			quantity := min(need, surplus[source.WarehouseID])
+			surplus[source.WarehouseID] -= quantity
+			need -= quantity
+
+			fromID, fromCode := source.WarehouseID, source.WarehouseCode
+			i.Suggestions = append(i.Suggestions, &RestockSuggestion{
+				Action:            RestockTransfer,
+				WarehouseID:       short.WarehouseID,
+				WarehouseCode:     short.WarehouseCode,
+				FromWarehouseID:   &fromID,
+				FromWarehouseCode: &fromCode,
+				Quantity:          quantity,
+			})
+		}
+
+		if need > 0 {
+			i.Suggestions = append(i.Suggestions, &RestockSuggestion{
+				Action:        RestockPurchase,
+				WarehouseID:   short.WarehouseID,
+				WarehouseCode: short.WarehouseCode,
+				Quantity:      need,
+			})
+		}
+	}
+}
+
+// FlagNegativeStock sets StockWarning when the item has been sold below zero
+func (i *Item) FlagNegativeStock() {
+	if i.CurrentStock < 0 {
+		warning := fmt.Sprintf("stock is negative (%d): sold ahead of incoming stock", i.CurrentStock)
+		i.StockWarning = &warning
+	}
 }
@@ -14,5 +14,6 @@ type ItemRepository interface {
 	CreateItem(ctx context.Context, item *models.Item) (int, error)
 	UpdateItem(ctx context.Context, item *models.Item) error
 	DeleteItem(ctx context.Context, id int) error
+	HasHistory(ctx context.Context, id int) (bool, error)
 	GetLowStockItems(ctx context.Context) ([]*models.Item, error)
 }
@@ -5,9 +5,14 @@ import (
 	"errors"
 	"fmt"
 
+	itemerrors "github.com/hsrvms/fixparts/internal/modules/inventory/items/errors"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/items/models"
+	stockerrors "github.com/hsrvms/fixparts/internal/modules/inventory/stock/errors"
+	stockModels "github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+	stock "github.com/hsrvms/fixparts/internal/modules/inventory/stock/repositories"
 	"github.com/hsrvms/fixparts/pkg/db"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type PostgresItemRepository struct {
@@ -24,9 +29,10 @@ func (r *PostgresItemRepository) GetItems(ctx context.Context, filter *models.It
 	query := `
 		SELECT
 			i.item_id, i.part_number, i.description, i.category_id, i.buy_price,
-			i.sell_price, i.current_stock, i.minimum_stock, i.barcode, i.supplier_id,
+			i.sell_price, i.current_stock, i.minimum_stock, i.reorder_up_to, i.barcode, i.supplier_id,
 			i.location_aisle, i.location_shelf, i.location_bin, i.weight_kg,
 			i.dimensions_cm, i.warranty_period, i.image_url, i.is_active, i.notes,
+			i.negative_stock_policy, i.abc_class, i.xyz_class, i.classified_at,
 			i.created_at, i.updated_at,
 			c.category_name, s.name as supplier_name
 		FROM items i
@@ -52,6 +58,12 @@ func (r *PostgresItemRepository) GetItems(ctx context.Context, filter *models.It
 			paramCount++
 		}
 
+		if filter.WarehouseID != nil {
+			query += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM item_stock ws WHERE ws.item_id = i.item_id AND ws.warehouse_id = $%d)", paramCount)
+			params = append(params, *filter.WarehouseID)
+			paramCount++
+		}
+
 		if filter.PartNumber != nil {
 			query += fmt.Sprintf(" AND i.part_number ILIKE $%d", paramCount)
 			params = append(params, "%"+*filter.PartNumber+"%")
@@ -73,6 +85,18 @@ func (r *PostgresItemRepository) GetItems(ctx context.Context, filter *models.It
 			params = append(params, *filter.IsActive)
 			paramCount++
 		}
+
+		if filter.AbcClass != nil {
+			query += fmt.Sprintf(" AND i.abc_class = $%d", paramCount)
+			params = append(params, *filter.AbcClass)
+			paramCount++
+		}
+
+		if filter.XyzClass != nil {
+			query += fmt.Sprintf(" AND i.xyz_class = $%d", paramCount)
+			params = append(params, *filter.XyzClass)
+			paramCount++
+		}
 	}
 
 	query += " ORDER BY i.part_number"
@@ -88,28 +112,38 @@ func (r *PostgresItemRepository) GetItems(ctx context.Context, filter *models.It
 		item := &models.Item{}
 		err := rows.Scan(
 			&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-			&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
+			&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock, &item.ReorderUpTo,
 			&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
 			&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-			&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
+			&item.ImageURL, &item.IsActive, &item.Notes, &item.NegativeStockPolicy,
+			&item.AbcClass, &item.XyzClass, &item.ClassifiedAt, &item.CreatedAt, &item.UpdatedAt,
 			&item.CategoryName, &item.SupplierName,
 		)
 		if err != nil {
 			return nil, err
 		}
+		item.FlagNegativeStock()
 		items = append(items, item)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadStock(ctx, items); err != nil {
+		return nil, err
+	}
 
-	return items, rows.Err()
+	return items, nil
 }
 
 func (r *PostgresItemRepository) GetItemByID(ctx context.Context, id int) (*models.Item, error) {
 	query := `
 		SELECT
 			i.item_id, i.part_number, i.description, i.category_id, i.buy_price,
-			i.sell_price, i.current_stock, i.minimum_stock, i.barcode, i.supplier_id,
+			i.sell_price, i.current_stock, i.minimum_stock, i.reorder_up_to, i.barcode, i.supplier_id,
 			i.location_aisle, i.location_shelf, i.location_bin, i.weight_kg,
 			i.dimensions_cm, i.warranty_period, i.image_url, i.is_active, i.notes,
+			i.negative_stock_policy, i.abc_class, i.xyz_class, i.classified_at,
 			i.created_at, i.updated_at,
 			c.category_name, s.name as supplier_name
 		FROM items i
@@ -121,10 +155,11 @@ func (r *PostgresItemRepository) GetItemByID(ctx context.Context, id int) (*mode
 	item := &models.Item{}
 	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
 		&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-		&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
+		&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock, &item.ReorderUpTo,
 		&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
 		&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-		&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
+		&item.ImageURL, &item.IsActive, &item.Notes, &item.NegativeStockPolicy,
+		&item.AbcClass, &item.XyzClass, &item.ClassifiedAt, &item.CreatedAt, &item.UpdatedAt,
 		&item.CategoryName, &item.SupplierName,
 	)
 
@@ -135,6 +170,10 @@ func (r *PostgresItemRepository) GetItemByID(ctx context.Context, id int) (*mode
 		return nil, err
 	}
 
+	if err := r.loadStock(ctx, []*models.Item{item}); err != nil {
+		return nil, err
+	}
+
 	return item, nil
 }
 
@@ -142,9 +181,10 @@ func (r *PostgresItemRepository) GetItemByPartNumber(ctx context.Context, partNu
 	query := `
 		SELECT
 			i.item_id, i.part_number, i.description, i.category_id, i.buy_price,
-			i.sell_price, i.current_stock, i.minimum_stock, i.barcode, i.supplier_id,
+			i.sell_price, i.current_stock, i.minimum_stock, i.reorder_up_to, i.barcode, i.supplier_id,
 			i.location_aisle, i.location_shelf, i.location_bin, i.weight_kg,
 			i.dimensions_cm, i.warranty_period, i.image_url, i.is_active, i.notes,
+			i.negative_stock_policy, i.abc_class, i.xyz_class, i.classified_at,
 			i.created_at, i.updated_at,
 			c.category_name, s.name as supplier_name
 		FROM items i
@@ -156,10 +196,11 @@ func (r *PostgresItemRepository) GetItemByPartNumber(ctx context.Context, partNu
 	item := &models.Item{}
 	err := r.db.Pool.QueryRow(ctx, query, partNumber).Scan(
 		&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-		&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
+		&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock, &item.ReorderUpTo,
 		&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
 		&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-		&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
+		&item.ImageURL, &item.IsActive, &item.Notes, &item.NegativeStockPolicy,
+		&item.AbcClass, &item.XyzClass, &item.ClassifiedAt, &item.CreatedAt, &item.UpdatedAt,
 		&item.CategoryName, &item.SupplierName,
 	)
 
@@ -170,6 +211,10 @@ func (r *PostgresItemRepository) GetItemByPartNumber(ctx context.Context, partNu
 		return nil, err
 	}
 
+	if err := r.loadStock(ctx, []*models.Item{item}); err != nil {
+		return nil, err
+	}
+
 	return item, nil
 }
 
@@ -177,9 +222,10 @@ func (r *PostgresItemRepository) GetItemByBarcode(ctx context.Context, barcode s
 	query := `
 		SELECT
 			i.item_id, i.part_number, i.description, i.category_id, i.buy_price,
-			i.sell_price, i.current_stock, i.minimum_stock, i.barcode, i.supplier_id,
+			i.sell_price, i.current_stock, i.minimum_stock, i.reorder_up_to, i.barcode, i.supplier_id,
 			i.location_aisle, i.location_shelf, i.location_bin, i.weight_kg,
 			i.dimensions_cm, i.warranty_period, i.image_url, i.is_active, i.notes,
+			i.negative_stock_policy, i.abc_class, i.xyz_class, i.classified_at,
 			i.created_at, i.updated_at,
 			c.category_name, s.name as supplier_name
 		FROM items i
@@ -191,10 +237,11 @@ func (r *PostgresItemRepository) GetItemByBarcode(ctx context.Context, barcode s
 	item := &models.Item{}
 	err := r.db.Pool.QueryRow(ctx, query, barcode).Scan(
 		&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-		&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
+		&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock, &item.ReorderUpTo,
 		&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
 		&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-		&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
+		&item.ImageURL, &item.IsActive, &item.Notes, &item.NegativeStockPolicy,
+		&item.AbcClass, &item.XyzClass, &item.ClassifiedAt, &item.CreatedAt, &item.UpdatedAt,
 		&item.CategoryName, &item.SupplierName,
 	)
 
@@ -205,70 +252,150 @@ func (r *PostgresItemRepository) GetItemByBarcode(ctx context.Context, barcode s
 		return nil, err
 	}
 
+	if err := r.loadStock(ctx, []*models.Item{item}); err != nil {
+		return nil, err
+	}
+
 	return item, nil
 }
 
+// CreateItem inserts the item with an empty balance and posts its initial
+// stock to the ledger as an opening movement
 func (r *PostgresItemRepository) CreateItem(ctx context.Context, item *models.Item) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 		INSERT INTO items (
 			part_number, item_name, description, category_id, buy_price, sell_price,
 			current_stock, minimum_stock, barcode, supplier_id, location_aisle,
 			location_shelf, location_bin, weight_kg, dimensions_cm,
-			warranty_period, image_url, is_active, notes
+			warranty_period, image_url, is_active, notes, negative_stock_policy,
+			reorder_up_to
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
-			$11, $12, $13, $14, $15, $16, $17, $18, $19
+			$1, $2, $3, $4, $5, $6, 0, $7, $8, $9,
+			$10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
 		)
 		RETURNING item_id
 	`
 
 	var id int
-	err := r.db.Pool.QueryRow(
+	err = tx.QueryRow(
 		ctx, query,
 		item.PartNumber, item.ItemName, item.Description, item.CategoryID, item.BuyPrice,
-		item.SellPrice, item.CurrentStock, item.MinimumStock, item.Barcode,
+		item.SellPrice, item.MinimumStock, item.Barcode,
 		item.SupplierID, item.LocationAisle, item.LocationShelf, item.LocationBin,
 		item.WeightKg, item.DimensionsCm, item.WarrantyPeriod, item.ImageURL,
-		item.IsActive, item.Notes,
+		item.IsActive, item.Notes, item.NegativeStockPolicy, item.ReorderUpTo,
 	).Scan(&id)
 
 	if err != nil {
 		return 0, err
 	}
 
+	if err := setDefaultLocation(ctx, tx, id, item); err != nil {
+		return 0, err
+	}
+
+	referenceType := stockModels.ReferenceTypeItem
+	movement := &stockModels.StockMovement{
+		ItemID:        id,
+		MovementType:  stockModels.MovementTypeOpening,
+		Quantity:      item.CurrentStock,
+		ReferenceType: &referenceType,
+		ReferenceID:   &id,
+		UnitCost:      &item.BuyPrice,
+	}
+	if err := stock.PostMovement(ctx, tx, movement); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
 	return id, nil
 }
 
+// UpdateItem updates the item's attributes. current_stock is never written
+// directly: a changed value is posted to the ledger as an adjustment for the
+// difference against the locked current balance, subject to the item's
+// negative stock policy.
 func (r *PostgresItemRepository) UpdateItem(ctx context.Context, item *models.Item) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var currentStock int
+	err = tx.QueryRow(ctx,
+		`SELECT current_stock FROM items WHERE item_id = $1 FOR UPDATE`,
+		item.ItemID,
+	).Scan(&currentStock)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("item not found")
+		}
+		return err
+	}
+
 	query := `
 		UPDATE items SET
 			part_number = $2, description = $3, category_id = $4,
-			buy_price = $5, sell_price = $6, current_stock = $7,
-			minimum_stock = $8, barcode = $9, supplier_id = $10,
-			location_aisle = $11, location_shelf = $12, location_bin = $13,
-			weight_kg = $14, dimensions_cm = $15, warranty_period = $16,
-			image_url = $17, is_active = $18, notes = $19
+			buy_price = $5, sell_price = $6, minimum_stock = $7,
+			barcode = $8, supplier_id = $9, location_aisle = $10,
+			location_shelf = $11, location_bin = $12, weight_kg = $13,
+			dimensions_cm = $14, warranty_period = $15, image_url = $16,
+			is_active = $17, notes = $18, negative_stock_policy = $19,
+			reorder_up_to = $20
 		WHERE item_id = $1
 	`
 
-	result, err := r.db.Pool.Exec(
+	_, err = tx.Exec(
 		ctx, query,
 		item.ItemID, item.PartNumber, item.Description, item.CategoryID,
-		item.BuyPrice, item.SellPrice, item.CurrentStock, item.MinimumStock,
+		item.BuyPrice, item.SellPrice, item.MinimumStock,
 		item.Barcode, item.SupplierID, item.LocationAisle, item.LocationShelf,
 		item.LocationBin, item.WeightKg, item.DimensionsCm, item.WarrantyPeriod,
-		item.ImageURL, item.IsActive, item.Notes,
+		item.ImageURL, item.IsActive, item.Notes, item.NegativeStockPolicy,
+		item.ReorderUpTo,
 	)
 
 	if err != nil {
 		return err
 	}
 
-	if result.RowsAffected() == 0 {
-		return errors.New("item not found")
+	if err := setDefaultLocation(ctx, tx, item.ItemID, item); err != nil {
+		return err
 	}
 
-	return nil
+	// The stock edit is posted after the update so it is checked against the
+	// item's new negative stock policy
+	if item.CurrentStock != currentStock {
+		referenceType := stockModels.ReferenceTypeItem
+		notes := "Stock edited on item"
+		movement := &stockModels.StockMovement{
+			ItemID:        item.ItemID,
+			MovementType:  stockModels.MovementTypeAdjustment,
+			Quantity:      item.CurrentStock - currentStock,
+			ReferenceType: &referenceType,
+			ReferenceID:   &item.ItemID,
+			Notes:         &notes,
+		}
+		if err := stock.PostMovement(ctx, tx, movement); err != nil {
+			if errors.Is(err, stockerrors.ErrInsufficientStock) {
+				return itemerrors.ErrInvalidStock
+			}
+			return err
+		}
+		item.StockWarning = movement.Warning
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (r *PostgresItemRepository) DeleteItem(ctx context.Context, id int) error {
@@ -276,6 +403,11 @@ func (r *PostgresItemRepository) DeleteItem(ctx context.Context, id int) error {
 
 	result, err := r.db.Pool.Exec(ctx, query, id)
 	if err != nil {
+		// Documents and ledger rows keep their item (foreign_key_violation)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return itemerrors.ErrItemHasHistory
+		}
 		return err
 	}
 
@@ -286,19 +418,45 @@ func (r *PostgresItemRepository) DeleteItem(ctx context.Context, id int) error {
 	return nil
 }
 
+// HasHistory reports whether the item has stock movements or appears on any
+// document, which keeps it from being deleted
+func (r *PostgresItemRepository) HasHistory(ctx context.Context, id int) (bool, error) {
+	query := `
+        SELECT
+            EXISTS (SELECT 1 FROM stock_movements WHERE item_id = $1)
+            OR EXISTS (SELECT 1 FROM sale_lines WHERE item_id = $1)
+            OR EXISTS (SELECT 1 FROM purchases WHERE item_id = $1)
+            OR EXISTS (SELECT 1 FROM purchase_order_lines WHERE item_id = $1)
+            OR EXISTS (SELECT 1 FROM stock_transfer_lines WHERE item_id = $1)
+    `
+
+	var hasHistory bool
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(&hasHistory)
+	return hasHistory, err
+}
+
 func (r *PostgresItemRepository) GetLowStockItems(ctx context.Context) ([]*models.Item, error) {
 	query := `
         SELECT
             i.item_id, i.part_number, i.description, i.category_id, i.buy_price,
-            i.sell_price, i.current_stock, i.minimum_stock, i.barcode, i.supplier_id,
+            i.sell_price, i.current_stock, i.minimum_stock, i.reorder_up_to, i.barcode, i.supplier_id,
             i.location_aisle, i.location_shelf, i.location_bin, i.weight_kg,
             i.dimensions_cm, i.warranty_period, i.image_url, i.is_active, i.notes,
+            i.negative_stock_policy, i.abc_class, i.xyz_class, i.classified_at,
             i.created_at, i.updated_at,
             c.category_name, s.name as supplier_name
         FROM items i
         LEFT JOIN categories c ON i.category_id = c.category_id
         LEFT JOIN suppliers s ON i.supplier_id = s.supplier_id
-        WHERE i.current_stock <= i.minimum_stock AND i.is_active = true
+        WHERE i.is_active = true AND (
+            i.current_stock <= i.minimum_stock
+            OR EXISTS (
+                SELECT 1 FROM item_stock ws
+                JOIN warehouses w ON ws.warehouse_id = w.warehouse_id
+                WHERE ws.item_id = i.item_id AND w.is_active
+                    AND ws.quantity <= i.minimum_stock
+            )
+        )
         ORDER BY i.current_stock ASC, i.part_number
     `
 
@@ -313,17 +471,110 @@ func (r *PostgresItemRepository) GetLowStockItems(ctx context.Context) ([]*model
 		item := &models.Item{}
 		err := rows.Scan(
 			&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-			&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
+			&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock, &item.ReorderUpTo,
 			&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
 			&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-			&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
+			&item.ImageURL, &item.IsActive, &item.Notes, &item.NegativeStockPolicy,
+			&item.AbcClass, &item.XyzClass, &item.ClassifiedAt, &item.CreatedAt, &item.UpdatedAt,
 			&item.CategoryName, &item.SupplierName,
 		)
 		if err != nil {
 			return nil, err
 		}
+		item.FlagNegativeStock()
 		items = append(items, item)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadStock(ctx, items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// loadStock fetches the per-warehouse stock of the given items in one query
+// and attaches it to them. Inactive warehouses are left out so they are
+// never suggested as a transfer source or destination.
+func (r *PostgresItemRepository) loadStock(ctx context.Context, items []*models.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	itemIDs := make([]int, 0, len(items))
+	itemsByID := make(map[int]*models.Item, len(items))
+	for _, item := range items {
+		item.Stock = []*models.ItemStock{}
+		itemIDs = append(itemIDs, item.ItemID)
+		itemsByID[item.ItemID] = item
+	}
+
+	query := `
+        SELECT
+            ws.item_id, ws.warehouse_id, w.code as warehouse_code,
+            w.name as warehouse_name, ws.quantity,
+            ws.location_aisle, ws.location_shelf, ws.location_bin,
+            COALESCE((
+                SELECT SUM(tl.quantity - tl.quantity_received)
+                FROM stock_transfer_lines tl
+                JOIN stock_transfers t ON tl.transfer_id = t.transfer_id
+                WHERE tl.item_id = ws.item_id AND t.destination_warehouse_id = ws.warehouse_id
+                    AND t.status IN ('requested', 'shipped', 'partially_received')
+            ), 0) as incoming,
+            COALESCE((
+                SELECT SUM(tl.quantity)
+                FROM stock_transfer_lines tl
+                JOIN stock_transfers t ON tl.transfer_id = t.transfer_id
+                WHERE tl.item_id = ws.item_id AND t.source_warehouse_id = ws.warehouse_id
+                    AND t.status = 'requested'
+            ), 0) as outgoing
+        FROM item_stock ws
+        JOIN warehouses w ON ws.warehouse_id = w.warehouse_id
+        WHERE ws.item_id = ANY($1) AND w.is_active
+        ORDER BY ws.item_id, w.is_default DESC, w.name
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, itemIDs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var itemID int
+		stock := &models.ItemStock{}
+		err := rows.Scan(
+			&itemID, &stock.WarehouseID, &stock.WarehouseCode,
+			&stock.WarehouseName, &stock.Quantity,
+			&stock.LocationAisle, &stock.LocationShelf, &stock.LocationBin,
+			&stock.Incoming, &stock.Outgoing,
+		)
+		if err != nil {
+			return err
+		}
+		if item, ok := itemsByID[itemID]; ok {
+			item.Stock = append(item.Stock, stock)
+		}
+	}
+
+	return rows.Err()
+}
+
+// setDefaultLocation keeps the item's location as its bin in the default
+// warehouse
+func setDefaultLocation(ctx context.Context, tx pgx.Tx, itemID int, item *models.Item) error {
+	query := `
+        INSERT INTO item_stock (
+            item_id, warehouse_id, location_aisle, location_shelf, location_bin
+        ) VALUES ($1, default_warehouse_id(), $2, $3, $4)
+        ON CONFLICT (item_id, warehouse_id) DO UPDATE SET
+            location_aisle = EXCLUDED.location_aisle,
+            location_shelf = EXCLUDED.location_shelf,
+            location_bin = EXCLUDED.location_bin
+    `
 
-	return items, rows.Err()
+	_, err := tx.Exec(ctx, query, itemID, item.LocationAisle, item.LocationShelf, item.LocationBin)
+	return err
 }
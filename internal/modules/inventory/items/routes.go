@@ -1,6 +1,8 @@
 package items
 
 import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/items/handlers"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/items/repositories"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/items/services"
@@ -13,7 +15,7 @@ func RegisterRoutes(e *echo.Echo, api *echo.Group, database *db.Database) {
 	service := services.NewItemService(repo)
 	handler := handlers.NewItemHandler(service)
 
-	items := api.Group("/items")
+	items := api.Group("/items", authMiddleware.Guard(authModels.PermItemsRead, authModels.PermItemsWrite))
 	items.GET("", handler.GetItems)
 	items.GET("/low-stock", handler.GetLowStockItems)
 	items.GET("/:id", handler.GetItemByID)
@@ -13,6 +13,10 @@ type ItemService interface {
 	GetItemByBarcode(ctx context.Context, barcode string) (*models.Item, error)
 	CreateItem(ctx context.Context, item *models.Item) (int, error)
 	UpdateItem(ctx context.Context, item *models.Item) error
+	// ValidateUpdate runs the checks of UpdateItem without saving anything
+	ValidateUpdate(ctx context.Context, item *models.Item) error
 	DeleteItem(ctx context.Context, id int) error
+	// CanDeleteItem runs the checks of DeleteItem without deleting anything
+	CanDeleteItem(ctx context.Context, id int) error
 	GetLowStockItems(ctx context.Context) ([]*models.Item, error)
 }
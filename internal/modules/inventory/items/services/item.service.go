@@ -8,6 +8,7 @@ import (
 	itemerrors "github.com/hsrvms/fixparts/internal/modules/inventory/items/errors"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/items/models"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/items/repositories"
+	stockModels "github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
 )
 
 type itemService struct {
@@ -63,6 +64,10 @@ func (s *itemService) CreateItem(ctx context.Context, item *models.Item) (int, e
 	if err := s.validateItem(item); err != nil {
 		return 0, err
 	}
+	// Opening stock cannot be negative whatever the policy
+	if item.CurrentStock < 0 {
+		return 0, itemerrors.ErrInvalidStock
+	}
 
 	// Generate barcode if not provided
 	if item.Barcode == nil || *item.Barcode == "" {
@@ -107,6 +112,14 @@ func (s *itemService) CreateItem(ctx context.Context, item *models.Item) (int, e
 }
 
 func (s *itemService) UpdateItem(ctx context.Context, item *models.Item) error {
+	if err := s.ValidateUpdate(ctx, item); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateItem(ctx, item)
+}
+
+func (s *itemService) ValidateUpdate(ctx context.Context, item *models.Item) error {
 	if item.ItemID <= 0 {
 		return itemerrors.ErrInvalidItemID
 	}
@@ -148,10 +161,18 @@ func (s *itemService) UpdateItem(ctx context.Context, item *models.Item) error {
 		}
 	}
 
-	return s.repo.UpdateItem(ctx, item)
+	return nil
 }
 
 func (s *itemService) DeleteItem(ctx context.Context, id int) error {
+	if err := s.CanDeleteItem(ctx, id); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteItem(ctx, id)
+}
+
+func (s *itemService) CanDeleteItem(ctx context.Context, id int) error {
 	if id <= 0 {
 		return itemerrors.ErrInvalidItemID
 	}
@@ -165,16 +186,33 @@ func (s *itemService) DeleteItem(ctx context.Context, id int) error {
 		return itemerrors.ErrItemNotFound
 	}
 
-	// You might want to add additional checks here:
-	// - Check if item has any active sales/purchases
-	// - Check if item is part of any active orders
-	// - Instead of deleting, maybe just mark as inactive
+	// The stock ledger and the documents keep their items; those are
+	// deactivated instead
+	hasHistory, err := s.repo.HasHistory(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasHistory {
+		return itemerrors.ErrItemHasHistory
+	}
 
-	return s.repo.DeleteItem(ctx, id)
+	return nil
 }
 
+// GetLowStockItems lists the items at or below minimum stock in total or in
+// any warehouse, with the transfers and purchases that would restock them.
+// Transfers from warehouses with stock to spare come before purchases.
 func (s *itemService) GetLowStockItems(ctx context.Context) ([]*models.Item, error) {
-	return s.repo.GetLowStockItems(ctx)
+	items, err := s.repo.GetLowStockItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		item.SuggestRestock()
+	}
+
+	return items, nil
 }
 
 func (s *itemService) validateItem(item *models.Item) error {
@@ -190,11 +228,14 @@ func (s *itemService) validateItem(item *models.Item) error {
 	if item.SellPrice <= 0 {
 		return errors.New("sell price must be greater than 0")
 	}
-	if item.CurrentStock < 0 {
-		return errors.New("current stock cannot be negative")
-	}
 	if item.MinimumStock < 0 {
 		return errors.New("minimum stock cannot be negative")
 	}
+	if item.ReorderUpTo != nil && *item.ReorderUpTo < item.MinimumStock {
+		return errors.New("reorder-up-to level cannot be below the minimum stock")
+	}
+	if item.NegativeStockPolicy != nil && !stockModels.IsValidNegativeStockPolicy(*item.NegativeStockPolicy) {
+		return itemerrors.ErrInvalidStockPolicy
+	}
 	return nil
 }
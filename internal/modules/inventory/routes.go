@@ -4,6 +4,10 @@ import (
 	"github.com/hsrvms/fixparts/internal/modules/inventory/categories"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/compatibility"
 	"github.com/hsrvms/fixparts/internal/modules/inventory/items"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stock"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stocktakes"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/transfers"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/warehouses"
 	"github.com/hsrvms/fixparts/pkg/db"
 	"github.com/labstack/echo/v4"
 )
@@ -12,8 +16,12 @@ func RegisterRoutes(e *echo.Echo, api *echo.Group, database *db.Database) {
 
 	inventoryGroup := api.Group("/inventory")
 
+	warehouses.RegisterRoutes(e, inventoryGroup, database)
 	categories.RegisterRoutes(e, inventoryGroup, database)
 	items.RegisterRoutes(e, inventoryGroup, database)
 	compatibility.RegisterRoutes(e, inventoryGroup, database)
+	stock.RegisterRoutes(e, inventoryGroup, database)
+	stocktakes.RegisterRoutes(e, inventoryGroup, database)
+	transfers.RegisterRoutes(e, inventoryGroup, database)
 
 }
@@ -0,0 +1,38 @@
+package stockerrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrItemNotFound         = errors.New("item not found")
+	ErrInvalidItemID        = errors.New("invalid item ID")
+	ErrInvalidQuantity      = errors.New("quantity must not be zero")
+	ErrInvalidMovementType  = errors.New("invalid movement type")
+	ErrInsufficientStock    = errors.New("insufficient stock")
+	ErrAdjustmentNoteNeeded = errors.New("a note explaining the adjustment is required")
+	ErrInvalidStockPolicy   = errors.New("negative stock policy must be disallow, allow_with_warning or allow")
+	ErrWarehouseNotFound    = errors.New("warehouse not found")
+	ErrInvalidCostingMethod = errors.New("costing method must be fifo or average")
+)
+
+// InsufficientStockError tells which item ran short, and where. It matches
+// ErrInsufficientStock with errors.Is.
+type InsufficientStockError struct {
+	PartNumber string
+	Warehouse  string
+	Available  int
+	Requested  int
+}
+
+func (e *InsufficientStockError) Error() string {
+	if e.Warehouse != "" {
+		return fmt.Sprintf("%s has %d in stock at %s, %d requested", e.PartNumber, e.Available, e.Warehouse, e.Requested)
+	}
+	return fmt.Sprintf("%s has %d in stock, %d requested", e.PartNumber, e.Available, e.Requested)
+}
+
+func (e *InsufficientStockError) Unwrap() error {
+	return ErrInsufficientStock
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	stockerrors "github.com/hsrvms/fixparts/internal/modules/inventory/stock/errors"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stock/services"
+	"github.com/labstack/echo/v4"
+)
+
+type StockHandler struct {
+	service services.StockService
+}
+
+func NewStockHandler(service services.StockService) *StockHandler {
+	return &StockHandler{
+		service: service,
+	}
+}
+
+// GetMovements handles retrieval of stock ledger entries with optional filtering
+func (h *StockHandler) GetMovements(c echo.Context) error {
+	filter := &models.MovementFilter{}
+
+	// Parse query parameters
+	if itemID := c.QueryParam("item_id"); itemID != "" {
+		id, err := strconv.Atoi(itemID)
+		if err == nil {
+			filter.ItemID = &id
+		}
+	}
+
+	if warehouseID := c.QueryParam("warehouse_id"); warehouseID != "" {
+		id, err := strconv.Atoi(warehouseID)
+		if err == nil {
+			filter.WarehouseID = &id
+		}
+	}
+
+	if movementType := c.QueryParam("movement_type"); movementType != "" {
+		filter.MovementType = &movementType
+	}
+
+	if referenceType := c.QueryParam("reference_type"); referenceType != "" {
+		filter.ReferenceType = &referenceType
+	}
+
+	if referenceID := c.QueryParam("reference_id"); referenceID != "" {
+		id, err := strconv.Atoi(referenceID)
+		if err == nil {
+			filter.ReferenceID = &id
+		}
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		if date, err := time.Parse(time.RFC3339, startDate); err == nil {
+			filter.StartDate = &date
+		}
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		if date, err := time.Parse(time.RFC3339, endDate); err == nil {
+			filter.EndDate = &date
+		}
+	}
+
+	ctx := c.Request().Context()
+	movements, err := h.service.GetMovements(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, movements)
+}
+
+// GetItemMovements handles retrieval of the stock ledger of a single item
+func (h *StockHandler) GetItemMovements(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	ctx := c.Request().Context()
+	movements, err := h.service.GetItemMovements(ctx, itemID)
+	if err != nil {
+		if err == stockerrors.ErrInvalidItemID {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, movements)
+}
+
+// CreateAdjustment handles posting a manual stock correction
+func (h *StockHandler) CreateAdjustment(c echo.Context) error {
+	adjustment := new(models.StockAdjustment)
+	if err := c.Bind(adjustment); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	movement, err := h.service.Adjust(ctx, adjustment)
+	if err != nil {
+		if errors.Is(err, stockerrors.ErrInsufficientStock) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		switch err {
+		case stockerrors.ErrItemNotFound, stockerrors.ErrWarehouseNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case stockerrors.ErrInvalidItemID, stockerrors.ErrInvalidQuantity,
+			stockerrors.ErrAdjustmentNoteNeeded:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusCreated, movement)
+}
+
+// GetDiscrepancies handles listing items whose stock differs from the ledger
+func (h *StockHandler) GetDiscrepancies(c echo.Context) error {
+	ctx := c.Request().Context()
+	discrepancies, err := h.service.GetDiscrepancies(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, discrepancies)
+}
+
+// Reconcile handles resetting item stock to the ledger balance
+func (h *StockHandler) Reconcile(c echo.Context) error {
+	ctx := c.Request().Context()
+	corrected, err := h.service.Reconcile(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, corrected)
+}
+
+// GetNegativeStockPolicy handles retrieval of the global negative stock policy
+func (h *StockHandler) GetNegativeStockPolicy(c echo.Context) error {
+	ctx := c.Request().Context()
+	setting, err := h.service.GetNegativeStockPolicy(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, setting)
+}
+
+// UpdateNegativeStockPolicy handles changing the global negative stock policy
+func (h *StockHandler) UpdateNegativeStockPolicy(c echo.Context) error {
+	setting := new(models.NegativeStockSetting)
+	if err := c.Bind(setting); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.SetNegativeStockPolicy(ctx, setting); err != nil {
+		if err == stockerrors.ErrInvalidStockPolicy {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, setting)
+}
+
+// GetCostingMethod handles retrieval of the costing method
+func (h *StockHandler) GetCostingMethod(c echo.Context) error {
+	ctx := c.Request().Context()
+	setting, err := h.service.GetCostingMethod(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, setting)
+}
+
+// UpdateCostingMethod handles changing the costing method
+func (h *StockHandler) UpdateCostingMethod(c echo.Context) error {
+	setting := new(models.CostingMethodSetting)
+	if err := c.Bind(setting); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.SetCostingMethod(ctx, setting); err != nil {
+		if err == stockerrors.ErrInvalidCostingMethod {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, setting)
+}
@@ -0,0 +1,146 @@
+package models
+
+import "time"
+
+// Movement types recorded in the stock ledger
+const (
+	MovementTypeOpening            = "opening"
+	MovementTypeSale               = "sale"
+	MovementTypeSaleCorrection     = "sale_correction"
+	MovementTypePurchase           = "purchase"
+	MovementTypePurchaseCorrection = "purchase_correction"
+	MovementTypeAdjustment         = "adjustment"
+	MovementTypeSaleReturn         = "sale_return"
+	MovementTypeSupplierReturn     = "supplier_return"
+	MovementTypeStocktake          = "stocktake"
+	MovementTypeTransferOut        = "transfer_out"
+	MovementTypeTransferIn         = "transfer_in"
+)
+
+// Negative stock policies. The policy of an item is its own override, then
+// its category's, then the global setting.
+const (
+	NegativeStockDisallow         = "disallow"
+	NegativeStockAllowWithWarning = "allow_with_warning"
+	NegativeStockAllow            = "allow"
+)
+
+// SettingNegativeStockPolicy is the settings key of the global policy
+const SettingNegativeStockPolicy = "negative_stock_policy"
+
+func IsValidNegativeStockPolicy(policy string) bool {
+	switch policy {
+	case NegativeStockDisallow, NegativeStockAllowWithWarning, NegativeStockAllow:
+		return true
+	}
+	return false
+}
+
+// Costing methods used to value stock. Both are tracked on every movement;
+// the setting picks the one COGS and the valuation report use by default.
+const (
+	CostingFIFO    = "fifo"
+	CostingAverage = "average"
+)
+
+// SettingCostingMethod is the settings key of the costing method
+const SettingCostingMethod = "costing_method"
+
+func IsValidCostingMethod(method string) bool {
+	return method == CostingFIFO || method == CostingAverage
+}
+
+// Reference document types a movement can point at
+const (
+	ReferenceTypeSale           = "sale"
+	ReferenceTypeSaleReturn     = "sale_return"
+	ReferenceTypePurchase       = "purchase"
+	ReferenceTypeSupplierReturn = "supplier_return"
+	ReferenceTypeStocktake      = "stocktake"
+	ReferenceTypeTransfer       = "transfer"
+	ReferenceTypeItem           = "item"
+)
+
+// StockMovement is a single entry of the append-only stock ledger. Quantity
+// is signed: positive for stock coming in, negative for stock going out.
+// Every movement happens in one warehouse; a zero WarehouseID posts to the
+// default warehouse. BalanceAfter is the item's total over all warehouses.
+type StockMovement struct {
+	MovementID      int       `json:"movement_id" db:"movement_id"`
+	ItemID          int       `json:"item_id" db:"item_id"`
+	WarehouseID     int       `json:"warehouse_id" db:"warehouse_id"`
+	MovementType    string    `json:"movement_type" db:"movement_type"`
+	Quantity        int       `json:"quantity" db:"quantity"`
+	BalanceAfter    int       `json:"balance_after" db:"balance_after"`
+	ReferenceType   *string   `json:"reference_type,omitempty" db:"reference_type"`
+	ReferenceID     *int      `json:"reference_id,omitempty" db:"reference_id"`
+	ReferenceLineID *int      `json:"reference_line_id,omitempty" db:"reference_line_id"`
+	PerformedBy     *string   `json:"performed_by,omitempty" db:"performed_by"`
+	Notes           *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+
+	// UnitCost is the cost of stock coming in. Left nil, the item's average
+	// cost is used, or its buy price when it has none.
+	UnitCost *float64 `json:"unit_cost,omitempty" db:"-" access:"full"`
+
+	// Reversal marks stock going out that takes back what the movement's
+	// reference document brought in. It consumes that document's own cost
+	// layers first and, when UnitCost is set, leaves the average cost as if
+	// the stock had come in at UnitCost.
+	Reversal bool `json:"-" db:"-"`
+
+	// Value of the movement, signed like the quantity, under each costing
+	// method. Cost is the one matching the costing method setting.
+	Cost        float64 `json:"cost" db:"cost" access:"full"`
+	CostFifo    float64 `json:"cost_fifo" db:"cost_fifo" access:"full"`
+	CostAverage float64 `json:"cost_average" db:"cost_average" access:"full"`
+
+	// Additional fields for API responses
+	ItemPartNumber  string `json:"item_part_number,omitempty" db:"-"`
+	ItemDescription string `json:"item_description,omitempty" db:"-"`
+	WarehouseCode   string `json:"warehouse_code,omitempty" db:"-"`
+
+	// Warning is set when the movement took the item below zero under the
+	// allow_with_warning policy
+	Warning *string `json:"warning,omitempty" db:"-"`
+}
+
+// NegativeStockSetting is the global negative stock policy
+type NegativeStockSetting struct {
+	Policy string `json:"policy"`
+}
+
+// CostingMethodSetting is the costing method used to value stock
+type CostingMethodSetting struct {
+	Method string `json:"method"`
+}
+
+// StockAdjustment is a manual correction of an item's stock
+type StockAdjustment struct {
+	ItemID      int     `json:"item_id"`
+	WarehouseID int     `json:"warehouse_id,omitempty"`
+	Quantity    int     `json:"quantity"`
+	PerformedBy *string `json:"performed_by,omitempty"`
+	Notes       *string `json:"notes,omitempty"`
+}
+
+// StockDiscrepancy reports an item whose stored balance differs from the
+// sum of its ledger movements
+type StockDiscrepancy struct {
+	ItemID       int    `json:"item_id"`
+	PartNumber   string `json:"part_number"`
+	Description  string `json:"description"`
+	CurrentStock int    `json:"current_stock"`
+	LedgerStock  int    `json:"ledger_stock"`
+	Difference   int    `json:"difference"`
+}
+
+type MovementFilter struct {
+	ItemID        *int       `query:"item_id"`
+	WarehouseID   *int       `query:"warehouse_id"`
+	MovementType  *string    `query:"movement_type"`
+	ReferenceType *string    `query:"reference_type"`
+	ReferenceID   *int       `query:"reference_id"`
+	StartDate     *time.Time `query:"start_date"`
+	EndDate       *time.Time `query:"end_date"`
+}
@@ -0,0 +1,374 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	stockerrors "github.com/hsrvms/fixparts/internal/modules/inventory/stock/errors"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostMovement appends a movement to the stock ledger and applies it to the
+// item's balance in its warehouse (item_stock) and to the item's total in
+// items.current_stock. It must be called inside the transaction of the
+// document that caused the movement, so that the document, the ledger and
+// the balances are always committed together.
+//
+// A movement that takes the warehouse balance below zero is governed by the
+// item's negative stock policy: refused with an InsufficientStockError under
+// disallow, posted with a warning under allow_with_warning and posted
+// silently under allow.
+//
+// Every movement is valued both FIFO and at the moving weighted average.
+// Stock coming in opens a cost layer and moves the average cost; stock
+// going out consumes the oldest layers first, except that a reversal takes
+// back the layers of its own document first. Transfers between warehouses
+// leave the item's value untouched and are not costed.
+func PostMovement(ctx context.Context, tx pgx.Tx, movement *models.StockMovement) error {
+	if movement.Quantity == 0 {
+		return nil
+	}
+
+	var currentStock int
+	var partNumber, policy string
+	var averageCost, buyPrice float64
+	err := tx.QueryRow(ctx,
+		`SELECT current_stock, part_number, item_negative_stock_policy(item_id),
+		        average_cost, buy_price
+		 FROM items WHERE item_id = $1 FOR UPDATE`,
+		movement.ItemID,
+	).Scan(&currentStock, &partNumber, &policy, &averageCost, &buyPrice)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return stockerrors.ErrItemNotFound
+		}
+		return err
+	}
+
+	warehouseID, err := ResolveWarehouse(ctx, tx, movement.WarehouseID)
+	if err != nil {
+		return err
+	}
+	movement.WarehouseID = warehouseID
+
+	// The item row lock above serialises every change to the item's stock,
+	// including the creation of its balance in a new warehouse
+	var warehouseStock int
+	err = tx.QueryRow(ctx, `
+        INSERT INTO item_stock (item_id, warehouse_id)
+        VALUES ($1, $2)
+        ON CONFLICT (item_id, warehouse_id) DO UPDATE SET quantity = item_stock.quantity
+        RETURNING quantity
+    `, movement.ItemID, movement.WarehouseID).Scan(&warehouseStock)
+	if err != nil {
+		return err
+	}
+
+	warehouseBalance := warehouseStock + movement.Quantity
+	if movement.Quantity < 0 && warehouseBalance < 0 {
+		var code string
+		err := tx.QueryRow(ctx,
+			`SELECT code FROM warehouses WHERE warehouse_id = $1`,
+			movement.WarehouseID,
+		).Scan(&code)
+		if err != nil {
+			return err
+		}
+
+		switch policy {
+		case models.NegativeStockAllow:
+		case models.NegativeStockAllowWithWarning:
+			warning := fmt.Sprintf("%s is now at %d in stock at %s", partNumber, warehouseBalance, code)
+			movement.Warning = &warning
+		default:
+			return &stockerrors.InsufficientStockError{
+				PartNumber: partNumber,
+				Warehouse:  code,
+				Available:  warehouseStock,
+				Requested:  -movement.Quantity,
+			}
+		}
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE item_stock SET quantity = $3 WHERE item_id = $1 AND warehouse_id = $2`,
+		movement.ItemID, movement.WarehouseID, warehouseBalance,
+	)
+	if err != nil {
+		return err
+	}
+
+	costed := movement.MovementType != models.MovementTypeTransferOut &&
+		movement.MovementType != models.MovementTypeTransferIn
+
+	var unitCost float64
+	if costed {
+		if averageCost <= 0 {
+			averageCost = buyPrice
+		}
+
+		if movement.Quantity > 0 {
+			unitCost = averageCost
+			if movement.UnitCost != nil {
+				unitCost = *movement.UnitCost
+			}
+
+			value := roundCost(float64(movement.Quantity) * unitCost)
+			movement.CostFifo = value
+			movement.CostAverage = value
+
+			// Stock coming in after the item went negative first covers the
+			// shortfall, which was already costed when it was sold
+			if currentStock <= 0 {
+				averageCost = unitCost
+			} else {
+				averageCost = (float64(currentStock)*averageCost + float64(movement.Quantity)*unitCost) /
+					float64(currentStock+movement.Quantity)
+			}
+		} else {
+			quantity := -movement.Quantity
+			fifo := 0.0
+			outCost := averageCost
+
+			if movement.Reversal && movement.ReferenceType != nil && movement.ReferenceID != nil {
+				layers, err := lockLayers(ctx, tx, `
+                    SELECT l.layer_id, l.unit_cost, l.remaining
+                    FROM cost_layers l
+                    JOIN stock_movements m ON l.movement_id = m.movement_id
+                    WHERE l.item_id = $1 AND l.remaining > 0
+                    AND m.reference_type = $2 AND m.reference_id = $3
+                    ORDER BY l.layer_id
+                    FOR UPDATE OF l
+                `, movement.ItemID, *movement.ReferenceType, *movement.ReferenceID)
+				if err != nil {
+					return err
+				}
+				if fifo, quantity, err = takeLayers(ctx, tx, layers, quantity); err != nil {
+					return err
+				}
+
+				// Taking stock back at the cost it came in at moves the
+				// average the way receiving it did, as long as some is left
+				balance := currentStock + movement.Quantity
+				if movement.UnitCost != nil && currentStock > 0 && balance > 0 {
+					outCost = *movement.UnitCost
+					averageCost = math.Max(0,
+						(float64(currentStock)*averageCost-float64(-movement.Quantity)*outCost)/float64(balance))
+				}
+			}
+
+			value, err := consumeLayers(ctx, tx, movement.ItemID, quantity, averageCost)
+			if err != nil {
+				return err
+			}
+			movement.CostFifo = -roundCost(fifo + value)
+			movement.CostAverage = -roundCost(float64(-movement.Quantity) * outCost)
+		}
+
+		method, err := costingMethod(ctx, tx)
+		if err != nil {
+			return err
+		}
+		movement.Cost = movement.CostFifo
+		if method == models.CostingAverage {
+			movement.Cost = movement.CostAverage
+		}
+	}
+
+	movement.BalanceAfter = currentStock + movement.Quantity
+	_, err = tx.Exec(ctx,
+		`UPDATE items SET current_stock = $2, average_cost = $3 WHERE item_id = $1`,
+		movement.ItemID, movement.BalanceAfter, math.Round(averageCost*10000)/10000,
+	)
+	if err != nil {
+		return err
+	}
+
+	query := `
+        INSERT INTO stock_movements (
+            item_id, warehouse_id, movement_type, quantity, balance_after,
+            reference_type, reference_id, reference_line_id,
+            performed_by, notes, cost, cost_fifo, cost_average
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+        RETURNING movement_id, created_at
+    `
+
+	err = tx.QueryRow(
+		ctx, query,
+		movement.ItemID,
+		movement.WarehouseID,
+		movement.MovementType,
+		movement.Quantity,
+		movement.BalanceAfter,
+		movement.ReferenceType,
+		movement.ReferenceID,
+		movement.ReferenceLineID,
+		movement.PerformedBy,
+		movement.Notes,
+		movement.Cost,
+		movement.CostFifo,
+		movement.CostAverage,
+	).Scan(&movement.MovementID, &movement.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if !costed || movement.Quantity < 0 {
+		return nil
+	}
+
+	remaining := movement.Quantity
+	if currentStock < 0 {
+		remaining = max(0, currentStock+movement.Quantity)
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO cost_layers (item_id, movement_id, unit_cost, quantity, remaining)
+        VALUES ($1, $2, $3, $4, $5)
+    `, movement.ItemID, movement.MovementID, unitCost, movement.Quantity, remaining)
+	return err
+}
+
+type costLayer struct {
+	id        int
+	unitCost  float64
+	remaining int
+}
+
+// consumeLayers takes quantity out of the item's cost layers, oldest first,
+// and returns its FIFO value. Whatever the layers cannot cover, because the
+// item is going negative, is valued at the fallback cost.
+func consumeLayers(ctx context.Context, tx pgx.Tx, itemID, quantity int, fallbackCost float64) (float64, error) {
+	if quantity == 0 {
+		return 0, nil
+	}
+
+	layers, err := lockLayers(ctx, tx, `
+        SELECT layer_id, unit_cost, remaining
+        FROM cost_layers
+        WHERE item_id = $1 AND remaining > 0
+        ORDER BY layer_id
+        FOR UPDATE
+    `, itemID)
+	if err != nil {
+		return 0, err
+	}
+
+	value, quantity, err := takeLayers(ctx, tx, layers, quantity)
+	if err != nil {
+		return 0, err
+	}
+
+	return value + float64(quantity)*fallbackCost, nil
+}
+
+// lockLayers reads and locks the cost layers selected by the query
+func lockLayers(ctx context.Context, tx pgx.Tx, query string, args ...interface{}) ([]costLayer, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	layers := []costLayer{}
+	for rows.Next() {
+		var layer costLayer
+		if err := rows.Scan(&layer.id, &layer.unitCost, &layer.remaining); err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+
+	return layers, rows.Err()
+}
+
+// takeLayers takes quantity out of the layers in order and returns the
+// value taken and the quantity the layers could not cover
+func takeLayers(ctx context.Context, tx pgx.Tx, layers []costLayer, quantity int) (float64, int, error) {
+	value := 0.0
+	for _, layer := range layers {
+		if quantity == 0 {
+			break
+		}
+
+		taken := min(quantity, layer.remaining)
+		_, err := tx.Exec(ctx,
+			`UPDATE cost_layers SET remaining = remaining - $2 WHERE layer_id = $1`,
+			layer.id, taken,
+		)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		value += float64(taken) * layer.unitCost
+		quantity -= taken
+	}
+
+	return value, quantity, nil
+}
+
+// ReferenceLayerQuantity returns how much of the stock a document brought
+// in for an item is still left in its cost layers
+func ReferenceLayerQuantity(ctx context.Context, tx pgx.Tx, itemID int, referenceType string, referenceID int) (int, error) {
+	var quantity int
+	err := tx.QueryRow(ctx, `
+        SELECT COALESCE(SUM(l.remaining), 0)
+        FROM cost_layers l
+        JOIN stock_movements m ON l.movement_id = m.movement_id
+        WHERE l.item_id = $1 AND m.reference_type = $2 AND m.reference_id = $3
+    `, itemID, referenceType, referenceID).Scan(&quantity)
+
+	return quantity, err
+}
+
+// costingMethod returns the configured costing method
+func costingMethod(ctx context.Context, tx pgx.Tx) (string, error) {
+	var method string
+	err := tx.QueryRow(ctx,
+		`SELECT COALESCE((SELECT value FROM settings WHERE key = $1), $2)`,
+		models.SettingCostingMethod, models.CostingFIFO,
+	).Scan(&method)
+	return method, err
+}
+
+func roundCost(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// PostMovements posts a batch of movements, stock coming in first, so that an
+// edit which moves stock around is only refused when its net effect on an
+// item would be negative
+func PostMovements(ctx context.Context, tx pgx.Tx, movements []*models.StockMovement) error {
+	for _, incoming := range []bool{true, false} {
+		for _, movement := range movements {
+			if (movement.Quantity > 0) != incoming {
+				continue
+			}
+			if err := PostMovement(ctx, tx, movement); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveWarehouse checks that a warehouse exists and returns its ID. Zero
+// stands for the default warehouse.
+func ResolveWarehouse(ctx context.Context, tx pgx.Tx, warehouseID int) (int, error) {
+	var id int
+	err := tx.QueryRow(ctx,
+		`SELECT warehouse_id FROM warehouses WHERE warehouse_id = COALESCE(NULLIF($1, 0), default_warehouse_id())`,
+		warehouseID,
+	).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, stockerrors.ErrWarehouseNotFound
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
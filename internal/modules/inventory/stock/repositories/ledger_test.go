@@ -0,0 +1,542 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	stockerrors "github.com/hsrvms/fixparts/internal/modules/inventory/stock/errors"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	testItemID      = 1
+	testWarehouseID = 1
+)
+
+type fakeItem struct {
+	currentStock int
+	partNumber   string
+	policy       string
+	averageCost  float64
+	buyPrice     float64
+}
+
+type fakeMovement struct {
+	id            int
+	referenceType *string
+	referenceID   *int
+}
+
+type fakeLayer struct {
+	id         int
+	itemID     int
+	movementID int
+	unitCost   float64
+	remaining  int
+}
+
+// fakeLedger is a pgx.Tx that answers the ledger's queries from memory
+type fakeLedger struct {
+	pgx.Tx
+	item          *fakeItem
+	stock         int
+	movements     []*fakeMovement
+	layers        []*fakeLayer
+	costingMethod string
+}
+
+func newFakeLedger(item fakeItem, layerCosts ...float64) *fakeLedger {
+	if item.partNumber == "" {
+		item.partNumber = "P-1"
+	}
+	if item.policy == "" {
+		item.policy = models.NegativeStockDisallow
+	}
+	ledger := &fakeLedger{item: &item, stock: item.currentStock, costingMethod: models.CostingFIFO}
+	// Opening layers split the item's stock evenly over the given costs
+	for _, cost := range layerCosts {
+		ledger.layers = append(ledger.layers, &fakeLayer{
+			id:        len(ledger.layers) + 1,
+			itemID:    testItemID,
+			unitCost:  cost,
+			remaining: item.currentStock / len(layerCosts),
+		})
+	}
+	return ledger
+}
+
+func (l *fakeLedger) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	switch {
+	case strings.Contains(sql, "FROM items WHERE item_id"):
+		if args[0].(int) != testItemID {
+			return fakeRow{err: pgx.ErrNoRows}
+		}
+		item := l.item
+		return fakeRow{values: []any{item.currentStock, item.partNumber, item.policy, item.averageCost, item.buyPrice}}
+	case strings.Contains(sql, "SELECT warehouse_id FROM warehouses"):
+		if id := args[0].(int); id != 0 && id != testWarehouseID {
+			return fakeRow{err: pgx.ErrNoRows}
+		}
+		return fakeRow{values: []any{testWarehouseID}}
+	case strings.Contains(sql, "INSERT INTO item_stock"):
+		return fakeRow{values: []any{l.stock}}
+	case strings.Contains(sql, "SELECT code FROM warehouses"):
+		return fakeRow{values: []any{"MAIN"}}
+	case strings.Contains(sql, "FROM settings"):
+		return fakeRow{values: []any{l.costingMethod}}
+	case strings.Contains(sql, "INSERT INTO stock_movements"):
+		movement := &fakeMovement{
+			id:            len(l.movements) + 1,
+			referenceType: args[5].(*string),
+			referenceID:   args[6].(*int),
+		}
+		l.movements = append(l.movements, movement)
+		return fakeRow{values: []any{movement.id, time.Now()}}
+	case strings.Contains(sql, "SUM(l.remaining)"):
+		quantity := 0
+		for _, layer := range l.referenceLayers(args[1].(string), args[2].(int)) {
+			quantity += layer.remaining
+		}
+		return fakeRow{values: []any{quantity}}
+	}
+	return fakeRow{err: fmt.Errorf("unexpected query: %s", sql)}
+}
+
+func (l *fakeLedger) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	switch {
+	case strings.Contains(sql, "UPDATE item_stock SET quantity"):
+		l.stock = args[2].(int)
+	case strings.Contains(sql, "UPDATE items SET current_stock"):
+		l.item.currentStock = args[1].(int)
+		l.item.averageCost = args[2].(float64)
+	case strings.Contains(sql, "INSERT INTO cost_layers"):
+		l.layers = append(l.layers, &fakeLayer{
+			id:         len(l.layers) + 1,
+			itemID:     args[0].(int),
+			movementID: args[1].(int),
+			unitCost:   args[2].(float64),
+			remaining:  args[4].(int),
+		})
+	case strings.Contains(sql, "UPDATE cost_layers SET remaining"):
+		l.layers[args[0].(int)-1].remaining -= args[1].(int)
+	default:
+		return pgconn.CommandTag{}, fmt.Errorf("unexpected statement: %s", sql)
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+func (l *fakeLedger) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if !strings.Contains(sql, "FROM cost_layers") {
+		return nil, fmt.Errorf("unexpected query: %s", sql)
+	}
+
+	layers := l.layers
+	if strings.Contains(sql, "JOIN stock_movements") {
+		layers = l.referenceLayers(args[1].(string), args[2].(int))
+	}
+
+	rows := &fakeRows{}
+	for _, layer := range layers {
+		if layer.itemID == args[0].(int) && layer.remaining > 0 {
+			rows.values = append(rows.values, []any{layer.id, layer.unitCost, layer.remaining})
+		}
+	}
+	return rows, nil
+}
+
+// referenceLayers returns the layers opened by the document's movements
+func (l *fakeLedger) referenceLayers(referenceType string, referenceID int) []*fakeLayer {
+	var layers []*fakeLayer
+	for _, layer := range l.layers {
+		for _, movement := range l.movements {
+			if movement.id == layer.movementID && movement.referenceType != nil &&
+				*movement.referenceType == referenceType && *movement.referenceID == referenceID {
+				layers = append(layers, layer)
+			}
+		}
+	}
+	return layers
+}
+
+func (l *fakeLedger) remaining() []int {
+	remaining := []int{}
+	for _, layer := range l.layers {
+		remaining = append(remaining, layer.remaining)
+	}
+	return remaining
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	return scanValues(dest, r.values)
+}
+
+type fakeRows struct {
+	pgx.Rows
+	values [][]any
+	next   int
+}
+
+func (r *fakeRows) Next() bool {
+	r.next++
+	return r.next <= len(r.values)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	return scanValues(dest, r.values[r.next-1])
+}
+
+func (r *fakeRows) Close() {}
+
+func (r *fakeRows) Err() error { return nil }
+
+func scanValues(dest, values []any) error {
+	if len(dest) != len(values) {
+		return fmt.Errorf("scanning %d values into %d destinations", len(values), len(dest))
+	}
+	for i, value := range values {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+func purchase(quantity int, unitCost float64, purchaseID int) *models.StockMovement {
+	referenceType := models.ReferenceTypePurchase
+	return &models.StockMovement{
+		ItemID:        testItemID,
+		MovementType:  models.MovementTypePurchase,
+		Quantity:      quantity,
+		ReferenceType: &referenceType,
+		ReferenceID:   &purchaseID,
+		UnitCost:      &unitCost,
+	}
+}
+
+func sale(quantity int) *models.StockMovement {
+	return &models.StockMovement{
+		ItemID:       testItemID,
+		MovementType: models.MovementTypeSale,
+		Quantity:     -quantity,
+	}
+}
+
+func purchaseReversal(quantity int, unitCost float64, purchaseID int) *models.StockMovement {
+	movement := purchase(-quantity, unitCost, purchaseID)
+	movement.MovementType = models.MovementTypePurchaseCorrection
+	movement.Reversal = true
+	return movement
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 0.0005
+}
+
+func TestTakeLayers(t *testing.T) {
+	tests := []struct {
+		name          string
+		quantity      int
+		wantValue     float64
+		wantLeft      int
+		wantRemaining []int
+	}{
+		{"nothing to take", 0, 0, 0, []int{5, 5}},
+		{"within the oldest layer", 3, 30, 0, []int{2, 5}},
+		{"across layers", 7, 90, 0, []int{0, 3}},
+		{"more than the layers hold", 12, 150, 2, []int{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := newFakeLedger(fakeItem{currentStock: 10}, 10, 20)
+			layers := []costLayer{{id: 1, unitCost: 10, remaining: 5}, {id: 2, unitCost: 20, remaining: 5}}
+
+			value, left, err := takeLayers(context.Background(), ledger, layers, tt.quantity)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !closeTo(value, tt.wantValue) || left != tt.wantLeft {
+				t.Errorf("takeLayers = %v, %d, want %v, %d", value, left, tt.wantValue, tt.wantLeft)
+			}
+			if got := ledger.remaining(); !reflect.DeepEqual(got, tt.wantRemaining) {
+				t.Errorf("remaining = %v, want %v", got, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestConsumeLayers(t *testing.T) {
+	tests := []struct {
+		name      string
+		stock     int
+		costs     []float64
+		quantity  int
+		wantValue float64
+	}{
+		{"oldest layer first", 4, []float64{10, 20}, 3, 10 + 10 + 20},
+		{"shortfall at the fallback cost", 2, []float64{10}, 5, 2*10 + 3*8},
+		{"no layers at all", 0, nil, 3, 3 * 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := newFakeLedger(fakeItem{currentStock: tt.stock}, tt.costs...)
+
+			value, err := consumeLayers(context.Background(), ledger, testItemID, tt.quantity, 8)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !closeTo(value, tt.wantValue) {
+				t.Errorf("consumeLayers = %v, want %v", value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestPostMovement(t *testing.T) {
+	tests := []struct {
+		name          string
+		item          fakeItem
+		layerCosts    []float64
+		costingMethod string
+		movements     []*models.StockMovement
+
+		// Expectations on the last movement and the item afterwards
+		wantErr         bool
+		wantWarning     bool
+		wantCost        float64
+		wantCostFifo    float64
+		wantCostAverage float64
+		wantBalance     int
+		wantAverageCost float64
+		wantRemaining   []int
+	}{
+		{
+			name:            "stock coming in opens a layer at its unit cost",
+			item:            fakeItem{buyPrice: 10},
+			movements:       []*models.StockMovement{purchase(5, 12, 1)},
+			wantCost:        60,
+			wantCostFifo:    60,
+			wantCostAverage: 60,
+			wantBalance:     5,
+			wantAverageCost: 12,
+			wantRemaining:   []int{5},
+		},
+		{
+			name: "stock coming in without a unit cost uses the buy price",
+			item: fakeItem{buyPrice: 10},
+			movements: []*models.StockMovement{{
+				ItemID: testItemID, MovementType: models.MovementTypeAdjustment, Quantity: 2,
+			}},
+			wantCost:        20,
+			wantCostFifo:    20,
+			wantCostAverage: 20,
+			wantBalance:     2,
+			wantAverageCost: 10,
+			wantRemaining:   []int{2},
+		},
+		{
+			name:            "FIFO consumes the oldest layers first",
+			movements:       []*models.StockMovement{purchase(5, 10, 1), purchase(5, 20, 2), sale(7)},
+			wantCost:        -(5*10 + 2*20),
+			wantCostFifo:    -(5*10 + 2*20),
+			wantCostAverage: -7 * 15,
+			wantBalance:     3,
+			wantAverageCost: 15,
+			wantRemaining:   []int{0, 3},
+		},
+		{
+			name:            "average costing values the movement at the average",
+			costingMethod:   models.CostingAverage,
+			movements:       []*models.StockMovement{purchase(5, 10, 1), purchase(5, 20, 2), sale(7)},
+			wantCost:        -7 * 15,
+			wantCostFifo:    -(5*10 + 2*20),
+			wantCostAverage: -7 * 15,
+			wantBalance:     3,
+			wantAverageCost: 15,
+			wantRemaining:   []int{0, 3},
+		},
+		{
+			name:            "going negative values the shortfall at the average cost",
+			item:            fakeItem{currentStock: 2, averageCost: 8, policy: models.NegativeStockAllow},
+			layerCosts:      []float64{10},
+			movements:       []*models.StockMovement{sale(5)},
+			wantCost:        -(2*10 + 3*8),
+			wantCostFifo:    -(2*10 + 3*8),
+			wantCostAverage: -5 * 8,
+			wantBalance:     -3,
+			wantAverageCost: 8,
+			wantRemaining:   []int{0},
+		},
+		{
+			name:            "stock coming in while negative covers the shortfall first",
+			item:            fakeItem{currentStock: -3, averageCost: 8, policy: models.NegativeStockAllow},
+			movements:       []*models.StockMovement{purchase(5, 9, 1)},
+			wantCost:        45,
+			wantCostFifo:    45,
+			wantCostAverage: 45,
+			wantBalance:     2,
+			wantAverageCost: 9,
+			wantRemaining:   []int{2},
+		},
+		{
+			name:          "disallow refuses to go negative",
+			item:          fakeItem{currentStock: 2, averageCost: 10},
+			layerCosts:    []float64{10},
+			movements:     []*models.StockMovement{sale(5)},
+			wantErr:       true,
+			wantBalance:   2,
+			wantRemaining: []int{2},
+		},
+		{
+			name:            "allow_with_warning goes negative with a warning",
+			item:            fakeItem{currentStock: 2, averageCost: 10, policy: models.NegativeStockAllowWithWarning},
+			layerCosts:      []float64{10},
+			movements:       []*models.StockMovement{sale(5)},
+			wantWarning:     true,
+			wantCost:        -50,
+			wantCostFifo:    -50,
+			wantCostAverage: -50,
+			wantBalance:     -3,
+			wantAverageCost: 10,
+			wantRemaining:   []int{0},
+		},
+		{
+			name: "a reversal takes back its own layers at its own cost",
+			movements: []*models.StockMovement{
+				purchase(5, 10, 1), purchase(5, 20, 2), purchaseReversal(5, 20, 2),
+			},
+			wantCost:        -100,
+			wantCostFifo:    -100,
+			wantCostAverage: -100,
+			wantBalance:     5,
+			wantAverageCost: 10,
+			wantRemaining:   []int{5, 0},
+		},
+		{
+			name: "a reversal of partly sold stock values the rest at the average cost",
+			item: fakeItem{policy: models.NegativeStockAllow},
+			movements: []*models.StockMovement{
+				purchase(5, 10, 1), purchase(5, 20, 2), sale(7), purchaseReversal(5, 20, 2),
+			},
+			wantCost:        -(3*20 + 2*15),
+			wantCostFifo:    -(3*20 + 2*15),
+			wantCostAverage: -5 * 15,
+			wantBalance:     -2,
+			wantAverageCost: 15,
+			wantRemaining:   []int{0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := newFakeLedger(tt.item, tt.layerCosts...)
+			if tt.costingMethod != "" {
+				ledger.costingMethod = tt.costingMethod
+			}
+
+			var err error
+			for _, movement := range tt.movements {
+				if err = PostMovement(context.Background(), ledger, movement); err != nil {
+					break
+				}
+			}
+
+			if tt.wantErr {
+				var short *stockerrors.InsufficientStockError
+				if !errors.As(err, &short) {
+					t.Fatalf("err = %v, want an InsufficientStockError", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				last := tt.movements[len(tt.movements)-1]
+				if (last.Warning != nil) != tt.wantWarning {
+					t.Errorf("warning = %v, want one: %v", last.Warning, tt.wantWarning)
+				}
+				if !closeTo(last.Cost, tt.wantCost) || !closeTo(last.CostFifo, tt.wantCostFifo) ||
+					!closeTo(last.CostAverage, tt.wantCostAverage) {
+					t.Errorf("cost, fifo, average = %v, %v, %v, want %v, %v, %v",
+						last.Cost, last.CostFifo, last.CostAverage,
+						tt.wantCost, tt.wantCostFifo, tt.wantCostAverage)
+				}
+				if last.BalanceAfter != tt.wantBalance {
+					t.Errorf("balance after = %d, want %d", last.BalanceAfter, tt.wantBalance)
+				}
+				if !closeTo(ledger.item.averageCost, tt.wantAverageCost) {
+					t.Errorf("average cost = %v, want %v", ledger.item.averageCost, tt.wantAverageCost)
+				}
+			}
+
+			if ledger.item.currentStock != tt.wantBalance || ledger.stock != tt.wantBalance {
+				t.Errorf("stock = %d in total and %d in the warehouse, want %d",
+					ledger.item.currentStock, ledger.stock, tt.wantBalance)
+			}
+			if got := ledger.remaining(); !reflect.DeepEqual(got, tt.wantRemaining) {
+				t.Errorf("remaining = %v, want %v", got, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+// TestPurchaseCostChange follows the corrections the purchase repository
+// posts when the unit cost of a partly sold purchase is changed
+func TestPurchaseCostChange(t *testing.T) {
+	ctx := context.Background()
+	ledger := newFakeLedger(fakeItem{})
+
+	for _, movement := range []*models.StockMovement{purchase(5, 8, 1), purchase(5, 10, 2), sale(2)} {
+		if err := PostMovement(ctx, ledger, movement); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	left, err := ReferenceLayerQuantity(ctx, ledger, testItemID, models.ReferenceTypePurchase, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if left != 5 {
+		t.Fatalf("left of purchase 2 = %d, want 5", left)
+	}
+
+	// Purchase 2 is re-costed from 10 to 12
+	reversal := purchaseReversal(left, 10, 2)
+	correction := purchase(left, 12, 2)
+	if err := PostMovements(ctx, ledger, []*models.StockMovement{reversal, correction}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The reversal takes back the old layer even though the re-costed one
+	// belongs to the same purchase and the oldest layer is still open
+	if !closeTo(reversal.CostFifo, -50) || !closeTo(reversal.CostAverage, -50) {
+		t.Errorf("reversal fifo, average = %v, %v, want -50, -50", reversal.CostFifo, reversal.CostAverage)
+	}
+	if got, want := ledger.remaining(), []int{3, 0, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("remaining = %v, want %v", got, want)
+	}
+	if ledger.item.currentStock != 8 {
+		t.Errorf("stock = %d, want 8", ledger.item.currentStock)
+	}
+
+	// 8 left at an average of 9 become 13 with 5 at 12, then 8 again once
+	// the 5 at 10 are taken out
+	want := (13*math.Round((8*9.0+5*12)/13*10000)/10000 - 5*10) / 8
+	if !closeTo(ledger.item.averageCost, want) {
+		t.Errorf("average cost = %v, want %v", ledger.item.averageCost, want)
+	}
+}
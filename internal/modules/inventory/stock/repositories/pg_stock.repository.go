@@ -0,0 +1,294 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresStockRepository struct {
+	db *db.Database
+}
+
+func NewPostgresStockRepository(database *db.Database) StockRepository {
+	return &PostgresStockRepository{
+		db: database,
+	}
+}
+
+func (r *PostgresStockRepository) GetMovements(ctx context.Context, filter *models.MovementFilter) ([]*models.StockMovement, error) {
+	query := `
+        SELECT
+            m.movement_id, m.item_id, m.warehouse_id, m.movement_type, m.quantity,
+            m.balance_after, m.reference_type, m.reference_id,
+            m.reference_line_id, m.performed_by, m.notes,
+            m.cost, m.cost_fifo, m.cost_average, m.created_at,
+            i.part_number as item_part_number,
+            i.description as item_description,
+            w.code as warehouse_code
+        FROM stock_movements m
+        JOIN items i ON m.item_id = i.item_id
+        JOIN warehouses w ON m.warehouse_id = w.warehouse_id
+        WHERE 1=1
+    `
+
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.ItemID != nil {
+			conditions = append(conditions, fmt.Sprintf("m.item_id = $%d", paramCount))
+			params = append(params, *filter.ItemID)
+			paramCount++
+		}
+
+		if filter.WarehouseID != nil {
+			conditions = append(conditions, fmt.Sprintf("m.warehouse_id = $%d", paramCount))
+			params = append(params, *filter.WarehouseID)
+			paramCount++
+		}
+
+		if filter.MovementType != nil {
+			conditions = append(conditions, fmt.Sprintf("m.movement_type = $%d", paramCount))
+			params = append(params, *filter.MovementType)
+			paramCount++
+		}
+
+		if filter.ReferenceType != nil {
+			conditions = append(conditions, fmt.Sprintf("m.reference_type = $%d", paramCount))
+			params = append(params, *filter.ReferenceType)
+			paramCount++
+		}
+
+		if filter.ReferenceID != nil {
+			conditions = append(conditions, fmt.Sprintf("m.reference_id = $%d", paramCount))
+			params = append(params, *filter.ReferenceID)
+			paramCount++
+		}
+
+		if filter.StartDate != nil {
+			conditions = append(conditions, fmt.Sprintf("m.created_at >= $%d", paramCount))
+			params = append(params, *filter.StartDate)
+			paramCount++
+		}
+
+		if filter.EndDate != nil {
+			conditions = append(conditions, fmt.Sprintf("m.created_at <= $%d", paramCount))
+			params = append(params, *filter.EndDate)
+			paramCount++
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY m.created_at DESC, m.movement_id DESC"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := []*models.StockMovement{}
+	for rows.Next() {
+		movement := &models.StockMovement{}
+		err := rows.Scan(
+			&movement.MovementID,
+			&movement.ItemID,
+			&movement.WarehouseID,
+			&movement.MovementType,
+			&movement.Quantity,
+			&movement.BalanceAfter,
+			&movement.ReferenceType,
+			&movement.ReferenceID,
+			&movement.ReferenceLineID,
+			&movement.PerformedBy,
+			&movement.Notes,
+			&movement.Cost,
+			&movement.CostFifo,
+			&movement.CostAverage,
+			&movement.CreatedAt,
+			&movement.ItemPartNumber,
+			&movement.ItemDescription,
+			&movement.WarehouseCode,
+		)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, rows.Err()
+}
+
+// Adjust posts a manual stock correction
+func (r *PostgresStockRepository) Adjust(ctx context.Context, adjustment *models.StockAdjustment) (*models.StockMovement, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	referenceType := models.ReferenceTypeItem
+	movement := &models.StockMovement{
+		ItemID:        adjustment.ItemID,
+		WarehouseID:   adjustment.WarehouseID,
+		MovementType:  models.MovementTypeAdjustment,
+		Quantity:      adjustment.Quantity,
+		ReferenceType: &referenceType,
+		ReferenceID:   &adjustment.ItemID,
+		PerformedBy:   adjustment.PerformedBy,
+		Notes:         adjustment.Notes,
+	}
+	if err := PostMovement(ctx, tx, movement); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+// GetDiscrepancies lists items whose stored balance no longer matches the
+// sum of their ledger movements
+func (r *PostgresStockRepository) GetDiscrepancies(ctx context.Context) ([]*models.StockDiscrepancy, error) {
+	query := `
+        SELECT
+            i.item_id, i.part_number, i.description, i.current_stock,
+            COALESCE(SUM(m.quantity), 0)::INTEGER as ledger_stock
+        FROM items i
+        LEFT JOIN stock_movements m ON m.item_id = i.item_id
+        GROUP BY i.item_id, i.part_number, i.description, i.current_stock
+        HAVING i.current_stock <> COALESCE(SUM(m.quantity), 0)
+        ORDER BY i.part_number
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	discrepancies := []*models.StockDiscrepancy{}
+	for rows.Next() {
+		d := &models.StockDiscrepancy{}
+		err := rows.Scan(
+			&d.ItemID,
+			&d.PartNumber,
+			&d.Description,
+			&d.CurrentStock,
+			&d.LedgerStock,
+		)
+		if err != nil {
+			return nil, err
+		}
+		d.Difference = d.CurrentStock - d.LedgerStock
+		discrepancies = append(discrepancies, d)
+	}
+
+	return discrepancies, rows.Err()
+}
+
+// Reconcile resets items.current_stock and the item's warehouse balances to
+// the ledger for every item that has drifted and returns the corrections
+// that were applied
+func (r *PostgresStockRepository) Reconcile(ctx context.Context) ([]*models.StockDiscrepancy, error) {
+	discrepancies, err := r.GetDiscrepancies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(discrepancies) == 0 {
+		return discrepancies, nil
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+        UPDATE items i
+        SET current_stock = l.ledger_stock
+        FROM (
+            SELECT i2.item_id, COALESCE(SUM(m.quantity), 0)::INTEGER as ledger_stock
+            FROM items i2
+            LEFT JOIN stock_movements m ON m.item_id = i2.item_id
+            WHERE i2.item_id = ANY($1)
+            GROUP BY i2.item_id
+        ) l
+        WHERE i.item_id = l.item_id
+    `
+
+	itemIDs := make([]int, 0, len(discrepancies))
+	for _, d := range discrepancies {
+		itemIDs = append(itemIDs, d.ItemID)
+	}
+
+	if _, err := tx.Exec(ctx, query, itemIDs); err != nil {
+		return nil, err
+	}
+
+	warehouseQuery := `
+        UPDATE item_stock ws
+        SET quantity = COALESCE((
+            SELECT SUM(m.quantity)::INTEGER FROM stock_movements m
+            WHERE m.item_id = ws.item_id AND m.warehouse_id = ws.warehouse_id
+        ), 0)
+        WHERE ws.item_id = ANY($1)
+    `
+	if _, err := tx.Exec(ctx, warehouseQuery, itemIDs); err != nil {
+		return nil, err
+	}
+
+	missingQuery := `
+        INSERT INTO item_stock (item_id, warehouse_id, quantity)
+        SELECT m.item_id, m.warehouse_id, SUM(m.quantity)::INTEGER
+        FROM stock_movements m
+        WHERE m.item_id = ANY($1)
+        GROUP BY m.item_id, m.warehouse_id
+        ON CONFLICT (item_id, warehouse_id) DO NOTHING
+    `
+	if _, err := tx.Exec(ctx, missingQuery, itemIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return discrepancies, nil
+}
+
+func (r *PostgresStockRepository) GetSetting(ctx context.Context, key string) (*string, error) {
+	var value string
+	err := r.db.Pool.QueryRow(ctx, `SELECT value FROM settings WHERE key = $1`, key).Scan(&value)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &value, nil
+}
+
+func (r *PostgresStockRepository) SetSetting(ctx context.Context, key, value string) error {
+	query := `
+        INSERT INTO settings (key, value) VALUES ($1, $2)
+        ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value
+    `
+
+	_, err := r.db.Pool.Exec(ctx, query, key, value)
+	return err
+}
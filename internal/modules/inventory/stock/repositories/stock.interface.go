@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+)
+
+type StockRepository interface {
+	GetMovements(ctx context.Context, filter *models.MovementFilter) ([]*models.StockMovement, error)
+	Adjust(ctx context.Context, adjustment *models.StockAdjustment) (*models.StockMovement, error)
+	GetDiscrepancies(ctx context.Context) ([]*models.StockDiscrepancy, error)
+	Reconcile(ctx context.Context) ([]*models.StockDiscrepancy, error)
+	GetSetting(ctx context.Context, key string) (*string, error)
+	SetSetting(ctx context.Context, key, value string) error
+}
@@ -0,0 +1,29 @@
+package stock
+
+import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stock/handlers"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stock/repositories"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stock/services"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(e *echo.Echo, api *echo.Group, database *db.Database) {
+	repo := repositories.NewPostgresStockRepository(database)
+	service := services.NewStockService(repo)
+	handler := handlers.NewStockHandler(service)
+
+	stock := api.Group("/stock", authMiddleware.Guard(authModels.PermInventoryRead, authModels.PermInventoryWrite))
+	stock.GET("/movements", handler.GetMovements)
+	stock.POST("/adjustments", handler.CreateAdjustment)
+	stock.GET("/reconciliation", handler.GetDiscrepancies)
+	stock.POST("/reconciliation", handler.Reconcile)
+	stock.GET("/negative-stock-policy", handler.GetNegativeStockPolicy)
+	stock.PUT("/negative-stock-policy", handler.UpdateNegativeStockPolicy, authMiddleware.Require(authModels.PermSettingsWrite))
+	stock.GET("/costing-method", handler.GetCostingMethod)
+	stock.PUT("/costing-method", handler.UpdateCostingMethod, authMiddleware.Require(authModels.PermSettingsWrite))
+
+	api.GET("/items/:itemId/movements", handler.GetItemMovements, authMiddleware.Require(authModels.PermInventoryRead))
+}
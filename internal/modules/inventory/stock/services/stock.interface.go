@@ -0,0 +1,19 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+)
+
+type StockService interface {
+	GetMovements(ctx context.Context, filter *models.MovementFilter) ([]*models.StockMovement, error)
+	GetItemMovements(ctx context.Context, itemID int) ([]*models.StockMovement, error)
+	Adjust(ctx context.Context, adjustment *models.StockAdjustment) (*models.StockMovement, error)
+	GetDiscrepancies(ctx context.Context) ([]*models.StockDiscrepancy, error)
+	Reconcile(ctx context.Context) ([]*models.StockDiscrepancy, error)
+	GetNegativeStockPolicy(ctx context.Context) (*models.NegativeStockSetting, error)
+	SetNegativeStockPolicy(ctx context.Context, setting *models.NegativeStockSetting) error
+	GetCostingMethod(ctx context.Context) (*models.CostingMethodSetting, error)
+	SetCostingMethod(ctx context.Context, setting *models.CostingMethodSetting) error
+}
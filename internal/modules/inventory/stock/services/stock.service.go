@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	stockerrors "github.com/hsrvms/fixparts/internal/modules/inventory/stock/errors"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stock/repositories"
+)
+
+type stockService struct {
+	repo repositories.StockRepository
+}
+
+func NewStockService(repo repositories.StockRepository) StockService {
+	return &stockService{
+		repo: repo,
+	}
+}
+
+func (s *stockService) GetMovements(ctx context.Context, filter *models.MovementFilter) ([]*models.StockMovement, error) {
+	return s.repo.GetMovements(ctx, filter)
+}
+
+func (s *stockService) GetItemMovements(ctx context.Context, itemID int) ([]*models.StockMovement, error) {
+	if itemID <= 0 {
+		return nil, stockerrors.ErrInvalidItemID
+	}
+
+	filter := &models.MovementFilter{
+		ItemID: &itemID,
+	}
+	return s.repo.GetMovements(ctx, filter)
+}
+
+func (s *stockService) Adjust(ctx context.Context, adjustment *models.StockAdjustment) (*models.StockMovement, error) {
+	if adjustment.ItemID <= 0 {
+		return nil, stockerrors.ErrInvalidItemID
+	}
+	if adjustment.Quantity == 0 {
+		return nil, stockerrors.ErrInvalidQuantity
+	}
+	// Manual corrections must say why they were made
+	if adjustment.Notes == nil || strings.TrimSpace(*adjustment.Notes) == "" {
+		return nil, stockerrors.ErrAdjustmentNoteNeeded
+	}
+
+	return s.repo.Adjust(ctx, adjustment)
+}
+
+func (s *stockService) GetDiscrepancies(ctx context.Context) ([]*models.StockDiscrepancy, error) {
+	return s.repo.GetDiscrepancies(ctx)
+}
+
+func (s *stockService) Reconcile(ctx context.Context) ([]*models.StockDiscrepancy, error) {
+	return s.repo.Reconcile(ctx)
+}
+
+// GetNegativeStockPolicy returns the global policy, which applies to items
+// without an item or category override
+func (s *stockService) GetNegativeStockPolicy(ctx context.Context) (*models.NegativeStockSetting, error) {
+	value, err := s.repo.GetSetting(ctx, models.SettingNegativeStockPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	setting := &models.NegativeStockSetting{Policy: models.NegativeStockDisallow}
+	if value != nil {
+		setting.Policy = *value
+	}
+
+	return setting, nil
+}
+
+func (s *stockService) SetNegativeStockPolicy(ctx context.Context, setting *models.NegativeStockSetting) error {
+	if !models.IsValidNegativeStockPolicy(setting.Policy) {
+		return stockerrors.ErrInvalidStockPolicy
+	}
+
+	return s.repo.SetSetting(ctx, models.SettingNegativeStockPolicy, setting.Policy)
+}
+
+// GetCostingMethod returns the method used to cost sales and value stock
+func (s *stockService) GetCostingMethod(ctx context.Context) (*models.CostingMethodSetting, error) {
+	value, err := s.repo.GetSetting(ctx, models.SettingCostingMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	setting := &models.CostingMethodSetting{Method: models.CostingFIFO}
+	if value != nil {
+		setting.Method = *value
+	}
+
+	return setting, nil
+}
+
+// SetCostingMethod changes the method used from now on. Movements already
+// posted keep the cost they were recorded with.
+func (s *stockService) SetCostingMethod(ctx context.Context, setting *models.CostingMethodSetting) error {
+	if !models.IsValidCostingMethod(setting.Method) {
+		return stockerrors.ErrInvalidCostingMethod
+	}
+
+	return s.repo.SetSetting(ctx, models.SettingCostingMethod, setting.Method)
+}
@@ -0,0 +1,18 @@
+package stocktakeerrors
+
+import "errors"
+
+var (
+	ErrStocktakeNotFound   = errors.New("stocktake not found")
+	ErrWarehouseNotFound   = errors.New("warehouse not found")
+	ErrInvalidStocktakeID  = errors.New("invalid stocktake ID")
+	ErrScopeRequired       = errors.New("a stocktake must be limited to a category, a supplier or a location")
+	ErrNoItemsInScope      = errors.New("no items match the stocktake scope")
+	ErrItemsAlreadyCounted = errors.New("some items are already on an open stocktake")
+	ErrStocktakeNotOpen    = errors.New("stocktake is no longer open")
+	ErrItemRequired        = errors.New("item ID or barcode is required")
+	ErrItemNotFound        = errors.New("item not found")
+	ErrItemNotInStocktake  = errors.New("item is not part of this stocktake")
+	ErrInvalidQuantity     = errors.New("counted quantity cannot be negative")
+	ErrReasonRequired      = errors.New("a reason for the stock corrections is required")
+)
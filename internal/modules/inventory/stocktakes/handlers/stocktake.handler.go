@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	stockerrors "github.com/hsrvms/fixparts/internal/modules/inventory/stock/errors"
+	stocktakeerrors "github.com/hsrvms/fixparts/internal/modules/inventory/stocktakes/errors"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stocktakes/models"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stocktakes/services"
+	"github.com/labstack/echo/v4"
+)
+
+type StocktakeHandler struct {
+	service services.StocktakeService
+}
+
+func NewStocktakeHandler(service services.StocktakeService) *StocktakeHandler {
+	return &StocktakeHandler{
+		service: service,
+	}
+}
+
+// GetStocktakes handles retrieval of all stocktakes with optional filtering
+func (h *StocktakeHandler) GetStocktakes(c echo.Context) error {
+	filter := &models.StocktakeFilter{}
+
+	// Parse query parameters
+	if status := c.QueryParam("status"); status != "" {
+		filter.Status = &status
+	}
+
+	if warehouseID := c.QueryParam("warehouse_id"); warehouseID != "" {
+		id, err := strconv.Atoi(warehouseID)
+		if err == nil {
+			filter.WarehouseID = &id
+		}
+	}
+
+	if categoryID := c.QueryParam("category_id"); categoryID != "" {
+		id, err := strconv.Atoi(categoryID)
+		if err == nil {
+			filter.CategoryID = &id
+		}
+	}
+
+	if supplierID := c.QueryParam("supplier_id"); supplierID != "" {
+		id, err := strconv.Atoi(supplierID)
+		if err == nil {
+			filter.SupplierID = &id
+		}
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		if date, err := time.Parse(time.RFC3339, startDate); err == nil {
+			filter.StartDate = &date
+		}
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		if date, err := time.Parse(time.RFC3339, endDate); err == nil {
+			filter.EndDate = &date
+		}
+	}
+
+	ctx := c.Request().Context()
+	stocktakes, err := h.service.GetAll(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, stocktakes)
+}
+
+// GetStocktakeByID handles retrieval of a single stocktake with its lines
+func (h *StocktakeHandler) GetStocktakeByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stocktake ID")
+	}
+
+	ctx := c.Request().Context()
+	stocktake, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return stocktakeError(err)
+	}
+
+	return c.JSON(http.StatusOK, stocktake)
+}
+
+// CreateStocktake handles starting a count and snapshotting expected stock
+func (h *StocktakeHandler) CreateStocktake(c echo.Context) error {
+	stocktake := new(models.Stocktake)
+	if err := c.Bind(stocktake); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.Create(ctx, stocktake)
+	if err != nil {
+		return stocktakeError(err)
+	}
+
+	created, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return stocktakeError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// RecordCount handles a counted quantity or a barcode scan
+func (h *StocktakeHandler) RecordCount(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stocktake ID")
+	}
+
+	count := new(models.StocktakeCount)
+	if err := c.Bind(count); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	line, err := h.service.RecordCount(ctx, id, count)
+	if err != nil {
+		return stocktakeError(err)
+	}
+
+	return c.JSON(http.StatusOK, line)
+}
+
+// GetVariance handles the variance report of a stocktake
+func (h *StocktakeHandler) GetVariance(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stocktake ID")
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetVariance(ctx, id)
+	if err != nil {
+		return stocktakeError(err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// ApproveStocktake handles applying the stocktake corrections to stock
+func (h *StocktakeHandler) ApproveStocktake(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stocktake ID")
+	}
+
+	approval := new(models.StocktakeApproval)
+	if err := c.Bind(approval); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Approve(ctx, id, approval); err != nil {
+		return stocktakeError(err)
+	}
+
+	return h.GetStocktakeByID(c)
+}
+
+// CancelStocktake handles abandoning a count without touching stock
+func (h *StocktakeHandler) CancelStocktake(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid stocktake ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Cancel(ctx, id); err != nil {
+		return stocktakeError(err)
+	}
+
+	return h.GetStocktakeByID(c)
+}
+
+// stocktakeError maps stocktake errors to HTTP errors
+func stocktakeError(err error) error {
+	if errors.Is(err, stockerrors.ErrInsufficientStock) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	switch err {
+	case stocktakeerrors.ErrStocktakeNotFound, stocktakeerrors.ErrItemNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case stocktakeerrors.ErrInvalidStocktakeID, stocktakeerrors.ErrScopeRequired,
+		stocktakeerrors.ErrNoItemsInScope, stocktakeerrors.ErrItemRequired,
+		stocktakeerrors.ErrItemNotInStocktake, stocktakeerrors.ErrInvalidQuantity,
+		stocktakeerrors.ErrReasonRequired, stocktakeerrors.ErrWarehouseNotFound:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case stocktakeerrors.ErrItemsAlreadyCounted, stocktakeerrors.ErrStocktakeNotOpen:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
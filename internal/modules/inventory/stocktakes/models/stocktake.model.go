@@ -0,0 +1,124 @@
+package models
+
+import "time"
+
+// Stocktake statuses
+const (
+	StocktakeOpen      = "open"
+	StocktakeApproved  = "approved"
+	StocktakeCancelled = "cancelled"
+)
+
+// Stocktake is a count of the items in a category, of a supplier or at a
+// location in one warehouse. Expected quantities are snapshotted when the count starts and
+// stock is only corrected when the count is approved.
+type Stocktake struct {
+	StocktakeID     int        `json:"stocktake_id" db:"stocktake_id"`
+	StocktakeNumber string     `json:"stocktake_number" db:"stocktake_number"`
+	Status          string     `json:"status" db:"status"`
+	WarehouseID     int        `json:"warehouse_id" db:"warehouse_id"`
+	CategoryID      *int       `json:"category_id,omitempty" db:"category_id"`
+	SupplierID      *int       `json:"supplier_id,omitempty" db:"supplier_id"`
+	LocationAisle   *string    `json:"location_aisle,omitempty" db:"location_aisle"`
+	LocationShelf   *string    `json:"location_shelf,omitempty" db:"location_shelf"`
+	LocationBin     *string    `json:"location_bin,omitempty" db:"location_bin"`
+	StartedAt       time.Time  `json:"started_at" db:"started_at"`
+	StartedBy       *string    `json:"started_by,omitempty" db:"started_by"`
+	ApprovedAt      *time.Time `json:"approved_at,omitempty" db:"approved_at"`
+	ApprovedBy      *string    `json:"approved_by,omitempty" db:"approved_by"`
+	CancelledAt     *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	Reason          *string    `json:"reason,omitempty" db:"reason"`
+	Notes           *string    `json:"notes,omitempty" db:"notes"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+
+	Lines []*StocktakeLine `json:"lines" db:"-"`
+
+	// Additional fields for API responses
+	WarehouseCode string  `json:"warehouse_code,omitempty" db:"warehouse_code"`
+	CategoryName  *string `json:"category_name,omitempty" db:"category_name"`
+	SupplierName  *string `json:"supplier_name,omitempty" db:"supplier_name"`
+	ItemCount     int     `json:"item_count" db:"-"`
+	CountedCount  int     `json:"counted_count" db:"-"`
+}
+
+// HasScope tells whether the stocktake is limited to a category, a supplier
+// or a location
+func (s *Stocktake) HasScope() bool {
+	return s.CategoryID != nil || s.SupplierID != nil ||
+		s.LocationAisle != nil || s.LocationShelf != nil || s.LocationBin != nil
+}
+
+// StocktakeLine is the expected and counted quantity of one item. An item
+// that has not been counted yet has no counted quantity.
+type StocktakeLine struct {
+	LineID           int        `json:"line_id" db:"line_id"`
+	StocktakeID      int        `json:"stocktake_id" db:"stocktake_id"`
+	ItemID           int        `json:"item_id" db:"item_id"`
+	ExpectedQuantity int        `json:"expected_quantity" db:"expected_quantity"`
+	CountedQuantity  *int       `json:"counted_quantity,omitempty" db:"counted_quantity"`
+	UnitCost         float64    `json:"unit_cost" db:"unit_cost" access:"full"`
+	CountedAt        *time.Time `json:"counted_at,omitempty" db:"counted_at"`
+	CountedBy        *string    `json:"counted_by,omitempty" db:"counted_by"`
+
+	// Additional fields for API responses
+	ItemPartNumber  string  `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription string  `json:"item_description,omitempty" db:"item_description"`
+	Barcode         *string `json:"barcode,omitempty" db:"barcode"`
+}
+
+// StocktakeCount records a counted quantity. The item is given by its ID or
+// by a scanned barcode. Add counts on top of what was counted so far, as each
+// scan of a barcode does; otherwise the quantity replaces the count.
+type StocktakeCount struct {
+	ItemID    int     `json:"item_id"`
+	Barcode   *string `json:"barcode,omitempty"`
+	Quantity  *int    `json:"quantity,omitempty"`
+	Add       bool    `json:"add"`
+	CountedBy *string `json:"counted_by,omitempty"`
+}
+
+// StocktakeApproval closes a count and applies its corrections to stock
+type StocktakeApproval struct {
+	ApprovedBy *string `json:"approved_by,omitempty"`
+	Reason     string  `json:"reason"`
+	Notes      *string `json:"notes,omitempty"`
+}
+
+// VarianceLine is a counted item whose count differs from the snapshot.
+// Value impact is the variance at the item's cost when the count started.
+type VarianceLine struct {
+	ItemID           int     `json:"item_id"`
+	PartNumber       string  `json:"part_number"`
+	Description      string  `json:"description"`
+	ExpectedQuantity int     `json:"expected_quantity"`
+	CountedQuantity  int     `json:"counted_quantity"`
+	Variance         int     `json:"variance"`
+	UnitCost         float64 `json:"unit_cost" access:"full"`
+	ValueImpact      float64 `json:"value_impact" access:"full"`
+}
+
+// VarianceReport summarises the differences found by a stocktake
+type VarianceReport struct {
+	StocktakeID      int             `json:"stocktake_id"`
+	StocktakeNumber  string          `json:"stocktake_number"`
+	Status           string          `json:"status"`
+	ItemCount        int             `json:"item_count"`
+	CountedCount     int             `json:"counted_count"`
+	UncountedCount   int             `json:"uncounted_count"`
+	VarianceCount    int             `json:"variance_count"`
+	GainValue        float64         `json:"gain_value" access:"full"`
+	LossValue        float64         `json:"loss_value" access:"full"`
+	NetValueImpact   float64         `json:"net_value_impact" access:"full"`
+	Lines            []*VarianceLine `json:"lines"`
+	UncountedItemIDs []int           `json:"uncounted_item_ids"`
+}
+
+type StocktakeFilter struct {
+	Status      *string    `query:"status"`
+	WarehouseID *int       `query:"warehouse_id"`
+	CategoryID  *int       `query:"category_id"`
+	SupplierID  *int       `query:"supplier_id"`
+	StartDate   *time.Time `query:"start_date"`
+	EndDate     *time.Time `query:"end_date"`
+}
@@ -0,0 +1,571 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	stockerrors "github.com/hsrvms/fixparts/internal/modules/inventory/stock/errors"
+	stockModels "github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+	stock "github.com/hsrvms/fixparts/internal/modules/inventory/stock/repositories"
+	stocktakeerrors "github.com/hsrvms/fixparts/internal/modules/inventory/stocktakes/errors"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stocktakes/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresStocktakeRepository struct {
+	db *db.Database
+}
+
+func NewPostgresStocktakeRepository(database *db.Database) StocktakeRepository {
+	return &PostgresStocktakeRepository{
+		db: database,
+	}
+}
+
+func (r *PostgresStocktakeRepository) GetAll(ctx context.Context, filter *models.StocktakeFilter) ([]*models.Stocktake, error) {
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.Status != nil {
+			conditions = append(conditions, fmt.Sprintf("st.status = $%d", paramCount))
+			params = append(params, *filter.Status)
+			paramCount++
+		}
+
+		if filter.WarehouseID != nil {
+			conditions = append(conditions, fmt.Sprintf("st.warehouse_id = $%d", paramCount))
+			params = append(params, *filter.WarehouseID)
+			paramCount++
+		}
+
+		if filter.CategoryID != nil {
+			conditions = append(conditions, fmt.Sprintf("st.category_id = $%d", paramCount))
+			params = append(params, *filter.CategoryID)
+			paramCount++
+		}
+
+		if filter.SupplierID != nil {
+			conditions = append(conditions, fmt.Sprintf("st.supplier_id = $%d", paramCount))
+			params = append(params, *filter.SupplierID)
+			paramCount++
+		}
+
+		if filter.StartDate != nil {
+			conditions = append(conditions, fmt.Sprintf("st.started_at >= $%d", paramCount))
+			params = append(params, *filter.StartDate)
+			paramCount++
+		}
+
+		if filter.EndDate != nil {
+			conditions = append(conditions, fmt.Sprintf("st.started_at <= $%d", paramCount))
+			params = append(params, *filter.EndDate)
+			paramCount++
+		}
+	}
+
+	where := "1=1"
+	if len(conditions) > 0 {
+		where = strings.Join(conditions, " AND ")
+	}
+
+	return r.getStocktakes(ctx, where, params...)
+}
+
+func (r *PostgresStocktakeRepository) GetByID(ctx context.Context, id int) (*models.Stocktake, error) {
+	stocktakes, err := r.getStocktakes(ctx, "st.stocktake_id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(stocktakes) == 0 {
+		return nil, nil
+	}
+
+	return stocktakes[0], nil
+}
+
+// Create opens a stocktake and snapshots the warehouse stock and cost of
+// every active item in its scope; the location scope matches the items' bins
+// in that warehouse. The items are locked while the snapshot is taken so it
+// matches the ledger at one point in time. An item can only be on one open
+// stocktake per warehouse at a time, otherwise its corrections would be
+// applied twice.
+func (r *PostgresStocktakeRepository) Create(ctx context.Context, stocktake *models.Stocktake) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	stocktake.WarehouseID, err = stock.ResolveWarehouse(ctx, tx, stocktake.WarehouseID)
+	if err != nil {
+		if errors.Is(err, stockerrors.ErrWarehouseNotFound) {
+			return 0, stocktakeerrors.ErrWarehouseNotFound
+		}
+		return 0, err
+	}
+
+	conditions := []string{"i.is_active = true"}
+	params := []interface{}{stocktake.WarehouseID}
+	paramCount := 2
+
+	if stocktake.CategoryID != nil {
+		conditions = append(conditions, fmt.Sprintf("i.category_id = $%d", paramCount))
+		params = append(params, *stocktake.CategoryID)
+		paramCount++
+	}
+
+	if stocktake.SupplierID != nil {
+		conditions = append(conditions, fmt.Sprintf("i.supplier_id = $%d", paramCount))
+		params = append(params, *stocktake.SupplierID)
+		paramCount++
+	}
+
+	if stocktake.LocationAisle != nil {
+		conditions = append(conditions, fmt.Sprintf("ws.location_aisle = $%d", paramCount))
+		params = append(params, *stocktake.LocationAisle)
+		paramCount++
+	}
+
+	if stocktake.LocationShelf != nil {
+		conditions = append(conditions, fmt.Sprintf("ws.location_shelf = $%d", paramCount))
+		params = append(params, *stocktake.LocationShelf)
+		paramCount++
+	}
+
+	if stocktake.LocationBin != nil {
+		conditions = append(conditions, fmt.Sprintf("ws.location_bin = $%d", paramCount))
+		params = append(params, *stocktake.LocationBin)
+		paramCount++
+	}
+
+	query := `
+        SELECT i.item_id, COALESCE(ws.quantity, 0), COALESCE(NULLIF(i.average_cost, 0), i.buy_price)
+        FROM items i
+        LEFT JOIN item_stock ws ON ws.item_id = i.item_id AND ws.warehouse_id = $1
+        WHERE ` + strings.Join(conditions, " AND ") + `
+        ORDER BY i.item_id
+        FOR UPDATE OF i
+    `
+
+	rows, err := tx.Query(ctx, query, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	var lines []*models.StocktakeLine
+	itemIDs := []int{}
+	for rows.Next() {
+		line := &models.StocktakeLine{}
+		if err := rows.Scan(&line.ItemID, &line.ExpectedQuantity, &line.UnitCost); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		lines = append(lines, line)
+		itemIDs = append(itemIDs, line.ItemID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(lines) == 0 {
+		return 0, stocktakeerrors.ErrNoItemsInScope
+	}
+
+	var alreadyCounted bool
+	err = tx.QueryRow(ctx, `
+        SELECT EXISTS (
+            SELECT 1 FROM stocktake_lines l
+            JOIN stocktakes st ON l.stocktake_id = st.stocktake_id
+            WHERE st.status = 'open' AND st.warehouse_id = $1 AND l.item_id = ANY($2)
+        )
+    `, stocktake.WarehouseID, itemIDs).Scan(&alreadyCounted)
+	if err != nil {
+		return 0, err
+	}
+	if alreadyCounted {
+		return 0, stocktakeerrors.ErrItemsAlreadyCounted
+	}
+
+	headerQuery := `
+        INSERT INTO stocktakes (
+            stocktake_number, status, category_id, supplier_id,
+            location_aisle, location_shelf, location_bin,
+            started_at, started_by, notes, warehouse_id
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        RETURNING stocktake_id
+    `
+
+	var id int
+	err = tx.QueryRow(
+		ctx, headerQuery,
+		stocktake.StocktakeNumber,
+		stocktake.Status,
+		stocktake.CategoryID,
+		stocktake.SupplierID,
+		stocktake.LocationAisle,
+		stocktake.LocationShelf,
+		stocktake.LocationBin,
+		stocktake.StartedAt,
+		stocktake.StartedBy,
+		stocktake.Notes,
+		stocktake.WarehouseID,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	lineQuery := `
+        INSERT INTO stocktake_lines (
+            stocktake_id, item_id, expected_quantity, unit_cost
+        ) VALUES ($1, $2, $3, $4)
+        RETURNING line_id
+    `
+
+	for _, line := range lines {
+		line.StocktakeID = id
+		err = tx.QueryRow(
+			ctx, lineQuery,
+			line.StocktakeID,
+			line.ItemID,
+			line.ExpectedQuantity,
+			line.UnitCost,
+		).Scan(&line.LineID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// RecordCount sets or adds to the counted quantity of an item on an open
+// stocktake and returns the updated line
+func (r *PostgresStocktakeRepository) RecordCount(ctx context.Context, id int, count *models.StocktakeCount) (*models.StocktakeLine, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := lockOpenStocktake(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	itemID := count.ItemID
+	if itemID == 0 {
+		err = tx.QueryRow(ctx, `SELECT item_id FROM items WHERE barcode = $1`, *count.Barcode).Scan(&itemID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, stocktakeerrors.ErrItemNotFound
+			}
+			return nil, err
+		}
+	}
+
+	query := `
+        UPDATE stocktake_lines SET
+            counted_quantity = CASE WHEN $3 THEN COALESCE(counted_quantity, 0) + $4 ELSE $4 END,
+            counted_at = CURRENT_TIMESTAMP,
+            counted_by = COALESCE($5, counted_by)
+        WHERE stocktake_id = $1 AND item_id = $2
+        RETURNING line_id
+    `
+
+	var lineID int
+	err = tx.QueryRow(ctx, query, id, itemID, count.Add, *count.Quantity, count.CountedBy).Scan(&lineID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, stocktakeerrors.ErrItemNotInStocktake
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	lines, err := r.getLines(ctx, "l.line_id = $1", lineID)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, stocktakeerrors.ErrItemNotInStocktake
+	}
+
+	return lines[0], nil
+}
+
+// Approve closes an open stocktake and posts a stocktake movement for every
+// counted item whose count differs from its snapshot. The movement is the
+// variance, not a reset to the counted quantity, so sales and receipts
+// posted while the count was in progress are kept.
+func (r *PostgresStocktakeRepository) Approve(ctx context.Context, id int, approval *models.StocktakeApproval) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	stocktakeNumber, err := lockOpenStocktake(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	var warehouseID int
+	err = tx.QueryRow(ctx,
+		`SELECT warehouse_id FROM stocktakes WHERE stocktake_id = $1`, id,
+	).Scan(&warehouseID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `
+        SELECT line_id, item_id, counted_quantity - expected_quantity
+        FROM stocktake_lines
+        WHERE stocktake_id = $1
+            AND counted_quantity IS NOT NULL
+            AND counted_quantity <> expected_quantity
+        ORDER BY item_id
+    `, id)
+	if err != nil {
+		return err
+	}
+
+	notes := fmt.Sprintf("Stocktake %s: %s", stocktakeNumber, approval.Reason)
+	referenceType := stockModels.ReferenceTypeStocktake
+	var movements []*stockModels.StockMovement
+	for rows.Next() {
+		var lineID, itemID, variance int
+		if err := rows.Scan(&lineID, &itemID, &variance); err != nil {
+			rows.Close()
+			return err
+		}
+		movements = append(movements, &stockModels.StockMovement{
+			ItemID:          itemID,
+			WarehouseID:     warehouseID,
+			MovementType:    stockModels.MovementTypeStocktake,
+			Quantity:        variance,
+			ReferenceType:   &referenceType,
+			ReferenceID:     &id,
+			ReferenceLineID: &lineID,
+			PerformedBy:     approval.ApprovedBy,
+			Notes:           &notes,
+		})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := stock.PostMovements(ctx, tx, movements); err != nil {
+		return err
+	}
+
+	query := `
+        UPDATE stocktakes SET
+            status = $2,
+            approved_at = CURRENT_TIMESTAMP,
+            approved_by = $3,
+            reason = $4,
+            notes = COALESCE($5, notes)
+        WHERE stocktake_id = $1
+    `
+	_, err = tx.Exec(ctx, query,
+		id,
+		models.StocktakeApproved,
+		approval.ApprovedBy,
+		approval.Reason,
+		approval.Notes,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Cancel closes an open stocktake without touching stock
+func (r *PostgresStocktakeRepository) Cancel(ctx context.Context, id int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := lockOpenStocktake(ctx, tx, id); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE stocktakes SET status = $2, cancelled_at = CURRENT_TIMESTAMP WHERE stocktake_id = $1`,
+		id, models.StocktakeCancelled,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// getStocktakes loads the stocktake headers matching the condition and their
+// lines
+func (r *PostgresStocktakeRepository) getStocktakes(ctx context.Context, condition string, params ...interface{}) ([]*models.Stocktake, error) {
+	query := `
+        SELECT
+            st.stocktake_id, st.stocktake_number, st.status,
+            st.warehouse_id, w.code as warehouse_code, st.category_id, st.supplier_id,
+            st.location_aisle, st.location_shelf, st.location_bin,
+            st.started_at, st.started_by, st.approved_at, st.approved_by,
+            st.cancelled_at, st.reason, st.notes, st.created_at, st.updated_at,
+            c.category_name, s.name as supplier_name
+        FROM stocktakes st
+        JOIN warehouses w ON st.warehouse_id = w.warehouse_id
+        LEFT JOIN categories c ON st.category_id = c.category_id
+        LEFT JOIN suppliers s ON st.supplier_id = s.supplier_id
+        WHERE ` + condition + `
+        ORDER BY st.started_at DESC, st.stocktake_id DESC
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stocktakes := []*models.Stocktake{}
+	stocktakesByID := make(map[int]*models.Stocktake)
+	stocktakeIDs := []int{}
+	for rows.Next() {
+		st := &models.Stocktake{Lines: []*models.StocktakeLine{}}
+		err := rows.Scan(
+			&st.StocktakeID,
+			&st.StocktakeNumber,
+			&st.Status,
+			&st.WarehouseID,
+			&st.WarehouseCode,
+			&st.CategoryID,
+			&st.SupplierID,
+			&st.LocationAisle,
+			&st.LocationShelf,
+			&st.LocationBin,
+			&st.StartedAt,
+			&st.StartedBy,
+			&st.ApprovedAt,
+			&st.ApprovedBy,
+			&st.CancelledAt,
+			&st.Reason,
+			&st.Notes,
+			&st.CreatedAt,
+			&st.UpdatedAt,
+			&st.CategoryName,
+			&st.SupplierName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		stocktakes = append(stocktakes, st)
+		stocktakesByID[st.StocktakeID] = st
+		stocktakeIDs = append(stocktakeIDs, st.StocktakeID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(stocktakeIDs) == 0 {
+		return stocktakes, nil
+	}
+
+	lines, err := r.getLines(ctx, "l.stocktake_id = ANY($1)", stocktakeIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		if st, ok := stocktakesByID[line.StocktakeID]; ok {
+			st.Lines = append(st.Lines, line)
+			st.ItemCount++
+			if line.CountedQuantity != nil {
+				st.CountedCount++
+			}
+		}
+	}
+
+	return stocktakes, nil
+}
+
+func (r *PostgresStocktakeRepository) getLines(ctx context.Context, condition string, params ...interface{}) ([]*models.StocktakeLine, error) {
+	query := `
+        SELECT
+            l.line_id, l.stocktake_id, l.item_id, l.expected_quantity,
+            l.counted_quantity, l.unit_cost, l.counted_at, l.counted_by,
+            i.part_number as item_part_number,
+            i.description as item_description,
+            i.barcode
+        FROM stocktake_lines l
+        JOIN stocktakes st ON l.stocktake_id = st.stocktake_id
+        JOIN items i ON l.item_id = i.item_id
+        LEFT JOIN item_stock ws ON ws.item_id = l.item_id AND ws.warehouse_id = st.warehouse_id
+        WHERE ` + condition + `
+        ORDER BY l.stocktake_id, ws.location_aisle, ws.location_shelf, ws.location_bin, i.part_number
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []*models.StocktakeLine{}
+	for rows.Next() {
+		line := &models.StocktakeLine{}
+		err := rows.Scan(
+			&line.LineID,
+			&line.StocktakeID,
+			&line.ItemID,
+			&line.ExpectedQuantity,
+			&line.CountedQuantity,
+			&line.UnitCost,
+			&line.CountedAt,
+			&line.CountedBy,
+			&line.ItemPartNumber,
+			&line.ItemDescription,
+			&line.Barcode,
+		)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+// lockOpenStocktake locks a stocktake for the rest of the transaction,
+// checks it can still be counted and returns its number
+func lockOpenStocktake(ctx context.Context, tx pgx.Tx, id int) (string, error) {
+	var number, status string
+	err := tx.QueryRow(ctx,
+		`SELECT stocktake_number, status FROM stocktakes WHERE stocktake_id = $1 FOR UPDATE`,
+		id,
+	).Scan(&number, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", stocktakeerrors.ErrStocktakeNotFound
+		}
+		return "", err
+	}
+	if status != models.StocktakeOpen {
+		return "", stocktakeerrors.ErrStocktakeNotOpen
+	}
+
+	return number, nil
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stocktakes/models"
+)
+
+type StocktakeRepository interface {
+	GetAll(ctx context.Context, filter *models.StocktakeFilter) ([]*models.Stocktake, error)
+	GetByID(ctx context.Context, id int) (*models.Stocktake, error)
+	Create(ctx context.Context, stocktake *models.Stocktake) (int, error)
+	RecordCount(ctx context.Context, id int, count *models.StocktakeCount) (*models.StocktakeLine, error)
+	Approve(ctx context.Context, id int, approval *models.StocktakeApproval) error
+	Cancel(ctx context.Context, id int) error
+}
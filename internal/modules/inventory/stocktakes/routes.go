@@ -0,0 +1,26 @@
+package stocktakes
+
+import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stocktakes/handlers"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stocktakes/repositories"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stocktakes/services"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(e *echo.Echo, api *echo.Group, database *db.Database) {
+	repo := repositories.NewPostgresStocktakeRepository(database)
+	service := services.NewStocktakeService(repo)
+	handler := handlers.NewStocktakeHandler(service)
+
+	stocktakes := api.Group("/stocktakes", authMiddleware.Guard(authModels.PermInventoryRead, authModels.PermInventoryWrite))
+	stocktakes.GET("", handler.GetStocktakes)
+	stocktakes.GET("/:id", handler.GetStocktakeByID)
+	stocktakes.POST("", handler.CreateStocktake)
+	stocktakes.POST("/:id/counts", handler.RecordCount)
+	stocktakes.GET("/:id/variance", handler.GetVariance)
+	stocktakes.POST("/:id/approve", handler.ApproveStocktake)
+	stocktakes.POST("/:id/cancel", handler.CancelStocktake)
+}
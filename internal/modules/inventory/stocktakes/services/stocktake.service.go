@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	stocktakeerrors "github.com/hsrvms/fixparts/internal/modules/inventory/stocktakes/errors"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stocktakes/models"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/stocktakes/repositories"
+)
+
+type stocktakeService struct {
+	repo repositories.StocktakeRepository
+}
+
+func NewStocktakeService(repo repositories.StocktakeRepository) StocktakeService {
+	return &stocktakeService{
+		repo: repo,
+	}
+}
+
+func (s *stocktakeService) GetAll(ctx context.Context, filter *models.StocktakeFilter) ([]*models.Stocktake, error) {
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *stocktakeService) GetByID(ctx context.Context, id int) (*models.Stocktake, error) {
+	if id <= 0 {
+		return nil, stocktakeerrors.ErrInvalidStocktakeID
+	}
+
+	stocktake, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if stocktake == nil {
+		return nil, stocktakeerrors.ErrStocktakeNotFound
+	}
+
+	return stocktake, nil
+}
+
+func (s *stocktakeService) Create(ctx context.Context, stocktake *models.Stocktake) (int, error) {
+	// Blank location parts do not narrow the scope
+	stocktake.LocationAisle = trimmed(stocktake.LocationAisle)
+	stocktake.LocationShelf = trimmed(stocktake.LocationShelf)
+	stocktake.LocationBin = trimmed(stocktake.LocationBin)
+
+	if !stocktake.HasScope() {
+		return 0, stocktakeerrors.ErrScopeRequired
+	}
+
+	number, err := generateDocumentNumber("ST")
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate stocktake number: %w", err)
+	}
+	stocktake.StocktakeNumber = number
+	stocktake.Status = models.StocktakeOpen
+	stocktake.StartedAt = time.Now()
+
+	return s.repo.Create(ctx, stocktake)
+}
+
+// RecordCount records a count for one item. A scanned barcode without a
+// quantity counts one unit on top of the count so far.
+func (s *stocktakeService) RecordCount(ctx context.Context, id int, count *models.StocktakeCount) (*models.StocktakeLine, error) {
+	if id <= 0 {
+		return nil, stocktakeerrors.ErrInvalidStocktakeID
+	}
+
+	count.Barcode = trimmed(count.Barcode)
+	if count.ItemID <= 0 && count.Barcode == nil {
+		return nil, stocktakeerrors.ErrItemRequired
+	}
+
+	if count.Quantity == nil {
+		if count.Barcode == nil {
+			return nil, stocktakeerrors.ErrInvalidQuantity
+		}
+		one := 1
+		count.Quantity = &one
+		count.Add = true
+	}
+	if *count.Quantity < 0 {
+		return nil, stocktakeerrors.ErrInvalidQuantity
+	}
+
+	return s.repo.RecordCount(ctx, id, count)
+}
+
+// GetVariance compares the counted quantities with the snapshot. Items not
+// counted yet are listed apart and are left unchanged on approval.
+func (s *stocktakeService) GetVariance(ctx context.Context, id int) (*models.VarianceReport, error) {
+	stocktake, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.VarianceReport{
+		StocktakeID:      stocktake.StocktakeID,
+		StocktakeNumber:  stocktake.StocktakeNumber,
+		Status:           stocktake.Status,
+		ItemCount:        stocktake.ItemCount,
+		CountedCount:     stocktake.CountedCount,
+		UncountedCount:   stocktake.ItemCount - stocktake.CountedCount,
+		Lines:            []*models.VarianceLine{},
+		UncountedItemIDs: []int{},
+	}
+
+	for _, line := range stocktake.Lines {
+		if line.CountedQuantity == nil {
+			report.UncountedItemIDs = append(report.UncountedItemIDs, line.ItemID)
+			continue
+		}
+
+		variance := *line.CountedQuantity - line.ExpectedQuantity
+		if variance == 0 {
+			continue
+		}
+
+		value := roundCurrency(float64(variance) * line.UnitCost)
+		report.Lines = append(report.Lines, &models.VarianceLine{
+			ItemID:           line.ItemID,
+			PartNumber:       line.ItemPartNumber,
+			Description:      line.ItemDescription,
+			ExpectedQuantity: line.ExpectedQuantity,
+			CountedQuantity:  *line.CountedQuantity,
+			Variance:         variance,
+			UnitCost:         line.UnitCost,
+			ValueImpact:      value,
+		})
+
+		if value > 0 {
+			report.GainValue += value
+		} else {
+			report.LossValue -= value
+		}
+	}
+
+	report.VarianceCount = len(report.Lines)
+	report.GainValue = roundCurrency(report.GainValue)
+	report.LossValue = roundCurrency(report.LossValue)
+	report.NetValueImpact = roundCurrency(report.GainValue - report.LossValue)
+
+	return report, nil
+}
+
+// Approve applies the variances of an open stocktake to stock. The reason is
+// recorded on the stocktake and on every correcting movement.
+func (s *stocktakeService) Approve(ctx context.Context, id int, approval *models.StocktakeApproval) error {
+	if id <= 0 {
+		return stocktakeerrors.ErrInvalidStocktakeID
+	}
+
+	approval.Reason = strings.TrimSpace(approval.Reason)
+	if approval.Reason == "" {
+		return stocktakeerrors.ErrReasonRequired
+	}
+
+	return s.repo.Approve(ctx, id, approval)
+}
+
+func (s *stocktakeService) Cancel(ctx context.Context, id int) error {
+	if id <= 0 {
+		return stocktakeerrors.ErrInvalidStocktakeID
+	}
+
+	return s.repo.Cancel(ctx, id)
+}
+
+// trimmed returns nil for a missing or blank value
+func trimmed(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*value)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func generateDocumentNumber(prefix string) (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(10000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%s-%04d", prefix, time.Now().Format("060102150405"), n.Int64()), nil
+}
@@ -0,0 +1,22 @@
+package transfererrors
+
+import "errors"
+
+var (
+	ErrTransferNotFound       = errors.New("transfer not found")
+	ErrInvalidTransferID      = errors.New("invalid transfer ID")
+	ErrWarehouseRequired      = errors.New("source and destination warehouses are required")
+	ErrSameWarehouse          = errors.New("source and destination warehouses must differ")
+	ErrWarehouseNotFound      = errors.New("warehouse not found")
+	ErrNoTransferLines        = errors.New("transfer must have at least one line")
+	ErrInvalidItemID          = errors.New("invalid item ID")
+	ErrItemNotFound           = errors.New("item not found")
+	ErrInvalidQuantity        = errors.New("quantity must be greater than 0")
+	ErrDuplicateTransferLine  = errors.New("an item can only appear once on a transfer")
+	ErrItemNotOnTransfer      = errors.New("item is not on this transfer")
+	ErrReceiptExceedsShipped  = errors.New("cannot receive more than was shipped")
+	ErrNothingToReceive       = errors.New("nothing is left to receive on this transfer")
+	ErrTransferNotShippable   = errors.New("only requested transfers can be shipped")
+	ErrTransferNotReceivable  = errors.New("only shipped or partially received transfers can be received")
+	ErrTransferNotCancellable = errors.New("only requested transfers can be cancelled")
+)
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	stockerrors "github.com/hsrvms/fixparts/internal/modules/inventory/stock/errors"
+	transfererrors "github.com/hsrvms/fixparts/internal/modules/inventory/transfers/errors"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/transfers/models"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/transfers/services"
+	"github.com/labstack/echo/v4"
+)
+
+type TransferHandler struct {
+	service services.TransferService
+}
+
+func NewTransferHandler(service services.TransferService) *TransferHandler {
+	return &TransferHandler{
+		service: service,
+	}
+}
+
+// GetTransfers handles retrieval of all transfers with optional filtering
+func (h *TransferHandler) GetTransfers(c echo.Context) error {
+	filter := &models.TransferFilter{}
+
+	// Parse query parameters
+	if status := c.QueryParam("status"); status != "" {
+		filter.Status = &status
+	}
+
+	if sourceID := c.QueryParam("source_warehouse_id"); sourceID != "" {
+		id, err := strconv.Atoi(sourceID)
+		if err == nil {
+			filter.SourceWarehouseID = &id
+		}
+	}
+
+	if destinationID := c.QueryParam("destination_warehouse_id"); destinationID != "" {
+		id, err := strconv.Atoi(destinationID)
+		if err == nil {
+			filter.DestinationWarehouseID = &id
+		}
+	}
+
+	if itemID := c.QueryParam("item_id"); itemID != "" {
+		id, err := strconv.Atoi(itemID)
+		if err == nil {
+			filter.ItemID = &id
+		}
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		if date, err := time.Parse(time.RFC3339, startDate); err == nil {
+			filter.StartDate = &date
+		}
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		if date, err := time.Parse(time.RFC3339, endDate); err == nil {
+			filter.EndDate = &date
+		}
+	}
+
+	ctx := c.Request().Context()
+	transfers, err := h.service.GetAll(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, transfers)
+}
+
+// GetTransferByID handles retrieval of a single transfer with its lines
+func (h *TransferHandler) GetTransferByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	ctx := c.Request().Context()
+	transfer, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.JSON(http.StatusOK, transfer)
+}
+
+// CreateTransfer handles requesting stock from another warehouse
+func (h *TransferHandler) CreateTransfer(c echo.Context) error {
+	transfer := new(models.Transfer)
+	if err := c.Bind(transfer); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.Create(ctx, transfer)
+	if err != nil {
+		return transferError(err)
+	}
+
+	created, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// ShipTransfer handles sending a requested transfer on its way
+func (h *TransferHandler) ShipTransfer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	shipment := new(models.TransferShipment)
+	if err := c.Bind(shipment); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Ship(ctx, id, shipment); err != nil {
+		return transferError(err)
+	}
+
+	return h.GetTransferByID(c)
+}
+
+// ReceiveTransfer handles a full or partial delivery at the destination
+func (h *TransferHandler) ReceiveTransfer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	receipt := new(models.TransferReceipt)
+	if err := c.Bind(receipt); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	receipt.ReceivedBy = authMiddleware.Actor(c)
+
+	ctx := c.Request().Context()
+	if err := h.service.Receive(ctx, id, receipt); err != nil {
+		return transferError(err)
+	}
+
+	return h.GetTransferByID(c)
+}
+
+// CancelTransfer handles dropping a transfer that has not been shipped
+func (h *TransferHandler) CancelTransfer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Cancel(ctx, id); err != nil {
+		return transferError(err)
+	}
+
+	return h.GetTransferByID(c)
+}
+
+// transferError maps transfer errors to HTTP errors
+func transferError(err error) error {
+	if errors.Is(err, stockerrors.ErrInsufficientStock) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	switch err {
+	case transfererrors.ErrTransferNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case transfererrors.ErrInvalidTransferID, transfererrors.ErrWarehouseRequired,
+		transfererrors.ErrSameWarehouse, transfererrors.ErrWarehouseNotFound,
+		transfererrors.ErrNoTransferLines, transfererrors.ErrInvalidItemID,
+		transfererrors.ErrItemNotFound, transfererrors.ErrInvalidQuantity,
+		transfererrors.ErrDuplicateTransferLine, transfererrors.ErrItemNotOnTransfer,
+		transfererrors.ErrReceiptExceedsShipped:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case transfererrors.ErrNothingToReceive, transfererrors.ErrTransferNotShippable,
+		transfererrors.ErrTransferNotReceivable, transfererrors.ErrTransferNotCancellable:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
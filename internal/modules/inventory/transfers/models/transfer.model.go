@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// Transfer statuses
+const (
+	TransferRequested         = "requested"
+	TransferShipped           = "shipped"
+	TransferPartiallyReceived = "partially_received"
+	TransferReceived          = "received"
+	TransferCancelled         = "cancelled"
+)
+
+// Transfer moves stock from one warehouse to another. Requesting it does not
+// change stock; shipping takes the lines out of the source and receiving puts
+// what arrived into the destination. Until then the stock is in transit.
+type Transfer struct {
+	TransferID             int        `json:"transfer_id" db:"transfer_id"`
+	TransferNumber         string     `json:"transfer_number" db:"transfer_number"`
+	Status                 string     `json:"status" db:"status"`
+	SourceWarehouseID      int        `json:"source_warehouse_id" db:"source_warehouse_id"`
+	DestinationWarehouseID int        `json:"destination_warehouse_id" db:"destination_warehouse_id"`
+	RequestedAt            time.Time  `json:"requested_at" db:"requested_at"`
+	RequestedBy            *string    `json:"requested_by,omitempty" db:"requested_by"`
+	ShippedAt              *time.Time `json:"shipped_at,omitempty" db:"shipped_at"`
+	ShippedBy              *string    `json:"shipped_by,omitempty" db:"shipped_by"`
+	ReceivedAt             *time.Time `json:"received_at,omitempty" db:"received_at"`
+	ReceivedBy             *string    `json:"received_by,omitempty" db:"received_by"`
+	CancelledAt            *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	Notes                  *string    `json:"notes,omitempty" db:"notes"`
+	CreatedAt              time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at" db:"updated_at"`
+
+	Lines []*TransferLine `json:"lines" db:"-"`
+
+	// Additional fields for API responses
+	SourceWarehouseCode      string `json:"source_warehouse_code,omitempty" db:"source_warehouse_code"`
+	DestinationWarehouseCode string `json:"destination_warehouse_code,omitempty" db:"destination_warehouse_code"`
+}
+
+// TransferLine is the quantity of one item sent on a transfer and how much
+// of it has arrived
+type TransferLine struct {
+	LineID           int       `json:"line_id" db:"line_id"`
+	TransferID       int       `json:"transfer_id" db:"transfer_id"`
+	ItemID           int       `json:"item_id" db:"item_id"`
+	Quantity         int       `json:"quantity" db:"quantity"`
+	QuantityReceived int       `json:"quantity_received" db:"quantity_received"`
+	Notes            *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+
+	// Quantity still to be received
+	QuantityOutstanding int `json:"quantity_outstanding" db:"-"`
+
+	// Additional fields for API responses
+	ItemPartNumber  string `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription string `json:"item_description,omitempty" db:"item_description"`
+}
+
+// TransferShipment records who sent a requested transfer
+type TransferShipment struct {
+	ShippedBy *string `json:"shipped_by,omitempty"`
+}
+
+// TransferReceipt records what arrived at the destination. Without lines
+// everything still outstanding is received.
+type TransferReceipt struct {
+	ReceivedBy *string                `json:"received_by,omitempty"`
+	Lines      []*TransferReceiptLine `json:"lines"`
+}
+
+// TransferReceiptLine is the quantity of an item received in one delivery
+type TransferReceiptLine struct {
+	ItemID   int `json:"item_id"`
+	Quantity int `json:"quantity"`
+}
+
+type TransferFilter struct {
+	Status                 *string    `query:"status"`
+	SourceWarehouseID      *int       `query:"source_warehouse_id"`
+	DestinationWarehouseID *int       `query:"destination_warehouse_id"`
+	ItemID                 *int       `query:"item_id"`
+	StartDate              *time.Time `query:"start_date"`
+	EndDate                *time.Time `query:"end_date"`
+}
@@ -0,0 +1,523 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	stockerrors "github.com/hsrvms/fixparts/internal/modules/inventory/stock/errors"
+	stockModels "github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+	stock "github.com/hsrvms/fixparts/internal/modules/inventory/stock/repositories"
+	transfererrors "github.com/hsrvms/fixparts/internal/modules/inventory/transfers/errors"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/transfers/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresTransferRepository struct {
+	db *db.Database
+}
+
+func NewPostgresTransferRepository(database *db.Database) TransferRepository {
+	return &PostgresTransferRepository{
+		db: database,
+	}
+}
+
+func (r *PostgresTransferRepository) GetAll(ctx context.Context, filter *models.TransferFilter) ([]*models.Transfer, error) {
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.Status != nil {
+			conditions = append(conditions, fmt.Sprintf("t.status = $%d", paramCount))
+			params = append(params, *filter.Status)
+			paramCount++
+		}
+
+		if filter.SourceWarehouseID != nil {
+			conditions = append(conditions, fmt.Sprintf("t.source_warehouse_id = $%d", paramCount))
+			params = append(params, *filter.SourceWarehouseID)
+			paramCount++
+		}
+
+		if filter.DestinationWarehouseID != nil {
+			conditions = append(conditions, fmt.Sprintf("t.destination_warehouse_id = $%d", paramCount))
+			params = append(params, *filter.DestinationWarehouseID)
+			paramCount++
+		}
+
+		if filter.ItemID != nil {
+			conditions = append(conditions, fmt.Sprintf(
+				"EXISTS (SELECT 1 FROM stock_transfer_lines tl WHERE tl.transfer_id = t.transfer_id AND tl.item_id = $%d)", paramCount))
+			params = append(params, *filter.ItemID)
+			paramCount++
+		}
+
+		if filter.StartDate != nil {
+			conditions = append(conditions, fmt.Sprintf("t.requested_at >= $%d", paramCount))
+			params = append(params, *filter.StartDate)
+			paramCount++
+		}
+
+		if filter.EndDate != nil {
+			conditions = append(conditions, fmt.Sprintf("t.requested_at <= $%d", paramCount))
+			params = append(params, *filter.EndDate)
+			paramCount++
+		}
+	}
+
+	where := "1=1"
+	if len(conditions) > 0 {
+		where = strings.Join(conditions, " AND ")
+	}
+
+	return r.getTransfers(ctx, where, params...)
+}
+
+func (r *PostgresTransferRepository) GetByID(ctx context.Context, id int) (*models.Transfer, error) {
+	transfers, err := r.getTransfers(ctx, "t.transfer_id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(transfers) == 0 {
+		return nil, nil
+	}
+
+	return transfers[0], nil
+}
+
+// Create records a requested transfer. Stock is not touched until the
+// transfer is shipped.
+func (r *PostgresTransferRepository) Create(ctx context.Context, transfer *models.Transfer) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, warehouseID := range []int{transfer.SourceWarehouseID, transfer.DestinationWarehouseID} {
+		if _, err := resolveWarehouse(ctx, tx, warehouseID); err != nil {
+			return 0, err
+		}
+	}
+
+	itemIDs := make([]int, 0, len(transfer.Lines))
+	for _, line := range transfer.Lines {
+		itemIDs = append(itemIDs, line.ItemID)
+	}
+	var found int
+	err = tx.QueryRow(ctx,
+		`SELECT COUNT(*) FROM items WHERE item_id = ANY($1)`, itemIDs,
+	).Scan(&found)
+	if err != nil {
+		return 0, err
+	}
+	if found != len(itemIDs) {
+		return 0, transfererrors.ErrItemNotFound
+	}
+
+	query := `
+        INSERT INTO stock_transfers (
+            transfer_number, status, source_warehouse_id,
+            destination_warehouse_id, requested_at, requested_by, notes
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING transfer_id
+    `
+
+	var id int
+	err = tx.QueryRow(
+		ctx, query,
+		transfer.TransferNumber,
+		transfer.Status,
+		transfer.SourceWarehouseID,
+		transfer.DestinationWarehouseID,
+		transfer.RequestedAt,
+		transfer.RequestedBy,
+		transfer.Notes,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	lineQuery := `
+        INSERT INTO stock_transfer_lines (
+            transfer_id, item_id, quantity, notes
+        ) VALUES ($1, $2, $3, $4)
+        RETURNING line_id
+    `
+
+	for _, line := range transfer.Lines {
+		line.TransferID = id
+		err = tx.QueryRow(
+			ctx, lineQuery,
+			line.TransferID,
+			line.ItemID,
+			line.Quantity,
+			line.Notes,
+		).Scan(&line.LineID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Ship takes every line of a requested transfer out of the source warehouse.
+// The stock stays in transit until it is received.
+func (r *PostgresTransferRepository) Ship(ctx context.Context, id int, shipment *models.TransferShipment) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	transfer, err := lockTransfer(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if transfer.Status != models.TransferRequested {
+		return transfererrors.ErrTransferNotShippable
+	}
+
+	lines, err := lockLines(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	notes := fmt.Sprintf("Transfer %s shipped", transfer.TransferNumber)
+	movements := make([]*stockModels.StockMovement, 0, len(lines))
+	for _, line := range lines {
+		movements = append(movements, transferMovement(
+			transfer, line, stockModels.MovementTypeTransferOut,
+			transfer.SourceWarehouseID, -line.Quantity, shipment.ShippedBy, notes))
+	}
+
+	if err := stock.PostMovements(ctx, tx, movements); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE stock_transfers SET
+            status = $2,
+            shipped_at = CURRENT_TIMESTAMP,
+            shipped_by = $3
+        WHERE transfer_id = $1
+    `, id, models.TransferShipped, shipment.ShippedBy)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Receive puts what arrived into the destination warehouse. The transfer is
+// received once every line has arrived in full and partially received until
+// then.
+func (r *PostgresTransferRepository) Receive(ctx context.Context, id int, receipt *models.TransferReceipt) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	transfer, err := lockTransfer(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if transfer.Status != models.TransferShipped && transfer.Status != models.TransferPartiallyReceived {
+		return transfererrors.ErrTransferNotReceivable
+	}
+
+	lines, err := lockLines(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	received := make(map[int]int, len(lines))
+	if len(receipt.Lines) == 0 {
+		for _, line := range lines {
+			received[line.ItemID] = line.Quantity - line.QuantityReceived
+		}
+	} else {
+		linesByItem := make(map[int]*models.TransferLine, len(lines))
+		for _, line := range lines {
+			linesByItem[line.ItemID] = line
+		}
+		for _, receiptLine := range receipt.Lines {
+			line, ok := linesByItem[receiptLine.ItemID]
+			if !ok {
+				return transfererrors.ErrItemNotOnTransfer
+			}
+			if receiptLine.Quantity > line.Quantity-line.QuantityReceived {
+				return transfererrors.ErrReceiptExceedsShipped
+			}
+			received[receiptLine.ItemID] = receiptLine.Quantity
+		}
+	}
+
+	notes := fmt.Sprintf("Transfer %s received", transfer.TransferNumber)
+	var movements []*stockModels.StockMovement
+	outstanding := 0
+	for _, line := range lines {
+		quantity := received[line.ItemID]
+		outstanding += line.Quantity - line.QuantityReceived - quantity
+		if quantity == 0 {
+			continue
+		}
+
+		_, err := tx.Exec(ctx,
+			`UPDATE stock_transfer_lines SET quantity_received = quantity_received + $2 WHERE line_id = $1`,
+			line.LineID, quantity,
+		)
+		if err != nil {
+			return err
+		}
+
+		movements = append(movements, transferMovement(
+			transfer, line, stockModels.MovementTypeTransferIn,
+			transfer.DestinationWarehouseID, quantity, receipt.ReceivedBy, notes))
+	}
+
+	if len(movements) == 0 {
+		return transfererrors.ErrNothingToReceive
+	}
+
+	if err := stock.PostMovements(ctx, tx, movements); err != nil {
+		return err
+	}
+
+	status := models.TransferPartiallyReceived
+	if outstanding == 0 {
+		status = models.TransferReceived
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE stock_transfers SET
+            status = $2,
+            received_at = CASE WHEN $2 = 'received' THEN CURRENT_TIMESTAMP ELSE received_at END,
+            received_by = COALESCE($3, received_by)
+        WHERE transfer_id = $1
+    `, id, status, receipt.ReceivedBy)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Cancel drops a transfer that has not been shipped yet
+func (r *PostgresTransferRepository) Cancel(ctx context.Context, id int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	transfer, err := lockTransfer(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if transfer.Status != models.TransferRequested {
+		return transfererrors.ErrTransferNotCancellable
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE stock_transfers SET status = $2, cancelled_at = CURRENT_TIMESTAMP WHERE transfer_id = $1`,
+		id, models.TransferCancelled,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// getTransfers loads the transfer headers matching the condition and their
+// lines
+func (r *PostgresTransferRepository) getTransfers(ctx context.Context, condition string, params ...interface{}) ([]*models.Transfer, error) {
+	query := `
+        SELECT
+            t.transfer_id, t.transfer_number, t.status,
+            t.source_warehouse_id, t.destination_warehouse_id,
+            t.requested_at, t.requested_by, t.shipped_at, t.shipped_by,
+            t.received_at, t.received_by, t.cancelled_at, t.notes,
+            t.created_at, t.updated_at,
+            src.code as source_warehouse_code,
+            dst.code as destination_warehouse_code
+        FROM stock_transfers t
+        JOIN warehouses src ON t.source_warehouse_id = src.warehouse_id
+        JOIN warehouses dst ON t.destination_warehouse_id = dst.warehouse_id
+        WHERE ` + condition + `
+        ORDER BY t.requested_at DESC, t.transfer_id DESC
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := []*models.Transfer{}
+	transfersByID := make(map[int]*models.Transfer)
+	transferIDs := []int{}
+	for rows.Next() {
+		t := &models.Transfer{Lines: []*models.TransferLine{}}
+		err := rows.Scan(
+			&t.TransferID,
+			&t.TransferNumber,
+			&t.Status,
+			&t.SourceWarehouseID,
+			&t.DestinationWarehouseID,
+			&t.RequestedAt,
+			&t.RequestedBy,
+			&t.ShippedAt,
+			&t.ShippedBy,
+			&t.ReceivedAt,
+			&t.ReceivedBy,
+			&t.CancelledAt,
+			&t.Notes,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+			&t.SourceWarehouseCode,
+			&t.DestinationWarehouseCode,
+		)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, t)
+		transfersByID[t.TransferID] = t
+		transferIDs = append(transferIDs, t.TransferID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(transferIDs) == 0 {
+		return transfers, nil
+	}
+
+	lineQuery := `
+        SELECT
+            l.line_id, l.transfer_id, l.item_id, l.quantity,
+            l.quantity_received, l.notes, l.created_at, l.updated_at,
+            i.part_number as item_part_number,
+            i.description as item_description
+        FROM stock_transfer_lines l
+        JOIN items i ON l.item_id = i.item_id
+        WHERE l.transfer_id = ANY($1)
+        ORDER BY l.transfer_id, i.part_number
+    `
+
+	lineRows, err := r.db.Pool.Query(ctx, lineQuery, transferIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer lineRows.Close()
+
+	for lineRows.Next() {
+		line := &models.TransferLine{}
+		err := lineRows.Scan(
+			&line.LineID,
+			&line.TransferID,
+			&line.ItemID,
+			&line.Quantity,
+			&line.QuantityReceived,
+			&line.Notes,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+			&line.ItemPartNumber,
+			&line.ItemDescription,
+		)
+		if err != nil {
+			return nil, err
+		}
+		line.QuantityOutstanding = line.Quantity - line.QuantityReceived
+		if t, ok := transfersByID[line.TransferID]; ok {
+			t.Lines = append(t.Lines, line)
+		}
+	}
+
+	return transfers, lineRows.Err()
+}
+
+// lockTransfer reads the number, status and warehouses of a transfer and
+// locks it for the rest of the transaction
+func lockTransfer(ctx context.Context, tx pgx.Tx, id int) (*models.Transfer, error) {
+	transfer := &models.Transfer{TransferID: id}
+	err := tx.QueryRow(ctx, `
+        SELECT transfer_number, status, source_warehouse_id, destination_warehouse_id
+        FROM stock_transfers WHERE transfer_id = $1 FOR UPDATE
+    `, id).Scan(
+		&transfer.TransferNumber,
+		&transfer.Status,
+		&transfer.SourceWarehouseID,
+		&transfer.DestinationWarehouseID,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, transfererrors.ErrTransferNotFound
+		}
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// lockLines reads the lines of a transfer and locks them for the rest of the
+// transaction
+func lockLines(ctx context.Context, tx pgx.Tx, transferID int) ([]*models.TransferLine, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT line_id, item_id, quantity, quantity_received FROM stock_transfer_lines
+		 WHERE transfer_id = $1 ORDER BY item_id FOR UPDATE`,
+		transferID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []*models.TransferLine
+	for rows.Next() {
+		line := &models.TransferLine{TransferID: transferID}
+		if err := rows.Scan(&line.LineID, &line.ItemID, &line.Quantity, &line.QuantityReceived); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+// transferMovement builds the movement of a transfer line in one of its
+// warehouses
+func transferMovement(transfer *models.Transfer, line *models.TransferLine, movementType string, warehouseID, quantity int, performedBy *string, notes string) *stockModels.StockMovement {
+	referenceType := stockModels.ReferenceTypeTransfer
+	return &stockModels.StockMovement{
+		ItemID:          line.ItemID,
+		WarehouseID:     warehouseID,
+		MovementType:    movementType,
+		Quantity:        quantity,
+		ReferenceType:   &referenceType,
+		ReferenceID:     &transfer.TransferID,
+		ReferenceLineID: &line.LineID,
+		PerformedBy:     performedBy,
+		Notes:           &notes,
+	}
+}
+
+// resolveWarehouse checks that a warehouse exists
+func resolveWarehouse(ctx context.Context, tx pgx.Tx, warehouseID int) (int, error) {
+	id, err := stock.ResolveWarehouse(ctx, tx, warehouseID)
+	if errors.Is(err, stockerrors.ErrWarehouseNotFound) {
+		return 0, transfererrors.ErrWarehouseNotFound
+	}
+	return id, err
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/inventory/transfers/models"
+)
+
+type TransferRepository interface {
+	GetAll(ctx context.Context, filter *models.TransferFilter) ([]*models.Transfer, error)
+	GetByID(ctx context.Context, id int) (*models.Transfer, error)
+	Create(ctx context.Context, transfer *models.Transfer) (int, error)
+	Ship(ctx context.Context, id int, shipment *models.TransferShipment) error
+	Receive(ctx context.Context, id int, receipt *models.TransferReceipt) error
+	Cancel(ctx context.Context, id int) error
+}
@@ -0,0 +1,25 @@
+package transfers
+
+import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/transfers/handlers"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/transfers/repositories"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/transfers/services"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(e *echo.Echo, api *echo.Group, database *db.Database) {
+	repo := repositories.NewPostgresTransferRepository(database)
+	service := services.NewTransferService(repo)
+	handler := handlers.NewTransferHandler(service)
+
+	transfers := api.Group("/transfers", authMiddleware.Guard(authModels.PermInventoryRead, authModels.PermInventoryWrite))
+	transfers.GET("", handler.GetTransfers)
+	transfers.GET("/:id", handler.GetTransferByID)
+	transfers.POST("", handler.CreateTransfer)
+	transfers.POST("/:id/ship", handler.ShipTransfer)
+	transfers.POST("/:id/receive", handler.ReceiveTransfer)
+	transfers.POST("/:id/cancel", handler.CancelTransfer)
+}
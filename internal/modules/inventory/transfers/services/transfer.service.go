@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	transfererrors "github.com/hsrvms/fixparts/internal/modules/inventory/transfers/errors"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/transfers/models"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/transfers/repositories"
+)
+
+type transferService struct {
+	repo repositories.TransferRepository
+}
+
+func NewTransferService(repo repositories.TransferRepository) TransferService {
+	return &transferService{
+		repo: repo,
+	}
+}
+
+func (s *transferService) GetAll(ctx context.Context, filter *models.TransferFilter) ([]*models.Transfer, error) {
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *transferService) GetByID(ctx context.Context, id int) (*models.Transfer, error) {
+	if id <= 0 {
+		return nil, transfererrors.ErrInvalidTransferID
+	}
+
+	transfer, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, transfererrors.ErrTransferNotFound
+	}
+
+	return transfer, nil
+}
+
+func (s *transferService) Create(ctx context.Context, transfer *models.Transfer) (int, error) {
+	if transfer.SourceWarehouseID <= 0 || transfer.DestinationWarehouseID <= 0 {
+		return 0, transfererrors.ErrWarehouseRequired
+	}
+	if transfer.SourceWarehouseID == transfer.DestinationWarehouseID {
+		return 0, transfererrors.ErrSameWarehouse
+	}
+
+	if len(transfer.Lines) == 0 {
+		return 0, transfererrors.ErrNoTransferLines
+	}
+	seen := make(map[int]bool, len(transfer.Lines))
+	for _, line := range transfer.Lines {
+		if line.ItemID <= 0 {
+			return 0, transfererrors.ErrInvalidItemID
+		}
+		if line.Quantity <= 0 {
+			return 0, transfererrors.ErrInvalidQuantity
+		}
+		if seen[line.ItemID] {
+			return 0, transfererrors.ErrDuplicateTransferLine
+		}
+		seen[line.ItemID] = true
+	}
+
+	number, err := generateDocumentNumber("TR")
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate transfer number: %w", err)
+	}
+	transfer.TransferNumber = number
+	transfer.Status = models.TransferRequested
+	transfer.RequestedAt = time.Now()
+
+	return s.repo.Create(ctx, transfer)
+}
+
+func (s *transferService) Ship(ctx context.Context, id int, shipment *models.TransferShipment) error {
+	if id <= 0 {
+		return transfererrors.ErrInvalidTransferID
+	}
+
+	return s.repo.Ship(ctx, id, shipment)
+}
+
+// Receive records a delivery at the destination. Each item may appear once
+// per delivery; a transfer can be received over several deliveries.
+func (s *transferService) Receive(ctx context.Context, id int, receipt *models.TransferReceipt) error {
+	if id <= 0 {
+		return transfererrors.ErrInvalidTransferID
+	}
+
+	seen := make(map[int]bool, len(receipt.Lines))
+	for _, line := range receipt.Lines {
+		if line.ItemID <= 0 {
+			return transfererrors.ErrInvalidItemID
+		}
+		if line.Quantity <= 0 {
+			return transfererrors.ErrInvalidQuantity
+		}
+		if seen[line.ItemID] {
+			return transfererrors.ErrDuplicateTransferLine
+		}
+		seen[line.ItemID] = true
+	}
+
+	return s.repo.Receive(ctx, id, receipt)
+}
+
+func (s *transferService) Cancel(ctx context.Context, id int) error {
+	if id <= 0 {
+		return transfererrors.ErrInvalidTransferID
+	}
+
+	return s.repo.Cancel(ctx, id)
+}
+
+func generateDocumentNumber(prefix string) (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(10000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%s-%04d", prefix, time.Now().Format("060102150405"), n.Int64()), nil
+}
@@ -0,0 +1,16 @@
+package warehouseerrors
+
+import "errors"
+
+var (
+	ErrWarehouseNotFound     = errors.New("warehouse not found")
+	ErrInvalidWarehouseID    = errors.New("invalid warehouse ID")
+	ErrInvalidItemID         = errors.New("invalid item ID")
+	ErrItemNotFound          = errors.New("item not found")
+	ErrCodeRequired          = errors.New("warehouse code is required")
+	ErrNameRequired          = errors.New("warehouse name is required")
+	ErrDuplicateCode         = errors.New("warehouse code already exists")
+	ErrDefaultNotDeletable   = errors.New("the default warehouse cannot be deleted")
+	ErrDefaultNotDeactivated = errors.New("the default warehouse cannot be deactivated")
+	ErrWarehouseInUse        = errors.New("warehouse holds stock or has documents")
+)
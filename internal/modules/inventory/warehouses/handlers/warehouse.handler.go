@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	warehouseerrors "github.com/hsrvms/fixparts/internal/modules/inventory/warehouses/errors"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/warehouses/models"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/warehouses/services"
+	"github.com/labstack/echo/v4"
+)
+
+type WarehouseHandler struct {
+	service services.WarehouseService
+}
+
+func NewWarehouseHandler(service services.WarehouseService) *WarehouseHandler {
+	return &WarehouseHandler{
+		service: service,
+	}
+}
+
+// GetWarehouses handles retrieval of all warehouses
+func (h *WarehouseHandler) GetWarehouses(c echo.Context) error {
+	filter := &models.WarehouseFilter{}
+
+	if isActive := c.QueryParam("is_active"); isActive != "" {
+		active := isActive == "true"
+		filter.IsActive = &active
+	}
+
+	ctx := c.Request().Context()
+	warehouses, err := h.service.GetAll(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, warehouses)
+}
+
+// GetWarehouseByID handles retrieval of a single warehouse
+func (h *WarehouseHandler) GetWarehouseByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	ctx := c.Request().Context()
+	warehouse, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	return c.JSON(http.StatusOK, warehouse)
+}
+
+// CreateWarehouse handles creation of a new warehouse
+func (h *WarehouseHandler) CreateWarehouse(c echo.Context) error {
+	warehouse := new(models.Warehouse)
+	if err := c.Bind(warehouse); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.Create(ctx, warehouse)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	created, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateWarehouse handles updating an existing warehouse
+func (h *WarehouseHandler) UpdateWarehouse(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	warehouse := new(models.Warehouse)
+	if err := c.Bind(warehouse); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	warehouse.WarehouseID = id
+
+	ctx := c.Request().Context()
+	if err := h.service.Update(ctx, warehouse); err != nil {
+		return warehouseError(err)
+	}
+
+	updated, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteWarehouse handles deletion of a warehouse that was never used
+func (h *WarehouseHandler) DeleteWarehouse(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Delete(ctx, id); err != nil {
+		return warehouseError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetWarehouseStock handles listing the items held in a warehouse
+func (h *WarehouseHandler) GetWarehouseStock(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	ctx := c.Request().Context()
+	stock, err := h.service.GetStock(ctx, id)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	return c.JSON(http.StatusOK, stock)
+}
+
+// SetItemLocation handles setting the bin location of an item in a warehouse
+func (h *WarehouseHandler) SetItemLocation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	location := new(models.BinLocation)
+	if err := c.Bind(location); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	stock, err := h.service.SetLocation(ctx, id, itemID, location)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	return c.JSON(http.StatusOK, stock)
+}
+
+// warehouseError maps warehouse errors to HTTP errors
+func warehouseError(err error) error {
+	switch err {
+	case warehouseerrors.ErrWarehouseNotFound, warehouseerrors.ErrItemNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case warehouseerrors.ErrInvalidWarehouseID, warehouseerrors.ErrInvalidItemID,
+		warehouseerrors.ErrCodeRequired, warehouseerrors.ErrNameRequired:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case warehouseerrors.ErrDuplicateCode, warehouseerrors.ErrDefaultNotDeletable,
+		warehouseerrors.ErrDefaultNotDeactivated, warehouseerrors.ErrWarehouseInUse:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
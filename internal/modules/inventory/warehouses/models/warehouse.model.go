@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Warehouse is a branch or storeroom holding stock. Exactly one warehouse is
+// the default, used by documents that do not name one.
+type Warehouse struct {
+	WarehouseID int       `json:"warehouse_id" db:"warehouse_id"`
+	Code        string    `json:"code" db:"code"`
+	Name        string    `json:"name" db:"name"`
+	Address     *string   `json:"address,omitempty" db:"address"`
+	Phone       *string   `json:"phone,omitempty" db:"phone"`
+	IsDefault   bool      `json:"is_default" db:"is_default"`
+	IsActive    bool      `json:"is_active" db:"is_active"`
+	Notes       *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	ItemCount  int `json:"item_count" db:"item_count"`
+	TotalUnits int `json:"total_units" db:"total_units"`
+}
+
+// WarehouseStock is the quantity and bin location of an item in a warehouse
+type WarehouseStock struct {
+	ItemID        int       `json:"item_id" db:"item_id"`
+	WarehouseID   int       `json:"warehouse_id" db:"warehouse_id"`
+	Quantity      int       `json:"quantity" db:"quantity"`
+	LocationAisle *string   `json:"location_aisle,omitempty" db:"location_aisle"`
+	LocationShelf *string   `json:"location_shelf,omitempty" db:"location_shelf"`
+	LocationBin   *string   `json:"location_bin,omitempty" db:"location_bin"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	ItemPartNumber  string `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription string `json:"item_description,omitempty" db:"item_description"`
+	MinimumStock    int    `json:"minimum_stock" db:"minimum_stock"`
+}
+
+// BinLocation is where an item is kept inside a warehouse
+type BinLocation struct {
+	LocationAisle *string `json:"location_aisle,omitempty"`
+	LocationShelf *string `json:"location_shelf,omitempty"`
+	LocationBin   *string `json:"location_bin,omitempty"`
+}
+
+type WarehouseFilter struct {
+	IsActive *bool `query:"is_active"`
+}
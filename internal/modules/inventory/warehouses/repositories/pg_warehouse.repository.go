@@ -0,0 +1,405 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	warehouseerrors "github.com/hsrvms/fixparts/internal/modules/inventory/warehouses/errors"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/warehouses/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresWarehouseRepository struct {
+	db *db.Database
+}
+
+func NewPostgresWarehouseRepository(database *db.Database) WarehouseRepository {
+	return &PostgresWarehouseRepository{
+		db: database,
+	}
+}
+
+// warehouseSelect returns warehouses with a summary of the stock they hold
+const warehouseSelect = `
+    SELECT
+        w.warehouse_id, w.code, w.name, w.address, w.phone,
+        w.is_default, w.is_active, w.notes, w.created_at, w.updated_at,
+        COUNT(ws.item_id) FILTER (WHERE ws.quantity <> 0) as item_count,
+        COALESCE(SUM(ws.quantity), 0)::INTEGER as total_units
+    FROM warehouses w
+    LEFT JOIN item_stock ws ON ws.warehouse_id = w.warehouse_id
+`
+
+const warehouseGroupBy = " GROUP BY w.warehouse_id"
+
+func (r *PostgresWarehouseRepository) GetAll(ctx context.Context, filter *models.WarehouseFilter) ([]*models.Warehouse, error) {
+	query := warehouseSelect + " WHERE 1=1"
+
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.IsActive != nil {
+			conditions = append(conditions, fmt.Sprintf("w.is_active = $%d", paramCount))
+			params = append(params, *filter.IsActive)
+			paramCount++
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	query += warehouseGroupBy + " ORDER BY w.is_default DESC, w.name"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWarehouses(rows)
+}
+
+func (r *PostgresWarehouseRepository) GetByID(ctx context.Context, id int) (*models.Warehouse, error) {
+	return r.getOne(ctx, "w.warehouse_id = $1", id)
+}
+
+func (r *PostgresWarehouseRepository) GetByCode(ctx context.Context, code string) (*models.Warehouse, error) {
+	return r.getOne(ctx, "UPPER(w.code) = UPPER($1)", code)
+}
+
+// Create inserts a warehouse. A new default warehouse takes over from the
+// previous one.
+func (r *PostgresWarehouseRepository) Create(ctx context.Context, warehouse *models.Warehouse) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if warehouse.IsDefault {
+		if _, err := tx.Exec(ctx, `UPDATE warehouses SET is_default = false WHERE is_default`); err != nil {
+			return 0, err
+		}
+	}
+
+	query := `
+        INSERT INTO warehouses (
+            code, name, address, phone, is_default, is_active, notes
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING warehouse_id
+    `
+
+	var id int
+	err = tx.QueryRow(
+		ctx, query,
+		warehouse.Code,
+		warehouse.Name,
+		warehouse.Address,
+		warehouse.Phone,
+		warehouse.IsDefault,
+		warehouse.IsActive,
+		warehouse.Notes,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Update modifies a warehouse. Making it the default takes the flag away
+// from the previous default; the default cannot be given up otherwise.
+func (r *PostgresWarehouseRepository) Update(ctx context.Context, warehouse *models.Warehouse) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var isDefault bool
+	err = tx.QueryRow(ctx,
+		`SELECT is_default FROM warehouses WHERE warehouse_id = $1 FOR UPDATE`,
+		warehouse.WarehouseID,
+	).Scan(&isDefault)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return warehouseerrors.ErrWarehouseNotFound
+		}
+		return err
+	}
+
+	if isDefault {
+		warehouse.IsDefault = true
+		if !warehouse.IsActive {
+			return warehouseerrors.ErrDefaultNotDeactivated
+		}
+	} else if warehouse.IsDefault {
+		if _, err := tx.Exec(ctx, `UPDATE warehouses SET is_default = false WHERE is_default`); err != nil {
+			return err
+		}
+	}
+
+	query := `
+        UPDATE warehouses SET
+            code = $2,
+            name = $3,
+            address = $4,
+            phone = $5,
+            is_default = $6,
+            is_active = $7,
+            notes = $8
+        WHERE warehouse_id = $1
+    `
+
+	_, err = tx.Exec(
+		ctx, query,
+		warehouse.WarehouseID,
+		warehouse.Code,
+		warehouse.Name,
+		warehouse.Address,
+		warehouse.Phone,
+		warehouse.IsDefault,
+		warehouse.IsActive,
+		warehouse.Notes,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Delete removes a warehouse that never held stock. Warehouses with history
+// are deactivated instead.
+func (r *PostgresWarehouseRepository) Delete(ctx context.Context, id int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var isDefault bool
+	err = tx.QueryRow(ctx,
+		`SELECT is_default FROM warehouses WHERE warehouse_id = $1 FOR UPDATE`,
+		id,
+	).Scan(&isDefault)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return warehouseerrors.ErrWarehouseNotFound
+		}
+		return err
+	}
+	if isDefault {
+		return warehouseerrors.ErrDefaultNotDeletable
+	}
+
+	var inUse bool
+	err = tx.QueryRow(ctx, `
+        SELECT EXISTS (SELECT 1 FROM stock_movements WHERE warehouse_id = $1)
+            OR EXISTS (SELECT 1 FROM sales WHERE warehouse_id = $1)
+            OR EXISTS (SELECT 1 FROM purchases WHERE warehouse_id = $1)
+            OR EXISTS (SELECT 1 FROM goods_receipts WHERE warehouse_id = $1)
+            OR EXISTS (SELECT 1 FROM stocktakes WHERE warehouse_id = $1)
+            OR EXISTS (SELECT 1 FROM stock_transfers
+                       WHERE source_warehouse_id = $1 OR destination_warehouse_id = $1)
+    `, id).Scan(&inUse)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return warehouseerrors.ErrWarehouseInUse
+	}
+
+	// Only bin locations can be left, as nothing was ever moved here
+	if _, err := tx.Exec(ctx, `DELETE FROM item_stock WHERE warehouse_id = $1`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM warehouses WHERE warehouse_id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetStock lists the items held in a warehouse or given a bin there
+func (r *PostgresWarehouseRepository) GetStock(ctx context.Context, warehouseID int) ([]*models.WarehouseStock, error) {
+	query := `
+        SELECT
+            ws.item_id, ws.warehouse_id, ws.quantity,
+            ws.location_aisle, ws.location_shelf, ws.location_bin, ws.updated_at,
+            i.part_number as item_part_number,
+            i.description as item_description,
+            i.minimum_stock
+        FROM item_stock ws
+        JOIN items i ON ws.item_id = i.item_id
+        WHERE ws.warehouse_id = $1
+        ORDER BY ws.location_aisle, ws.location_shelf, ws.location_bin, i.part_number
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stock := []*models.WarehouseStock{}
+	for rows.Next() {
+		ws := &models.WarehouseStock{}
+		if err := scanWarehouseStock(rows, ws); err != nil {
+			return nil, err
+		}
+		stock = append(stock, ws)
+	}
+
+	return stock, rows.Err()
+}
+
+// SetLocation records where an item is kept in a warehouse. The location in
+// the default warehouse is also the one shown on the item.
+func (r *PostgresWarehouseRepository) SetLocation(ctx context.Context, warehouseID, itemID int, location *models.BinLocation) (*models.WarehouseStock, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var isDefault bool
+	err = tx.QueryRow(ctx,
+		`SELECT is_default FROM warehouses WHERE warehouse_id = $1`,
+		warehouseID,
+	).Scan(&isDefault)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, warehouseerrors.ErrWarehouseNotFound
+		}
+		return nil, err
+	}
+
+	var exists bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM items WHERE item_id = $1)`, itemID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, warehouseerrors.ErrItemNotFound
+	}
+
+	query := `
+        INSERT INTO item_stock (
+            item_id, warehouse_id, location_aisle, location_shelf, location_bin
+        ) VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (item_id, warehouse_id) DO UPDATE SET
+            location_aisle = EXCLUDED.location_aisle,
+            location_shelf = EXCLUDED.location_shelf,
+            location_bin = EXCLUDED.location_bin
+    `
+	_, err = tx.Exec(ctx, query,
+		itemID, warehouseID,
+		location.LocationAisle, location.LocationShelf, location.LocationBin,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if isDefault {
+		_, err = tx.Exec(ctx,
+			`UPDATE items SET location_aisle = $2, location_shelf = $3, location_bin = $4 WHERE item_id = $1`,
+			itemID, location.LocationAisle, location.LocationShelf, location.LocationBin,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ws := &models.WarehouseStock{}
+	row := tx.QueryRow(ctx, `
+        SELECT
+            ws.item_id, ws.warehouse_id, ws.quantity,
+            ws.location_aisle, ws.location_shelf, ws.location_bin, ws.updated_at,
+            i.part_number as item_part_number,
+            i.description as item_description,
+            i.minimum_stock
+        FROM item_stock ws
+        JOIN items i ON ws.item_id = i.item_id
+        WHERE ws.item_id = $1 AND ws.warehouse_id = $2
+    `, itemID, warehouseID)
+	if err := scanWarehouseStock(row, ws); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+func (r *PostgresWarehouseRepository) getOne(ctx context.Context, condition string, arg interface{}) (*models.Warehouse, error) {
+	rows, err := r.db.Pool.Query(ctx, warehouseSelect+" WHERE "+condition+warehouseGroupBy, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	warehouses, err := scanWarehouses(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(warehouses) == 0 {
+		return nil, nil
+	}
+
+	return warehouses[0], nil
+}
+
+func scanWarehouses(rows pgx.Rows) ([]*models.Warehouse, error) {
+	warehouses := []*models.Warehouse{}
+	for rows.Next() {
+		warehouse := &models.Warehouse{}
+		err := rows.Scan(
+			&warehouse.WarehouseID,
+			&warehouse.Code,
+			&warehouse.Name,
+			&warehouse.Address,
+			&warehouse.Phone,
+			&warehouse.IsDefault,
+			&warehouse.IsActive,
+			&warehouse.Notes,
+			&warehouse.CreatedAt,
+			&warehouse.UpdatedAt,
+			&warehouse.ItemCount,
+			&warehouse.TotalUnits,
+		)
+		if err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, warehouse)
+	}
+
+	return warehouses, rows.Err()
+}
+
+func scanWarehouseStock(row pgx.Row, ws *models.WarehouseStock) error {
+	return row.Scan(
+		&ws.ItemID,
+		&ws.WarehouseID,
+		&ws.Quantity,
+		&ws.LocationAisle,
+		&ws.LocationShelf,
+		&ws.LocationBin,
+		&ws.UpdatedAt,
+		&ws.ItemPartNumber,
+		&ws.ItemDescription,
+		&ws.MinimumStock,
+	)
+}
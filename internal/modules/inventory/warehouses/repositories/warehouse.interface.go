@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/inventory/warehouses/models"
+)
+
+type WarehouseRepository interface {
+	GetAll(ctx context.Context, filter *models.WarehouseFilter) ([]*models.Warehouse, error)
+	GetByID(ctx context.Context, id int) (*models.Warehouse, error)
+	GetByCode(ctx context.Context, code string) (*models.Warehouse, error)
+	Create(ctx context.Context, warehouse *models.Warehouse) (int, error)
+	Update(ctx context.Context, warehouse *models.Warehouse) error
+	Delete(ctx context.Context, id int) error
+	GetStock(ctx context.Context, warehouseID int) ([]*models.WarehouseStock, error)
+	SetLocation(ctx context.Context, warehouseID, itemID int, location *models.BinLocation) (*models.WarehouseStock, error)
+}
@@ -0,0 +1,26 @@
+package warehouses
+
+import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/warehouses/handlers"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/warehouses/repositories"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/warehouses/services"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(e *echo.Echo, api *echo.Group, database *db.Database) {
+	repo := repositories.NewPostgresWarehouseRepository(database)
+	service := services.NewWarehouseService(repo)
+	handler := handlers.NewWarehouseHandler(service)
+
+	warehouses := api.Group("/warehouses", authMiddleware.Guard(authModels.PermInventoryRead, authModels.PermInventoryWrite))
+	warehouses.GET("", handler.GetWarehouses)
+	warehouses.GET("/:id", handler.GetWarehouseByID)
+	warehouses.POST("", handler.CreateWarehouse)
+	warehouses.PUT("/:id", handler.UpdateWarehouse)
+	warehouses.DELETE("/:id", handler.DeleteWarehouse)
+	warehouses.GET("/:id/stock", handler.GetWarehouseStock)
+	warehouses.PUT("/:id/stock/:itemId", handler.SetItemLocation)
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	warehouseerrors "github.com/hsrvms/fixparts/internal/modules/inventory/warehouses/errors"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/warehouses/models"
+	"github.com/hsrvms/fixparts/internal/modules/inventory/warehouses/repositories"
+)
+
+type warehouseService struct {
+	repo repositories.WarehouseRepository
+}
+
+func NewWarehouseService(repo repositories.WarehouseRepository) WarehouseService {
+	return &warehouseService{
+		repo: repo,
+	}
+}
+
+func (s *warehouseService) GetAll(ctx context.Context, filter *models.WarehouseFilter) ([]*models.Warehouse, error) {
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *warehouseService) GetByID(ctx context.Context, id int) (*models.Warehouse, error) {
+	if id <= 0 {
+		return nil, warehouseerrors.ErrInvalidWarehouseID
+	}
+
+	warehouse, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if warehouse == nil {
+		return nil, warehouseerrors.ErrWarehouseNotFound
+	}
+
+	return warehouse, nil
+}
+
+func (s *warehouseService) Create(ctx context.Context, warehouse *models.Warehouse) (int, error) {
+	if err := s.validateWarehouse(ctx, warehouse); err != nil {
+		return 0, err
+	}
+
+	// New warehouses are always in use
+	warehouse.IsActive = true
+
+	return s.repo.Create(ctx, warehouse)
+}
+
+func (s *warehouseService) Update(ctx context.Context, warehouse *models.Warehouse) error {
+	if warehouse.WarehouseID <= 0 {
+		return warehouseerrors.ErrInvalidWarehouseID
+	}
+
+	if err := s.validateWarehouse(ctx, warehouse); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, warehouse)
+}
+
+func (s *warehouseService) Delete(ctx context.Context, id int) error {
+	if id <= 0 {
+		return warehouseerrors.ErrInvalidWarehouseID
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *warehouseService) GetStock(ctx context.Context, warehouseID int) ([]*models.WarehouseStock, error) {
+	if _, err := s.GetByID(ctx, warehouseID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetStock(ctx, warehouseID)
+}
+
+func (s *warehouseService) SetLocation(ctx context.Context, warehouseID, itemID int, location *models.BinLocation) (*models.WarehouseStock, error) {
+	if warehouseID <= 0 {
+		return nil, warehouseerrors.ErrInvalidWarehouseID
+	}
+	if itemID <= 0 {
+		return nil, warehouseerrors.ErrInvalidItemID
+	}
+
+	location.LocationAisle = trimmed(location.LocationAisle)
+	location.LocationShelf = trimmed(location.LocationShelf)
+	location.LocationBin = trimmed(location.LocationBin)
+
+	return s.repo.SetLocation(ctx, warehouseID, itemID, location)
+}
+
+// validateWarehouse normalises the code and checks it is unique
+func (s *warehouseService) validateWarehouse(ctx context.Context, warehouse *models.Warehouse) error {
+	warehouse.Code = strings.ToUpper(strings.TrimSpace(warehouse.Code))
+	warehouse.Name = strings.TrimSpace(warehouse.Name)
+
+	if warehouse.Code == "" {
+		return warehouseerrors.ErrCodeRequired
+	}
+	if warehouse.Name == "" {
+		return warehouseerrors.ErrNameRequired
+	}
+
+	existing, err := s.repo.GetByCode(ctx, warehouse.Code)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.WarehouseID != warehouse.WarehouseID {
+		return warehouseerrors.ErrDuplicateCode
+	}
+
+	return nil
+}
+
+// trimmed returns nil for a missing or blank value
+func trimmed(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*value)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
@@ -13,4 +13,39 @@ var (
 	ErrInvalidCostPerUnit     = errors.New("cost per unit must be greater than 0")
 	ErrDuplicateInvoiceNumber = errors.New("invoice number already exists")
 	ErrInvalidDate            = errors.New("purchase date cannot be in the future")
+	ErrInsufficientStock      = errors.New("insufficient stock to reverse purchase")
+	ErrWarehouseNotFound      = errors.New("warehouse not found")
+
+	ErrPurchaseOrderNotFound   = errors.New("purchase order not found")
+	ErrInvalidPurchaseOrderID  = errors.New("invalid purchase order ID")
+	ErrDuplicatePONumber       = errors.New("purchase order number already exists")
+	ErrNoOrderLines            = errors.New("purchase order must have at least one line")
+	ErrDuplicateOrderItem      = errors.New("an item can only appear once on a purchase order")
+	ErrOrderLineNotFound       = errors.New("purchase order line not found")
+	ErrOrderNotEditable        = errors.New("only draft purchase orders can be changed or deleted")
+	ErrInvalidStatusTransition = errors.New("purchase order status change is not allowed")
+	ErrOrderNotReceivable      = errors.New("goods can only be received against sent or partially received purchase orders")
+	ErrNoReceiptLines          = errors.New("goods receipt must have at least one line")
+	ErrReceiptNotFound         = errors.New("goods receipt not found")
+	ErrInvalidReceiptID        = errors.New("invalid goods receipt ID")
+	ErrReceiptPurchaseLocked   = errors.New("purchases posted by a goods receipt cannot change supplier, item, warehouse or quantity, or be deleted")
+	ErrPurchaseHasReturns      = errors.New("purchases with supplier returns cannot be deleted, change supplier, item or warehouse, or drop below the returned quantity")
+
+	ErrSupplierReturnNotFound     = errors.New("supplier return not found")
+	ErrInvalidSupplierReturnID    = errors.New("invalid supplier return ID")
+	ErrNoReturnLines              = errors.New("supplier return must have at least one line")
+	ErrInvalidReturnReason        = errors.New("invalid supplier return reason code")
+	ErrReturnLineNotResolved      = errors.New("each return line needs a purchase, or an item on the given invoice")
+	ErrReturnSupplierMismatch     = errors.New("all returned purchases must be from the supplier of the return")
+	ErrReturnInvoiceMismatch      = errors.New("returned purchase is not on the given invoice")
+	ErrReturnExceedsPurchased     = errors.New("cannot return more than was purchased")
+	ErrDuplicateReturnLine        = errors.New("a purchase can only appear once on a supplier return")
+	ErrReturnNotEditable          = errors.New("only requested supplier returns can be deleted")
+	ErrInvalidReturnTransition    = errors.New("supplier return status change is not allowed")
+	ErrCreditNoteNeeded           = errors.New("credit note number is required")
+	ErrInvalidCreditedAmount      = errors.New("credited amount cannot be negative")
+	ErrInsufficientStockForReturn = errors.New("insufficient stock to ship supplier return")
+
+	ErrInvalidReorderDays = errors.New("reorder sales window must be between 1 and 365 days")
+	ErrNothingToReorder   = errors.New("no items need to be reordered from the selected suppliers")
 )
@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	auditMiddleware "github.com/hsrvms/fixparts/internal/modules/audit/middleware"
+	auditModels "github.com/hsrvms/fixparts/internal/modules/audit/models"
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
 	purchaseErrors "github.com/hsrvms/fixparts/internal/modules/purchases/errors"
 	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
 	"github.com/hsrvms/fixparts/internal/modules/purchases/services"
@@ -56,6 +61,13 @@ func (h *PurchaseHandler) GetPurchases(c echo.Context) error {
 		filter.InvoiceNumber = &invoiceNumber
 	}
 
+	if warehouseID := c.QueryParam("warehouse_id"); warehouseID != "" {
+		id, err := strconv.Atoi(warehouseID)
+		if err == nil {
+			filter.WarehouseID = &id
+		}
+	}
+
 	ctx := c.Request().Context()
 	purchases, err := h.service.GetAll(ctx, filter)
 	if err != nil {
@@ -92,6 +104,7 @@ func (h *PurchaseHandler) CreatePurchase(c echo.Context) error {
 	if err := c.Bind(purchase); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	purchase.ReceivedBy = authMiddleware.Actor(c)
 
 	ctx := c.Request().Context()
 	id, err := h.service.Create(ctx, purchase)
@@ -99,7 +112,7 @@ func (h *PurchaseHandler) CreatePurchase(c echo.Context) error {
 		switch err {
 		case purchaseErrors.ErrInvalidSupplierID, purchaseErrors.ErrInvalidItemID,
 			purchaseErrors.ErrInvalidQuantity, purchaseErrors.ErrInvalidCostPerUnit,
-			purchaseErrors.ErrInvalidDate:
+			purchaseErrors.ErrInvalidDate, purchaseErrors.ErrWarehouseNotFound:
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		case purchaseErrors.ErrDuplicateInvoiceNumber:
 			return echo.NewHTTPError(http.StatusConflict, err.Error())
@@ -109,6 +122,13 @@ func (h *PurchaseHandler) CreatePurchase(c echo.Context) error {
 	}
 
 	purchase.PurchaseID = id
+
+	created, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordCreate(c, auditModels.EntityPurchase, id, created)
+
 	return c.JSON(http.StatusCreated, purchase)
 }
 
@@ -123,25 +143,46 @@ func (h *PurchaseHandler) UpdatePurchase(c echo.Context) error {
 	if err := c.Bind(purchase); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	purchase.ReceivedBy = authMiddleware.Actor(c)
 	purchase.PurchaseID = id
 
 	ctx := c.Request().Context()
+	existing, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		switch err {
+		case purchaseErrors.ErrPurchaseNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
 	err = h.service.Update(ctx, purchase)
 	if err != nil {
+		if errors.Is(err, purchaseErrors.ErrInsufficientStock) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
 		switch err {
 		case purchaseErrors.ErrPurchaseNotFound:
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
 		case purchaseErrors.ErrInvalidSupplierID, purchaseErrors.ErrInvalidItemID,
 			purchaseErrors.ErrInvalidQuantity, purchaseErrors.ErrInvalidCostPerUnit,
-			purchaseErrors.ErrInvalidDate:
+			purchaseErrors.ErrInvalidDate, purchaseErrors.ErrWarehouseNotFound:
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-		case purchaseErrors.ErrDuplicateInvoiceNumber:
+		case purchaseErrors.ErrDuplicateInvoiceNumber, purchaseErrors.ErrReceiptPurchaseLocked,
+			purchaseErrors.ErrPurchaseHasReturns:
 			return echo.NewHTTPError(http.StatusConflict, err.Error())
 		default:
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 	}
 
+	updated, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordUpdate(c, auditModels.EntityPurchase, id, existing, updated)
+
 	return c.JSON(http.StatusOK, purchase)
 }
 
@@ -153,7 +194,7 @@ func (h *PurchaseHandler) DeletePurchase(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
-	err = h.service.Delete(ctx, id)
+	existing, err := h.service.GetByID(ctx, id)
 	if err != nil {
 		switch err {
 		case purchaseErrors.ErrPurchaseNotFound:
@@ -163,9 +204,39 @@ func (h *PurchaseHandler) DeletePurchase(c echo.Context) error {
 		}
 	}
 
+	// A deletion that would be refused must not spend the manager's code
+	if err := h.service.CanDelete(ctx, id); err != nil {
+		return deletePurchaseError(err)
+	}
+
+	if err := authMiddleware.Approve(c, authModels.ActionPurchaseDelete, id, ""); err != nil {
+		return err
+	}
+
+	err = h.service.Delete(ctx, id)
+	if err != nil {
+		return deletePurchaseError(err)
+	}
+	auditMiddleware.RecordDelete(c, auditModels.EntityPurchase, id, existing)
+
 	return c.NoContent(http.StatusNoContent)
 }
 
+// deletePurchaseError maps purchase deletion errors to HTTP errors
+func deletePurchaseError(err error) error {
+	if errors.Is(err, purchaseErrors.ErrInsufficientStock) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	switch err {
+	case purchaseErrors.ErrPurchaseNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case purchaseErrors.ErrReceiptPurchaseLocked, purchaseErrors.ErrPurchaseHasReturns:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
 // GetSupplierPurchases handles retrieval of all purchases for a supplier
 func (h *PurchaseHandler) GetSupplierPurchases(c echo.Context) error {
 	supplierID, err := strconv.Atoi(c.Param("supplierId"))
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	auditMiddleware "github.com/hsrvms/fixparts/internal/modules/audit/middleware"
+	auditModels "github.com/hsrvms/fixparts/internal/modules/audit/models"
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	purchaseErrors "github.com/hsrvms/fixparts/internal/modules/purchases/errors"
+	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
+	"github.com/hsrvms/fixparts/internal/modules/purchases/services"
+	"github.com/labstack/echo/v4"
+)
+
+type PurchaseOrderHandler struct {
+	service         services.PurchaseOrderService
+	purchaseService services.PurchaseService
+}
+
+func NewPurchaseOrderHandler(service services.PurchaseOrderService, purchaseService services.PurchaseService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{
+		service:         service,
+		purchaseService: purchaseService,
+	}
+}
+
+// GetPurchaseOrders handles retrieval of all purchase orders with optional filtering
+func (h *PurchaseOrderHandler) GetPurchaseOrders(c echo.Context) error {
+	filter := &models.PurchaseOrderFilter{}
+
+	// Parse query parameters
+	if supplierID := c.QueryParam("supplier_id"); supplierID != "" {
+		id, err := strconv.Atoi(supplierID)
+		if err == nil {
+			filter.SupplierID = &id
+		}
+	}
+
+	if status := c.QueryParam("status"); status != "" {
+		filter.Status = &status
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		if date, err := time.Parse(time.RFC3339, startDate); err == nil {
+			filter.StartDate = &date
+		}
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		if date, err := time.Parse(time.RFC3339, endDate); err == nil {
+			filter.EndDate = &date
+		}
+	}
+
+	ctx := c.Request().Context()
+	orders, err := h.service.GetAll(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, orders)
+}
+
+// GetPurchaseOrderByID handles retrieval of a purchase order with its lines
+// and the received quantity per line
+func (h *PurchaseOrderHandler) GetPurchaseOrderByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	ctx := c.Request().Context()
+	order, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return orderError(err)
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// CreatePurchaseOrder handles creation of a new draft purchase order
+func (h *PurchaseOrderHandler) CreatePurchaseOrder(c echo.Context) error {
+	order := new(models.PurchaseOrder)
+	if err := c.Bind(order); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.Create(ctx, order)
+	if err != nil {
+		return orderError(err)
+	}
+
+	order.POID = id
+	return c.JSON(http.StatusCreated, order)
+}
+
+// UpdatePurchaseOrder handles updating a draft purchase order
+func (h *PurchaseOrderHandler) UpdatePurchaseOrder(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	order := new(models.PurchaseOrder)
+	if err := c.Bind(order); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	order.POID = id
+
+	ctx := c.Request().Context()
+	if err := h.service.Update(ctx, order); err != nil {
+		return orderError(err)
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// DeletePurchaseOrder handles deletion of a draft purchase order
+func (h *PurchaseOrderHandler) DeletePurchaseOrder(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Delete(ctx, id); err != nil {
+		return orderError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SendPurchaseOrder handles marking a draft purchase order as sent
+func (h *PurchaseOrderHandler) SendPurchaseOrder(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Send(ctx, id); err != nil {
+		return orderError(err)
+	}
+
+	return h.GetPurchaseOrderByID(c)
+}
+
+// CancelPurchaseOrder handles cancellation of a purchase order
+func (h *PurchaseOrderHandler) CancelPurchaseOrder(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Cancel(ctx, id); err != nil {
+		return orderError(err)
+	}
+
+	return h.GetPurchaseOrderByID(c)
+}
+
+// ReceiveGoods handles posting a goods receipt against a purchase order
+func (h *PurchaseOrderHandler) ReceiveGoods(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	receipt := new(models.GoodsReceipt)
+	if err := c.Bind(receipt); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	receipt.POID = id
+	receipt.ReceivedBy = authMiddleware.Actor(c)
+
+	ctx := c.Request().Context()
+	receiptID, err := h.service.ReceiveGoods(ctx, receipt)
+	if err != nil {
+		return orderError(err)
+	}
+
+	created, err := h.service.GetReceiptByID(ctx, receiptID)
+	if err != nil {
+		return orderError(err)
+	}
+
+	// Every received line is a purchase of its own
+	for _, line := range created.Lines {
+		purchase, err := h.purchaseService.GetByID(ctx, line.PurchaseID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		auditMiddleware.RecordCreate(c, auditModels.EntityPurchase, line.PurchaseID, purchase)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// GetPurchaseOrderReceipts handles retrieval of the goods receipts of a purchase order
+func (h *PurchaseOrderHandler) GetPurchaseOrderReceipts(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	ctx := c.Request().Context()
+	receipts, err := h.service.GetReceipts(ctx, id)
+	if err != nil {
+		return orderError(err)
+	}
+
+	return c.JSON(http.StatusOK, receipts)
+}
+
+// GetGoodsReceiptByID handles retrieval of a single goods receipt
+func (h *PurchaseOrderHandler) GetGoodsReceiptByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid goods receipt ID")
+	}
+
+	ctx := c.Request().Context()
+	receipt, err := h.service.GetReceiptByID(ctx, id)
+	if err != nil {
+		return orderError(err)
+	}
+
+	return c.JSON(http.StatusOK, receipt)
+}
+
+// orderError maps purchase order errors to HTTP errors
+func orderError(err error) error {
+	switch err {
+	case purchaseErrors.ErrPurchaseOrderNotFound, purchaseErrors.ErrReceiptNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case purchaseErrors.ErrInvalidPurchaseOrderID, purchaseErrors.ErrInvalidReceiptID,
+		purchaseErrors.ErrInvalidSupplierID, purchaseErrors.ErrInvalidItemID,
+		purchaseErrors.ErrInvalidQuantity, purchaseErrors.ErrInvalidCostPerUnit,
+		purchaseErrors.ErrInvalidDate, purchaseErrors.ErrNoOrderLines,
+		purchaseErrors.ErrDuplicateOrderItem, purchaseErrors.ErrNoReceiptLines,
+		purchaseErrors.ErrOrderLineNotFound, purchaseErrors.ErrWarehouseNotFound:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case purchaseErrors.ErrDuplicatePONumber, purchaseErrors.ErrOrderNotEditable,
+		purchaseErrors.ErrInvalidStatusTransition, purchaseErrors.ErrOrderNotReceivable:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
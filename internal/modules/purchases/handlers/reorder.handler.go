@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	purchaseErrors "github.com/hsrvms/fixparts/internal/modules/purchases/errors"
+	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
+	"github.com/hsrvms/fixparts/internal/modules/purchases/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ReorderHandler struct {
+	service services.ReorderService
+}
+
+func NewReorderHandler(service services.ReorderService) *ReorderHandler {
+	return &ReorderHandler{
+		service: service,
+	}
+}
+
+// GetReorderSuggestions handles retrieval of the items to reorder, grouped
+// by supplier
+func (h *ReorderHandler) GetReorderSuggestions(c echo.Context) error {
+	filter := &models.ReorderFilter{}
+
+	if supplierID := c.QueryParam("supplier_id"); supplierID != "" {
+		id, err := strconv.Atoi(supplierID)
+		if err == nil {
+			filter.SupplierID = &id
+		}
+	}
+
+	if days := c.QueryParam("days"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, purchaseErrors.ErrInvalidReorderDays.Error())
+		}
+		filter.Days = n
+	}
+
+	ctx := c.Request().Context()
+	plan, err := h.service.GetSuggestions(ctx, filter)
+	if err != nil {
+		return reorderError(err)
+	}
+
+	return c.JSON(http.StatusOK, plan)
+}
+
+// CreateReorderDrafts handles creating a draft purchase order per supplier
+// from the reorder suggestions
+func (h *ReorderHandler) CreateReorderDrafts(c echo.Context) error {
+	request := new(models.ReorderRequest)
+	if err := c.Bind(request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	orders, err := h.service.CreateDraftOrders(ctx, request)
+	if err != nil {
+		return reorderError(err)
+	}
+
+	return c.JSON(http.StatusCreated, orders)
+}
+
+// reorderError maps reorder errors to HTTP errors
+func reorderError(err error) error {
+	switch err {
+	case purchaseErrors.ErrInvalidReorderDays:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case purchaseErrors.ErrNothingToReorder:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	purchaseErrors "github.com/hsrvms/fixparts/internal/modules/purchases/errors"
+	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
+	"github.com/hsrvms/fixparts/internal/modules/purchases/services"
+	"github.com/labstack/echo/v4"
+)
+
+type SupplierReturnHandler struct {
+	service services.SupplierReturnService
+}
+
+func NewSupplierReturnHandler(service services.SupplierReturnService) *SupplierReturnHandler {
+	return &SupplierReturnHandler{
+		service: service,
+	}
+}
+
+// GetSupplierReturns handles retrieval of all supplier returns with optional filtering
+func (h *SupplierReturnHandler) GetSupplierReturns(c echo.Context) error {
+	filter := &models.SupplierReturnFilter{}
+
+	// Parse query parameters
+	if supplierID := c.QueryParam("supplier_id"); supplierID != "" {
+		id, err := strconv.Atoi(supplierID)
+		if err == nil {
+			filter.SupplierID = &id
+		}
+	}
+
+	if status := c.QueryParam("status"); status != "" {
+		filter.Status = &status
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		if date, err := time.Parse(time.RFC3339, startDate); err == nil {
+			filter.StartDate = &date
+		}
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		if date, err := time.Parse(time.RFC3339, endDate); err == nil {
+			filter.EndDate = &date
+		}
+	}
+
+	ctx := c.Request().Context()
+	returns, err := h.service.GetAll(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, returns)
+}
+
+// GetSupplierReturnsOfSupplier handles retrieval of the returns sent to a supplier
+func (h *SupplierReturnHandler) GetSupplierReturnsOfSupplier(c echo.Context) error {
+	supplierID, err := strconv.Atoi(c.Param("supplierId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid supplier ID")
+	}
+
+	ctx := c.Request().Context()
+	returns, err := h.service.GetAll(ctx, &models.SupplierReturnFilter{SupplierID: &supplierID})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, returns)
+}
+
+// GetSupplierReturnByID handles retrieval of a single supplier return
+func (h *SupplierReturnHandler) GetSupplierReturnByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid supplier return ID")
+	}
+
+	ctx := c.Request().Context()
+	supplierReturn, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return supplierReturnError(err)
+	}
+
+	return c.JSON(http.StatusOK, supplierReturn)
+}
+
+// CreateSupplierReturn handles requesting a return of purchased parts
+func (h *SupplierReturnHandler) CreateSupplierReturn(c echo.Context) error {
+	supplierReturn := new(models.SupplierReturn)
+	if err := c.Bind(supplierReturn); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.Create(ctx, supplierReturn)
+	if err != nil {
+		return supplierReturnError(err)
+	}
+
+	created, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return supplierReturnError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// DeleteSupplierReturn handles deletion of a return that was not shipped
+func (h *SupplierReturnHandler) DeleteSupplierReturn(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid supplier return ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Delete(ctx, id); err != nil {
+		return supplierReturnError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ShipSupplierReturn handles sending a requested return to the supplier
+func (h *SupplierReturnHandler) ShipSupplierReturn(c echo.Context) error {
+	return h.changeStatus(c, h.service.Ship)
+}
+
+// CreditSupplierReturn handles recording the supplier's credit note
+func (h *SupplierReturnHandler) CreditSupplierReturn(c echo.Context) error {
+	return h.changeStatus(c, h.service.Credit)
+}
+
+// RejectSupplierReturn handles closing a return the supplier refused
+func (h *SupplierReturnHandler) RejectSupplierReturn(c echo.Context) error {
+	return h.changeStatus(c, h.service.Reject)
+}
+
+// changeStatus applies a status change to the return and responds with the
+// updated document
+func (h *SupplierReturnHandler) changeStatus(c echo.Context, change func(context.Context, int, *models.SupplierReturnUpdate) error) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid supplier return ID")
+	}
+
+	update := new(models.SupplierReturnUpdate)
+	if err := c.Bind(update); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	if err := change(ctx, id, update); err != nil {
+		return supplierReturnError(err)
+	}
+
+	return h.GetSupplierReturnByID(c)
+}
+
+// supplierReturnError maps supplier return errors to HTTP errors
+func supplierReturnError(err error) error {
+	if errors.Is(err, purchaseErrors.ErrInsufficientStockForReturn) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	switch err {
+	case purchaseErrors.ErrSupplierReturnNotFound, purchaseErrors.ErrPurchaseNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case purchaseErrors.ErrInvalidSupplierReturnID, purchaseErrors.ErrInvalidSupplierID,
+		purchaseErrors.ErrNoReturnLines, purchaseErrors.ErrInvalidReturnReason,
+		purchaseErrors.ErrReturnLineNotResolved, purchaseErrors.ErrReturnSupplierMismatch,
+		purchaseErrors.ErrReturnInvoiceMismatch, purchaseErrors.ErrDuplicateReturnLine,
+		purchaseErrors.ErrInvalidQuantity, purchaseErrors.ErrInvalidCostPerUnit,
+		purchaseErrors.ErrInvalidDate, purchaseErrors.ErrCreditNoteNeeded,
+		purchaseErrors.ErrInvalidCreditedAmount:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case purchaseErrors.ErrReturnExceedsPurchased, purchaseErrors.ErrReturnNotEditable,
+		purchaseErrors.ErrInvalidReturnTransition:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
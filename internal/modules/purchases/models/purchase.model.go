@@ -8,10 +8,13 @@ type Purchase struct {
 	SupplierID    int       `json:"supplier_id" db:"supplier_id"`
 	ItemID        int       `json:"item_id" db:"item_id"`
 	Quantity      int       `json:"quantity" db:"quantity"`
-	CostPerUnit   float64   `json:"cost_per_unit" db:"cost_per_unit"`
-	TotalCost     float64   `json:"total_cost" db:"total_cost"`
+	CostPerUnit   float64   `json:"cost_per_unit" db:"cost_per_unit" access:"full"`
+	TotalCost     float64   `json:"total_cost" db:"total_cost" access:"full"`
 	InvoiceNumber *string   `json:"invoice_number,omitempty" db:"invoice_number"`
 	ReceivedBy    *string   `json:"received_by,omitempty" db:"received_by"`
+	ReceiptID     *int      `json:"receipt_id,omitempty" db:"receipt_id"`
+	POLineID      *int      `json:"po_line_id,omitempty" db:"po_line_id"`
+	WarehouseID   int       `json:"warehouse_id" db:"warehouse_id"`
 	Notes         *string   `json:"notes,omitempty" db:"notes"`
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
@@ -20,6 +23,7 @@ type Purchase struct {
 	SupplierName    string `json:"supplier_name,omitempty" db:"supplier_name"`
 	ItemPartNumber  string `json:"item_part_number,omitempty" db:"item_part_number"`
 	ItemDescription string `json:"item_description,omitempty" db:"item_description"`
+	WarehouseCode   string `json:"warehouse_code,omitempty" db:"warehouse_code"`
 }
 
 type PurchaseFilter struct {
@@ -28,4 +32,6 @@ type PurchaseFilter struct {
 	StartDate     *time.Time `query:"start_date"`
 	EndDate       *time.Time `query:"end_date"`
 	InvoiceNumber *string    `query:"invoice_number"`
+	ReceiptID     *int       `query:"receipt_id"`
+	WarehouseID   *int       `query:"warehouse_id"`
 }
@@ -0,0 +1,99 @@
+package models
+
+import "time"
+
+// Purchase order statuses
+const (
+	POStatusDraft             = "draft"
+	POStatusSent              = "sent"
+	POStatusPartiallyReceived = "partially_received"
+	POStatusReceived          = "received"
+	POStatusCancelled         = "cancelled"
+)
+
+// PurchaseOrder is an order placed with a supplier. It does not change
+// stock; only goods receipts posted against it do.
+type PurchaseOrder struct {
+	POID         int        `json:"po_id" db:"po_id"`
+	PONumber     string     `json:"po_number" db:"po_number"`
+	SupplierID   int        `json:"supplier_id" db:"supplier_id"`
+	Status       string     `json:"status" db:"status"`
+	OrderDate    time.Time  `json:"order_date" db:"order_date"`
+	ExpectedDate *time.Time `json:"expected_date,omitempty" db:"expected_date"`
+	CreatedBy    *string    `json:"created_by,omitempty" db:"created_by"`
+	Notes        *string    `json:"notes,omitempty" db:"notes"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+
+	Lines []*PurchaseOrderLine `json:"lines" db:"-"`
+
+	// Additional fields for API responses
+	SupplierName string  `json:"supplier_name,omitempty" db:"supplier_name"`
+	TotalAmount  float64 `json:"total_amount" db:"-" access:"full"`
+}
+
+// PurchaseOrderLine is an item ordered on a purchase order. The received
+// quantity is the sum of the goods receipt lines posted against it.
+type PurchaseOrderLine struct {
+	POLineID        int       `json:"po_line_id" db:"po_line_id"`
+	POID            int       `json:"po_id" db:"po_id"`
+	ItemID          int       `json:"item_id" db:"item_id"`
+	QuantityOrdered int       `json:"quantity_ordered" db:"quantity_ordered"`
+	CostPerUnit     float64   `json:"cost_per_unit" db:"cost_per_unit" access:"full"`
+	Notes           *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+
+	// Receiving progress, computed from goods receipts
+	QuantityReceived    int `json:"quantity_received" db:"quantity_received"`
+	QuantityOutstanding int `json:"quantity_outstanding" db:"-"`
+	QuantityOver        int `json:"quantity_over" db:"-"`
+
+	// Additional fields for API responses
+	ItemPartNumber  string `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription string `json:"item_description,omitempty" db:"item_description"`
+}
+
+// GoodsReceipt is a delivery received against a purchase order. Each of its
+// lines is stored as a Purchase, which is what moves stock.
+type GoodsReceipt struct {
+	ReceiptID     int       `json:"receipt_id" db:"receipt_id"`
+	ReceiptNumber string    `json:"receipt_number" db:"receipt_number"`
+	POID          int       `json:"po_id" db:"po_id"`
+	Date          time.Time `json:"date" db:"date"`
+	InvoiceNumber *string   `json:"invoice_number,omitempty" db:"invoice_number"`
+	ReceivedBy    *string   `json:"received_by,omitempty" db:"received_by"`
+	WarehouseID   int       `json:"warehouse_id" db:"warehouse_id"`
+	Notes         *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+
+	Lines []*GoodsReceiptLine `json:"lines" db:"-"`
+
+	// Additional fields for API responses
+	PONumber      string `json:"po_number,omitempty" db:"po_number"`
+	WarehouseCode string `json:"warehouse_code,omitempty" db:"warehouse_code"`
+}
+
+// GoodsReceiptLine is the quantity of a purchase order line received in a
+// goods receipt
+type GoodsReceiptLine struct {
+	PurchaseID  int     `json:"purchase_id" db:"purchase_id"`
+	POLineID    int     `json:"po_line_id" db:"po_line_id"`
+	ItemID      int     `json:"item_id" db:"item_id"`
+	Quantity    int     `json:"quantity" db:"quantity"`
+	CostPerUnit float64 `json:"cost_per_unit" db:"cost_per_unit" access:"full"`
+	TotalCost   float64 `json:"total_cost" db:"total_cost" access:"full"`
+	Notes       *string `json:"notes,omitempty" db:"notes"`
+
+	// Additional fields for API responses
+	ItemPartNumber  string `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription string `json:"item_description,omitempty" db:"item_description"`
+}
+
+type PurchaseOrderFilter struct {
+	SupplierID *int       `query:"supplier_id"`
+	Status     *string    `query:"status"`
+	StartDate  *time.Time `query:"start_date"`
+	EndDate    *time.Time `query:"end_date"`
+}
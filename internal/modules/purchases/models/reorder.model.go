@@ -0,0 +1,67 @@
+package models
+
+// DefaultReorderDays is the sales window used for the velocity when none is
+// given
+const DefaultReorderDays = 30
+
+// ReorderSuggestion is the quantity of an item to order from its supplier.
+// An item is suggested once its stock position (stock on hand plus quantity
+// still outstanding on open purchase orders, drafts included) falls to the
+// reorder point: the minimum stock plus the expected sales over the supplier
+// lead time. It is then ordered up to the reorder-up-to level, or the reorder
+// point if that is higher.
+type ReorderSuggestion struct {
+	ItemID          int     `json:"item_id" db:"item_id"`
+	ItemPartNumber  string  `json:"item_part_number" db:"item_part_number"`
+	ItemDescription string  `json:"item_description" db:"item_description"`
+	SupplierID      *int    `json:"supplier_id,omitempty" db:"supplier_id"`
+	SupplierName    *string `json:"supplier_name,omitempty" db:"supplier_name"`
+	CurrentStock    int     `json:"current_stock" db:"current_stock"`
+	OnOrder         int     `json:"on_order" db:"on_order"`
+	InTransit       int     `json:"in_transit" db:"in_transit"`
+	MinimumStock    int     `json:"minimum_stock" db:"minimum_stock"`
+	ReorderUpTo     int     `json:"reorder_up_to" db:"reorder_up_to"`
+	LeadTimeDays    int     `json:"lead_time_days" db:"lead_time_days"`
+	QuantitySold    int     `json:"quantity_sold" db:"quantity_sold"`
+	CostPerUnit     float64 `json:"cost_per_unit" db:"cost_per_unit" access:"full"`
+
+	// Computed by the reorder service
+	DailyVelocity     float64 `json:"daily_velocity" db:"-"`
+	ReorderPoint      int     `json:"reorder_point" db:"-"`
+	SuggestedQuantity int     `json:"suggested_quantity" db:"-"`
+	LineTotal         float64 `json:"line_total" db:"-" access:"full"`
+}
+
+// SupplierReorder groups the reorder suggestions of one supplier, which
+// become the lines of a single draft purchase order
+type SupplierReorder struct {
+	SupplierID   int                  `json:"supplier_id"`
+	SupplierName string               `json:"supplier_name"`
+	LeadTimeDays int                  `json:"lead_time_days"`
+	Lines        []*ReorderSuggestion `json:"lines"`
+	TotalAmount  float64              `json:"total_amount" access:"full"`
+}
+
+// ReorderPlan is the reorder suggestions grouped by supplier. Items without
+// a supplier or without a buy price cannot be ordered automatically and are
+// listed separately.
+type ReorderPlan struct {
+	Days       int                  `json:"days"`
+	Suppliers  []*SupplierReorder   `json:"suppliers"`
+	Unassigned []*ReorderSuggestion `json:"unassigned"`
+	Unpriced   []*ReorderSuggestion `json:"unpriced"`
+}
+
+// ReorderRequest creates draft purchase orders from the reorder suggestions.
+// An empty supplier list orders from every supplier with suggestions.
+type ReorderRequest struct {
+	SupplierIDs []int   `json:"supplier_ids"`
+	Days        int     `json:"days"`
+	CreatedBy   *string `json:"created_by,omitempty"`
+	Notes       *string `json:"notes,omitempty"`
+}
+
+type ReorderFilter struct {
+	SupplierID *int `query:"supplier_id"`
+	Days       int  `query:"days"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Supplier return statuses
+const (
+	SupplierReturnRequested = "requested"
+	SupplierReturnShipped   = "shipped"
+	SupplierReturnCredited  = "credited"
+	SupplierReturnRejected  = "rejected"
+)
+
+// Reasons for sending parts back to a supplier
+const (
+	SupplierReturnReasonDefective = "defective"
+	SupplierReturnReasonWrongPart = "wrong_part"
+	SupplierReturnReasonDamaged   = "damaged"
+	SupplierReturnReasonOther     = "other"
+)
+
+// SupplierReturn is a return authorisation (RMA) for parts sent back to a
+// supplier. Stock only leaves when the return is shipped.
+type SupplierReturn struct {
+	SupplierReturnID int        `json:"supplier_return_id" db:"supplier_return_id"`
+	ReturnNumber     string     `json:"return_number" db:"return_number"`
+	SupplierID       int        `json:"supplier_id" db:"supplier_id"`
+	InvoiceNumber    *string    `json:"invoice_number,omitempty" db:"invoice_number"`
+	RMANumber        *string    `json:"rma_number,omitempty" db:"rma_number"`
+	Status           string     `json:"status" db:"status"`
+	ReasonCode       string     `json:"reason_code" db:"reason_code"`
+	RequestedAt      time.Time  `json:"requested_at" db:"requested_at"`
+	ShippedAt        *time.Time `json:"shipped_at,omitempty" db:"shipped_at"`
+	CreditedAt       *time.Time `json:"credited_at,omitempty" db:"credited_at"`
+	CreditNoteNumber *string    `json:"credit_note_number,omitempty" db:"credit_note_number"`
+	CreditedAmount   *float64   `json:"credited_amount,omitempty" db:"credited_amount" access:"full"`
+	RequestedBy      *string    `json:"requested_by,omitempty" db:"requested_by"`
+	Notes            *string    `json:"notes,omitempty" db:"notes"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+
+	Lines []*SupplierReturnLine `json:"lines" db:"-"`
+
+	// Additional fields for API responses
+	SupplierName string  `json:"supplier_name,omitempty" db:"supplier_name"`
+	TotalAmount  float64 `json:"total_amount" db:"-" access:"full"`
+}
+
+// SupplierReturnLine is a quantity of one purchase sent back. Lines can
+// give the purchase directly, or the item when the return names the invoice.
+type SupplierReturnLine struct {
+	LineID           int       `json:"line_id" db:"line_id"`
+	SupplierReturnID int       `json:"supplier_return_id" db:"supplier_return_id"`
+	PurchaseID       int       `json:"purchase_id" db:"purchase_id"`
+	ItemID           int       `json:"item_id" db:"item_id"`
+	Quantity         int       `json:"quantity" db:"quantity"`
+	CostPerUnit      float64   `json:"cost_per_unit" db:"cost_per_unit" access:"full"`
+	Notes            *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+
+	// Additional fields for API responses
+	ItemPartNumber  string `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription string `json:"item_description,omitempty" db:"item_description"`
+}
+
+// SupplierReturnUpdate carries the details recorded when a return moves to
+// its next status
+type SupplierReturnUpdate struct {
+	Date             time.Time `json:"date"`
+	PerformedBy      *string   `json:"performed_by,omitempty"`
+	CreditNoteNumber *string   `json:"credit_note_number,omitempty"`
+	CreditedAmount   *float64  `json:"credited_amount,omitempty" access:"full"`
+	Notes            *string   `json:"notes,omitempty"`
+}
+
+type SupplierReturnFilter struct {
+	SupplierID *int       `query:"supplier_id"`
+	Status     *string    `query:"status"`
+	StartDate  *time.Time `query:"start_date"`
+	EndDate    *time.Time `query:"end_date"`
+}
@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"strings"
 
+	stockerrors "github.com/hsrvms/fixparts/internal/modules/inventory/stock/errors"
+	stockModels "github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+	stock "github.com/hsrvms/fixparts/internal/modules/inventory/stock/repositories"
+	purchaseErrors "github.com/hsrvms/fixparts/internal/modules/purchases/errors"
 	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
 	"github.com/hsrvms/fixparts/pkg/db"
 	"github.com/jackc/pgx/v5"
@@ -26,14 +30,17 @@ func (r *PostgresPurchaseRepository) GetAll(ctx context.Context, filter *models.
         SELECT
             p.purchase_id, p.date, p.supplier_id, p.item_id,
             p.quantity, p.cost_per_unit, p.total_cost,
-            p.invoice_number, p.received_by, p.notes,
+            p.invoice_number, p.received_by, p.receipt_id,
+            p.po_line_id, p.notes,
             p.created_at, p.updated_at,
             s.name as supplier_name,
             i.part_number as item_part_number,
-            i.description as item_description
+            i.description as item_description,
+            p.warehouse_id, w.code as warehouse_code
         FROM purchases p
         JOIN suppliers s ON p.supplier_id = s.supplier_id
         JOIN items i ON p.item_id = i.item_id
+        JOIN warehouses w ON p.warehouse_id = w.warehouse_id
         WHERE 1=1
     `
 
@@ -71,6 +78,18 @@ func (r *PostgresPurchaseRepository) GetAll(ctx context.Context, filter *models.
 			params = append(params, "%"+*filter.InvoiceNumber+"%")
 			paramCount++
 		}
+
+		if filter.WarehouseID != nil {
+			conditions = append(conditions, fmt.Sprintf("p.warehouse_id = $%d", paramCount))
+			params = append(params, *filter.WarehouseID)
+			paramCount++
+		}
+
+		if filter.ReceiptID != nil {
+			conditions = append(conditions, fmt.Sprintf("p.receipt_id = $%d", paramCount))
+			params = append(params, *filter.ReceiptID)
+			paramCount++
+		}
 	}
 
 	if len(conditions) > 0 {
@@ -98,12 +117,16 @@ func (r *PostgresPurchaseRepository) GetAll(ctx context.Context, filter *models.
 			&purchase.TotalCost,
 			&purchase.InvoiceNumber,
 			&purchase.ReceivedBy,
+			&purchase.ReceiptID,
+			&purchase.POLineID,
 			&purchase.Notes,
 			&purchase.CreatedAt,
 			&purchase.UpdatedAt,
 			&purchase.SupplierName,
 			&purchase.ItemPartNumber,
 			&purchase.ItemDescription,
+			&purchase.WarehouseID,
+			&purchase.WarehouseCode,
 		)
 		if err != nil {
 			return nil, err
@@ -119,14 +142,17 @@ func (r *PostgresPurchaseRepository) GetByID(ctx context.Context, id int) (*mode
         SELECT
             p.purchase_id, p.date, p.supplier_id, p.item_id,
             p.quantity, p.cost_per_unit, p.total_cost,
-            p.invoice_number, p.received_by, p.notes,
+            p.invoice_number, p.received_by, p.receipt_id,
+            p.po_line_id, p.notes,
             p.created_at, p.updated_at,
             s.name as supplier_name,
             i.part_number as item_part_number,
-            i.description as item_description
+            i.description as item_description,
+            p.warehouse_id, w.code as warehouse_code
         FROM purchases p
         JOIN suppliers s ON p.supplier_id = s.supplier_id
         JOIN items i ON p.item_id = i.item_id
+        JOIN warehouses w ON p.warehouse_id = w.warehouse_id
         WHERE p.purchase_id = $1
     `
 
@@ -141,12 +167,16 @@ func (r *PostgresPurchaseRepository) GetByID(ctx context.Context, id int) (*mode
 		&purchase.TotalCost,
 		&purchase.InvoiceNumber,
 		&purchase.ReceivedBy,
+		&purchase.ReceiptID,
+		&purchase.POLineID,
 		&purchase.Notes,
 		&purchase.CreatedAt,
 		&purchase.UpdatedAt,
 		&purchase.SupplierName,
 		&purchase.ItemPartNumber,
 		&purchase.ItemDescription,
+		&purchase.WarehouseID,
+		&purchase.WarehouseCode,
 	)
 
 	if err != nil {
@@ -160,37 +190,54 @@ func (r *PostgresPurchaseRepository) GetByID(ctx context.Context, id int) (*mode
 }
 
 func (r *PostgresPurchaseRepository) Create(ctx context.Context, purchase *models.Purchase) (int, error) {
-	query := `
-        INSERT INTO purchases (
-            date, supplier_id, item_id, quantity,
-            cost_per_unit, total_cost, invoice_number,
-            received_by, notes
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-        RETURNING purchase_id
-    `
-
-	var id int
-	err := r.db.Pool.QueryRow(
-		ctx, query,
-		purchase.Date,
-		purchase.SupplierID,
-		purchase.ItemID,
-		purchase.Quantity,
-		purchase.CostPerUnit,
-		purchase.TotalCost,
-		purchase.InvoiceNumber,
-		purchase.ReceivedBy,
-		purchase.Notes,
-	).Scan(&id)
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
 
+	id, err := insertPurchase(ctx, tx, purchase)
 	if err != nil {
 		return 0, err
 	}
 
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
 	return id, nil
 }
 
+// Update modifies the purchase and corrects stock for a changed quantity,
+// cost, item or warehouse in the same transaction. Stock taken back comes
+// out of the purchase's own cost layers; a new cost re-costs what is left of
+// the purchase in stock.
 func (r *PostgresPurchaseRepository) Update(ctx context.Context, purchase *models.Purchase) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	old, err := lockPurchase(ctx, tx, purchase.PurchaseID)
+	if err != nil {
+		return err
+	}
+
+	// Parts sent back to the supplier must still match the purchase
+	returned, err := returnedQuantity(ctx, tx, purchase.PurchaseID)
+	if err != nil {
+		return err
+	}
+	if purchase.WarehouseID, err = resolveWarehouse(ctx, tx, purchase.WarehouseID); err != nil {
+		return err
+	}
+	if returned > 0 && (purchase.SupplierID != old.SupplierID ||
+		purchase.ItemID != old.ItemID || purchase.WarehouseID != old.WarehouseID ||
+		purchase.Quantity < returned) {
+		return purchaseErrors.ErrPurchaseHasReturns
+	}
+
 	query := `
         UPDATE purchases SET
             date = $2,
@@ -200,12 +247,13 @@ func (r *PostgresPurchaseRepository) Update(ctx context.Context, purchase *model
             cost_per_unit = $6,
             total_cost = $7,
             invoice_number = $8,
-            received_by = $9,
-            notes = $10
+            received_by = COALESCE(received_by, $9),
+            notes = $10,
+            warehouse_id = $11
         WHERE purchase_id = $1
     `
 
-	result, err := r.db.Pool.Exec(
+	_, err = tx.Exec(
 		ctx, query,
 		purchase.PurchaseID,
 		purchase.Date,
@@ -217,32 +265,107 @@ func (r *PostgresPurchaseRepository) Update(ctx context.Context, purchase *model
 		purchase.InvoiceNumber,
 		purchase.ReceivedBy,
 		purchase.Notes,
+		purchase.WarehouseID,
 	)
 
 	if err != nil {
 		return err
 	}
 
-	if result.RowsAffected() == 0 {
-		return errors.New("purchase not found")
+	var corrections []*stockModels.StockMovement
+	switch {
+	case purchase.WarehouseID != old.WarehouseID:
+		corrections = append(corrections,
+			purchaseReversal(old, old.Quantity, "Purchase moved to another warehouse"),
+			purchaseCorrection(purchase, purchase.Quantity, "Purchase moved to another warehouse"))
+	case purchase.ItemID != old.ItemID:
+		corrections = append(corrections,
+			purchaseReversal(old, old.Quantity, "Item changed on purchase"),
+			purchaseCorrection(purchase, purchase.Quantity, "Item changed on purchase"))
+	default:
+		if purchase.CostPerUnit != old.CostPerUnit {
+			left, err := stock.ReferenceLayerQuantity(ctx, tx, old.ItemID, stockModels.ReferenceTypePurchase, old.PurchaseID)
+			if err != nil {
+				return err
+			}
+			// Incoming movements are posted first, so the reversal still
+			// finds the old layers ahead of the re-costed one
+			if left > 0 {
+				corrections = append(corrections,
+					purchaseReversal(old, left, "Cost changed on purchase"),
+					purchaseCorrection(purchase, left, "Cost changed on purchase"))
+			}
+		}
+
+		if purchase.Quantity > old.Quantity {
+			corrections = append(corrections,
+				purchaseCorrection(purchase, purchase.Quantity-old.Quantity, "Quantity changed on purchase"))
+		} else if purchase.Quantity < old.Quantity {
+			corrections = append(corrections,
+				purchaseReversal(purchase, old.Quantity-purchase.Quantity, "Quantity changed on purchase"))
+		}
+	}
+
+	if err := stock.PostMovements(ctx, tx, corrections); err != nil {
+		return stockError(err)
 	}
 
-	return nil
+	return tx.Commit(ctx)
 }
 
+// Delete removes the purchase and takes its quantity back out of stock
 func (r *PostgresPurchaseRepository) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM purchases WHERE purchase_id = $1`
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := deletePurchase(ctx, tx, id); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
 
-	result, err := r.db.Pool.Exec(ctx, query, id)
+// CanDelete reports whether Delete would succeed. The stock check depends on
+// the ledger and the negative stock policy, so the deletion is carried out
+// and then rolled back.
+func (r *PostgresPurchaseRepository) CanDelete(ctx context.Context, id int) error {
+	tx, err := r.db.Pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
+
+	return deletePurchase(ctx, tx, id)
+}
 
-	if result.RowsAffected() == 0 {
-		return errors.New("purchase not found")
+// deletePurchase reverses the stock of a purchase and deletes it within tx
+func deletePurchase(ctx context.Context, tx pgx.Tx, id int) error {
+	old, err := lockPurchase(ctx, tx, id)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	var hasReturns bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM supplier_return_lines WHERE purchase_id = $1)`, id,
+	).Scan(&hasReturns)
+	if err != nil {
+		return err
+	}
+	if hasReturns {
+		return purchaseErrors.ErrPurchaseHasReturns
+	}
+
+	correction := purchaseReversal(old, old.Quantity, "Purchase deleted")
+	if err := stock.PostMovement(ctx, tx, correction); err != nil {
+		return stockError(err)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM purchases WHERE purchase_id = $1`, id)
+	return err
 }
 
 func (r *PostgresPurchaseRepository) GetByInvoiceNumber(ctx context.Context, invoiceNumber string) (*models.Purchase, error) {
@@ -250,14 +373,17 @@ func (r *PostgresPurchaseRepository) GetByInvoiceNumber(ctx context.Context, inv
         SELECT
             p.purchase_id, p.date, p.supplier_id, p.item_id,
             p.quantity, p.cost_per_unit, p.total_cost,
-            p.invoice_number, p.received_by, p.notes,
+            p.invoice_number, p.received_by, p.receipt_id,
+            p.po_line_id, p.notes,
             p.created_at, p.updated_at,
             s.name as supplier_name,
             i.part_number as item_part_number,
-            i.description as item_description
+            i.description as item_description,
+            p.warehouse_id, w.code as warehouse_code
         FROM purchases p
         JOIN suppliers s ON p.supplier_id = s.supplier_id
         JOIN items i ON p.item_id = i.item_id
+        JOIN warehouses w ON p.warehouse_id = w.warehouse_id
         WHERE p.invoice_number = $1
     `
 
@@ -272,12 +398,16 @@ func (r *PostgresPurchaseRepository) GetByInvoiceNumber(ctx context.Context, inv
 		&purchase.TotalCost,
 		&purchase.InvoiceNumber,
 		&purchase.ReceivedBy,
+		&purchase.ReceiptID,
+		&purchase.POLineID,
 		&purchase.Notes,
 		&purchase.CreatedAt,
 		&purchase.UpdatedAt,
 		&purchase.SupplierName,
 		&purchase.ItemPartNumber,
 		&purchase.ItemDescription,
+		&purchase.WarehouseID,
+		&purchase.WarehouseCode,
 	)
 
 	if err != nil {
@@ -303,3 +433,133 @@ func (r *PostgresPurchaseRepository) GetItemPurchases(ctx context.Context, itemI
 	}
 	return r.GetAll(ctx, filter)
 }
+
+// insertPurchase records goods received into stock and posts the matching
+// incoming movement to the stock ledger. It is shared by direct purchases and
+// goods receipt lines so both go through the same path.
+func insertPurchase(ctx context.Context, tx pgx.Tx, purchase *models.Purchase) (int, error) {
+	warehouseID, err := resolveWarehouse(ctx, tx, purchase.WarehouseID)
+	if err != nil {
+		return 0, err
+	}
+	purchase.WarehouseID = warehouseID
+
+	query := `
+        INSERT INTO purchases (
+            date, supplier_id, item_id, quantity,
+            cost_per_unit, total_cost, invoice_number,
+            received_by, receipt_id, po_line_id, notes, warehouse_id
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+        RETURNING purchase_id
+    `
+
+	var id int
+	err = tx.QueryRow(
+		ctx, query,
+		purchase.Date,
+		purchase.SupplierID,
+		purchase.ItemID,
+		purchase.Quantity,
+		purchase.CostPerUnit,
+		purchase.TotalCost,
+		purchase.InvoiceNumber,
+		purchase.ReceivedBy,
+		purchase.ReceiptID,
+		purchase.POLineID,
+		purchase.Notes,
+		purchase.WarehouseID,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	referenceType := stockModels.ReferenceTypePurchase
+	movement := &stockModels.StockMovement{
+		ItemID:        purchase.ItemID,
+		WarehouseID:   purchase.WarehouseID,
+		MovementType:  stockModels.MovementTypePurchase,
+		Quantity:      purchase.Quantity,
+		ReferenceType: &referenceType,
+		ReferenceID:   &id,
+		PerformedBy:   purchase.ReceivedBy,
+		UnitCost:      &purchase.CostPerUnit,
+	}
+	if err := stock.PostMovement(ctx, tx, movement); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// lockPurchase reads the stored supplier, item, quantity, cost and warehouse
+// of a purchase and locks the row for the rest of the transaction
+func lockPurchase(ctx context.Context, tx pgx.Tx, id int) (*models.Purchase, error) {
+	purchase := &models.Purchase{PurchaseID: id}
+	err := tx.QueryRow(ctx,
+		`SELECT supplier_id, item_id, quantity, cost_per_unit, received_by, warehouse_id
+		 FROM purchases WHERE purchase_id = $1 FOR UPDATE`,
+		id,
+	).Scan(&purchase.SupplierID, &purchase.ItemID, &purchase.Quantity, &purchase.CostPerUnit,
+		&purchase.ReceivedBy, &purchase.WarehouseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("purchase not found")
+		}
+		return nil, err
+	}
+
+	return purchase, nil
+}
+
+// purchaseCorrection builds the movement that adds more of a purchase to
+// stock, at the purchase's unit cost
+func purchaseCorrection(purchase *models.Purchase, quantity int, notes string) *stockModels.StockMovement {
+	referenceType := stockModels.ReferenceTypePurchase
+	return &stockModels.StockMovement{
+		ItemID:        purchase.ItemID,
+		WarehouseID:   purchase.WarehouseID,
+		MovementType:  stockModels.MovementTypePurchaseCorrection,
+		Quantity:      quantity,
+		ReferenceType: &referenceType,
+		ReferenceID:   &purchase.PurchaseID,
+		PerformedBy:   purchase.ReceivedBy,
+		Notes:         &notes,
+		UnitCost:      &purchase.CostPerUnit,
+	}
+}
+
+// purchaseReversal builds the movement that takes stock added by a purchase
+// back out. It reverses the purchase's own cost layers and takes the stock
+// out of the average at the purchase's unit cost.
+func purchaseReversal(purchase *models.Purchase, quantity int, notes string) *stockModels.StockMovement {
+	movement := purchaseCorrection(purchase, -quantity, notes)
+	movement.Reversal = true
+	return movement
+}
+
+// resolveWarehouse picks the warehouse goods are received into, the default
+// one when none is given
+func resolveWarehouse(ctx context.Context, tx pgx.Tx, warehouseID int) (int, error) {
+	id, err := stock.ResolveWarehouse(ctx, tx, warehouseID)
+	if errors.Is(err, stockerrors.ErrWarehouseNotFound) {
+		return 0, purchaseErrors.ErrWarehouseNotFound
+	}
+	return id, err
+}
+
+// stockError translates ledger errors into purchase errors, keeping the
+// detail of which item ran short
+func stockError(err error) error {
+	return wrapStockError(err, purchaseErrors.ErrInsufficientStock)
+}
+
+func wrapStockError(err, sentinel error) error {
+	var short *stockerrors.InsufficientStockError
+	if errors.As(err, &short) {
+		return fmt.Errorf("%w: %s", sentinel, short.Error())
+	}
+	if errors.Is(err, stockerrors.ErrInsufficientStock) {
+		return sentinel
+	}
+	return err
+}
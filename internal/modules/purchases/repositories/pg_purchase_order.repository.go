@@ -0,0 +1,614 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	purchaseErrors "github.com/hsrvms/fixparts/internal/modules/purchases/errors"
+	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresPurchaseOrderRepository struct {
+	db *db.Database
+}
+
+func NewPostgresPurchaseOrderRepository(database *db.Database) PurchaseOrderRepository {
+	return &PostgresPurchaseOrderRepository{
+		db: database,
+	}
+}
+
+func (r *PostgresPurchaseOrderRepository) GetAll(ctx context.Context, filter *models.PurchaseOrderFilter) ([]*models.PurchaseOrder, error) {
+	query := `
+        SELECT
+            po.po_id, po.po_number, po.supplier_id, po.status,
+            po.order_date, po.expected_date, po.created_by, po.notes,
+            po.created_at, po.updated_at,
+            s.name as supplier_name
+        FROM purchase_orders po
+        JOIN suppliers s ON po.supplier_id = s.supplier_id
+        WHERE 1=1
+    `
+
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.SupplierID != nil {
+			conditions = append(conditions, fmt.Sprintf("po.supplier_id = $%d", paramCount))
+			params = append(params, *filter.SupplierID)
+			paramCount++
+		}
+
+		if filter.Status != nil {
+			conditions = append(conditions, fmt.Sprintf("po.status = $%d", paramCount))
+			params = append(params, *filter.Status)
+			paramCount++
+		}
+
+		if filter.StartDate != nil {
+			conditions = append(conditions, fmt.Sprintf("po.order_date >= $%d", paramCount))
+			params = append(params, *filter.StartDate)
+			paramCount++
+		}
+
+		if filter.EndDate != nil {
+			conditions = append(conditions, fmt.Sprintf("po.order_date <= $%d", paramCount))
+			params = append(params, *filter.EndDate)
+			paramCount++
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY po.order_date DESC"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*models.PurchaseOrder
+	for rows.Next() {
+		order := &models.PurchaseOrder{}
+		err := rows.Scan(
+			&order.POID,
+			&order.PONumber,
+			&order.SupplierID,
+			&order.Status,
+			&order.OrderDate,
+			&order.ExpectedDate,
+			&order.CreatedBy,
+			&order.Notes,
+			&order.CreatedAt,
+			&order.UpdatedAt,
+			&order.SupplierName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadLines(ctx, orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r *PostgresPurchaseOrderRepository) GetByID(ctx context.Context, id int) (*models.PurchaseOrder, error) {
+	return r.getOne(ctx, "po.po_id = $1", id)
+}
+
+func (r *PostgresPurchaseOrderRepository) GetByPONumber(ctx context.Context, poNumber string) (*models.PurchaseOrder, error) {
+	return r.getOne(ctx, "po.po_number = $1", poNumber)
+}
+
+func (r *PostgresPurchaseOrderRepository) Create(ctx context.Context, order *models.PurchaseOrder) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	id, err := insertOrder(ctx, tx, order)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Update modifies a draft purchase order and replaces its lines. Draft
+// orders have no receipts, so the lines can be rewritten safely.
+func (r *PostgresPurchaseOrderRepository) Update(ctx context.Context, order *models.PurchaseOrder) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+        UPDATE purchase_orders SET
+            po_number = $2,
+            supplier_id = $3,
+            order_date = $4,
+            expected_date = $5,
+            notes = $6
+        WHERE po_id = $1 AND status = 'draft'
+    `
+
+	result, err := tx.Exec(
+		ctx, query,
+		order.POID,
+		order.PONumber,
+		order.SupplierID,
+		order.OrderDate,
+		order.ExpectedDate,
+		order.Notes,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return purchaseErrors.ErrOrderNotEditable
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM purchase_order_lines WHERE po_id = $1`, order.POID); err != nil {
+		return err
+	}
+
+	for _, line := range order.Lines {
+		line.POID = order.POID
+		if line.POLineID, err = insertOrderLine(ctx, tx, line); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresPurchaseOrderRepository) UpdateStatus(ctx context.Context, id int, status string) error {
+	query := `UPDATE purchase_orders SET status = $2 WHERE po_id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, status)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("purchase order not found")
+	}
+
+	return nil
+}
+
+func (r *PostgresPurchaseOrderRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM purchase_orders WHERE po_id = $1 AND status = 'draft'`
+
+	result, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return purchaseErrors.ErrOrderNotEditable
+	}
+
+	return nil
+}
+
+// CreateReceipt records a goods receipt against a purchase order. Every
+// receipt line is stored as a purchase so stock is only changed here, and the
+// order status is moved to partially received or received in the same
+// transaction.
+func (r *PostgresPurchaseOrderRepository) CreateReceipt(ctx context.Context, receipt *models.GoodsReceipt) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the order so concurrent receipts see each other's quantities
+	var status string
+	var supplierID int
+	err = tx.QueryRow(ctx,
+		`SELECT status, supplier_id FROM purchase_orders WHERE po_id = $1 FOR UPDATE`,
+		receipt.POID,
+	).Scan(&status, &supplierID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, purchaseErrors.ErrPurchaseOrderNotFound
+		}
+		return 0, err
+	}
+
+	if status != models.POStatusSent && status != models.POStatusPartiallyReceived {
+		return 0, purchaseErrors.ErrOrderNotReceivable
+	}
+
+	if receipt.WarehouseID, err = resolveWarehouse(ctx, tx, receipt.WarehouseID); err != nil {
+		return 0, err
+	}
+
+	query := `
+        INSERT INTO goods_receipts (
+            receipt_number, po_id, date, invoice_number, received_by, notes,
+            warehouse_id
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING receipt_id
+    `
+
+	var id int
+	err = tx.QueryRow(
+		ctx, query,
+		receipt.ReceiptNumber,
+		receipt.POID,
+		receipt.Date,
+		receipt.InvoiceNumber,
+		receipt.ReceivedBy,
+		receipt.Notes,
+		receipt.WarehouseID,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range receipt.Lines {
+		var orderedCost float64
+		err = tx.QueryRow(ctx,
+			`SELECT item_id, cost_per_unit FROM purchase_order_lines WHERE po_line_id = $1 AND po_id = $2`,
+			line.POLineID, receipt.POID,
+		).Scan(&line.ItemID, &orderedCost)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, purchaseErrors.ErrOrderLineNotFound
+			}
+			return 0, err
+		}
+
+		// Receive at the ordered cost unless the invoice says otherwise
+		if line.CostPerUnit == 0 {
+			line.CostPerUnit = orderedCost
+		}
+		line.TotalCost = float64(line.Quantity) * line.CostPerUnit
+
+		poLineID := line.POLineID
+		purchase := &models.Purchase{
+			Date:          receipt.Date,
+			SupplierID:    supplierID,
+			ItemID:        line.ItemID,
+			Quantity:      line.Quantity,
+			CostPerUnit:   line.CostPerUnit,
+			TotalCost:     line.TotalCost,
+			InvoiceNumber: receipt.InvoiceNumber,
+			ReceivedBy:    receipt.ReceivedBy,
+			ReceiptID:     &id,
+			POLineID:      &poLineID,
+			WarehouseID:   receipt.WarehouseID,
+			Notes:         line.Notes,
+		}
+		if line.PurchaseID, err = insertPurchase(ctx, tx, purchase); err != nil {
+			return 0, err
+		}
+	}
+
+	// The order is received once every line has been delivered in full
+	statusQuery := `
+        UPDATE purchase_orders po SET status = CASE
+            WHEN NOT EXISTS (
+                SELECT 1
+                FROM purchase_order_lines l
+                WHERE l.po_id = po.po_id
+                AND l.quantity_ordered > COALESCE(
+                    (SELECT SUM(p.quantity) FROM purchases p WHERE p.po_line_id = l.po_line_id), 0)
+            ) THEN 'received'
+            ELSE 'partially_received'
+        END
+        WHERE po.po_id = $1
+    `
+	if _, err = tx.Exec(ctx, statusQuery, receipt.POID); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *PostgresPurchaseOrderRepository) GetReceiptByID(ctx context.Context, id int) (*models.GoodsReceipt, error) {
+	receipts, err := r.getReceipts(ctx, "gr.receipt_id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) == 0 {
+		return nil, nil
+	}
+	return receipts[0], nil
+}
+
+func (r *PostgresPurchaseOrderRepository) GetReceipts(ctx context.Context, poID int) ([]*models.GoodsReceipt, error) {
+	return r.getReceipts(ctx, "gr.po_id = $1", poID)
+}
+
+func (r *PostgresPurchaseOrderRepository) getOne(ctx context.Context, condition string, arg interface{}) (*models.PurchaseOrder, error) {
+	query := `
+        SELECT
+            po.po_id, po.po_number, po.supplier_id, po.status,
+            po.order_date, po.expected_date, po.created_by, po.notes,
+            po.created_at, po.updated_at,
+            s.name as supplier_name
+        FROM purchase_orders po
+        JOIN suppliers s ON po.supplier_id = s.supplier_id
+        WHERE ` + condition
+
+	order := &models.PurchaseOrder{}
+	err := r.db.Pool.QueryRow(ctx, query, arg).Scan(
+		&order.POID,
+		&order.PONumber,
+		&order.SupplierID,
+		&order.Status,
+		&order.OrderDate,
+		&order.ExpectedDate,
+		&order.CreatedBy,
+		&order.Notes,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+		&order.SupplierName,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := r.loadLines(ctx, []*models.PurchaseOrder{order}); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// loadLines attaches the lines of the given orders, with the quantity
+// received so far against each line
+func (r *PostgresPurchaseOrderRepository) loadLines(ctx context.Context, orders []*models.PurchaseOrder) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	orderIDs := make([]int, 0, len(orders))
+	ordersByID := make(map[int]*models.PurchaseOrder, len(orders))
+	for _, order := range orders {
+		order.Lines = []*models.PurchaseOrderLine{}
+		order.TotalAmount = 0
+		orderIDs = append(orderIDs, order.POID)
+		ordersByID[order.POID] = order
+	}
+
+	query := `
+        SELECT
+            l.po_line_id, l.po_id, l.item_id, l.quantity_ordered,
+            l.cost_per_unit, l.notes, l.created_at, l.updated_at,
+            COALESCE(SUM(p.quantity), 0) as quantity_received,
+            i.part_number as item_part_number,
+            i.description as item_description
+        FROM purchase_order_lines l
+        JOIN items i ON l.item_id = i.item_id
+        LEFT JOIN purchases p ON p.po_line_id = l.po_line_id
+        WHERE l.po_id = ANY($1)
+        GROUP BY l.po_line_id, i.part_number, i.description
+        ORDER BY l.po_id, l.po_line_id
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, orderIDs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		line := &models.PurchaseOrderLine{}
+		err := rows.Scan(
+			&line.POLineID,
+			&line.POID,
+			&line.ItemID,
+			&line.QuantityOrdered,
+			&line.CostPerUnit,
+			&line.Notes,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+			&line.QuantityReceived,
+			&line.ItemPartNumber,
+			&line.ItemDescription,
+		)
+		if err != nil {
+			return err
+		}
+
+		line.QuantityOutstanding = max(line.QuantityOrdered-line.QuantityReceived, 0)
+		line.QuantityOver = max(line.QuantityReceived-line.QuantityOrdered, 0)
+
+		if order, ok := ordersByID[line.POID]; ok {
+			order.Lines = append(order.Lines, line)
+			order.TotalAmount += float64(line.QuantityOrdered) * line.CostPerUnit
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *PostgresPurchaseOrderRepository) getReceipts(ctx context.Context, condition string, arg interface{}) ([]*models.GoodsReceipt, error) {
+	query := `
+        SELECT
+            gr.receipt_id, gr.receipt_number, gr.po_id, gr.date,
+            gr.invoice_number, gr.received_by, gr.notes,
+            gr.created_at, gr.updated_at,
+            po.po_number, gr.warehouse_id, w.code as warehouse_code
+        FROM goods_receipts gr
+        JOIN purchase_orders po ON gr.po_id = po.po_id
+        JOIN warehouses w ON gr.warehouse_id = w.warehouse_id
+        WHERE ` + condition + `
+        ORDER BY gr.date DESC
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var receipts []*models.GoodsReceipt
+	receiptIDs := []int{}
+	receiptsByID := make(map[int]*models.GoodsReceipt)
+	for rows.Next() {
+		receipt := &models.GoodsReceipt{Lines: []*models.GoodsReceiptLine{}}
+		err := rows.Scan(
+			&receipt.ReceiptID,
+			&receipt.ReceiptNumber,
+			&receipt.POID,
+			&receipt.Date,
+			&receipt.InvoiceNumber,
+			&receipt.ReceivedBy,
+			&receipt.Notes,
+			&receipt.CreatedAt,
+			&receipt.UpdatedAt,
+			&receipt.PONumber,
+			&receipt.WarehouseID,
+			&receipt.WarehouseCode,
+		)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+		receiptIDs = append(receiptIDs, receipt.ReceiptID)
+		receiptsByID[receipt.ReceiptID] = receipt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(receipts) == 0 {
+		return receipts, nil
+	}
+
+	linesQuery := `
+        SELECT
+            p.purchase_id, p.receipt_id, p.po_line_id, p.item_id, p.quantity,
+            p.cost_per_unit, p.total_cost, p.notes,
+            i.part_number as item_part_number,
+            i.description as item_description
+        FROM purchases p
+        JOIN items i ON p.item_id = i.item_id
+        WHERE p.receipt_id = ANY($1)
+        ORDER BY p.receipt_id, p.purchase_id
+    `
+
+	lineRows, err := r.db.Pool.Query(ctx, linesQuery, receiptIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer lineRows.Close()
+
+	for lineRows.Next() {
+		line := &models.GoodsReceiptLine{}
+		var receiptID int
+		err := lineRows.Scan(
+			&line.PurchaseID,
+			&receiptID,
+			&line.POLineID,
+			&line.ItemID,
+			&line.Quantity,
+			&line.CostPerUnit,
+			&line.TotalCost,
+			&line.Notes,
+			&line.ItemPartNumber,
+			&line.ItemDescription,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if receipt, ok := receiptsByID[receiptID]; ok {
+			receipt.Lines = append(receipt.Lines, line)
+		}
+	}
+
+	return receipts, lineRows.Err()
+}
+
+// insertOrder inserts the order header and its lines
+func insertOrder(ctx context.Context, tx pgx.Tx, order *models.PurchaseOrder) (int, error) {
+	query := `
+        INSERT INTO purchase_orders (
+            po_number, supplier_id, status, order_date,
+            expected_date, created_by, notes
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING po_id
+    `
+
+	var id int
+	err := tx.QueryRow(
+		ctx, query,
+		order.PONumber,
+		order.SupplierID,
+		order.Status,
+		order.OrderDate,
+		order.ExpectedDate,
+		order.CreatedBy,
+		order.Notes,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range order.Lines {
+		line.POID = id
+		if line.POLineID, err = insertOrderLine(ctx, tx, line); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, nil
+}
+
+func insertOrderLine(ctx context.Context, tx pgx.Tx, line *models.PurchaseOrderLine) (int, error) {
+	query := `
+        INSERT INTO purchase_order_lines (
+            po_id, item_id, quantity_ordered, cost_per_unit, notes
+        ) VALUES ($1, $2, $3, $4, $5)
+        RETURNING po_line_id
+    `
+
+	var id int
+	err := tx.QueryRow(
+		ctx, query,
+		line.POID,
+		line.ItemID,
+		line.QuantityOrdered,
+		line.CostPerUnit,
+		line.Notes,
+	).Scan(&id)
+
+	return id, err
+}
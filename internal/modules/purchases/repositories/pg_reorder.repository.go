@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+)
+
+type PostgresReorderRepository struct {
+	db *db.Database
+}
+
+func NewPostgresReorderRepository(database *db.Database) ReorderRepository {
+	return &PostgresReorderRepository{
+		db: database,
+	}
+}
+
+// GetCandidates returns the active items with the figures the reorder
+// suggestions are computed from: the stock on hand, the quantity still
+// outstanding on open purchase orders (drafts included, so suggestions that
+// were already turned into drafts are not suggested again), the quantity
+// shipped between warehouses but not received yet (it has left the stock on
+// hand but does not need to be ordered again), the quantity sold net of
+// returns over the last filter.Days days and the supplier lead time. Without
+// a reorder-up-to level an item is ordered up to twice its minimum stock.
+func (r *PostgresReorderRepository) GetCandidates(ctx context.Context, filter *models.ReorderFilter) ([]*models.ReorderSuggestion, error) {
+	query := `
+        SELECT
+            i.item_id, i.part_number, i.description, i.supplier_id,
+            s.name as supplier_name,
+            i.current_stock, i.minimum_stock,
+            COALESCE(i.reorder_up_to, i.minimum_stock * 2) as reorder_up_to,
+            COALESCE(s.lead_time_days, 0) as lead_time_days,
+            i.buy_price,
+            COALESCE((
+                SELECT SUM(GREATEST(l.quantity_ordered - COALESCE((
+                    SELECT SUM(p.quantity) FROM purchases p WHERE p.po_line_id = l.po_line_id
+                ), 0), 0))
+                FROM purchase_order_lines l
+                JOIN purchase_orders po ON l.po_id = po.po_id
+                WHERE l.item_id = i.item_id
+                AND po.status IN ('draft', 'sent', 'partially_received')
+            ), 0) as on_order,
+            COALESCE((
+                SELECT SUM(tl.quantity - tl.quantity_received)
+                FROM stock_transfer_lines tl
+                JOIN stock_transfers t ON tl.transfer_id = t.transfer_id
+                WHERE tl.item_id = i.item_id
+                AND t.status IN ('shipped', 'partially_received')
+            ), 0) as in_transit,
+            COALESCE((
+                SELECT SUM(sl.quantity)
+                FROM sale_lines sl
+                JOIN sales sa ON sl.sale_id = sa.sale_id
+                WHERE sl.item_id = i.item_id
+                AND sa.date >= CURRENT_TIMESTAMP - make_interval(days => $1)
+            ), 0) - COALESCE((
+                SELECT SUM(rl.quantity)
+                FROM sale_return_lines rl
+                JOIN sale_returns sr ON rl.return_id = sr.return_id
+                WHERE rl.item_id = i.item_id
+                AND sr.date >= CURRENT_TIMESTAMP - make_interval(days => $1)
+            ), 0) as quantity_sold
+        FROM items i
+        LEFT JOIN suppliers s ON i.supplier_id = s.supplier_id
+        WHERE i.is_active = true
+    `
+	params := []interface{}{filter.Days}
+	paramCount := 2
+
+	if filter.SupplierID != nil {
+		query += fmt.Sprintf(" AND i.supplier_id = $%d", paramCount)
+		params = append(params, *filter.SupplierID)
+	}
+
+	query += " ORDER BY i.part_number"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []*models.ReorderSuggestion
+	for rows.Next() {
+		candidate := &models.ReorderSuggestion{}
+		err := rows.Scan(
+			&candidate.ItemID,
+			&candidate.ItemPartNumber,
+			&candidate.ItemDescription,
+			&candidate.SupplierID,
+			&candidate.SupplierName,
+			&candidate.CurrentStock,
+			&candidate.MinimumStock,
+			&candidate.ReorderUpTo,
+			&candidate.LeadTimeDays,
+			&candidate.CostPerUnit,
+			&candidate.OnOrder,
+			&candidate.InTransit,
+			&candidate.QuantitySold,
+		)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, rows.Err()
+}
+
+// CreateDraftOrders inserts the given orders and their lines in a single
+// transaction, so either every supplier gets its draft or none does
+func (r *PostgresReorderRepository) CreateDraftOrders(ctx context.Context, orders []*models.PurchaseOrder) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, order := range orders {
+		if order.POID, err = insertOrder(ctx, tx, order); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,588 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	stockModels "github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+	stock "github.com/hsrvms/fixparts/internal/modules/inventory/stock/repositories"
+	purchaseErrors "github.com/hsrvms/fixparts/internal/modules/purchases/errors"
+	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresSupplierReturnRepository struct {
+	db *db.Database
+}
+
+func NewPostgresSupplierReturnRepository(database *db.Database) SupplierReturnRepository {
+	return &PostgresSupplierReturnRepository{
+		db: database,
+	}
+}
+
+func (r *PostgresSupplierReturnRepository) GetAll(ctx context.Context, filter *models.SupplierReturnFilter) ([]*models.SupplierReturn, error) {
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.SupplierID != nil {
+			conditions = append(conditions, fmt.Sprintf("sr.supplier_id = $%d", paramCount))
+			params = append(params, *filter.SupplierID)
+			paramCount++
+		}
+
+		if filter.Status != nil {
+			conditions = append(conditions, fmt.Sprintf("sr.status = $%d", paramCount))
+			params = append(params, *filter.Status)
+			paramCount++
+		}
+
+		if filter.StartDate != nil {
+			conditions = append(conditions, fmt.Sprintf("sr.requested_at >= $%d", paramCount))
+			params = append(params, *filter.StartDate)
+			paramCount++
+		}
+
+		if filter.EndDate != nil {
+			conditions = append(conditions, fmt.Sprintf("sr.requested_at <= $%d", paramCount))
+			params = append(params, *filter.EndDate)
+			paramCount++
+		}
+	}
+
+	where := "1=1"
+	if len(conditions) > 0 {
+		where = strings.Join(conditions, " AND ")
+	}
+
+	return r.getReturns(ctx, where, params...)
+}
+
+func (r *PostgresSupplierReturnRepository) GetByID(ctx context.Context, id int) (*models.SupplierReturn, error) {
+	returns, err := r.getReturns(ctx, "sr.supplier_return_id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(returns) == 0 {
+		return nil, nil
+	}
+
+	return returns[0], nil
+}
+
+// Create records a requested return. Every line is resolved to the purchase
+// it sends back, and that purchase is locked so that concurrent returns
+// cannot send back more than was bought. Rejected returns do not count.
+func (r *PostgresSupplierReturnRepository) Create(ctx context.Context, supplierReturn *models.SupplierReturn) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	seen := make(map[int]bool, len(supplierReturn.Lines))
+	for _, line := range supplierReturn.Lines {
+		// Find the purchase of the item on the named invoice
+		if line.PurchaseID == 0 {
+			if supplierReturn.InvoiceNumber == nil || line.ItemID <= 0 {
+				return 0, purchaseErrors.ErrReturnLineNotResolved
+			}
+			line.PurchaseID, err = resolveReturnPurchase(ctx, tx, supplierReturn, line, seen)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		if seen[line.PurchaseID] {
+			return 0, purchaseErrors.ErrDuplicateReturnLine
+		}
+		seen[line.PurchaseID] = true
+
+		var supplierID, purchased int
+		var costPerUnit float64
+		var invoiceNumber *string
+		err = tx.QueryRow(ctx, `
+            SELECT supplier_id, item_id, quantity, cost_per_unit, invoice_number
+            FROM purchases
+            WHERE purchase_id = $1
+            FOR UPDATE
+        `, line.PurchaseID).Scan(&supplierID, &line.ItemID, &purchased, &costPerUnit, &invoiceNumber)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, purchaseErrors.ErrPurchaseNotFound
+			}
+			return 0, err
+		}
+
+		// The supplier of the return is the supplier of its purchases
+		if supplierReturn.SupplierID == 0 {
+			supplierReturn.SupplierID = supplierID
+		}
+		if supplierID != supplierReturn.SupplierID {
+			return 0, purchaseErrors.ErrReturnSupplierMismatch
+		}
+		if supplierReturn.InvoiceNumber != nil &&
+			(invoiceNumber == nil || *invoiceNumber != *supplierReturn.InvoiceNumber) {
+			return 0, purchaseErrors.ErrReturnInvoiceMismatch
+		}
+
+		returned, err := returnedQuantity(ctx, tx, line.PurchaseID)
+		if err != nil {
+			return 0, err
+		}
+		if returned+line.Quantity > purchased {
+			return 0, purchaseErrors.ErrReturnExceedsPurchased
+		}
+
+		// Credit is expected at the price paid unless stated otherwise
+		if line.CostPerUnit == 0 {
+			line.CostPerUnit = costPerUnit
+		}
+	}
+
+	query := `
+        INSERT INTO supplier_returns (
+            return_number, supplier_id, invoice_number, rma_number,
+            status, reason_code, requested_at, requested_by, notes
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        RETURNING supplier_return_id
+    `
+
+	var id int
+	err = tx.QueryRow(
+		ctx, query,
+		supplierReturn.ReturnNumber,
+		supplierReturn.SupplierID,
+		supplierReturn.InvoiceNumber,
+		supplierReturn.RMANumber,
+		supplierReturn.Status,
+		supplierReturn.ReasonCode,
+		supplierReturn.RequestedAt,
+		supplierReturn.RequestedBy,
+		supplierReturn.Notes,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	lineQuery := `
+        INSERT INTO supplier_return_lines (
+            supplier_return_id, purchase_id, item_id, quantity, cost_per_unit, notes
+        ) VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING line_id
+    `
+
+	for _, line := range supplierReturn.Lines {
+		line.SupplierReturnID = id
+		err = tx.QueryRow(
+			ctx, lineQuery,
+			line.SupplierReturnID,
+			line.PurchaseID,
+			line.ItemID,
+			line.Quantity,
+			line.CostPerUnit,
+			line.Notes,
+		).Scan(&line.LineID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Delete removes a return that has not been shipped yet
+func (r *PostgresSupplierReturnRepository) Delete(ctx context.Context, id int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := lockReturn(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if status != models.SupplierReturnRequested {
+		return purchaseErrors.ErrReturnNotEditable
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM supplier_returns WHERE supplier_return_id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Ship marks a requested return as sent to the supplier and takes the parts
+// out of stock
+func (r *PostgresSupplierReturnRepository) Ship(ctx context.Context, id int, update *models.SupplierReturnUpdate) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := lockReturn(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if status != models.SupplierReturnRequested {
+		return purchaseErrors.ErrInvalidReturnTransition
+	}
+
+	if err := postReturnMovements(ctx, tx, id, -1, update.PerformedBy, "Shipped to supplier"); err != nil {
+		return returnStockError(err)
+	}
+
+	query := `
+        UPDATE supplier_returns SET
+            status = $2,
+            shipped_at = $3,
+            notes = COALESCE($4, notes)
+        WHERE supplier_return_id = $1
+    `
+	if _, err := tx.Exec(ctx, query, id, models.SupplierReturnShipped, update.Date, update.Notes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Credit records the supplier's credit note against a shipped return
+func (r *PostgresSupplierReturnRepository) Credit(ctx context.Context, id int, update *models.SupplierReturnUpdate) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := lockReturn(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if status != models.SupplierReturnShipped {
+		return purchaseErrors.ErrInvalidReturnTransition
+	}
+
+	query := `
+        UPDATE supplier_returns SET
+            status = $2,
+            credited_at = $3,
+            credit_note_number = $4,
+            credited_amount = $5,
+            notes = COALESCE($6, notes)
+        WHERE supplier_return_id = $1
+    `
+	_, err = tx.Exec(ctx, query,
+		id,
+		models.SupplierReturnCredited,
+		update.Date,
+		update.CreditNoteNumber,
+		update.CreditedAmount,
+		update.Notes,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Reject closes a return the supplier refused. Parts already shipped come
+// back into stock.
+func (r *PostgresSupplierReturnRepository) Reject(ctx context.Context, id int, update *models.SupplierReturnUpdate) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := lockReturn(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case models.SupplierReturnRequested:
+	case models.SupplierReturnShipped:
+		if err := postReturnMovements(ctx, tx, id, 1, update.PerformedBy, "Return rejected by supplier"); err != nil {
+			return err
+		}
+	default:
+		return purchaseErrors.ErrInvalidReturnTransition
+	}
+
+	query := `
+        UPDATE supplier_returns SET
+            status = $2,
+            notes = COALESCE($3, notes)
+        WHERE supplier_return_id = $1
+    `
+	if _, err := tx.Exec(ctx, query, id, models.SupplierReturnRejected, update.Notes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// getReturns loads the return headers matching the condition and their lines
+func (r *PostgresSupplierReturnRepository) getReturns(ctx context.Context, condition string, params ...interface{}) ([]*models.SupplierReturn, error) {
+	query := `
+        SELECT
+            sr.supplier_return_id, sr.return_number, sr.supplier_id,
+            sr.invoice_number, sr.rma_number, sr.status, sr.reason_code,
+            sr.requested_at, sr.shipped_at, sr.credited_at,
+            sr.credit_note_number, sr.credited_amount, sr.requested_by,
+            sr.notes, sr.created_at, sr.updated_at,
+            s.name as supplier_name
+        FROM supplier_returns sr
+        JOIN suppliers s ON sr.supplier_id = s.supplier_id
+        WHERE ` + condition + `
+        ORDER BY sr.requested_at DESC, sr.supplier_return_id DESC
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	returns := []*models.SupplierReturn{}
+	returnsByID := make(map[int]*models.SupplierReturn)
+	returnIDs := []int{}
+	for rows.Next() {
+		sr := &models.SupplierReturn{Lines: []*models.SupplierReturnLine{}}
+		err := rows.Scan(
+			&sr.SupplierReturnID,
+			&sr.ReturnNumber,
+			&sr.SupplierID,
+			&sr.InvoiceNumber,
+			&sr.RMANumber,
+			&sr.Status,
+			&sr.ReasonCode,
+			&sr.RequestedAt,
+			&sr.ShippedAt,
+			&sr.CreditedAt,
+			&sr.CreditNoteNumber,
+			&sr.CreditedAmount,
+			&sr.RequestedBy,
+			&sr.Notes,
+			&sr.CreatedAt,
+			&sr.UpdatedAt,
+			&sr.SupplierName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		returns = append(returns, sr)
+		returnsByID[sr.SupplierReturnID] = sr
+		returnIDs = append(returnIDs, sr.SupplierReturnID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(returnIDs) == 0 {
+		return returns, nil
+	}
+
+	lineQuery := `
+        SELECT
+            l.line_id, l.supplier_return_id, l.purchase_id, l.item_id,
+            l.quantity, l.cost_per_unit, l.notes, l.created_at,
+            i.part_number as item_part_number,
+            i.description as item_description
+        FROM supplier_return_lines l
+        JOIN items i ON l.item_id = i.item_id
+        WHERE l.supplier_return_id = ANY($1)
+        ORDER BY l.supplier_return_id, l.line_id
+    `
+
+	lineRows, err := r.db.Pool.Query(ctx, lineQuery, returnIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer lineRows.Close()
+
+	for lineRows.Next() {
+		line := &models.SupplierReturnLine{}
+		err := lineRows.Scan(
+			&line.LineID,
+			&line.SupplierReturnID,
+			&line.PurchaseID,
+			&line.ItemID,
+			&line.Quantity,
+			&line.CostPerUnit,
+			&line.Notes,
+			&line.CreatedAt,
+			&line.ItemPartNumber,
+			&line.ItemDescription,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if sr, ok := returnsByID[line.SupplierReturnID]; ok {
+			sr.Lines = append(sr.Lines, line)
+			sr.TotalAmount += float64(line.Quantity) * line.CostPerUnit
+		}
+	}
+
+	return returns, lineRows.Err()
+}
+
+// lockReturn reads the status of a supplier return and locks it for the
+// rest of the transaction
+func lockReturn(ctx context.Context, tx pgx.Tx, id int) (string, error) {
+	var status string
+	err := tx.QueryRow(ctx,
+		`SELECT status FROM supplier_returns WHERE supplier_return_id = $1 FOR UPDATE`,
+		id,
+	).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", purchaseErrors.ErrSupplierReturnNotFound
+		}
+		return "", err
+	}
+
+	return status, nil
+}
+
+// postReturnMovements posts one ledger movement per return line, in the
+// warehouse the returned purchase was received into. Direction -1 takes the
+// parts out of stock, 1 puts them back at the cost they were bought at.
+func postReturnMovements(ctx context.Context, tx pgx.Tx, id, direction int, performedBy *string, notes string) error {
+	rows, err := tx.Query(ctx,
+		`SELECT l.line_id, l.item_id, l.quantity, p.warehouse_id, p.cost_per_unit
+		 FROM supplier_return_lines l
+		 JOIN purchases p ON l.purchase_id = p.purchase_id
+		 WHERE l.supplier_return_id = $1 ORDER BY l.item_id, l.line_id`,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	referenceType := stockModels.ReferenceTypeSupplierReturn
+	var movements []*stockModels.StockMovement
+	for rows.Next() {
+		var lineID, itemID, quantity, warehouseID int
+		var costPerUnit float64
+		if err := rows.Scan(&lineID, &itemID, &quantity, &warehouseID, &costPerUnit); err != nil {
+			rows.Close()
+			return err
+		}
+
+		var unitCost *float64
+		if direction > 0 {
+			unitCost = &costPerUnit
+		}
+		movements = append(movements, &stockModels.StockMovement{
+			ItemID:          itemID,
+			WarehouseID:     warehouseID,
+			MovementType:    stockModels.MovementTypeSupplierReturn,
+			Quantity:        direction * quantity,
+			ReferenceType:   &referenceType,
+			ReferenceID:     &id,
+			ReferenceLineID: &lineID,
+			PerformedBy:     performedBy,
+			Notes:           &notes,
+			UnitCost:        unitCost,
+		})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return stock.PostMovements(ctx, tx, movements)
+}
+
+// resolveReturnPurchase picks the purchase a line without one sends back:
+// the item on the return's invoice from the return's supplier, skipping
+// purchases already on the return or fully sent back. The first purchase
+// with enough left for the line wins. The same invoice number from several
+// suppliers cannot be told apart when the return names no supplier.
+func resolveReturnPurchase(ctx context.Context, tx pgx.Tx, supplierReturn *models.SupplierReturn, line *models.SupplierReturnLine, seen map[int]bool) (int, error) {
+	rows, err := tx.Query(ctx, `
+        SELECT p.purchase_id, p.supplier_id, p.quantity - COALESCE((
+            SELECT SUM(l.quantity)
+            FROM supplier_return_lines l
+            JOIN supplier_returns sr ON l.supplier_return_id = sr.supplier_return_id
+            WHERE l.purchase_id = p.purchase_id AND sr.status <> 'rejected'
+        ), 0)
+        FROM purchases p
+        WHERE p.invoice_number = $1 AND p.item_id = $2
+        AND ($3 = 0 OR p.supplier_id = $3)
+        ORDER BY p.purchase_id
+    `, *supplierReturn.InvoiceNumber, line.ItemID, supplierReturn.SupplierID)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	found := false
+	purchaseID, fallbackID := 0, 0
+	suppliers := make(map[int]bool)
+	for rows.Next() {
+		var id, supplierID, returnable int
+		if err := rows.Scan(&id, &supplierID, &returnable); err != nil {
+			return 0, err
+		}
+		found = true
+		if seen[id] || returnable <= 0 {
+			continue
+		}
+		suppliers[supplierID] = true
+		if fallbackID == 0 {
+			fallbackID = id
+		}
+		if purchaseID == 0 && returnable >= line.Quantity {
+			purchaseID = id
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case !found, len(suppliers) > 1:
+		return 0, purchaseErrors.ErrReturnLineNotResolved
+	case fallbackID == 0:
+		return 0, purchaseErrors.ErrReturnExceedsPurchased
+	case purchaseID == 0:
+		// Nothing has enough left; the quantity check reports it
+		return fallbackID, nil
+	}
+
+	return purchaseID, nil
+}
+
+// returnedQuantity is the quantity of a purchase on supplier returns that
+// were not rejected
+func returnedQuantity(ctx context.Context, tx pgx.Tx, purchaseID int) (int, error) {
+	var quantity int
+	err := tx.QueryRow(ctx, `
+        SELECT COALESCE(SUM(l.quantity), 0)
+        FROM supplier_return_lines l
+        JOIN supplier_returns sr ON l.supplier_return_id = sr.supplier_return_id
+        WHERE l.purchase_id = $1 AND sr.status <> 'rejected'
+    `, purchaseID).Scan(&quantity)
+
+	return quantity, err
+}
+
+// returnStockError translates ledger errors raised while shipping a return
+func returnStockError(err error) error {
+	return wrapStockError(err, purchaseErrors.ErrInsufficientStockForReturn)
+}
@@ -12,6 +12,8 @@ type PurchaseRepository interface {
 	Create(ctx context.Context, purchase *models.Purchase) (int, error)
 	Update(ctx context.Context, purchase *models.Purchase) error
 	Delete(ctx context.Context, id int) error
+	// CanDelete runs Delete in a transaction that is always rolled back
+	CanDelete(ctx context.Context, id int) error
 	GetByInvoiceNumber(ctx context.Context, invoiceNumber string) (*models.Purchase, error)
 	GetSupplierPurchases(ctx context.Context, supplierID int) ([]*models.Purchase, error)
 	GetItemPurchases(ctx context.Context, itemID int) ([]*models.Purchase, error)
@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
+)
+
+type PurchaseOrderRepository interface {
+	GetAll(ctx context.Context, filter *models.PurchaseOrderFilter) ([]*models.PurchaseOrder, error)
+	GetByID(ctx context.Context, id int) (*models.PurchaseOrder, error)
+	GetByPONumber(ctx context.Context, poNumber string) (*models.PurchaseOrder, error)
+	Create(ctx context.Context, order *models.PurchaseOrder) (int, error)
+	Update(ctx context.Context, order *models.PurchaseOrder) error
+	UpdateStatus(ctx context.Context, id int, status string) error
+	Delete(ctx context.Context, id int) error
+	CreateReceipt(ctx context.Context, receipt *models.GoodsReceipt) (int, error)
+	GetReceiptByID(ctx context.Context, id int) (*models.GoodsReceipt, error)
+	GetReceipts(ctx context.Context, poID int) ([]*models.GoodsReceipt, error)
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
+)
+
+type ReorderRepository interface {
+	GetCandidates(ctx context.Context, filter *models.ReorderFilter) ([]*models.ReorderSuggestion, error)
+	CreateDraftOrders(ctx context.Context, orders []*models.PurchaseOrder) error
+}
@@ -1,6 +1,8 @@
 package purchases
 
 import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
 	"github.com/hsrvms/fixparts/internal/modules/purchases/handlers"
 	"github.com/hsrvms/fixparts/internal/modules/purchases/repositories"
 	"github.com/hsrvms/fixparts/internal/modules/purchases/services"
@@ -13,13 +15,53 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
 	service := services.NewPurchaseService(repo)
 	handler := handlers.NewPurchaseHandler(service)
 
-	purchases := api.Group("/purchases")
+	purchases := api.Group("/purchases", authMiddleware.Guard(authModels.PermPurchasesRead, authModels.PermPurchasesWrite))
 	purchases.GET("", handler.GetPurchases)
 	purchases.GET("/:id", handler.GetPurchaseByID)
 	purchases.POST("", handler.CreatePurchase)
 	purchases.PUT("/:id", handler.UpdatePurchase)
 	purchases.DELETE("/:id", handler.DeletePurchase)
 
-	api.GET("/suppliers/:supplierId/purchases", handler.GetSupplierPurchases)
-	api.GET("/items/:itemId/purchases", handler.GetItemPurchases)
+	api.GET("/suppliers/:supplierId/purchases", handler.GetSupplierPurchases, authMiddleware.Require(authModels.PermPurchasesRead))
+	api.GET("/items/:itemId/purchases", handler.GetItemPurchases, authMiddleware.Require(authModels.PermPurchasesRead))
+
+	orderRepo := repositories.NewPostgresPurchaseOrderRepository(database)
+	orderService := services.NewPurchaseOrderService(orderRepo)
+	orderHandler := handlers.NewPurchaseOrderHandler(orderService, service)
+
+	orders := api.Group("/purchase-orders", authMiddleware.Guard(authModels.PermPurchasesRead, authModels.PermPurchasesWrite))
+	orders.GET("", orderHandler.GetPurchaseOrders)
+	orders.GET("/:id", orderHandler.GetPurchaseOrderByID)
+	orders.POST("", orderHandler.CreatePurchaseOrder)
+	orders.PUT("/:id", orderHandler.UpdatePurchaseOrder)
+	orders.DELETE("/:id", orderHandler.DeletePurchaseOrder)
+	orders.POST("/:id/send", orderHandler.SendPurchaseOrder)
+	orders.POST("/:id/cancel", orderHandler.CancelPurchaseOrder)
+	orders.GET("/:id/receipts", orderHandler.GetPurchaseOrderReceipts)
+	orders.POST("/:id/receipts", orderHandler.ReceiveGoods)
+
+	api.GET("/goods-receipts/:id", orderHandler.GetGoodsReceiptByID, authMiddleware.Require(authModels.PermPurchasesRead))
+
+	reorderRepo := repositories.NewPostgresReorderRepository(database)
+	reorderService := services.NewReorderService(reorderRepo)
+	reorderHandler := handlers.NewReorderHandler(reorderService)
+
+	reorder := api.Group("/reorder", authMiddleware.Guard(authModels.PermPurchasesRead, authModels.PermPurchasesWrite))
+	reorder.GET("/suggestions", reorderHandler.GetReorderSuggestions)
+	reorder.POST("/orders", reorderHandler.CreateReorderDrafts)
+
+	returnRepo := repositories.NewPostgresSupplierReturnRepository(database)
+	returnService := services.NewSupplierReturnService(returnRepo)
+	returnHandler := handlers.NewSupplierReturnHandler(returnService)
+
+	returns := api.Group("/supplier-returns", authMiddleware.Guard(authModels.PermPurchasesRead, authModels.PermPurchasesWrite))
+	returns.GET("", returnHandler.GetSupplierReturns)
+	returns.GET("/:id", returnHandler.GetSupplierReturnByID)
+	returns.POST("", returnHandler.CreateSupplierReturn)
+	returns.DELETE("/:id", returnHandler.DeleteSupplierReturn)
+	returns.POST("/:id/ship", returnHandler.ShipSupplierReturn)
+	returns.POST("/:id/credit", returnHandler.CreditSupplierReturn)
+	returns.POST("/:id/reject", returnHandler.RejectSupplierReturn)
+
+	api.GET("/suppliers/:supplierId/returns", returnHandler.GetSupplierReturnsOfSupplier, authMiddleware.Require(authModels.PermPurchasesRead))
 }
@@ -12,6 +12,8 @@ type PurchaseService interface {
 	Create(ctx context.Context, purchase *models.Purchase) (int, error)
 	Update(ctx context.Context, purchase *models.Purchase) error
 	Delete(ctx context.Context, id int) error
+	// CanDelete runs the checks of Delete without deleting anything
+	CanDelete(ctx context.Context, id int) error
 	GetSupplierPurchases(ctx context.Context, supplierID int) ([]*models.Purchase, error)
 	GetItemPurchases(ctx context.Context, itemID int) ([]*models.Purchase, error)
 }
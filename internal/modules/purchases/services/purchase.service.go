@@ -61,6 +61,11 @@ func (s *purchaseService) Create(ctx context.Context, purchase *models.Purchase)
 		purchase.Date = time.Now()
 	}
 
+	// Purchases created here are direct deliveries; goods receipts link
+	// themselves to purchase orders
+	purchase.ReceiptID = nil
+	purchase.POLineID = nil
+
 	// Calculate total cost if not provided
 	if purchase.TotalCost == 0 {
 		purchase.TotalCost = float64(purchase.Quantity) * purchase.CostPerUnit
@@ -88,6 +93,19 @@ func (s *purchaseService) Update(ctx context.Context, purchase *models.Purchase)
 		return purchaseErrors.ErrPurchaseNotFound
 	}
 
+	// Keep the purchase in its warehouse if none is given
+	if purchase.WarehouseID == 0 {
+		purchase.WarehouseID = existing.WarehouseID
+	}
+
+	// Goods receipt lines stay tied to their purchase order line and receipt,
+	// whose received quantity and status they make up
+	if existing.POLineID != nil &&
+		(purchase.SupplierID != existing.SupplierID || purchase.ItemID != existing.ItemID ||
+			purchase.WarehouseID != existing.WarehouseID || purchase.Quantity != existing.Quantity) {
+		return purchaseErrors.ErrReceiptPurchaseLocked
+	}
+
 	// Check if invoice number is unique if changed
 	if purchase.InvoiceNumber != nil && *purchase.InvoiceNumber != "" &&
 		(existing.InvoiceNumber == nil || *purchase.InvoiceNumber != *existing.InvoiceNumber) {
@@ -107,6 +125,24 @@ func (s *purchaseService) Update(ctx context.Context, purchase *models.Purchase)
 }
 
 func (s *purchaseService) Delete(ctx context.Context, id int) error {
+	if err := s.checkDelete(ctx, id); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *purchaseService) CanDelete(ctx context.Context, id int) error {
+	if err := s.checkDelete(ctx, id); err != nil {
+		return err
+	}
+
+	return s.repo.CanDelete(ctx, id)
+}
+
+// checkDelete rejects deleting a purchase that is missing or was posted by a
+// goods receipt
+func (s *purchaseService) checkDelete(ctx context.Context, id int) error {
 	if id <= 0 {
 		return purchaseErrors.ErrInvalidPurchaseID
 	}
@@ -119,8 +155,11 @@ func (s *purchaseService) Delete(ctx context.Context, id int) error {
 	if existing == nil {
 		return purchaseErrors.ErrPurchaseNotFound
 	}
+	if existing.POLineID != nil {
+		return purchaseErrors.ErrReceiptPurchaseLocked
+	}
 
-	return s.repo.Delete(ctx, id)
+	return nil
 }
 
 func (s *purchaseService) GetSupplierPurchases(ctx context.Context, supplierID int) ([]*models.Purchase, error) {
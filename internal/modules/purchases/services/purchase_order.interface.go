@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
+)
+
+type PurchaseOrderService interface {
+	GetAll(ctx context.Context, filter *models.PurchaseOrderFilter) ([]*models.PurchaseOrder, error)
+	GetByID(ctx context.Context, id int) (*models.PurchaseOrder, error)
+	Create(ctx context.Context, order *models.PurchaseOrder) (int, error)
+	Update(ctx context.Context, order *models.PurchaseOrder) error
+	Delete(ctx context.Context, id int) error
+	Send(ctx context.Context, id int) error
+	Cancel(ctx context.Context, id int) error
+	ReceiveGoods(ctx context.Context, receipt *models.GoodsReceipt) (int, error)
+	GetReceiptByID(ctx context.Context, id int) (*models.GoodsReceipt, error)
+	GetReceipts(ctx context.Context, poID int) ([]*models.GoodsReceipt, error)
+}
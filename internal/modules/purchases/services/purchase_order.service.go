@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	purchaseErrors "github.com/hsrvms/fixparts/internal/modules/purchases/errors"
+	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
+	"github.com/hsrvms/fixparts/internal/modules/purchases/repositories"
+)
+
+type purchaseOrderService struct {
+	repo repositories.PurchaseOrderRepository
+}
+
+func NewPurchaseOrderService(repo repositories.PurchaseOrderRepository) PurchaseOrderService {
+	return &purchaseOrderService{
+		repo: repo,
+	}
+}
+
+func (s *purchaseOrderService) GetAll(ctx context.Context, filter *models.PurchaseOrderFilter) ([]*models.PurchaseOrder, error) {
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *purchaseOrderService) GetByID(ctx context.Context, id int) (*models.PurchaseOrder, error) {
+	if id <= 0 {
+		return nil, purchaseErrors.ErrInvalidPurchaseOrderID
+	}
+
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, purchaseErrors.ErrPurchaseOrderNotFound
+	}
+
+	return order, nil
+}
+
+func (s *purchaseOrderService) Create(ctx context.Context, order *models.PurchaseOrder) (int, error) {
+	if err := validateOrder(order); err != nil {
+		return 0, err
+	}
+
+	// Generate an order number if not provided, otherwise check it is unique
+	if order.PONumber == "" {
+		poNumber, err := generateDocumentNumber("PO")
+		if err != nil {
+			return 0, fmt.Errorf("failed to generate purchase order number: %w", err)
+		}
+		order.PONumber = poNumber
+	} else {
+		existing, err := s.repo.GetByPONumber(ctx, order.PONumber)
+		if err != nil {
+			return 0, err
+		}
+		if existing != nil {
+			return 0, purchaseErrors.ErrDuplicatePONumber
+		}
+	}
+
+	// New orders always start as drafts
+	order.Status = models.POStatusDraft
+
+	if order.OrderDate.IsZero() {
+		order.OrderDate = time.Now()
+	}
+
+	return s.repo.Create(ctx, order)
+}
+
+func (s *purchaseOrderService) Update(ctx context.Context, order *models.PurchaseOrder) error {
+	if order.POID <= 0 {
+		return purchaseErrors.ErrInvalidPurchaseOrderID
+	}
+
+	if err := validateOrder(order); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetByID(ctx, order.POID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return purchaseErrors.ErrPurchaseOrderNotFound
+	}
+	if existing.Status != models.POStatusDraft {
+		return purchaseErrors.ErrOrderNotEditable
+	}
+
+	if order.PONumber == "" {
+		order.PONumber = existing.PONumber
+	}
+	if order.PONumber != existing.PONumber {
+		existingByNumber, err := s.repo.GetByPONumber(ctx, order.PONumber)
+		if err != nil {
+			return err
+		}
+		if existingByNumber != nil && existingByNumber.POID != order.POID {
+			return purchaseErrors.ErrDuplicatePONumber
+		}
+	}
+
+	if order.OrderDate.IsZero() {
+		order.OrderDate = existing.OrderDate
+	}
+	order.Status = existing.Status
+
+	return s.repo.Update(ctx, order)
+}
+
+func (s *purchaseOrderService) Delete(ctx context.Context, id int) error {
+	if id <= 0 {
+		return purchaseErrors.ErrInvalidPurchaseOrderID
+	}
+
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return purchaseErrors.ErrPurchaseOrderNotFound
+	}
+	if existing.Status != models.POStatusDraft {
+		return purchaseErrors.ErrOrderNotEditable
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// Send marks a draft order as sent to the supplier
+func (s *purchaseOrderService) Send(ctx context.Context, id int) error {
+	return s.changeStatus(ctx, id, models.POStatusSent, models.POStatusDraft)
+}
+
+// Cancel cancels an order that has not been fully received. Goods already
+// received against it stay in stock.
+func (s *purchaseOrderService) Cancel(ctx context.Context, id int) error {
+	return s.changeStatus(ctx, id, models.POStatusCancelled,
+		models.POStatusDraft, models.POStatusSent, models.POStatusPartiallyReceived)
+}
+
+func (s *purchaseOrderService) ReceiveGoods(ctx context.Context, receipt *models.GoodsReceipt) (int, error) {
+	if receipt.POID <= 0 {
+		return 0, purchaseErrors.ErrInvalidPurchaseOrderID
+	}
+	if len(receipt.Lines) == 0 {
+		return 0, purchaseErrors.ErrNoReceiptLines
+	}
+	for _, line := range receipt.Lines {
+		if line.POLineID <= 0 {
+			return 0, purchaseErrors.ErrOrderLineNotFound
+		}
+		if line.Quantity <= 0 {
+			return 0, purchaseErrors.ErrInvalidQuantity
+		}
+		if line.CostPerUnit < 0 {
+			return 0, purchaseErrors.ErrInvalidCostPerUnit
+		}
+	}
+	if !receipt.Date.IsZero() && receipt.Date.After(time.Now()) {
+		return 0, purchaseErrors.ErrInvalidDate
+	}
+
+	order, err := s.repo.GetByID(ctx, receipt.POID)
+	if err != nil {
+		return 0, err
+	}
+	if order == nil {
+		return 0, purchaseErrors.ErrPurchaseOrderNotFound
+	}
+	if order.Status != models.POStatusSent && order.Status != models.POStatusPartiallyReceived {
+		return 0, purchaseErrors.ErrOrderNotReceivable
+	}
+
+	receiptNumber, err := generateDocumentNumber("GR")
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate goods receipt number: %w", err)
+	}
+	receipt.ReceiptNumber = receiptNumber
+
+	if receipt.Date.IsZero() {
+		receipt.Date = time.Now()
+	}
+
+	return s.repo.CreateReceipt(ctx, receipt)
+}
+
+func (s *purchaseOrderService) GetReceiptByID(ctx context.Context, id int) (*models.GoodsReceipt, error) {
+	if id <= 0 {
+		return nil, purchaseErrors.ErrInvalidReceiptID
+	}
+
+	receipt, err := s.repo.GetReceiptByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, purchaseErrors.ErrReceiptNotFound
+	}
+
+	return receipt, nil
+}
+
+func (s *purchaseOrderService) GetReceipts(ctx context.Context, poID int) ([]*models.GoodsReceipt, error) {
+	if poID <= 0 {
+		return nil, purchaseErrors.ErrInvalidPurchaseOrderID
+	}
+
+	return s.repo.GetReceipts(ctx, poID)
+}
+
+// Helper functions
+func (s *purchaseOrderService) changeStatus(ctx context.Context, id int, status string, allowedFrom ...string) error {
+	if id <= 0 {
+		return purchaseErrors.ErrInvalidPurchaseOrderID
+	}
+
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		return purchaseErrors.ErrPurchaseOrderNotFound
+	}
+
+	for _, from := range allowedFrom {
+		if order.Status == from {
+			return s.repo.UpdateStatus(ctx, id, status)
+		}
+	}
+
+	return purchaseErrors.ErrInvalidStatusTransition
+}
+
+func validateOrder(order *models.PurchaseOrder) error {
+	if order.SupplierID <= 0 {
+		return purchaseErrors.ErrInvalidSupplierID
+	}
+	if len(order.Lines) == 0 {
+		return purchaseErrors.ErrNoOrderLines
+	}
+
+	seen := make(map[int]bool, len(order.Lines))
+	for _, line := range order.Lines {
+		if line.ItemID <= 0 {
+			return purchaseErrors.ErrInvalidItemID
+		}
+		if line.QuantityOrdered <= 0 {
+			return purchaseErrors.ErrInvalidQuantity
+		}
+		if line.CostPerUnit <= 0 {
+			return purchaseErrors.ErrInvalidCostPerUnit
+		}
+		if seen[line.ItemID] {
+			return purchaseErrors.ErrDuplicateOrderItem
+		}
+		seen[line.ItemID] = true
+	}
+
+	return nil
+}
+
+func generateDocumentNumber(prefix string) (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(10000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%s-%04d", prefix, time.Now().Format("060102150405"), n.Int64()), nil
+}
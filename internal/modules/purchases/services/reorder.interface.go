@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
+)
+
+type ReorderService interface {
+	GetSuggestions(ctx context.Context, filter *models.ReorderFilter) (*models.ReorderPlan, error)
+	CreateDraftOrders(ctx context.Context, request *models.ReorderRequest) ([]*models.PurchaseOrder, error)
+}
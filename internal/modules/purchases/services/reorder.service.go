@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	purchaseErrors "github.com/hsrvms/fixparts/internal/modules/purchases/errors"
+	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
+	"github.com/hsrvms/fixparts/internal/modules/purchases/repositories"
+)
+
+type reorderService struct {
+	repo repositories.ReorderRepository
+}
+
+func NewReorderService(repo repositories.ReorderRepository) ReorderService {
+	return &reorderService{
+		repo: repo,
+	}
+}
+
+// GetSuggestions computes the items to reorder and groups them by supplier
+func (s *reorderService) GetSuggestions(ctx context.Context, filter *models.ReorderFilter) (*models.ReorderPlan, error) {
+	if filter == nil {
+		filter = &models.ReorderFilter{}
+	}
+	if filter.Days == 0 {
+		filter.Days = models.DefaultReorderDays
+	}
+	if filter.Days < 1 || filter.Days > 365 {
+		return nil, purchaseErrors.ErrInvalidReorderDays
+	}
+
+	candidates, err := s.repo.GetCandidates(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &models.ReorderPlan{
+		Days:       filter.Days,
+		Suppliers:  []*models.SupplierReorder{},
+		Unassigned: []*models.ReorderSuggestion{},
+		Unpriced:   []*models.ReorderSuggestion{},
+	}
+	groups := make(map[int]*models.SupplierReorder)
+
+	for _, candidate := range candidates {
+		if !suggestReorder(candidate, filter.Days) {
+			continue
+		}
+
+		if candidate.SupplierID == nil {
+			plan.Unassigned = append(plan.Unassigned, candidate)
+			continue
+		}
+		if candidate.CostPerUnit <= 0 {
+			plan.Unpriced = append(plan.Unpriced, candidate)
+			continue
+		}
+
+		group, ok := groups[*candidate.SupplierID]
+		if !ok {
+			group = &models.SupplierReorder{
+				SupplierID:   *candidate.SupplierID,
+				LeadTimeDays: candidate.LeadTimeDays,
+				Lines:        []*models.ReorderSuggestion{},
+			}
+			if candidate.SupplierName != nil {
+				group.SupplierName = *candidate.SupplierName
+			}
+			groups[group.SupplierID] = group
+			plan.Suppliers = append(plan.Suppliers, group)
+		}
+		group.Lines = append(group.Lines, candidate)
+		group.TotalAmount = roundCurrency(group.TotalAmount + candidate.LineTotal)
+	}
+
+	sort.SliceStable(plan.Suppliers, func(a, b int) bool {
+		return plan.Suppliers[a].SupplierName < plan.Suppliers[b].SupplierName
+	})
+
+	return plan, nil
+}
+
+// CreateDraftOrders creates one draft purchase order per supplier from the
+// current suggestions. Drafts do not change stock; they are reviewed, sent
+// and received like any other purchase order.
+func (s *reorderService) CreateDraftOrders(ctx context.Context, request *models.ReorderRequest) ([]*models.PurchaseOrder, error) {
+	plan, err := s.GetSuggestions(ctx, &models.ReorderFilter{Days: request.Days})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	orders := []*models.PurchaseOrder{}
+	for _, group := range plan.Suppliers {
+		if len(request.SupplierIDs) > 0 && !slices.Contains(request.SupplierIDs, group.SupplierID) {
+			continue
+		}
+
+		poNumber, err := generateDocumentNumber("PO")
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate purchase order number: %w", err)
+		}
+
+		expectedDate := now.AddDate(0, 0, group.LeadTimeDays)
+		order := &models.PurchaseOrder{
+			PONumber:     poNumber,
+			SupplierID:   group.SupplierID,
+			Status:       models.POStatusDraft,
+			OrderDate:    now,
+			ExpectedDate: &expectedDate,
+			CreatedBy:    request.CreatedBy,
+			Notes:        request.Notes,
+			SupplierName: group.SupplierName,
+			TotalAmount:  group.TotalAmount,
+		}
+		for _, suggestion := range group.Lines {
+			order.Lines = append(order.Lines, &models.PurchaseOrderLine{
+				ItemID:              suggestion.ItemID,
+				QuantityOrdered:     suggestion.SuggestedQuantity,
+				CostPerUnit:         suggestion.CostPerUnit,
+				QuantityOutstanding: suggestion.SuggestedQuantity,
+				ItemPartNumber:      suggestion.ItemPartNumber,
+				ItemDescription:     suggestion.ItemDescription,
+			})
+		}
+		if err := validateOrder(order); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	if len(orders) == 0 {
+		return nil, purchaseErrors.ErrNothingToReorder
+	}
+
+	if err := s.repo.CreateDraftOrders(ctx, orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// suggestReorder fills in the computed fields of the candidate and reports
+// whether it should be ordered. The reorder point covers the minimum stock
+// plus the expected sales until a new order arrives; once the stock position
+// drops to it, the item is ordered up to the reorder-up-to level, raised to
+// the reorder point when sales over the lead time exceed that level.
+func suggestReorder(candidate *models.ReorderSuggestion, days int) bool {
+	candidate.DailyVelocity = math.Round(float64(max(candidate.QuantitySold, 0))/float64(days)*100) / 100
+	leadTimeDemand := int(math.Ceil(float64(max(candidate.QuantitySold, 0)) * float64(candidate.LeadTimeDays) / float64(days)))
+	candidate.ReorderPoint = candidate.MinimumStock + leadTimeDemand
+
+	position := candidate.CurrentStock + candidate.OnOrder + candidate.InTransit
+	if position > candidate.ReorderPoint {
+		return false
+	}
+
+	candidate.SuggestedQuantity = max(candidate.ReorderUpTo, candidate.ReorderPoint) - position
+	if candidate.SuggestedQuantity <= 0 {
+		return false
+	}
+
+	candidate.LineTotal = roundCurrency(float64(candidate.SuggestedQuantity) * candidate.CostPerUnit)
+	return true
+}
+
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
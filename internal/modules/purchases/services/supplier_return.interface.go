@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
+)
+
+type SupplierReturnService interface {
+	GetAll(ctx context.Context, filter *models.SupplierReturnFilter) ([]*models.SupplierReturn, error)
+	GetByID(ctx context.Context, id int) (*models.SupplierReturn, error)
+	Create(ctx context.Context, supplierReturn *models.SupplierReturn) (int, error)
+	Delete(ctx context.Context, id int) error
+	Ship(ctx context.Context, id int, update *models.SupplierReturnUpdate) error
+	Credit(ctx context.Context, id int, update *models.SupplierReturnUpdate) error
+	Reject(ctx context.Context, id int, update *models.SupplierReturnUpdate) error
+}
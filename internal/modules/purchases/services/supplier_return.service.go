@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	purchaseErrors "github.com/hsrvms/fixparts/internal/modules/purchases/errors"
+	"github.com/hsrvms/fixparts/internal/modules/purchases/models"
+	"github.com/hsrvms/fixparts/internal/modules/purchases/repositories"
+)
+
+type supplierReturnService struct {
+	repo repositories.SupplierReturnRepository
+}
+
+func NewSupplierReturnService(repo repositories.SupplierReturnRepository) SupplierReturnService {
+	return &supplierReturnService{
+		repo: repo,
+	}
+}
+
+func (s *supplierReturnService) GetAll(ctx context.Context, filter *models.SupplierReturnFilter) ([]*models.SupplierReturn, error) {
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *supplierReturnService) GetByID(ctx context.Context, id int) (*models.SupplierReturn, error) {
+	if id <= 0 {
+		return nil, purchaseErrors.ErrInvalidSupplierReturnID
+	}
+
+	supplierReturn, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if supplierReturn == nil {
+		return nil, purchaseErrors.ErrSupplierReturnNotFound
+	}
+
+	return supplierReturn, nil
+}
+
+func (s *supplierReturnService) Create(ctx context.Context, supplierReturn *models.SupplierReturn) (int, error) {
+	if err := s.validateReturn(supplierReturn); err != nil {
+		return 0, err
+	}
+
+	returnNumber, err := generateDocumentNumber("RMA")
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate supplier return number: %w", err)
+	}
+	supplierReturn.ReturnNumber = returnNumber
+
+	// New returns always start as requested
+	supplierReturn.Status = models.SupplierReturnRequested
+
+	if supplierReturn.RequestedAt.IsZero() {
+		supplierReturn.RequestedAt = time.Now()
+	}
+
+	return s.repo.Create(ctx, supplierReturn)
+}
+
+func (s *supplierReturnService) Delete(ctx context.Context, id int) error {
+	if id <= 0 {
+		return purchaseErrors.ErrInvalidSupplierReturnID
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// Ship sends a requested return to the supplier, taking the parts out of stock
+func (s *supplierReturnService) Ship(ctx context.Context, id int, update *models.SupplierReturnUpdate) error {
+	if err := s.validateUpdate(id, update); err != nil {
+		return err
+	}
+
+	return s.repo.Ship(ctx, id, update)
+}
+
+// Credit records the supplier's credit note against a shipped return
+func (s *supplierReturnService) Credit(ctx context.Context, id int, update *models.SupplierReturnUpdate) error {
+	if err := s.validateUpdate(id, update); err != nil {
+		return err
+	}
+
+	if update.CreditNoteNumber == nil || strings.TrimSpace(*update.CreditNoteNumber) == "" {
+		return purchaseErrors.ErrCreditNoteNeeded
+	}
+	if update.CreditedAmount == nil || *update.CreditedAmount < 0 {
+		return purchaseErrors.ErrInvalidCreditedAmount
+	}
+
+	return s.repo.Credit(ctx, id, update)
+}
+
+// Reject closes a return the supplier refused
+func (s *supplierReturnService) Reject(ctx context.Context, id int, update *models.SupplierReturnUpdate) error {
+	if err := s.validateUpdate(id, update); err != nil {
+		return err
+	}
+
+	return s.repo.Reject(ctx, id, update)
+}
+
+// Helper functions
+func (s *supplierReturnService) validateReturn(supplierReturn *models.SupplierReturn) error {
+	if supplierReturn.SupplierID < 0 {
+		return purchaseErrors.ErrInvalidSupplierID
+	}
+	if len(supplierReturn.Lines) == 0 {
+		return purchaseErrors.ErrNoReturnLines
+	}
+
+	switch supplierReturn.ReasonCode {
+	case models.SupplierReturnReasonDefective, models.SupplierReturnReasonWrongPart,
+		models.SupplierReturnReasonDamaged, models.SupplierReturnReasonOther:
+	default:
+		return purchaseErrors.ErrInvalidReturnReason
+	}
+
+	if supplierReturn.InvoiceNumber != nil && *supplierReturn.InvoiceNumber == "" {
+		supplierReturn.InvoiceNumber = nil
+	}
+	if !supplierReturn.RequestedAt.IsZero() && supplierReturn.RequestedAt.After(time.Now()) {
+		return purchaseErrors.ErrInvalidDate
+	}
+
+	for _, line := range supplierReturn.Lines {
+		if line.PurchaseID < 0 || line.ItemID < 0 {
+			return purchaseErrors.ErrReturnLineNotResolved
+		}
+		if line.Quantity <= 0 {
+			return purchaseErrors.ErrInvalidQuantity
+		}
+		if line.CostPerUnit < 0 {
+			return purchaseErrors.ErrInvalidCostPerUnit
+		}
+	}
+
+	return nil
+}
+
+func (s *supplierReturnService) validateUpdate(id int, update *models.SupplierReturnUpdate) error {
+	if id <= 0 {
+		return purchaseErrors.ErrInvalidSupplierReturnID
+	}
+
+	if update.Date.IsZero() {
+		update.Date = time.Now()
+	}
+	if update.Date.After(time.Now()) {
+		return purchaseErrors.ErrInvalidDate
+	}
+
+	return nil
+}
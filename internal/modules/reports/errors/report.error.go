@@ -0,0 +1,10 @@
+package reporterrors
+
+import "errors"
+
+var (
+	ErrInvalidBasis  = errors.New("classification basis must be revenue or margin")
+	ErrInvalidPeriod = errors.New("report start date must be before its end date")
+	ErrInvalidDays   = errors.New("dead stock threshold must be a positive number of days")
+	ErrInvalidMethod = errors.New("valuation method must be fifo or average")
+)
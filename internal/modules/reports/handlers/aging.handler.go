@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	reporterrors "github.com/hsrvms/fixparts/internal/modules/reports/errors"
+	"github.com/hsrvms/fixparts/internal/modules/reports/models"
+	"github.com/hsrvms/fixparts/internal/modules/reports/services"
+	"github.com/labstack/echo/v4"
+)
+
+type AgingHandler struct {
+	service services.AgingService
+}
+
+func NewAgingHandler(service services.AgingService) *AgingHandler {
+	return &AgingHandler{
+		service: service,
+	}
+}
+
+// GetAgingReport handles the inventory aging report
+func (h *AgingHandler) GetAgingReport(c echo.Context) error {
+	filter, err := parseAgingFilter(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetReport(ctx, filter)
+	if err != nil {
+		return agingError(err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// ExportAgingReport handles downloading the inventory aging report as CSV
+func (h *AgingHandler) ExportAgingReport(c echo.Context) error {
+	if !authMiddleware.FullAccess(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "the aging export shows stock at cost and needs full access")
+	}
+
+	filter, err := parseAgingFilter(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetReport(ctx, filter)
+	if err != nil {
+		return agingError(err)
+	}
+
+	filename := fmt.Sprintf("stok-yaslandirma-%s.csv", report.AsOf.Format("20060102"))
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Response().WriteHeader(http.StatusOK)
+
+	return writeAgingCSV(c.Response(), report)
+}
+
+// writeAgingCSV writes one row per item and a totals row. The byte order
+// mark lets spreadsheet programs detect UTF-8 for the Turkish headers.
+func writeAgingCSV(w io.Writer, report *models.AgingReport) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+
+	header := []string{"Parça No", "Açıklama", "Kategori", "Tedarikçi", "Stok", "Alış Fiyatı", "Stok Değeri"}
+	for _, bucket := range report.Buckets {
+		header = append(header, bucket.Label+" gün")
+	}
+	header = append(header, "Son Satış", "Son Alım", "Satışsız Gün", "Ölü Stok")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, item := range report.Items {
+		row := []string{
+			item.PartNumber,
+			item.Description,
+			stringValue(item.CategoryName),
+			stringValue(item.SupplierName),
+			strconv.Itoa(item.CurrentStock),
+			formatAmount(item.BuyPrice),
+			formatAmount(item.StockValue),
+		}
+		for _, bucket := range item.Buckets {
+			row = append(row, formatAmount(bucket.Value))
+		}
+
+		lastSale, lastPurchase := "", ""
+		if item.LastSaleDate != nil {
+			lastSale = item.LastSaleDate.Format("2006-01-02")
+		}
+		if item.LastPurchaseDate != nil {
+			lastPurchase = item.LastPurchaseDate.Format("2006-01-02")
+		}
+		dead := "Hayır"
+		if item.Dead {
+			dead = "Evet"
+		}
+		row = append(row, lastSale, lastPurchase, strconv.Itoa(item.IdleDays), dead)
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	total := []string{"Toplam", "", "", "", "", "", formatAmount(report.TotalValue)}
+	for _, bucket := range report.Buckets {
+		total = append(total, formatAmount(bucket.Value))
+	}
+	if err := writer.Write(total); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func parseAgingFilter(c echo.Context) (*models.AgingFilter, error) {
+	filter := &models.AgingFilter{}
+
+	if categoryID := c.QueryParam("category_id"); categoryID != "" {
+		id, err := strconv.Atoi(categoryID)
+		if err == nil {
+			filter.CategoryID = &id
+		}
+	}
+
+	if supplierID := c.QueryParam("supplier_id"); supplierID != "" {
+		id, err := strconv.Atoi(supplierID)
+		if err == nil {
+			filter.SupplierID = &id
+		}
+	}
+
+	if days := c.QueryParam("dead_stock_days"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, reporterrors.ErrInvalidDays.Error())
+		}
+		filter.DeadStockDays = n
+	}
+
+	if deadOnly := c.QueryParam("dead_only"); deadOnly == "true" {
+		filter.DeadOnly = true
+	}
+
+	return filter, nil
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// agingError maps aging report errors to HTTP errors
+func agingError(err error) error {
+	switch err {
+	case reporterrors.ErrInvalidDays:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
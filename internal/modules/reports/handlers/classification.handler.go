@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	reporterrors "github.com/hsrvms/fixparts/internal/modules/reports/errors"
+	"github.com/hsrvms/fixparts/internal/modules/reports/models"
+	"github.com/hsrvms/fixparts/internal/modules/reports/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ClassificationHandler struct {
+	service services.ClassificationService
+}
+
+func NewClassificationHandler(service services.ClassificationService) *ClassificationHandler {
+	return &ClassificationHandler{
+		service: service,
+	}
+}
+
+// GetClassification handles the ABC/XYZ classification report of the
+// active items, without storing the classes
+func (h *ClassificationHandler) GetClassification(c echo.Context) error {
+	filter := parseClassificationFilter(c)
+	if err := checkBasisAccess(c, filter); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetReport(ctx, filter)
+	if err != nil {
+		return classificationError(err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// ClassifyItems handles running the ABC/XYZ classification and storing the
+// classes on the items
+func (h *ClassificationHandler) ClassifyItems(c echo.Context) error {
+	filter := parseClassificationFilter(c)
+	if err := checkBasisAccess(c, filter); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.Classify(ctx, filter)
+	if err != nil {
+		return classificationError(err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// checkBasisAccess keeps counter access from ranking items by margin
+func checkBasisAccess(c echo.Context, filter *models.ClassificationFilter) error {
+	if filter.Basis == models.BasisMargin && !authMiddleware.FullAccess(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "classifying by margin needs full access")
+	}
+	return nil
+}
+
+func parseClassificationFilter(c echo.Context) *models.ClassificationFilter {
+	filter := &models.ClassificationFilter{
+		Basis: c.QueryParam("basis"),
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		if date, err := time.Parse(time.RFC3339, startDate); err == nil {
+			filter.StartDate = &date
+		}
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		if date, err := time.Parse(time.RFC3339, endDate); err == nil {
+			filter.EndDate = &date
+		}
+	}
+
+	return filter
+}
+
+// classificationError maps classification errors to HTTP errors
+func classificationError(err error) error {
+	switch err {
+	case reporterrors.ErrInvalidBasis, reporterrors.ErrInvalidPeriod:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
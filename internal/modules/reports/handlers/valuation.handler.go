@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	reporterrors "github.com/hsrvms/fixparts/internal/modules/reports/errors"
+	"github.com/hsrvms/fixparts/internal/modules/reports/models"
+	"github.com/hsrvms/fixparts/internal/modules/reports/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ValuationHandler struct {
+	service services.ValuationService
+}
+
+func NewValuationHandler(service services.ValuationService) *ValuationHandler {
+	return &ValuationHandler{
+		service: service,
+	}
+}
+
+// GetValuationReport handles the inventory valuation report
+func (h *ValuationHandler) GetValuationReport(c echo.Context) error {
+	filter, err := parseValuationFilter(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetReport(ctx, filter)
+	if err != nil {
+		if err == reporterrors.ErrInvalidMethod {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+func parseValuationFilter(c echo.Context) (*models.ValuationFilter, error) {
+	filter := &models.ValuationFilter{
+		Method: c.QueryParam("method"),
+	}
+
+	if asOf := c.QueryParam("as_of"); asOf != "" {
+		date, err := time.Parse(time.RFC3339, asOf)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "as_of must be an RFC 3339 date")
+		}
+		filter.AsOf = &date
+	}
+
+	if categoryID := c.QueryParam("category_id"); categoryID != "" {
+		id, err := strconv.Atoi(categoryID)
+		if err == nil {
+			filter.CategoryID = &id
+		}
+	}
+
+	if supplierID := c.QueryParam("supplier_id"); supplierID != "" {
+		id, err := strconv.Atoi(supplierID)
+		if err == nil {
+			filter.SupplierID = &id
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// DefaultDeadStockDays is how long an item must go without a sale to be
+// dead stock when no threshold is given
+const DefaultDeadStockDays = 180
+
+// Aging buckets, by days since the stock was purchased
+var AgingBuckets = []*AgingBucket{
+	{Label: "0-90", MinDays: 0, MaxDays: 90},
+	{Label: "90-180", MinDays: 90, MaxDays: 180},
+	{Label: "180-365", MinDays: 180, MaxDays: 365},
+	{Label: "365+", MinDays: 365},
+}
+
+// AgingBucket is the stock purchased between MinDays and MaxDays ago; a
+// zero MaxDays is open ended
+type AgingBucket struct {
+	Label    string  `json:"label"`
+	MinDays  int     `json:"min_days"`
+	MaxDays  int     `json:"max_days,omitempty"`
+	Quantity int     `json:"quantity"`
+	Value    float64 `json:"value" access:"full"`
+}
+
+// Contains reports whether stock of the given age falls in the bucket
+func (b *AgingBucket) Contains(days int) bool {
+	return days >= b.MinDays && (b.MaxDays == 0 || days < b.MaxDays)
+}
+
+// AgingItem is the stock of an item split by age and valued at its buy
+// price. Stock is assumed to leave first in first out, so what is on hand
+// comes from the most recent purchases; stock not covered by purchases
+// (opening stock, adjustments) is aged from when the item was created.
+type AgingItem struct {
+	ItemID           int        `json:"item_id" db:"item_id"`
+	PartNumber       string     `json:"part_number" db:"part_number"`
+	Description      string     `json:"description" db:"description"`
+	CategoryID       *int       `json:"category_id,omitempty" db:"category_id"`
+	CategoryName     *string    `json:"category_name,omitempty" db:"category_name"`
+	SupplierID       *int       `json:"supplier_id,omitempty" db:"supplier_id"`
+	SupplierName     *string    `json:"supplier_name,omitempty" db:"supplier_name"`
+	CurrentStock     int        `json:"current_stock" db:"current_stock"`
+	BuyPrice         float64    `json:"buy_price" db:"buy_price" access:"full"`
+	LastSaleDate     *time.Time `json:"last_sale_date,omitempty" db:"last_sale_date"`
+	LastPurchaseDate *time.Time `json:"last_purchase_date,omitempty" db:"last_purchase_date"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+
+	// Computed by the aging service
+	StockValue float64        `json:"stock_value" db:"-" access:"full"`
+	Buckets    []*AgingBucket `json:"buckets" db:"-"`
+	// Days since the last sale, or since the item was created if it never sold
+	IdleDays int  `json:"idle_days" db:"-"`
+	Dead     bool `json:"dead" db:"-"`
+}
+
+// PurchaseLayer is a purchase of an item still counted in its stock age
+type PurchaseLayer struct {
+	ItemID   int       `db:"item_id"`
+	Date     time.Time `db:"date"`
+	Quantity int       `db:"quantity"`
+}
+
+// AgingReport is the stock on hand by age, with the totals per bucket
+type AgingReport struct {
+	AsOf           time.Time      `json:"as_of"`
+	DeadStockDays  int            `json:"dead_stock_days"`
+	Buckets        []*AgingBucket `json:"buckets"`
+	TotalValue     float64        `json:"total_value" access:"full"`
+	DeadStockValue float64        `json:"dead_stock_value" access:"full"`
+	Items          []*AgingItem   `json:"items"`
+}
+
+type AgingFilter struct {
+	CategoryID *int `query:"category_id"`
+	SupplierID *int `query:"supplier_id"`
+	// Items without sales in this many days are dead stock
+	DeadStockDays int `query:"dead_stock_days"`
+	// Only list the dead stock
+	DeadOnly bool `query:"dead_only"`
+}
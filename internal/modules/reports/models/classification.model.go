@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// Classification bases: what an item's ABC class is ranked by
+const (
+	BasisRevenue = "revenue"
+	BasisMargin  = "margin"
+)
+
+// ABC classes by cumulative share of the revenue or margin, and XYZ classes
+// by the coefficient of variation of the weekly demand
+const (
+	ClassA = "A"
+	ClassB = "B"
+	ClassC = "C"
+	ClassX = "X"
+	ClassY = "Y"
+	ClassZ = "Z"
+
+	ClassAShare = 0.80
+	ClassBShare = 0.95
+
+	ClassXVariation = 0.5
+	ClassYVariation = 1.0
+)
+
+// DefaultClassificationDays is the period classified when none is given
+const DefaultClassificationDays = 365
+
+// ItemClassification is the ABC and XYZ class of an active item over a
+// period. Items are ranked by their contribution (revenue or margin) and
+// the ones making up the first 80% of the total are A, the next 15% B and
+// the rest C. Demand variability is the standard deviation of the weekly
+// quantity sold divided by its mean: up to 0.5 is X, up to 1 Y, and above
+// that, or without any demand, Z.
+type ItemClassification struct {
+	ItemID       int     `json:"item_id" db:"item_id"`
+	PartNumber   string  `json:"part_number" db:"part_number"`
+	Description  string  `json:"description" db:"description"`
+	CategoryName *string `json:"category_name,omitempty" db:"category_name"`
+	QuantitySold int     `json:"quantity_sold" db:"quantity_sold"`
+	Revenue      float64 `json:"revenue" db:"revenue"`
+	Margin       float64 `json:"margin" db:"margin" access:"full"`
+
+	// Computed by the classification service
+	Contribution    float64  `json:"contribution" db:"-"`
+	Share           float64  `json:"share" db:"-"`
+	CumulativeShare float64  `json:"cumulative_share" db:"-"`
+	WeeklyMean      float64  `json:"weekly_mean" db:"-"`
+	WeeklyStdDev    float64  `json:"weekly_std_dev" db:"-"`
+	Variation       *float64 `json:"variation,omitempty" db:"-"`
+	AbcClass        string   `json:"abc_class" db:"-"`
+	XyzClass        string   `json:"xyz_class" db:"-"`
+}
+
+// WeeklyDemand is the quantity of an item sold in a week of the period,
+// counted from the start of the period
+type WeeklyDemand struct {
+	ItemID   int `db:"item_id"`
+	Week     int `db:"week"`
+	Quantity int `db:"quantity"`
+}
+
+// ClassificationReport is the classification of every active item over a
+// period, highest contribution first
+type ClassificationReport struct {
+	StartDate         time.Time             `json:"start_date"`
+	EndDate           time.Time             `json:"end_date"`
+	Basis             string                `json:"basis"`
+	TotalContribution float64               `json:"total_contribution"`
+	Summary           map[string]int        `json:"summary"`
+	Items             []*ItemClassification `json:"items"`
+	Saved             bool                  `json:"saved"`
+}
+
+type ClassificationFilter struct {
+	StartDate *time.Time `query:"start_date"`
+	EndDate   *time.Time `query:"end_date"`
+	Basis     string     `query:"basis"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// ValuationItem is the stock of an item at a point in time and its value
+// under a costing method
+type ValuationItem struct {
+	ItemID       int     `json:"item_id" db:"item_id"`
+	PartNumber   string  `json:"part_number" db:"part_number"`
+	Description  string  `json:"description" db:"description"`
+	CategoryID   *int    `json:"category_id,omitempty" db:"category_id"`
+	CategoryName *string `json:"category_name,omitempty" db:"category_name"`
+	SupplierID   *int    `json:"supplier_id,omitempty" db:"supplier_id"`
+	SupplierName *string `json:"supplier_name,omitempty" db:"supplier_name"`
+	Quantity     int     `json:"quantity" db:"quantity"`
+	Value        float64 `json:"value" db:"value" access:"full"`
+
+	// Computed by the valuation service
+	UnitCost float64 `json:"unit_cost" db:"-" access:"full"`
+}
+
+// ValuationReport is the value of the stock on hand as of a date
+type ValuationReport struct {
+	AsOf          time.Time        `json:"as_of"`
+	Method        string           `json:"method"`
+	TotalQuantity int              `json:"total_quantity"`
+	TotalValue    float64          `json:"total_value" access:"full"`
+	Items         []*ValuationItem `json:"items"`
+}
+
+type ValuationFilter struct {
+	// Defaults to now
+	AsOf *time.Time `query:"as_of"`
+	// fifo or average; defaults to the costing method setting
+	Method     string `query:"method"`
+	CategoryID *int   `query:"category_id"`
+	SupplierID *int   `query:"supplier_id"`
+}
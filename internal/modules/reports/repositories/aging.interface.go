@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/reports/models"
+)
+
+type AgingRepository interface {
+	GetStockedItems(ctx context.Context, filter *models.AgingFilter) ([]*models.AgingItem, error)
+	GetPurchaseLayers(ctx context.Context, itemIDs []int) ([]*models.PurchaseLayer, error)
+}
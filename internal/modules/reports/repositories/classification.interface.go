@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/hsrvms/fixparts/internal/modules/reports/models"
+)
+
+type ClassificationRepository interface {
+	GetItemSales(ctx context.Context, start, end time.Time) ([]*models.ItemClassification, error)
+	GetWeeklyDemand(ctx context.Context, start, end time.Time) ([]*models.WeeklyDemand, error)
+	SaveClasses(ctx context.Context, items []*models.ItemClassification) error
+}
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hsrvms/fixparts/internal/modules/reports/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+)
+
+type PostgresAgingRepository struct {
+	db *db.Database
+}
+
+func NewPostgresAgingRepository(database *db.Database) AgingRepository {
+	return &PostgresAgingRepository{
+		db: database,
+	}
+}
+
+// GetStockedItems returns the items with stock on hand, with their last sale
+// and purchase dates
+func (r *PostgresAgingRepository) GetStockedItems(ctx context.Context, filter *models.AgingFilter) ([]*models.AgingItem, error) {
+	query := `
+        SELECT
+            i.item_id, i.part_number, i.description,
+            i.category_id, c.category_name,
+            i.supplier_id, s.name as supplier_name,
+            i.current_stock, i.buy_price,
+            (
+                SELECT MAX(sa.date)
+                FROM sale_lines sl
+                JOIN sales sa ON sl.sale_id = sa.sale_id
+                WHERE sl.item_id = i.item_id
+            ) as last_sale_date,
+            (
+                SELECT MAX(p.date) FROM purchases p WHERE p.item_id = i.item_id
+            ) as last_purchase_date,
+            i.created_at
+        FROM items i
+        LEFT JOIN categories c ON i.category_id = c.category_id
+        LEFT JOIN suppliers s ON i.supplier_id = s.supplier_id
+        WHERE i.current_stock > 0
+    `
+	params := []interface{}{}
+	paramCount := 1
+
+	if filter.CategoryID != nil {
+		query += fmt.Sprintf(" AND i.category_id = $%d", paramCount)
+		params = append(params, *filter.CategoryID)
+		paramCount++
+	}
+
+	if filter.SupplierID != nil {
+		query += fmt.Sprintf(" AND i.supplier_id = $%d", paramCount)
+		params = append(params, *filter.SupplierID)
+		paramCount++
+	}
+
+	query += " ORDER BY i.part_number"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.AgingItem
+	for rows.Next() {
+		item := &models.AgingItem{}
+		err := rows.Scan(
+			&item.ItemID,
+			&item.PartNumber,
+			&item.Description,
+			&item.CategoryID,
+			&item.CategoryName,
+			&item.SupplierID,
+			&item.SupplierName,
+			&item.CurrentStock,
+			&item.BuyPrice,
+			&item.LastSaleDate,
+			&item.LastPurchaseDate,
+			&item.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// GetPurchaseLayers returns the purchases of the given items, newest first
+func (r *PostgresAgingRepository) GetPurchaseLayers(ctx context.Context, itemIDs []int) ([]*models.PurchaseLayer, error) {
+	if len(itemIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+        SELECT item_id, date, quantity
+        FROM purchases
+        WHERE item_id = ANY($1)
+        ORDER BY item_id, date DESC, purchase_id DESC
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var layers []*models.PurchaseLayer
+	for rows.Next() {
+		layer := &models.PurchaseLayer{}
+		if err := rows.Scan(&layer.ItemID, &layer.Date, &layer.Quantity); err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+
+	return layers, rows.Err()
+}
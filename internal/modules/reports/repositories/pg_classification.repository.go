@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/hsrvms/fixparts/internal/modules/reports/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+)
+
+type PostgresClassificationRepository struct {
+	db *db.Database
+}
+
+func NewPostgresClassificationRepository(database *db.Database) ClassificationRepository {
+	return &PostgresClassificationRepository{
+		db: database,
+	}
+}
+
+// GetItemSales returns the quantity sold, revenue and margin of every active
+// item between start and end. It uses the joins and margin of the
+// top_selling_items view, made outer joins so items that did not sell in the
+// period, or have no category, are still classified.
+func (r *PostgresClassificationRepository) GetItemSales(ctx context.Context, start, end time.Time) ([]*models.ItemClassification, error) {
+	query := `
+        SELECT
+            i.item_id,
+            i.part_number,
+            i.description,
+            c.category_name,
+            COALESCE(SUM(sl.quantity), 0) as quantity_sold,
+            COALESCE(SUM(sl.total_price), 0) as revenue,
+            COALESCE(SUM(sl.total_price) - SUM(sl.cost_of_goods), 0) as margin
+        FROM
+            items i
+        LEFT JOIN
+            (sale_lines sl
+             JOIN sales s ON sl.sale_id = s.sale_id AND s.date >= $1 AND s.date < $2)
+            ON i.item_id = sl.item_id
+        LEFT JOIN
+            categories c ON i.category_id = c.category_id
+        WHERE
+            i.is_active = true
+        GROUP BY
+            i.item_id, i.part_number, i.description, c.category_name
+        ORDER BY
+            i.part_number
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.ItemClassification
+	for rows.Next() {
+		item := &models.ItemClassification{}
+		err := rows.Scan(
+			&item.ItemID,
+			&item.PartNumber,
+			&item.Description,
+			&item.CategoryName,
+			&item.QuantitySold,
+			&item.Revenue,
+			&item.Margin,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// GetWeeklyDemand returns the quantity of each active item sold per week
+// between start and end. Weeks are seven-day spans counted from start, and
+// weeks without sales are left out.
+func (r *PostgresClassificationRepository) GetWeeklyDemand(ctx context.Context, start, end time.Time) ([]*models.WeeklyDemand, error) {
+	query := `
+        SELECT
+            sl.item_id,
+            FLOOR(EXTRACT(EPOCH FROM s.date - $1::timestamptz) / 604800)::integer as week,
+            SUM(sl.quantity)::integer as quantity
+        FROM sale_lines sl
+        JOIN sales s ON sl.sale_id = s.sale_id
+        JOIN items i ON sl.item_id = i.item_id
+        WHERE i.is_active = true
+        AND s.date >= $1 AND s.date < $2
+        GROUP BY sl.item_id, week
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var demand []*models.WeeklyDemand
+	for rows.Next() {
+		week := &models.WeeklyDemand{}
+		if err := rows.Scan(&week.ItemID, &week.Week, &week.Quantity); err != nil {
+			return nil, err
+		}
+		demand = append(demand, week)
+	}
+
+	return demand, rows.Err()
+}
+
+// SaveClasses stores the classes on the items. Inactive items are not
+// classified, so any class they still carry is cleared.
+func (r *PostgresClassificationRepository) SaveClasses(ctx context.Context, items []*models.ItemClassification) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	itemIDs := make([]int, 0, len(items))
+	abcClasses := make([]string, 0, len(items))
+	xyzClasses := make([]string, 0, len(items))
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.ItemID)
+		abcClasses = append(abcClasses, item.AbcClass)
+		xyzClasses = append(xyzClasses, item.XyzClass)
+	}
+
+	query := `
+        UPDATE items SET
+            abc_class = c.abc_class,
+            xyz_class = c.xyz_class,
+            classified_at = CURRENT_TIMESTAMP
+        FROM unnest($1::integer[], $2::text[], $3::text[]) AS c(item_id, abc_class, xyz_class)
+        WHERE items.item_id = c.item_id
+    `
+	if _, err := tx.Exec(ctx, query, itemIDs, abcClasses, xyzClasses); err != nil {
+		return err
+	}
+
+	query = `
+        UPDATE items SET
+            abc_class = NULL,
+            xyz_class = NULL,
+            classified_at = NULL
+        WHERE is_active = false
+        AND (abc_class IS NOT NULL OR xyz_class IS NOT NULL)
+    `
+	if _, err := tx.Exec(ctx, query); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
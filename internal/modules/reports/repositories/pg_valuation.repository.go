@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	stockModels "github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+	"github.com/hsrvms/fixparts/internal/modules/reports/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresValuationRepository struct {
+	db *db.Database
+}
+
+func NewPostgresValuationRepository(database *db.Database) ValuationRepository {
+	return &PostgresValuationRepository{
+		db: database,
+	}
+}
+
+// GetValuation replays the stock ledger up to the filter's date and returns
+// every item with stock or value left, valued under the filter's method.
+// Transfers between warehouses are left out of the quantity as they are of
+// the value, so stock shipped but not received yet still counts.
+func (r *PostgresValuationRepository) GetValuation(ctx context.Context, filter *models.ValuationFilter) ([]*models.ValuationItem, error) {
+	costColumn := "m.cost_fifo"
+	if filter.Method == stockModels.CostingAverage {
+		costColumn = "m.cost_average"
+	}
+
+	quantityColumn := "COALESCE(SUM(m.quantity) FILTER (WHERE m.movement_type NOT IN ($2, $3)), 0)"
+
+	query := `
+        SELECT
+            i.item_id, i.part_number, i.description,
+            i.category_id, c.category_name,
+            i.supplier_id, s.name as supplier_name,
+            ` + quantityColumn + `::INTEGER as quantity,
+            SUM(` + costColumn + `) as value
+        FROM stock_movements m
+        JOIN items i ON m.item_id = i.item_id
+        LEFT JOIN categories c ON i.category_id = c.category_id
+        LEFT JOIN suppliers s ON i.supplier_id = s.supplier_id
+        WHERE m.created_at <= $1
+    `
+	params := []interface{}{
+		*filter.AsOf,
+		stockModels.MovementTypeTransferOut,
+		stockModels.MovementTypeTransferIn,
+	}
+	paramCount := 4
+
+	if filter.CategoryID != nil {
+		query += fmt.Sprintf(" AND i.category_id = $%d", paramCount)
+		params = append(params, *filter.CategoryID)
+		paramCount++
+	}
+
+	if filter.SupplierID != nil {
+		query += fmt.Sprintf(" AND i.supplier_id = $%d", paramCount)
+		params = append(params, *filter.SupplierID)
+		paramCount++
+	}
+
+	query += `
+        GROUP BY i.item_id, i.part_number, i.description,
+                 i.category_id, c.category_name, i.supplier_id, s.name
+        HAVING ` + quantityColumn + ` <> 0 OR SUM(` + costColumn + `) <> 0
+        ORDER BY i.part_number
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.ValuationItem
+	for rows.Next() {
+		item := &models.ValuationItem{}
+		err := rows.Scan(
+			&item.ItemID,
+			&item.PartNumber,
+			&item.Description,
+			&item.CategoryID,
+			&item.CategoryName,
+			&item.SupplierID,
+			&item.SupplierName,
+			&item.Quantity,
+			&item.Value,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// GetCostingMethod returns the costing method setting, nil when unset
+func (r *PostgresValuationRepository) GetCostingMethod(ctx context.Context) (*string, error) {
+	var method string
+	err := r.db.Pool.QueryRow(ctx,
+		`SELECT value FROM settings WHERE key = $1`, stockModels.SettingCostingMethod,
+	).Scan(&method)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &method, nil
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/reports/models"
+)
+
+type ValuationRepository interface {
+	GetValuation(ctx context.Context, filter *models.ValuationFilter) ([]*models.ValuationItem, error)
+	GetCostingMethod(ctx context.Context) (*string, error)
+}
@@ -0,0 +1,35 @@
+package reports
+
+import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
+	"github.com/hsrvms/fixparts/internal/modules/reports/handlers"
+	"github.com/hsrvms/fixparts/internal/modules/reports/repositories"
+	"github.com/hsrvms/fixparts/internal/modules/reports/services"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database) {
+	reports := api.Group("/reports", authMiddleware.Require(authModels.PermReportsView))
+
+	classificationRepo := repositories.NewPostgresClassificationRepository(database)
+	classificationService := services.NewClassificationService(classificationRepo)
+	classificationHandler := handlers.NewClassificationHandler(classificationService)
+
+	reports.GET("/classification", classificationHandler.GetClassification)
+	reports.POST("/classification", classificationHandler.ClassifyItems)
+
+	agingRepo := repositories.NewPostgresAgingRepository(database)
+	agingService := services.NewAgingService(agingRepo)
+	agingHandler := handlers.NewAgingHandler(agingService)
+
+	reports.GET("/aging", agingHandler.GetAgingReport)
+	reports.GET("/aging/export", agingHandler.ExportAgingReport)
+
+	valuationRepo := repositories.NewPostgresValuationRepository(database)
+	valuationService := services.NewValuationService(valuationRepo)
+	valuationHandler := handlers.NewValuationHandler(valuationService)
+
+	reports.GET("/valuation", valuationHandler.GetValuationReport)
+}
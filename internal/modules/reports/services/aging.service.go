@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	reporterrors "github.com/hsrvms/fixparts/internal/modules/reports/errors"
+	"github.com/hsrvms/fixparts/internal/modules/reports/models"
+	"github.com/hsrvms/fixparts/internal/modules/reports/repositories"
+)
+
+type agingService struct {
+	repo repositories.AgingRepository
+}
+
+func NewAgingService(repo repositories.AgingRepository) AgingService {
+	return &agingService{
+		repo: repo,
+	}
+}
+
+// GetReport ages the stock on hand of the matching items and totals its
+// value per bucket
+func (s *agingService) GetReport(ctx context.Context, filter *models.AgingFilter) (*models.AgingReport, error) {
+	if filter == nil {
+		filter = &models.AgingFilter{}
+	}
+	if filter.DeadStockDays == 0 {
+		filter.DeadStockDays = models.DefaultDeadStockDays
+	}
+	if filter.DeadStockDays < 0 {
+		return nil, reporterrors.ErrInvalidDays
+	}
+
+	items, err := s.repo.GetStockedItems(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	itemIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.ItemID)
+	}
+
+	layers, err := s.repo.GetPurchaseLayers(ctx, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	layersByItem := make(map[int][]*models.PurchaseLayer, len(items))
+	for _, layer := range layers {
+		layersByItem[layer.ItemID] = append(layersByItem[layer.ItemID], layer)
+	}
+
+	report := &models.AgingReport{
+		AsOf:          time.Now(),
+		DeadStockDays: filter.DeadStockDays,
+		Buckets:       newBuckets(),
+		Items:         []*models.AgingItem{},
+	}
+
+	for _, item := range items {
+		ageItem(item, layersByItem[item.ItemID], report.AsOf, filter.DeadStockDays)
+		if filter.DeadOnly && !item.Dead {
+			continue
+		}
+
+		for i, bucket := range item.Buckets {
+			report.Buckets[i].Quantity += bucket.Quantity
+			report.Buckets[i].Value = roundCurrency(report.Buckets[i].Value + bucket.Value)
+		}
+		report.TotalValue = roundCurrency(report.TotalValue + item.StockValue)
+		if item.Dead {
+			report.DeadStockValue = roundCurrency(report.DeadStockValue + item.StockValue)
+		}
+		report.Items = append(report.Items, item)
+	}
+
+	return report, nil
+}
+
+// ageItem splits the stock of an item over the aging buckets. The newest
+// purchases are taken to still be on hand; whatever stock they do not cover
+// is aged from when the item was created.
+func ageItem(item *models.AgingItem, layers []*models.PurchaseLayer, asOf time.Time, deadStockDays int) {
+	item.Buckets = newBuckets()
+	item.StockValue = roundCurrency(float64(item.CurrentStock) * item.BuyPrice)
+
+	remaining := item.CurrentStock
+	for _, layer := range layers {
+		if remaining <= 0 {
+			break
+		}
+		quantity := min(layer.Quantity, remaining)
+		addToBucket(item, daysBetween(layer.Date, asOf), quantity)
+		remaining -= quantity
+	}
+	if remaining > 0 {
+		addToBucket(item, daysBetween(item.CreatedAt, asOf), remaining)
+	}
+
+	idleSince := item.CreatedAt
+	if item.LastSaleDate != nil {
+		idleSince = *item.LastSaleDate
+	}
+	item.IdleDays = daysBetween(idleSince, asOf)
+	item.Dead = item.IdleDays >= deadStockDays
+}
+
+func addToBucket(item *models.AgingItem, days, quantity int) {
+	for _, bucket := range item.Buckets {
+		if bucket.Contains(days) {
+			bucket.Quantity += quantity
+			bucket.Value = roundCurrency(bucket.Value + float64(quantity)*item.BuyPrice)
+			return
+		}
+	}
+}
+
+func newBuckets() []*models.AgingBucket {
+	buckets := make([]*models.AgingBucket, 0, len(models.AgingBuckets))
+	for _, bucket := range models.AgingBuckets {
+		buckets = append(buckets, &models.AgingBucket{
+			Label:   bucket.Label,
+			MinDays: bucket.MinDays,
+			MaxDays: bucket.MaxDays,
+		})
+	}
+	return buckets
+}
+
+// daysBetween returns the whole days from since to until, never negative
+func daysBetween(since, until time.Time) int {
+	return max(int(until.Sub(since).Hours()/24), 0)
+}
@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/reports/models"
+)
+
+type ClassificationService interface {
+	GetReport(ctx context.Context, filter *models.ClassificationFilter) (*models.ClassificationReport, error)
+	Classify(ctx context.Context, filter *models.ClassificationFilter) (*models.ClassificationReport, error)
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	reporterrors "github.com/hsrvms/fixparts/internal/modules/reports/errors"
+	"github.com/hsrvms/fixparts/internal/modules/reports/models"
+	"github.com/hsrvms/fixparts/internal/modules/reports/repositories"
+)
+
+const week = 7 * 24 * time.Hour
+
+type classificationService struct {
+	repo repositories.ClassificationRepository
+}
+
+func NewClassificationService(repo repositories.ClassificationRepository) ClassificationService {
+	return &classificationService{
+		repo: repo,
+	}
+}
+
+// GetReport classifies the active items over the period without storing
+// the classes
+func (s *classificationService) GetReport(ctx context.Context, filter *models.ClassificationFilter) (*models.ClassificationReport, error) {
+	if filter == nil {
+		filter = &models.ClassificationFilter{}
+	}
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.GetItemSales(ctx, *filter.StartDate, *filter.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	demand, err := s.repo.GetWeeklyDemand(ctx, *filter.StartDate, *filter.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ClassificationReport{
+		StartDate: *filter.StartDate,
+		EndDate:   *filter.EndDate,
+		Basis:     filter.Basis,
+		Summary:   map[string]int{},
+		Items:     items,
+	}
+	if report.Items == nil {
+		report.Items = []*models.ItemClassification{}
+	}
+
+	weeks := int(math.Ceil(float64(filter.EndDate.Sub(*filter.StartDate)) / float64(week)))
+	classifyABC(report)
+	classifyXYZ(report.Items, demand, weeks)
+
+	for _, item := range report.Items {
+		report.Summary[item.AbcClass+item.XyzClass]++
+	}
+
+	return report, nil
+}
+
+// Classify classifies the active items over the period and stores the
+// classes on the items, so items can be filtered by them
+func (s *classificationService) Classify(ctx context.Context, filter *models.ClassificationFilter) (*models.ClassificationReport, error) {
+	report, err := s.GetReport(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SaveClasses(ctx, report.Items); err != nil {
+		return nil, err
+	}
+	report.Saved = true
+
+	return report, nil
+}
+
+// classifyABC ranks the items by contribution and assigns the ABC classes.
+// Items that lost money or did not sell contribute nothing and are C.
+func classifyABC(report *models.ClassificationReport) {
+	for _, item := range report.Items {
+		item.Contribution = item.Revenue
+		if report.Basis == models.BasisMargin {
+			item.Contribution = item.Margin
+		}
+		if item.Contribution > 0 {
+			report.TotalContribution += item.Contribution
+		}
+	}
+	report.TotalContribution = roundCurrency(report.TotalContribution)
+
+	sort.SliceStable(report.Items, func(a, b int) bool {
+		return report.Items[a].Contribution > report.Items[b].Contribution
+	})
+
+	cumulative := 0.0
+	for _, item := range report.Items {
+		if item.Contribution <= 0 || report.TotalContribution <= 0 {
+			item.AbcClass = models.ClassC
+			continue
+		}
+
+		// An item is classed by where its contribution starts, so the item
+		// that crosses the 80% line is still A
+		start := cumulative
+		item.Share = round(item.Contribution / report.TotalContribution)
+		cumulative += item.Contribution / report.TotalContribution
+		item.CumulativeShare = round(cumulative)
+
+		switch {
+		case start < models.ClassAShare:
+			item.AbcClass = models.ClassA
+		case start < models.ClassBShare:
+			item.AbcClass = models.ClassB
+		default:
+			item.AbcClass = models.ClassC
+		}
+	}
+}
+
+// classifyXYZ assigns the XYZ classes from the coefficient of variation of
+// the weekly demand over the period, weeks without sales counting as zero
+func classifyXYZ(items []*models.ItemClassification, demand []*models.WeeklyDemand, weeks int) {
+	weeks = max(weeks, 1)
+
+	series := make(map[int][]float64, len(items))
+	for _, item := range items {
+		series[item.ItemID] = make([]float64, weeks)
+	}
+	for _, d := range demand {
+		if quantities, ok := series[d.ItemID]; ok && d.Week >= 0 && d.Week < weeks {
+			quantities[d.Week] += float64(d.Quantity)
+		}
+	}
+
+	for _, item := range items {
+		quantities := series[item.ItemID]
+
+		mean := 0.0
+		for _, quantity := range quantities {
+			mean += quantity
+		}
+		mean /= float64(weeks)
+
+		variance := 0.0
+		for _, quantity := range quantities {
+			variance += (quantity - mean) * (quantity - mean)
+		}
+		stdDev := math.Sqrt(variance / float64(weeks))
+
+		item.WeeklyMean = round(mean)
+		item.WeeklyStdDev = round(stdDev)
+
+		if mean <= 0 {
+			item.XyzClass = models.ClassZ
+			continue
+		}
+
+		variation := round(stdDev / mean)
+		item.Variation = &variation
+
+		switch {
+		case variation <= models.ClassXVariation:
+			item.XyzClass = models.ClassX
+		case variation <= models.ClassYVariation:
+			item.XyzClass = models.ClassY
+		default:
+			item.XyzClass = models.ClassZ
+		}
+	}
+}
+
+func validateFilter(filter *models.ClassificationFilter) error {
+	if filter.Basis == "" {
+		filter.Basis = models.BasisRevenue
+	}
+	if filter.Basis != models.BasisRevenue && filter.Basis != models.BasisMargin {
+		return reporterrors.ErrInvalidBasis
+	}
+
+	if filter.EndDate == nil {
+		end := time.Now()
+		filter.EndDate = &end
+	}
+	if filter.StartDate == nil {
+		start := filter.EndDate.AddDate(0, 0, -models.DefaultClassificationDays)
+		filter.StartDate = &start
+	}
+	if !filter.StartDate.Before(*filter.EndDate) {
+		return reporterrors.ErrInvalidPeriod
+	}
+
+	return nil
+}
+
+func round(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
+
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
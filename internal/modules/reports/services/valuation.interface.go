@@ -0,0 +1,11 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/reports/models"
+)
+
+type ValuationService interface {
+	GetReport(ctx context.Context, filter *models.ValuationFilter) (*models.ValuationReport, error)
+}
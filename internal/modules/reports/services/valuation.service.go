@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	stockModels "github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+	reporterrors "github.com/hsrvms/fixparts/internal/modules/reports/errors"
+	"github.com/hsrvms/fixparts/internal/modules/reports/models"
+	"github.com/hsrvms/fixparts/internal/modules/reports/repositories"
+)
+
+type valuationService struct {
+	repo repositories.ValuationRepository
+}
+
+func NewValuationService(repo repositories.ValuationRepository) ValuationService {
+	return &valuationService{
+		repo: repo,
+	}
+}
+
+// GetReport values the stock on hand as of the filter's date, under the
+// requested costing method or the configured one
+func (s *valuationService) GetReport(ctx context.Context, filter *models.ValuationFilter) (*models.ValuationReport, error) {
+	if filter == nil {
+		filter = &models.ValuationFilter{}
+	}
+	if filter.AsOf == nil {
+		now := time.Now()
+		filter.AsOf = &now
+	}
+
+	if filter.Method == "" {
+		method, err := s.repo.GetCostingMethod(ctx)
+		if err != nil {
+			return nil, err
+		}
+		filter.Method = stockModels.CostingFIFO
+		if method != nil {
+			filter.Method = *method
+		}
+	}
+	if !stockModels.IsValidCostingMethod(filter.Method) {
+		return nil, reporterrors.ErrInvalidMethod
+	}
+
+	items, err := s.repo.GetValuation(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ValuationReport{
+		AsOf:   *filter.AsOf,
+		Method: filter.Method,
+		Items:  []*models.ValuationItem{},
+	}
+
+	for _, item := range items {
+		if item.Quantity != 0 {
+			item.UnitCost = round(item.Value / float64(item.Quantity))
+		}
+
+		report.TotalQuantity += item.Quantity
+		report.TotalValue = roundCurrency(report.TotalValue + item.Value)
+		report.Items = append(report.Items, item)
+	}
+
+	return report, nil
+}
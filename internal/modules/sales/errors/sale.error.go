@@ -12,4 +12,23 @@ var (
 	ErrInvalidDate                = errors.New("sale date cannot be in the future")
 	ErrInsufficientStock          = errors.New("insufficient stock for sale")
 	ErrInvalidCustomerEmail       = errors.New("invalid customer email format")
+	ErrInvalidCustomerID          = errors.New("invalid customer ID")
+	ErrCustomerNotFound           = errors.New("customer not found")
+	ErrNoSaleLines                = errors.New("sale must have at least one line")
+	ErrSaleLineNotFound           = errors.New("sale line not found")
+	ErrInvalidPaymentMethod       = errors.New("invalid payment method")
+	ErrInvalidAmount              = errors.New("discount, tax and paid amounts cannot be negative")
+	ErrDiscountExceedsSubtotal    = errors.New("discount cannot exceed the sale subtotal")
+	ErrSaleHasReturns             = errors.New("sales with returns cannot be changed or deleted")
+	ErrWarehouseNotFound          = errors.New("warehouse not found")
+
+	ErrReturnNotFound      = errors.New("sale return not found")
+	ErrInvalidReturnID     = errors.New("invalid sale return ID")
+	ErrNoReturnLines       = errors.New("sale return must have at least one line")
+	ErrInvalidReasonCode   = errors.New("invalid return reason code")
+	ErrInvalidDisposition  = errors.New("disposition must be either restock or scrap")
+	ErrInvalidRefundAmount = errors.New("refund amount cannot be negative")
+	ErrReturnExceedsSold   = errors.New("cannot return more than was sold on the sale line")
+	ErrRefundExceedsPaid   = errors.New("refund cannot exceed the amount paid on the sale")
+	ErrDuplicateReturnLine = errors.New("a sale line can only appear once on a return")
 )
@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	auditMiddleware "github.com/hsrvms/fixparts/internal/modules/audit/middleware"
+	auditModels "github.com/hsrvms/fixparts/internal/modules/audit/models"
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
 	saleErrors "github.com/hsrvms/fixparts/internal/modules/sales/errors"
 	"github.com/hsrvms/fixparts/internal/modules/sales/models"
 	"github.com/hsrvms/fixparts/internal/modules/sales/services"
@@ -45,6 +50,13 @@ func (h *SaleHandler) GetSales(c echo.Context) error {
 		}
 	}
 
+	if customerID := c.QueryParam("customer_id"); customerID != "" {
+		id, err := strconv.Atoi(customerID)
+		if err == nil {
+			filter.CustomerID = &id
+		}
+	}
+
 	if customerName := c.QueryParam("customer_name"); customerName != "" {
 		filter.CustomerName = &customerName
 	}
@@ -61,10 +73,21 @@ func (h *SaleHandler) GetSales(c echo.Context) error {
 		filter.TransactionNumber = &transactionNumber
 	}
 
+	if warehouseID := c.QueryParam("warehouse_id"); warehouseID != "" {
+		id, err := strconv.Atoi(warehouseID)
+		if err == nil {
+			filter.WarehouseID = &id
+		}
+	}
+
 	if soldBy := c.QueryParam("sold_by"); soldBy != "" {
 		filter.SoldBy = &soldBy
 	}
 
+	if paymentMethod := c.QueryParam("payment_method"); paymentMethod != "" {
+		filter.PaymentMethod = &paymentMethod
+	}
+
 	ctx := c.Request().Context()
 	sales, err := h.service.GetAll(ctx, filter)
 	if err != nil {
@@ -95,31 +118,43 @@ func (h *SaleHandler) GetSaleByID(c echo.Context) error {
 	return c.JSON(http.StatusOK, sale)
 }
 
-// CreateSale handles creation of a new sale
+// CreateSale handles creation of a new sale ticket with its lines
 func (h *SaleHandler) CreateSale(c echo.Context) error {
 	sale := new(models.Sale)
 	if err := c.Bind(sale); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	sale.SoldBy = authMiddleware.Actor(c)
 
 	ctx := c.Request().Context()
 	id, err := h.service.Create(ctx, sale)
 	if err != nil {
+		if errors.Is(err, saleErrors.ErrInsufficientStock) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
 		switch err {
 		case saleErrors.ErrInvalidItemID, saleErrors.ErrInvalidQuantity,
 			saleErrors.ErrInvalidPricePerUnit, saleErrors.ErrInvalidDate,
-			saleErrors.ErrInvalidCustomerEmail:
+			saleErrors.ErrInvalidCustomerEmail, saleErrors.ErrNoSaleLines,
+			saleErrors.ErrInvalidPaymentMethod, saleErrors.ErrInvalidAmount,
+			saleErrors.ErrDiscountExceedsSubtotal, saleErrors.ErrInvalidCustomerID,
+			saleErrors.ErrCustomerNotFound, saleErrors.ErrWarehouseNotFound:
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		case saleErrors.ErrDuplicateTransactionNumber:
 			return echo.NewHTTPError(http.StatusConflict, err.Error())
-		case saleErrors.ErrInsufficientStock:
-			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 		default:
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 	}
 
 	sale.SaleID = id
+
+	created, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordCreate(c, auditModels.EntitySale, id, created)
+
 	return c.JSON(http.StatusCreated, sale)
 }
 
@@ -134,27 +169,48 @@ func (h *SaleHandler) UpdateSale(c echo.Context) error {
 	if err := c.Bind(sale); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	sale.SoldBy = authMiddleware.Actor(c)
 	sale.SaleID = id
 
 	ctx := c.Request().Context()
-	err = h.service.Update(ctx, sale)
+	existing, err := h.service.GetByID(ctx, id)
 	if err != nil {
 		switch err {
 		case saleErrors.ErrSaleNotFound:
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	err = h.service.Update(ctx, sale)
+	if err != nil {
+		if errors.Is(err, saleErrors.ErrInsufficientStock) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		switch err {
+		case saleErrors.ErrSaleNotFound, saleErrors.ErrSaleLineNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
 		case saleErrors.ErrInvalidItemID, saleErrors.ErrInvalidQuantity,
 			saleErrors.ErrInvalidPricePerUnit, saleErrors.ErrInvalidDate,
-			saleErrors.ErrInvalidCustomerEmail:
+			saleErrors.ErrInvalidCustomerEmail, saleErrors.ErrNoSaleLines,
+			saleErrors.ErrInvalidPaymentMethod, saleErrors.ErrInvalidAmount,
+			saleErrors.ErrDiscountExceedsSubtotal, saleErrors.ErrInvalidCustomerID,
+			saleErrors.ErrCustomerNotFound, saleErrors.ErrWarehouseNotFound:
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-		case saleErrors.ErrDuplicateTransactionNumber:
+		case saleErrors.ErrDuplicateTransactionNumber, saleErrors.ErrSaleHasReturns:
 			return echo.NewHTTPError(http.StatusConflict, err.Error())
-		case saleErrors.ErrInsufficientStock:
-			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 		default:
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 	}
 
+	updated, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordUpdate(c, auditModels.EntitySale, id, existing, updated)
+
 	return c.JSON(http.StatusOK, sale)
 }
 
@@ -166,7 +222,7 @@ func (h *SaleHandler) DeleteSale(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
-	err = h.service.Delete(ctx, id)
+	existing, err := h.service.GetByID(ctx, id)
 	if err != nil {
 		switch err {
 		case saleErrors.ErrSaleNotFound:
@@ -176,10 +232,38 @@ func (h *SaleHandler) DeleteSale(c echo.Context) error {
 		}
 	}
 
+	// A deletion that would be refused must not spend the manager's code
+	if err := h.service.CanDelete(ctx, id); err != nil {
+		return deleteSaleError(err)
+	}
+
+	if err := authMiddleware.Approve(c, authModels.ActionSaleDelete, id, ""); err != nil {
+		return err
+	}
+
+	err = h.service.Delete(ctx, id)
+	if err != nil {
+		return deleteSaleError(err)
+	}
+	auditMiddleware.RecordDelete(c, auditModels.EntitySale, id, existing)
+
 	return c.NoContent(http.StatusNoContent)
 }
 
-// GetByTransactionNumber handles retrieval of a sale by transaction number
+// deleteSaleError maps sale deletion errors to HTTP errors
+func deleteSaleError(err error) error {
+	switch err {
+	case saleErrors.ErrSaleNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case saleErrors.ErrSaleHasReturns:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// GetByTransactionNumber handles retrieval of a whole sale ticket, with its
+// lines and totals, by transaction number
 func (h *SaleHandler) GetByTransactionNumber(c echo.Context) error {
 	transactionNumber := c.Param("transactionNumber")
 	if transactionNumber == "" {
@@ -189,10 +273,12 @@ func (h *SaleHandler) GetByTransactionNumber(c echo.Context) error {
 	ctx := c.Request().Context()
 	sale, err := h.service.GetByTransactionNumber(ctx, transactionNumber)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	if sale == nil {
-		return echo.NewHTTPError(http.StatusNotFound, "sale not found")
+		switch err {
+		case saleErrors.ErrSaleNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
 	}
 
 	return c.JSON(http.StatusOK, sale)
@@ -213,3 +299,22 @@ func (h *SaleHandler) GetCustomerSales(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, sales)
 }
+
+// GetSalesByCustomerID handles retrieval of the sales linked to a customer record
+func (h *SaleHandler) GetSalesByCustomerID(c echo.Context) error {
+	customerID, err := strconv.Atoi(c.Param("customerId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	ctx := c.Request().Context()
+	sales, err := h.service.GetByCustomerID(ctx, customerID)
+	if err != nil {
+		if err == saleErrors.ErrInvalidCustomerID {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, sales)
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	saleErrors "github.com/hsrvms/fixparts/internal/modules/sales/errors"
+	"github.com/hsrvms/fixparts/internal/modules/sales/models"
+	"github.com/hsrvms/fixparts/internal/modules/sales/services"
+	"github.com/labstack/echo/v4"
+)
+
+type SaleReturnHandler struct {
+	service services.SaleReturnService
+}
+
+func NewSaleReturnHandler(service services.SaleReturnService) *SaleReturnHandler {
+	return &SaleReturnHandler{
+		service: service,
+	}
+}
+
+// GetSaleReturns handles retrieval of all sale returns with optional filtering
+func (h *SaleReturnHandler) GetSaleReturns(c echo.Context) error {
+	filter := &models.SaleReturnFilter{}
+
+	// Parse query parameters
+	if saleID := c.QueryParam("sale_id"); saleID != "" {
+		id, err := strconv.Atoi(saleID)
+		if err == nil {
+			filter.SaleID = &id
+		}
+	}
+
+	if reasonCode := c.QueryParam("reason_code"); reasonCode != "" {
+		filter.ReasonCode = &reasonCode
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		if date, err := time.Parse(time.RFC3339, startDate); err == nil {
+			filter.StartDate = &date
+		}
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		if date, err := time.Parse(time.RFC3339, endDate); err == nil {
+			filter.EndDate = &date
+		}
+	}
+
+	ctx := c.Request().Context()
+	returns, err := h.service.GetAll(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, returns)
+}
+
+// GetSaleReturnByID handles retrieval of a single return document
+func (h *SaleReturnHandler) GetSaleReturnByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid sale return ID")
+	}
+
+	ctx := c.Request().Context()
+	saleReturn, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return returnError(err)
+	}
+
+	return c.JSON(http.StatusOK, saleReturn)
+}
+
+// GetReturnsOfSale handles retrieval of the returns taken against a sale
+func (h *SaleReturnHandler) GetReturnsOfSale(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid sale ID")
+	}
+
+	ctx := c.Request().Context()
+	returns, err := h.service.GetSaleReturns(ctx, id)
+	if err != nil {
+		return returnError(err)
+	}
+
+	return c.JSON(http.StatusOK, returns)
+}
+
+// CreateSaleReturn handles taking back parts sold on a sale
+func (h *SaleReturnHandler) CreateSaleReturn(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid sale ID")
+	}
+
+	saleReturn := new(models.SaleReturn)
+	if err := c.Bind(saleReturn); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	saleReturn.SaleID = id
+
+	ctx := c.Request().Context()
+	returnID, err := h.service.Create(ctx, saleReturn)
+	if err != nil {
+		return returnError(err)
+	}
+
+	created, err := h.service.GetByID(ctx, returnID)
+	if err != nil {
+		return returnError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// returnError maps sale return errors to HTTP errors
+func returnError(err error) error {
+	switch err {
+	case saleErrors.ErrSaleNotFound, saleErrors.ErrReturnNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case saleErrors.ErrInvalidSaleID, saleErrors.ErrInvalidReturnID,
+		saleErrors.ErrNoReturnLines, saleErrors.ErrInvalidReasonCode,
+		saleErrors.ErrInvalidDisposition, saleErrors.ErrInvalidRefundAmount,
+		saleErrors.ErrInvalidQuantity, saleErrors.ErrInvalidDate,
+		saleErrors.ErrSaleLineNotFound, saleErrors.ErrDuplicateReturnLine:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case saleErrors.ErrReturnExceedsSold, saleErrors.ErrRefundExceedsPaid:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
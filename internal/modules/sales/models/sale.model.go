@@ -2,22 +2,64 @@ package models
 
 import "time"
 
+// Payment methods accepted on a sale
+const (
+	PaymentMethodCash         = "cash"
+	PaymentMethodCard         = "card"
+	PaymentMethodBankTransfer = "bank_transfer"
+	PaymentMethodAccount      = "account"
+)
+
+// Sale is the header of a sales transaction (one ticket). The items sold
+// on the ticket are stored as SaleLines.
 type Sale struct {
 	SaleID            int       `json:"sale_id" db:"sale_id"`
 	Date              time.Time `json:"date" db:"date"`
-	ItemID            int       `json:"item_id" db:"item_id"`
-	Quantity          int       `json:"quantity" db:"quantity"`
-	PricePerUnit      float64   `json:"price_per_unit" db:"price_per_unit"`
-	TotalPrice        float64   `json:"total_price" db:"total_price"`
 	TransactionNumber string    `json:"transaction_number" db:"transaction_number"`
+	WarehouseID       int       `json:"warehouse_id" db:"warehouse_id"`
+	CustomerID        *int      `json:"customer_id,omitempty" db:"customer_id"`
 	CustomerName      *string   `json:"customer_name,omitempty" db:"customer_name"`
 	CustomerPhone     *string   `json:"customer_phone,omitempty" db:"customer_phone"`
 	CustomerEmail     *string   `json:"customer_email,omitempty" db:"customer_email"`
 	SoldBy            *string   `json:"sold_by,omitempty" db:"sold_by"`
+	PaymentMethod     string    `json:"payment_method" db:"payment_method"`
+	Subtotal          float64   `json:"subtotal" db:"subtotal"`
+	DiscountAmount    float64   `json:"discount_amount" db:"discount_amount"`
+	TaxAmount         float64   `json:"tax_amount" db:"tax_amount"`
+	TotalAmount       float64   `json:"total_amount" db:"total_amount"`
+	AmountPaid        *float64  `json:"amount_paid" db:"amount_paid"`
 	Notes             *string   `json:"notes,omitempty" db:"notes"`
 	CreatedAt         time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
 
+	// Additional fields for API responses
+	WarehouseCode string `json:"warehouse_code,omitempty" db:"warehouse_code"`
+
+	Lines []*SaleLine `json:"lines" db:"-"`
+
+	// Warnings raised while posting stock, e.g. an item sold below zero
+	// under the allow_with_warning policy
+	Warnings []string `json:"warnings,omitempty" db:"-"`
+}
+
+// SaleLine is a single item sold on a sale ticket
+type SaleLine struct {
+	SaleLineID   int       `json:"sale_line_id" db:"sale_line_id"`
+	SaleID       int       `json:"sale_id" db:"sale_id"`
+	ItemID       int       `json:"item_id" db:"item_id"`
+	Quantity     int       `json:"quantity" db:"quantity"`
+	PricePerUnit float64   `json:"price_per_unit" db:"price_per_unit"`
+	TotalPrice   float64   `json:"total_price" db:"total_price"`
+	Notes        *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+
+	// Cost of the goods sold on the line, as valued by the stock ledger
+	CostOfGoods float64 `json:"cost_of_goods" db:"cost_of_goods" access:"full"`
+
+	// Quantity taken back by sale returns
+	QuantityReturned int `json:"quantity_returned" db:"quantity_returned"`
+
 	// Additional fields for API responses
 	ItemPartNumber  string `json:"item_part_number,omitempty" db:"item_part_number"`
 	ItemDescription string `json:"item_description,omitempty" db:"item_description"`
@@ -28,9 +70,12 @@ type SaleFilter struct {
 	ItemID            *int       `query:"item_id"`
 	StartDate         *time.Time `query:"start_date"`
 	EndDate           *time.Time `query:"end_date"`
+	CustomerID        *int       `query:"customer_id"`
 	CustomerName      *string    `query:"customer_name"`
 	CustomerPhone     *string    `query:"customer_phone"`
 	CustomerEmail     *string    `query:"customer_email"`
 	TransactionNumber *string    `query:"transaction_number"`
+	WarehouseID       *int       `query:"warehouse_id"`
 	SoldBy            *string    `query:"sold_by"`
+	PaymentMethod     *string    `query:"payment_method"`
 }
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Reasons a customer brings a part back
+const (
+	ReturnReasonDefective = "defective"
+	ReturnReasonWrongPart = "wrong_part"
+	ReturnReasonNotNeeded = "not_needed"
+	ReturnReasonWarranty  = "warranty"
+	ReturnReasonOther     = "other"
+)
+
+// What happens to a returned part: restocked parts go back on the shelf,
+// scrapped parts do not re-enter stock
+const (
+	DispositionRestock = "restock"
+	DispositionScrap   = "scrap"
+)
+
+// SaleReturn is a return document taking back parts sold on a sale. Its
+// refund can never exceed what was paid on the original sale.
+type SaleReturn struct {
+	ReturnID     int       `json:"return_id" db:"return_id"`
+	ReturnNumber string    `json:"return_number" db:"return_number"`
+	SaleID       int       `json:"sale_id" db:"sale_id"`
+	Date         time.Time `json:"date" db:"date"`
+	ReasonCode   string    `json:"reason_code" db:"reason_code"`
+	RefundAmount float64   `json:"refund_amount" db:"refund_amount"`
+	ProcessedBy  *string   `json:"processed_by,omitempty" db:"processed_by"`
+	Notes        *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+
+	Lines []*SaleReturnLine `json:"lines" db:"-"`
+
+	// Additional fields for API responses
+	TransactionNumber string `json:"transaction_number,omitempty" db:"transaction_number"`
+}
+
+// SaleReturnLine is the quantity of one sale line taken back
+type SaleReturnLine struct {
+	ReturnLineID int       `json:"return_line_id" db:"return_line_id"`
+	ReturnID     int       `json:"return_id" db:"return_id"`
+	SaleLineID   int       `json:"sale_line_id" db:"sale_line_id"`
+	ItemID       int       `json:"item_id" db:"item_id"`
+	Quantity     int       `json:"quantity" db:"quantity"`
+	Disposition  string    `json:"disposition" db:"disposition"`
+	RefundAmount float64   `json:"refund_amount" db:"refund_amount"`
+	Notes        *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+
+	// Additional fields for API responses
+	ItemPartNumber  string `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription string `json:"item_description,omitempty" db:"item_description"`
+}
+
+type SaleReturnFilter struct {
+	SaleID     *int       `query:"sale_id"`
+	ReasonCode *string    `query:"reason_code"`
+	StartDate  *time.Time `query:"start_date"`
+	EndDate    *time.Time `query:"end_date"`
+}
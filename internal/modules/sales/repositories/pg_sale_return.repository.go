@@ -0,0 +1,354 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	stockModels "github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+	stock "github.com/hsrvms/fixparts/internal/modules/inventory/stock/repositories"
+	saleErrors "github.com/hsrvms/fixparts/internal/modules/sales/errors"
+	"github.com/hsrvms/fixparts/internal/modules/sales/models"
+	"github.com/hsrvms/fixparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresSaleReturnRepository struct {
+	db *db.Database
+}
+
+func NewPostgresSaleReturnRepository(database *db.Database) SaleReturnRepository {
+	return &PostgresSaleReturnRepository{
+		db: database,
+	}
+}
+
+func (r *PostgresSaleReturnRepository) GetAll(ctx context.Context, filter *models.SaleReturnFilter) ([]*models.SaleReturn, error) {
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.SaleID != nil {
+			conditions = append(conditions, fmt.Sprintf("sr.sale_id = $%d", paramCount))
+			params = append(params, *filter.SaleID)
+			paramCount++
+		}
+
+		if filter.ReasonCode != nil {
+			conditions = append(conditions, fmt.Sprintf("sr.reason_code = $%d", paramCount))
+			params = append(params, *filter.ReasonCode)
+			paramCount++
+		}
+
+		if filter.StartDate != nil {
+			conditions = append(conditions, fmt.Sprintf("sr.date >= $%d", paramCount))
+			params = append(params, *filter.StartDate)
+			paramCount++
+		}
+
+		if filter.EndDate != nil {
+			conditions = append(conditions, fmt.Sprintf("sr.date <= $%d", paramCount))
+			params = append(params, *filter.EndDate)
+			paramCount++
+		}
+	}
+
+	where := "1=1"
+	if len(conditions) > 0 {
+		where = strings.Join(conditions, " AND ")
+	}
+
+	return r.getReturns(ctx, where, params...)
+}
+
+func (r *PostgresSaleReturnRepository) GetByID(ctx context.Context, id int) (*models.SaleReturn, error) {
+	returns, err := r.getReturns(ctx, "sr.return_id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(returns) == 0 {
+		return nil, nil
+	}
+
+	return returns[0], nil
+}
+
+// Create records a return against a sale. The sale is locked so concurrent
+// returns of the same ticket see each other's quantities and refunds. Lines
+// without a refund amount are refunded at what the customer paid for them,
+// after the ticket discount and tax, as far as the amount paid allows. An
+// explicit refund may not exceed what was paid for the returned parts.
+func (r *PostgresSaleReturnRepository) Create(ctx context.Context, saleReturn *models.SaleReturn) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var subtotal, totalAmount, amountPaid, refunded float64
+	var warehouseID int
+	err = tx.QueryRow(ctx,
+		`SELECT subtotal, total_amount, amount_paid, warehouse_id FROM sales WHERE sale_id = $1 FOR UPDATE`,
+		saleReturn.SaleID,
+	).Scan(&subtotal, &totalAmount, &amountPaid, &warehouseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, saleErrors.ErrSaleNotFound
+		}
+		return 0, err
+	}
+
+	err = tx.QueryRow(ctx,
+		`SELECT COALESCE(SUM(refund_amount), 0) FROM sale_returns WHERE sale_id = $1`,
+		saleReturn.SaleID,
+	).Scan(&refunded)
+	if err != nil {
+		return 0, err
+	}
+
+	// Share of the line price the customer actually paid once the ticket
+	// discount and tax are applied
+	priceFactor := 1.0
+	if subtotal > 0 {
+		priceFactor = totalAmount / subtotal
+	}
+
+	remaining := roundAmount(amountPaid - refunded)
+	saleReturn.RefundAmount = 0
+
+	// Restocked parts go back at the cost they were sold at
+	unitCosts := make(map[int]float64, len(saleReturn.Lines))
+	// Refunded so far per sale line, this return included
+	lineRefunds := make(map[int]float64, len(saleReturn.Lines))
+	for _, line := range saleReturn.Lines {
+		var sold, returned int
+		var linePrice, lineCost, lineRefunded float64
+		err = tx.QueryRow(ctx, `
+            SELECT
+                sl.item_id, sl.quantity, sl.total_price, sl.cost_of_goods,
+                COALESCE((
+                    SELECT SUM(rl.quantity) FROM sale_return_lines rl
+                    WHERE rl.sale_line_id = sl.sale_line_id
+                ), 0),
+                COALESCE((
+                    SELECT SUM(rl.refund_amount) FROM sale_return_lines rl
+                    WHERE rl.sale_line_id = sl.sale_line_id
+                ), 0)
+            FROM sale_lines sl
+            WHERE sl.sale_line_id = $1 AND sl.sale_id = $2
+        `, line.SaleLineID, saleReturn.SaleID,
+		).Scan(&line.ItemID, &sold, &linePrice, &lineCost, &returned, &lineRefunded)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, saleErrors.ErrSaleLineNotFound
+			}
+			return 0, err
+		}
+
+		unitCosts[line.SaleLineID] = lineCost / float64(sold)
+
+		if returned+line.Quantity > sold {
+			return 0, saleErrors.ErrReturnExceedsSold
+		}
+
+		// What was paid for the returned parts, within what is left of the
+		// line once its earlier refunds are taken off
+		lineRefunded += lineRefunds[line.SaleLineID]
+		paid := roundAmount(linePrice * priceFactor * float64(line.Quantity) / float64(sold))
+		linePaid := roundAmount(linePrice*priceFactor - lineRefunded)
+		refundable := math.Max(0, math.Min(paid, linePaid))
+
+		if line.RefundAmount == 0 {
+			line.RefundAmount = math.Max(0, math.Min(refundable, remaining))
+		} else if line.RefundAmount > refundable || line.RefundAmount > remaining {
+			return 0, saleErrors.ErrRefundExceedsPaid
+		}
+
+		lineRefunds[line.SaleLineID] += line.RefundAmount
+		remaining = roundAmount(remaining - line.RefundAmount)
+		saleReturn.RefundAmount = roundAmount(saleReturn.RefundAmount + line.RefundAmount)
+	}
+
+	query := `
+        INSERT INTO sale_returns (
+            return_number, sale_id, date, reason_code,
+            refund_amount, processed_by, notes
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING return_id
+    `
+
+	var id int
+	err = tx.QueryRow(
+		ctx, query,
+		saleReturn.ReturnNumber,
+		saleReturn.SaleID,
+		saleReturn.Date,
+		saleReturn.ReasonCode,
+		saleReturn.RefundAmount,
+		saleReturn.ProcessedBy,
+		saleReturn.Notes,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	lineQuery := `
+        INSERT INTO sale_return_lines (
+            return_id, sale_line_id, item_id, quantity,
+            disposition, refund_amount, notes
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING return_line_id
+    `
+
+	referenceType := stockModels.ReferenceTypeSaleReturn
+	for _, line := range saleReturn.Lines {
+		line.ReturnID = id
+		err = tx.QueryRow(
+			ctx, lineQuery,
+			line.ReturnID,
+			line.SaleLineID,
+			line.ItemID,
+			line.Quantity,
+			line.Disposition,
+			line.RefundAmount,
+			line.Notes,
+		).Scan(&line.ReturnLineID)
+		if err != nil {
+			return 0, err
+		}
+
+		// Scrapped parts never make it back to the shelf; restocked ones go
+		// back to the warehouse they were sold from
+		if line.Disposition != models.DispositionRestock {
+			continue
+		}
+
+		lineID := line.ReturnLineID
+		unitCost := unitCosts[line.SaleLineID]
+		movement := &stockModels.StockMovement{
+			ItemID:          line.ItemID,
+			WarehouseID:     warehouseID,
+			MovementType:    stockModels.MovementTypeSaleReturn,
+			Quantity:        line.Quantity,
+			ReferenceType:   &referenceType,
+			ReferenceID:     &id,
+			ReferenceLineID: &lineID,
+			PerformedBy:     saleReturn.ProcessedBy,
+			UnitCost:        &unitCost,
+		}
+		if err := stock.PostMovement(ctx, tx, movement); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// getReturns loads the return headers matching the condition and their lines
+func (r *PostgresSaleReturnRepository) getReturns(ctx context.Context, condition string, params ...interface{}) ([]*models.SaleReturn, error) {
+	query := `
+        SELECT
+            sr.return_id, sr.return_number, sr.sale_id, sr.date,
+            sr.reason_code, sr.refund_amount, sr.processed_by, sr.notes,
+            sr.created_at, sr.updated_at,
+            s.transaction_number
+        FROM sale_returns sr
+        JOIN sales s ON sr.sale_id = s.sale_id
+        WHERE ` + condition + `
+        ORDER BY sr.date DESC, sr.return_id DESC
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	returns := []*models.SaleReturn{}
+	returnsByID := make(map[int]*models.SaleReturn)
+	returnIDs := []int{}
+	for rows.Next() {
+		saleReturn := &models.SaleReturn{Lines: []*models.SaleReturnLine{}}
+		err := rows.Scan(
+			&saleReturn.ReturnID,
+			&saleReturn.ReturnNumber,
+			&saleReturn.SaleID,
+			&saleReturn.Date,
+			&saleReturn.ReasonCode,
+			&saleReturn.RefundAmount,
+			&saleReturn.ProcessedBy,
+			&saleReturn.Notes,
+			&saleReturn.CreatedAt,
+			&saleReturn.UpdatedAt,
+			&saleReturn.TransactionNumber,
+		)
+		if err != nil {
+			return nil, err
+		}
+		returns = append(returns, saleReturn)
+		returnsByID[saleReturn.ReturnID] = saleReturn
+		returnIDs = append(returnIDs, saleReturn.ReturnID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(returnIDs) == 0 {
+		return returns, nil
+	}
+
+	lineQuery := `
+        SELECT
+            rl.return_line_id, rl.return_id, rl.sale_line_id, rl.item_id,
+            rl.quantity, rl.disposition, rl.refund_amount, rl.notes,
+            rl.created_at,
+            i.part_number as item_part_number,
+            i.description as item_description
+        FROM sale_return_lines rl
+        JOIN items i ON rl.item_id = i.item_id
+        WHERE rl.return_id = ANY($1)
+        ORDER BY rl.return_id, rl.return_line_id
+    `
+
+	lineRows, err := r.db.Pool.Query(ctx, lineQuery, returnIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer lineRows.Close()
+
+	for lineRows.Next() {
+		line := &models.SaleReturnLine{}
+		err := lineRows.Scan(
+			&line.ReturnLineID,
+			&line.ReturnID,
+			&line.SaleLineID,
+			&line.ItemID,
+			&line.Quantity,
+			&line.Disposition,
+			&line.RefundAmount,
+			&line.Notes,
+			&line.CreatedAt,
+			&line.ItemPartNumber,
+			&line.ItemDescription,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if saleReturn, ok := returnsByID[line.ReturnID]; ok {
+			saleReturn.Lines = append(saleReturn.Lines, line)
+		}
+	}
+
+	return returns, lineRows.Err()
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
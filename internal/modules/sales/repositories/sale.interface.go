@@ -15,4 +15,5 @@ type SaleRepository interface {
 	GetByTransactionNumber(ctx context.Context, transactionNumber string) (*models.Sale, error)
 	GetItemSales(ctx context.Context, itemID int) ([]*models.Sale, error)
 	GetCustomerSales(ctx context.Context, customerEmail string) ([]*models.Sale, error)
+	GetByCustomerID(ctx context.Context, customerID int) ([]*models.Sale, error)
 }
@@ -4,8 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
+	stockerrors "github.com/hsrvms/fixparts/internal/modules/inventory/stock/errors"
+	stockModels "github.com/hsrvms/fixparts/internal/modules/inventory/stock/models"
+	stock "github.com/hsrvms/fixparts/internal/modules/inventory/stock/repositories"
+	saleErrors "github.com/hsrvms/fixparts/internal/modules/sales/errors"
 	"github.com/hsrvms/fixparts/internal/modules/sales/models"
 	"github.com/hsrvms/fixparts/pkg/db"
 	"github.com/jackc/pgx/v5"
@@ -24,16 +29,14 @@ func NewPostgresSaleRepository(database *db.Database) SaleRepository {
 func (r *PostgresSaleRepository) GetAll(ctx context.Context, filter *models.SaleFilter) ([]*models.Sale, error) {
 	query := `
         SELECT
-            s.sale_id, s.date, s.item_id, s.quantity,
-            s.price_per_unit, s.total_price, s.transaction_number,
+            s.sale_id, s.date, s.transaction_number, s.customer_id,
             s.customer_name, s.customer_phone, s.customer_email,
-            s.sold_by, s.notes, s.created_at, s.updated_at,
-            i.part_number as item_part_number,
-            i.description as item_description,
-            c.category_name
+            s.sold_by, s.payment_method, s.subtotal, s.discount_amount,
+            s.tax_amount, s.total_amount, s.amount_paid,
+            s.notes, s.created_at, s.updated_at,
+            s.warehouse_id, w.code as warehouse_code
         FROM sales s
-        JOIN items i ON s.item_id = i.item_id
-        LEFT JOIN categories c ON i.category_id = c.category_id
+        JOIN warehouses w ON s.warehouse_id = w.warehouse_id
         WHERE 1=1
     `
 
@@ -43,7 +46,8 @@ func (r *PostgresSaleRepository) GetAll(ctx context.Context, filter *models.Sale
 
 	if filter != nil {
 		if filter.ItemID != nil {
-			conditions = append(conditions, fmt.Sprintf("s.item_id = $%d", paramCount))
+			conditions = append(conditions, fmt.Sprintf(
+				"EXISTS (SELECT 1 FROM sale_lines sl WHERE sl.sale_id = s.sale_id AND sl.item_id = $%d)", paramCount))
 			params = append(params, *filter.ItemID)
 			paramCount++
 		}
@@ -60,6 +64,12 @@ func (r *PostgresSaleRepository) GetAll(ctx context.Context, filter *models.Sale
 			paramCount++
 		}
 
+		if filter.CustomerID != nil {
+			conditions = append(conditions, fmt.Sprintf("s.customer_id = $%d", paramCount))
+			params = append(params, *filter.CustomerID)
+			paramCount++
+		}
+
 		if filter.CustomerName != nil {
 			conditions = append(conditions, fmt.Sprintf("s.customer_name ILIKE $%d", paramCount))
 			params = append(params, "%"+*filter.CustomerName+"%")
@@ -84,11 +94,23 @@ func (r *PostgresSaleRepository) GetAll(ctx context.Context, filter *models.Sale
 			paramCount++
 		}
 
+		if filter.WarehouseID != nil {
+			conditions = append(conditions, fmt.Sprintf("s.warehouse_id = $%d", paramCount))
+			params = append(params, *filter.WarehouseID)
+			paramCount++
+		}
+
 		if filter.SoldBy != nil {
 			conditions = append(conditions, fmt.Sprintf("s.sold_by = $%d", paramCount))
 			params = append(params, *filter.SoldBy)
 			paramCount++
 		}
+
+		if filter.PaymentMethod != nil {
+			conditions = append(conditions, fmt.Sprintf("s.payment_method = $%d", paramCount))
+			params = append(params, *filter.PaymentMethod)
+			paramCount++
+		}
 	}
 
 	if len(conditions) > 0 {
@@ -109,44 +131,51 @@ func (r *PostgresSaleRepository) GetAll(ctx context.Context, filter *models.Sale
 		err := rows.Scan(
 			&sale.SaleID,
 			&sale.Date,
-			&sale.ItemID,
-			&sale.Quantity,
-			&sale.PricePerUnit,
-			&sale.TotalPrice,
 			&sale.TransactionNumber,
+			&sale.CustomerID,
 			&sale.CustomerName,
 			&sale.CustomerPhone,
 			&sale.CustomerEmail,
 			&sale.SoldBy,
+			&sale.PaymentMethod,
+			&sale.Subtotal,
+			&sale.DiscountAmount,
+			&sale.TaxAmount,
+			&sale.TotalAmount,
+			&sale.AmountPaid,
 			&sale.Notes,
 			&sale.CreatedAt,
 			&sale.UpdatedAt,
-			&sale.ItemPartNumber,
-			&sale.ItemDescription,
-			&sale.CategoryName,
+			&sale.WarehouseID,
+			&sale.WarehouseCode,
 		)
 		if err != nil {
 			return nil, err
 		}
 		sales = append(sales, sale)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadLines(ctx, sales); err != nil {
+		return nil, err
+	}
 
-	return sales, rows.Err()
+	return sales, nil
 }
 
 func (r *PostgresSaleRepository) GetByID(ctx context.Context, id int) (*models.Sale, error) {
 	query := `
         SELECT
-            s.sale_id, s.date, s.item_id, s.quantity,
-            s.price_per_unit, s.total_price, s.transaction_number,
+            s.sale_id, s.date, s.transaction_number, s.customer_id,
             s.customer_name, s.customer_phone, s.customer_email,
-            s.sold_by, s.notes, s.created_at, s.updated_at,
-            i.part_number as item_part_number,
-            i.description as item_description,
-            c.category_name
+            s.sold_by, s.payment_method, s.subtotal, s.discount_amount,
+            s.tax_amount, s.total_amount, s.amount_paid,
+            s.notes, s.created_at, s.updated_at,
+            s.warehouse_id, w.code as warehouse_code
         FROM sales s
-        JOIN items i ON s.item_id = i.item_id
-        LEFT JOIN categories c ON i.category_id = c.category_id
+        JOIN warehouses w ON s.warehouse_id = w.warehouse_id
         WHERE s.sale_id = $1
     `
 
@@ -154,21 +183,23 @@ func (r *PostgresSaleRepository) GetByID(ctx context.Context, id int) (*models.S
 	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
 		&sale.SaleID,
 		&sale.Date,
-		&sale.ItemID,
-		&sale.Quantity,
-		&sale.PricePerUnit,
-		&sale.TotalPrice,
 		&sale.TransactionNumber,
+		&sale.CustomerID,
 		&sale.CustomerName,
 		&sale.CustomerPhone,
 		&sale.CustomerEmail,
 		&sale.SoldBy,
+		&sale.PaymentMethod,
+		&sale.Subtotal,
+		&sale.DiscountAmount,
+		&sale.TaxAmount,
+		&sale.TotalAmount,
+		&sale.AmountPaid,
 		&sale.Notes,
 		&sale.CreatedAt,
 		&sale.UpdatedAt,
-		&sale.ItemPartNumber,
-		&sale.ItemDescription,
-		&sale.CategoryName,
+		&sale.WarehouseID,
+		&sale.WarehouseCode,
 	)
 
 	if err != nil {
@@ -178,9 +209,17 @@ func (r *PostgresSaleRepository) GetByID(ctx context.Context, id int) (*models.S
 		return nil, err
 	}
 
+	if err := r.loadLines(ctx, []*models.Sale{sale}); err != nil {
+		return nil, err
+	}
+
 	return sale, nil
 }
 
+// Create inserts the sale header and all of its lines in a single transaction.
+// The sold items are locked and checked first, so of two tickets racing for
+// the last unit the first to lock it wins and the other gets
+// ErrInsufficientStock.
 func (r *PostgresSaleRepository) Create(ctx context.Context, sale *models.Sale) (int, error) {
 	tx, err := r.db.Pool.Begin(ctx)
 	if err != nil {
@@ -188,13 +227,26 @@ func (r *PostgresSaleRepository) Create(ctx context.Context, sale *models.Sale)
 	}
 	defer tx.Rollback(ctx)
 
-	// Insert the sale
+	if err := resolveCustomer(ctx, tx, sale); err != nil {
+		return 0, err
+	}
+
+	if sale.WarehouseID, err = resolveWarehouse(ctx, tx, sale.WarehouseID); err != nil {
+		return 0, err
+	}
+
+	if err := lockStock(ctx, tx, sale.WarehouseID, sale.Lines); err != nil {
+		return 0, err
+	}
+
+	// Insert the sale header
 	query := `
         INSERT INTO sales (
-            date, item_id, quantity, price_per_unit,
-            total_price, transaction_number, customer_name,
-            customer_phone, customer_email, sold_by, notes
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+            date, transaction_number, customer_id, customer_name,
+            customer_phone, customer_email, sold_by, payment_method,
+            subtotal, discount_amount, tax_amount, total_amount,
+            amount_paid, notes, warehouse_id
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
         RETURNING sale_id
     `
 
@@ -202,22 +254,38 @@ func (r *PostgresSaleRepository) Create(ctx context.Context, sale *models.Sale)
 	err = tx.QueryRow(
 		ctx, query,
 		sale.Date,
-		sale.ItemID,
-		sale.Quantity,
-		sale.PricePerUnit,
-		sale.TotalPrice,
 		sale.TransactionNumber,
+		sale.CustomerID,
 		sale.CustomerName,
 		sale.CustomerPhone,
 		sale.CustomerEmail,
 		sale.SoldBy,
+		sale.PaymentMethod,
+		sale.Subtotal,
+		sale.DiscountAmount,
+		sale.TaxAmount,
+		sale.TotalAmount,
+		sale.AmountPaid,
 		sale.Notes,
+		sale.WarehouseID,
 	).Scan(&id)
 
 	if err != nil {
 		return 0, err
 	}
 
+	// Insert the sale lines
+	for _, line := range sale.Lines {
+		line.SaleID = id
+		if line.SaleLineID, err = insertLine(ctx, tx, sale, line); err != nil {
+			return 0, stockError(err)
+		}
+	}
+
+	if err := updateCostOfGoods(ctx, tx, id); err != nil {
+		return 0, err
+	}
+
 	// Commit the transaction
 	if err = tx.Commit(ctx); err != nil {
 		return 0, err
@@ -226,37 +294,73 @@ func (r *PostgresSaleRepository) Create(ctx context.Context, sale *models.Sale)
 	return id, nil
 }
 
+// Update modifies the sale header and synchronises its lines: lines with an
+// ID are updated, lines without one are inserted and lines missing from the
+// sale are removed.
 func (r *PostgresSaleRepository) Update(ctx context.Context, sale *models.Sale) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := resolveCustomer(ctx, tx, sale); err != nil {
+		return err
+	}
+
+	var oldWarehouseID int
+	err = tx.QueryRow(ctx,
+		`SELECT warehouse_id FROM sales WHERE sale_id = $1 FOR UPDATE`, sale.SaleID,
+	).Scan(&oldWarehouseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return saleErrors.ErrSaleNotFound
+		}
+		return err
+	}
+
+	if sale.WarehouseID, err = resolveWarehouse(ctx, tx, sale.WarehouseID); err != nil {
+		return err
+	}
+
 	query := `
         UPDATE sales SET
             date = $2,
-            item_id = $3,
-            quantity = $4,
-            price_per_unit = $5,
-            total_price = $6,
-            transaction_number = $7,
-            customer_name = $8,
-            customer_phone = $9,
-            customer_email = $10,
-            sold_by = $11,
-            notes = $12
+            transaction_number = $3,
+            customer_id = $4,
+            customer_name = $5,
+            customer_phone = $6,
+            customer_email = $7,
+            sold_by = COALESCE(sold_by, $8),
+            payment_method = $9,
+            subtotal = $10,
+            discount_amount = $11,
+            tax_amount = $12,
+            total_amount = $13,
+            amount_paid = $14,
+            notes = $15,
+            warehouse_id = $16
         WHERE sale_id = $1
     `
 
-	result, err := r.db.Pool.Exec(
+	result, err := tx.Exec(
 		ctx, query,
 		sale.SaleID,
 		sale.Date,
-		sale.ItemID,
-		sale.Quantity,
-		sale.PricePerUnit,
-		sale.TotalPrice,
 		sale.TransactionNumber,
+		sale.CustomerID,
 		sale.CustomerName,
 		sale.CustomerPhone,
 		sale.CustomerEmail,
 		sale.SoldBy,
+		sale.PaymentMethod,
+		sale.Subtotal,
+		sale.DiscountAmount,
+		sale.TaxAmount,
+		sale.TotalAmount,
+		sale.AmountPaid,
 		sale.Notes,
+		sale.WarehouseID,
 	)
 
 	if err != nil {
@@ -264,40 +368,137 @@ func (r *PostgresSaleRepository) Update(ctx context.Context, sale *models.Sale)
 	}
 
 	if result.RowsAffected() == 0 {
-		return errors.New("sale not found")
+		return saleErrors.ErrSaleNotFound
 	}
 
-	return nil
+	// Give back or take the stock difference of every existing line that is
+	// removed, changed to another item or changed in quantity. A sale moved
+	// to another warehouse gives all of its stock back to the old one and
+	// takes the kept lines from the new one.
+	existing, err := lockLines(ctx, tx, sale.SaleID)
+	if err != nil {
+		return err
+	}
+
+	incoming := make(map[int]*models.SaleLine, len(sale.Lines))
+	keep := []int{}
+	for _, line := range sale.Lines {
+		if line.SaleLineID > 0 {
+			incoming[line.SaleLineID] = line
+			keep = append(keep, line.SaleLineID)
+		}
+	}
+
+	var corrections []*stockModels.StockMovement
+	for _, old := range existing {
+		line, ok := incoming[old.SaleLineID]
+		switch {
+		case oldWarehouseID != sale.WarehouseID:
+			corrections = append(corrections,
+				saleCorrection(sale, old, oldWarehouseID, old.ItemID, old.Quantity, "Sale moved to another warehouse"))
+			if ok {
+				corrections = append(corrections,
+					saleCorrection(sale, old, sale.WarehouseID, line.ItemID, -line.Quantity, "Sale moved to another warehouse"))
+			}
+		case !ok:
+			corrections = append(corrections,
+				saleCorrection(sale, old, sale.WarehouseID, old.ItemID, old.Quantity, "Line removed from sale"))
+		case line.ItemID != old.ItemID:
+			corrections = append(corrections,
+				saleCorrection(sale, old, sale.WarehouseID, old.ItemID, old.Quantity, "Item changed on sale line"),
+				saleCorrection(sale, old, sale.WarehouseID, line.ItemID, -line.Quantity, "Item changed on sale line"))
+		case line.Quantity != old.Quantity:
+			corrections = append(corrections,
+				saleCorrection(sale, old, sale.WarehouseID, old.ItemID, old.Quantity-line.Quantity, "Quantity changed on sale line"))
+		}
+	}
+
+	if err := stock.PostMovements(ctx, tx, corrections); err != nil {
+		return stockError(err)
+	}
+	addWarnings(sale, corrections...)
+
+	_, err = tx.Exec(ctx,
+		`DELETE FROM sale_lines WHERE sale_id = $1 AND NOT (sale_line_id = ANY($2))`,
+		sale.SaleID, keep,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range sale.Lines {
+		line.SaleID = sale.SaleID
+		if line.SaleLineID > 0 {
+			if err := updateLine(ctx, tx, line); err != nil {
+				return err
+			}
+			continue
+		}
+		if line.SaleLineID, err = insertLine(ctx, tx, sale, line); err != nil {
+			return stockError(err)
+		}
+	}
+
+	if err := updateCostOfGoods(ctx, tx, sale.SaleID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
+// Delete removes the sale and returns the stock of all of its lines
 func (r *PostgresSaleRepository) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM sales WHERE sale_id = $1`
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
 
-	result, err := r.db.Pool.Exec(ctx, query, id)
+	sale := &models.Sale{SaleID: id}
+	err = tx.QueryRow(ctx,
+		`SELECT sold_by, warehouse_id FROM sales WHERE sale_id = $1 FOR UPDATE`, id,
+	).Scan(&sale.SoldBy, &sale.WarehouseID)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return saleErrors.ErrSaleNotFound
+		}
 		return err
 	}
 
-	if result.RowsAffected() == 0 {
-		return errors.New("sale not found")
+	existing, err := lockLines(ctx, tx, id)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	corrections := make([]*stockModels.StockMovement, 0, len(existing))
+	for _, old := range existing {
+		corrections = append(corrections,
+			saleCorrection(sale, old, sale.WarehouseID, old.ItemID, old.Quantity, "Sale deleted"))
+	}
+
+	if err := stock.PostMovements(ctx, tx, corrections); err != nil {
+		return stockError(err)
+	}
+	addWarnings(sale, corrections...)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM sales WHERE sale_id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (r *PostgresSaleRepository) GetByTransactionNumber(ctx context.Context, transactionNumber string) (*models.Sale, error) {
 	query := `
         SELECT
-            s.sale_id, s.date, s.item_id, s.quantity,
-            s.price_per_unit, s.total_price, s.transaction_number,
+            s.sale_id, s.date, s.transaction_number, s.customer_id,
             s.customer_name, s.customer_phone, s.customer_email,
-            s.sold_by, s.notes, s.created_at, s.updated_at,
-            i.part_number as item_part_number,
-            i.description as item_description,
-            c.category_name
+            s.sold_by, s.payment_method, s.subtotal, s.discount_amount,
+            s.tax_amount, s.total_amount, s.amount_paid,
+            s.notes, s.created_at, s.updated_at,
+            s.warehouse_id, w.code as warehouse_code
         FROM sales s
-        JOIN items i ON s.item_id = i.item_id
-        LEFT JOIN categories c ON i.category_id = c.category_id
+        JOIN warehouses w ON s.warehouse_id = w.warehouse_id
         WHERE s.transaction_number = $1
     `
 
@@ -305,21 +506,23 @@ func (r *PostgresSaleRepository) GetByTransactionNumber(ctx context.Context, tra
 	err := r.db.Pool.QueryRow(ctx, query, transactionNumber).Scan(
 		&sale.SaleID,
 		&sale.Date,
-		&sale.ItemID,
-		&sale.Quantity,
-		&sale.PricePerUnit,
-		&sale.TotalPrice,
 		&sale.TransactionNumber,
+		&sale.CustomerID,
 		&sale.CustomerName,
 		&sale.CustomerPhone,
 		&sale.CustomerEmail,
 		&sale.SoldBy,
+		&sale.PaymentMethod,
+		&sale.Subtotal,
+		&sale.DiscountAmount,
+		&sale.TaxAmount,
+		&sale.TotalAmount,
+		&sale.AmountPaid,
 		&sale.Notes,
 		&sale.CreatedAt,
 		&sale.UpdatedAt,
-		&sale.ItemPartNumber,
-		&sale.ItemDescription,
-		&sale.CategoryName,
+		&sale.WarehouseID,
+		&sale.WarehouseCode,
 	)
 
 	if err != nil {
@@ -329,6 +532,10 @@ func (r *PostgresSaleRepository) GetByTransactionNumber(ctx context.Context, tra
 		return nil, err
 	}
 
+	if err := r.loadLines(ctx, []*models.Sale{sale}); err != nil {
+		return nil, err
+	}
+
 	return sale, nil
 }
 
@@ -345,3 +552,389 @@ func (r *PostgresSaleRepository) GetCustomerSales(ctx context.Context, customerE
 	}
 	return r.GetAll(ctx, filter)
 }
+
+func (r *PostgresSaleRepository) GetByCustomerID(ctx context.Context, customerID int) ([]*models.Sale, error) {
+	filter := &models.SaleFilter{
+		CustomerID: &customerID,
+	}
+	return r.GetAll(ctx, filter)
+}
+
+// resolveCustomer links the sale to a customer record. A given customer ID
+// must exist and fills in any contact details left blank on the ticket;
+// without one the sale is matched to a known customer by email
+// (case-insensitive) or phone number (digits only). Walk-in sales that match
+// no one stay unlinked.
+func resolveCustomer(ctx context.Context, tx pgx.Tx, sale *models.Sale) error {
+	var name string
+	var phone, email *string
+
+	if sale.CustomerID != nil {
+		err := tx.QueryRow(ctx,
+			`SELECT name, phone, email FROM customers WHERE customer_id = $1`,
+			*sale.CustomerID,
+		).Scan(&name, &phone, &email)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return saleErrors.ErrCustomerNotFound
+			}
+			return err
+		}
+
+		if isBlank(sale.CustomerName) {
+			sale.CustomerName = &name
+		}
+		if isBlank(sale.CustomerPhone) {
+			sale.CustomerPhone = phone
+		}
+		if isBlank(sale.CustomerEmail) {
+			sale.CustomerEmail = email
+		}
+		return nil
+	}
+
+	if isBlank(sale.CustomerEmail) && isBlank(sale.CustomerPhone) {
+		return nil
+	}
+
+	query := `
+        SELECT customer_id FROM customers
+        WHERE ($1::TEXT <> '' AND LOWER(email) = LOWER($1))
+           OR ($2::TEXT <> '' AND regexp_replace(phone, '\D', '', 'g') = $2)
+        ORDER BY COALESCE(LOWER(email) = LOWER($1), false) DESC, customer_id
+        LIMIT 1
+    `
+
+	var customerID int
+	err := tx.QueryRow(ctx, query,
+		valueOf(sale.CustomerEmail), digitsOf(sale.CustomerPhone),
+	).Scan(&customerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	sale.CustomerID = &customerID
+	return nil
+}
+
+func isBlank(s *string) bool {
+	return s == nil || strings.TrimSpace(*s) == ""
+}
+
+func valueOf(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return strings.TrimSpace(*s)
+}
+
+func digitsOf(s *string) string {
+	var b strings.Builder
+	for _, r := range valueOf(s) {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// loadLines fetches the lines of the given sales in one query and attaches
+// them to their headers
+func (r *PostgresSaleRepository) loadLines(ctx context.Context, sales []*models.Sale) error {
+	if len(sales) == 0 {
+		return nil
+	}
+
+	saleIDs := make([]int, 0, len(sales))
+	salesByID := make(map[int]*models.Sale, len(sales))
+	for _, sale := range sales {
+		sale.Lines = []*models.SaleLine{}
+		saleIDs = append(saleIDs, sale.SaleID)
+		salesByID[sale.SaleID] = sale
+	}
+
+	query := `
+        SELECT
+            sl.sale_line_id, sl.sale_id, sl.item_id, sl.quantity,
+            sl.price_per_unit, sl.total_price, sl.notes,
+            sl.created_at, sl.updated_at, sl.cost_of_goods,
+            COALESCE((
+                SELECT SUM(rl.quantity) FROM sale_return_lines rl
+                WHERE rl.sale_line_id = sl.sale_line_id
+            ), 0) as quantity_returned,
+            i.part_number as item_part_number,
+            i.description as item_description,
+            COALESCE(c.category_name, '') as category_name
+        FROM sale_lines sl
+        JOIN items i ON sl.item_id = i.item_id
+        LEFT JOIN categories c ON i.category_id = c.category_id
+        WHERE sl.sale_id = ANY($1)
+        ORDER BY sl.sale_id, sl.sale_line_id
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, saleIDs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		line := &models.SaleLine{}
+		err := rows.Scan(
+			&line.SaleLineID,
+			&line.SaleID,
+			&line.ItemID,
+			&line.Quantity,
+			&line.PricePerUnit,
+			&line.TotalPrice,
+			&line.Notes,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+			&line.CostOfGoods,
+			&line.QuantityReturned,
+			&line.ItemPartNumber,
+			&line.ItemDescription,
+			&line.CategoryName,
+		)
+		if err != nil {
+			return err
+		}
+		if sale, ok := salesByID[line.SaleID]; ok {
+			sale.Lines = append(sale.Lines, line)
+		}
+	}
+
+	return rows.Err()
+}
+
+// insertLine records a sale line and posts the matching outgoing movement to
+// the stock ledger
+func insertLine(ctx context.Context, tx pgx.Tx, sale *models.Sale, line *models.SaleLine) (int, error) {
+	query := `
+        INSERT INTO sale_lines (
+            sale_id, item_id, quantity, price_per_unit, total_price, notes
+        ) VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING sale_line_id
+    `
+
+	var id int
+	err := tx.QueryRow(
+		ctx, query,
+		line.SaleID,
+		line.ItemID,
+		line.Quantity,
+		line.PricePerUnit,
+		line.TotalPrice,
+		line.Notes,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	referenceType := stockModels.ReferenceTypeSale
+	movement := &stockModels.StockMovement{
+		ItemID:          line.ItemID,
+		WarehouseID:     sale.WarehouseID,
+		MovementType:    stockModels.MovementTypeSale,
+		Quantity:        -line.Quantity,
+		ReferenceType:   &referenceType,
+		ReferenceID:     &line.SaleID,
+		ReferenceLineID: &id,
+		PerformedBy:     sale.SoldBy,
+	}
+	if err := stock.PostMovement(ctx, tx, movement); err != nil {
+		return 0, err
+	}
+	addWarnings(sale, movement)
+
+	return id, nil
+}
+
+func updateLine(ctx context.Context, tx pgx.Tx, line *models.SaleLine) error {
+	query := `
+        UPDATE sale_lines SET
+            item_id = $3,
+            quantity = $4,
+            price_per_unit = $5,
+            total_price = $6,
+            notes = $7
+        WHERE sale_line_id = $1 AND sale_id = $2
+    `
+
+	result, err := tx.Exec(
+		ctx, query,
+		line.SaleLineID,
+		line.SaleID,
+		line.ItemID,
+		line.Quantity,
+		line.PricePerUnit,
+		line.TotalPrice,
+		line.Notes,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return saleErrors.ErrSaleLineNotFound
+	}
+
+	return nil
+}
+
+// updateCostOfGoods stores on every line of a sale the cost of the stock it
+// took, net of the corrections posted against it
+func updateCostOfGoods(ctx context.Context, tx pgx.Tx, saleID int) error {
+	_, err := tx.Exec(ctx, `
+        UPDATE sale_lines sl SET cost_of_goods = COALESCE((
+            SELECT -SUM(m.cost) FROM stock_movements m
+            WHERE m.reference_type = $2 AND m.reference_line_id = sl.sale_line_id
+        ), 0)
+        WHERE sl.sale_id = $1
+    `, saleID, stockModels.ReferenceTypeSale)
+	return err
+}
+
+// lockLines reads the stored lines of a sale and locks them for the rest of
+// the transaction
+func lockLines(ctx context.Context, tx pgx.Tx, saleID int) ([]*models.SaleLine, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT sale_line_id, item_id, quantity, cost_of_goods FROM sale_lines
+		 WHERE sale_id = $1 ORDER BY sale_line_id FOR UPDATE`,
+		saleID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []*models.SaleLine
+	for rows.Next() {
+		line := &models.SaleLine{SaleID: saleID}
+		if err := rows.Scan(&line.SaleLineID, &line.ItemID, &line.Quantity, &line.CostOfGoods); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+// lockStock locks the rows of the items sold on the given lines, in item_id
+// order so concurrent tickets queue up instead of deadlocking, and checks each
+// item has enough stock in the sale's warehouse for the total quantity sold on
+// the ticket. Items whose negative stock policy lets them go below zero are
+// only locked.
+func lockStock(ctx context.Context, tx pgx.Tx, warehouseID int, lines []*models.SaleLine) error {
+	requested := make(map[int]int, len(lines))
+	itemIDs := make([]int, 0, len(lines))
+	for _, line := range lines {
+		if _, ok := requested[line.ItemID]; !ok {
+			itemIDs = append(itemIDs, line.ItemID)
+		}
+		requested[line.ItemID] += line.Quantity
+	}
+	sort.Ints(itemIDs)
+
+	rows, err := tx.Query(ctx,
+		`SELECT i.item_id, i.part_number, COALESCE(ws.quantity, 0),
+		        item_negative_stock_policy(i.item_id), w.code
+		 FROM items i
+		 JOIN warehouses w ON w.warehouse_id = $2
+		 LEFT JOIN item_stock ws ON ws.item_id = i.item_id AND ws.warehouse_id = w.warehouse_id
+		 WHERE i.item_id = ANY($1) ORDER BY i.item_id FOR UPDATE OF i`,
+		itemIDs, warehouseID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := 0
+	for rows.Next() {
+		var itemID, currentStock int
+		var partNumber, policy, warehouse string
+		if err := rows.Scan(&itemID, &partNumber, &currentStock, &policy, &warehouse); err != nil {
+			return err
+		}
+		found++
+
+		if currentStock < requested[itemID] && policy == stockModels.NegativeStockDisallow {
+			return stockError(&stockerrors.InsufficientStockError{
+				PartNumber: partNumber,
+				Warehouse:  warehouse,
+				Available:  currentStock,
+				Requested:  requested[itemID],
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if found != len(itemIDs) {
+		return saleErrors.ErrInvalidItemID
+	}
+
+	return nil
+}
+
+// addWarnings copies the warnings raised by posted movements onto the sale
+func addWarnings(sale *models.Sale, movements ...*stockModels.StockMovement) {
+	for _, movement := range movements {
+		if movement.Warning != nil {
+			sale.Warnings = append(sale.Warnings, *movement.Warning)
+		}
+	}
+}
+
+// saleCorrection builds the movement that corrects the stock taken by a sale
+// line in a warehouse. A positive quantity gives stock back, a negative one
+// takes more. Stock given back returns at the cost it was sold at.
+func saleCorrection(sale *models.Sale, line *models.SaleLine, warehouseID, itemID, quantity int, notes string) *stockModels.StockMovement {
+	var unitCost *float64
+	if quantity > 0 && itemID == line.ItemID && line.Quantity > 0 {
+		cost := line.CostOfGoods / float64(line.Quantity)
+		unitCost = &cost
+	}
+
+	referenceType := stockModels.ReferenceTypeSale
+	return &stockModels.StockMovement{
+		ItemID:          itemID,
+		WarehouseID:     warehouseID,
+		MovementType:    stockModels.MovementTypeSaleCorrection,
+		Quantity:        quantity,
+		ReferenceType:   &referenceType,
+		ReferenceID:     &sale.SaleID,
+		ReferenceLineID: &line.SaleLineID,
+		PerformedBy:     sale.SoldBy,
+		Notes:           &notes,
+		UnitCost:        unitCost,
+	}
+}
+
+// resolveWarehouse picks the warehouse a sale is posted to, the default one
+// when none is given
+func resolveWarehouse(ctx context.Context, tx pgx.Tx, warehouseID int) (int, error) {
+	id, err := stock.ResolveWarehouse(ctx, tx, warehouseID)
+	if errors.Is(err, stockerrors.ErrWarehouseNotFound) {
+		return 0, saleErrors.ErrWarehouseNotFound
+	}
+	return id, err
+}
+
+// stockError translates ledger errors into sale errors, keeping the detail of
+// which item ran short
+func stockError(err error) error {
+	var short *stockerrors.InsufficientStockError
+	if errors.As(err, &short) {
+		return fmt.Errorf("%w: %s", saleErrors.ErrInsufficientStock, short.Error())
+	}
+	if errors.Is(err, stockerrors.ErrInsufficientStock) {
+		return saleErrors.ErrInsufficientStock
+	}
+	return err
+}
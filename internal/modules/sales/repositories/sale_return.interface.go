@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/sales/models"
+)
+
+type SaleReturnRepository interface {
+	GetAll(ctx context.Context, filter *models.SaleReturnFilter) ([]*models.SaleReturn, error)
+	GetByID(ctx context.Context, id int) (*models.SaleReturn, error)
+	Create(ctx context.Context, saleReturn *models.SaleReturn) (int, error)
+}
@@ -1,6 +1,8 @@
 package sales
 
 import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
 	"github.com/hsrvms/fixparts/internal/modules/sales/handlers"
 	"github.com/hsrvms/fixparts/internal/modules/sales/repositories"
 	"github.com/hsrvms/fixparts/internal/modules/sales/services"
@@ -13,7 +15,7 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
 	service := services.NewSaleService(repo)
 	handler := handlers.NewSaleHandler(service)
 
-	sales := api.Group("/sales")
+	sales := api.Group("/sales", authMiddleware.Guard(authModels.PermSalesRead, authModels.PermSalesWrite))
 	sales.GET("", handler.GetSales)
 	sales.GET("/:id", handler.GetSaleByID)
 	sales.POST("", handler.CreateSale)
@@ -21,4 +23,17 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
 	sales.DELETE("/:id", handler.DeleteSale)
 	sales.GET("/transaction/:transactionNumber", handler.GetByTransactionNumber)
 	sales.GET("/customer/:customerEmail", handler.GetCustomerSales)
+
+	api.GET("/customers/:customerId/sales", handler.GetSalesByCustomerID, authMiddleware.Require(authModels.PermSalesRead))
+
+	returnRepo := repositories.NewPostgresSaleReturnRepository(database)
+	returnService := services.NewSaleReturnService(returnRepo)
+	returnHandler := handlers.NewSaleReturnHandler(returnService)
+
+	sales.GET("/:id/returns", returnHandler.GetReturnsOfSale)
+	sales.POST("/:id/returns", returnHandler.CreateSaleReturn)
+
+	saleReturns := api.Group("/sale-returns", authMiddleware.Guard(authModels.PermSalesRead, authModels.PermSalesWrite))
+	saleReturns.GET("", returnHandler.GetSaleReturns)
+	saleReturns.GET("/:id", returnHandler.GetSaleReturnByID)
 }
@@ -12,7 +12,10 @@ type SaleService interface {
 	Create(ctx context.Context, sale *models.Sale) (int, error)
 	Update(ctx context.Context, sale *models.Sale) error
 	Delete(ctx context.Context, id int) error
+	// CanDelete runs the checks of Delete without deleting anything
+	CanDelete(ctx context.Context, id int) error
 	GetByTransactionNumber(ctx context.Context, transactionNumber string) (*models.Sale, error)
 	GetItemSales(ctx context.Context, itemID int) ([]*models.Sale, error)
 	GetCustomerSales(ctx context.Context, customerEmail string) ([]*models.Sale, error)
+	GetByCustomerID(ctx context.Context, customerID int) ([]*models.Sale, error)
 }
@@ -2,7 +2,11 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
+	"fmt"
+	"math"
+	"math/big"
 	"time"
 
 	saleErrors "github.com/hsrvms/fixparts/internal/modules/sales/errors"
@@ -46,8 +50,14 @@ func (s *saleService) Create(ctx context.Context, sale *models.Sale) (int, error
 		return 0, err
 	}
 
-	// Check if transaction number is unique if provided
-	if sale.TransactionNumber != "" {
+	// Generate a transaction number if not provided, otherwise check it is unique
+	if sale.TransactionNumber == "" {
+		transactionNumber, err := generateDocumentNumber("TXN")
+		if err != nil {
+			return 0, fmt.Errorf("failed to generate transaction number: %w", err)
+		}
+		sale.TransactionNumber = transactionNumber
+	} else {
 		existing, err := s.repo.GetByTransactionNumber(ctx, sale.TransactionNumber)
 		if err != nil {
 			return 0, err
@@ -62,9 +72,18 @@ func (s *saleService) Create(ctx context.Context, sale *models.Sale) (int, error
 		sale.Date = time.Now()
 	}
 
-	// Calculate total price if not provided
-	if sale.TotalPrice == 0 {
-		sale.TotalPrice = float64(sale.Quantity) * sale.PricePerUnit
+	if err := s.calculateTotals(sale); err != nil {
+		return 0, err
+	}
+
+	// Assume the ticket was paid in full if no amount is given, unless it
+	// was put on the customer's account
+	if sale.AmountPaid == nil {
+		amountPaid := sale.TotalAmount
+		if sale.PaymentMethod == models.PaymentMethodAccount {
+			amountPaid = 0
+		}
+		sale.AmountPaid = &amountPaid
 	}
 
 	return s.repo.Create(ctx, sale)
@@ -88,6 +107,25 @@ func (s *saleService) Update(ctx context.Context, sale *models.Sale) error {
 	if existing == nil {
 		return saleErrors.ErrSaleNotFound
 	}
+	if hasReturns(existing) {
+		return saleErrors.ErrSaleHasReturns
+	}
+
+	// Lines being updated must belong to this sale
+	existingLines := make(map[int]bool, len(existing.Lines))
+	for _, line := range existing.Lines {
+		existingLines[line.SaleLineID] = true
+	}
+	for _, line := range sale.Lines {
+		if line.SaleLineID > 0 && !existingLines[line.SaleLineID] {
+			return saleErrors.ErrSaleLineNotFound
+		}
+	}
+
+	// Keep the existing transaction number if none is given
+	if sale.TransactionNumber == "" {
+		sale.TransactionNumber = existing.TransactionNumber
+	}
 
 	// Check if transaction number is unique if changed
 	if sale.TransactionNumber != existing.TransactionNumber {
@@ -100,13 +138,37 @@ func (s *saleService) Update(ctx context.Context, sale *models.Sale) error {
 		}
 	}
 
-	// Recalculate total price
-	sale.TotalPrice = float64(sale.Quantity) * sale.PricePerUnit
+	if sale.Date.IsZero() {
+		sale.Date = existing.Date
+	}
+
+	// Keep the sale in its warehouse if none is given
+	if sale.WarehouseID == 0 {
+		sale.WarehouseID = existing.WarehouseID
+	}
+
+	// Recalculate totals
+	if err := s.calculateTotals(sale); err != nil {
+		return err
+	}
+
+	// Keep the stored payment if none is given
+	if sale.AmountPaid == nil {
+		sale.AmountPaid = existing.AmountPaid
+	}
 
 	return s.repo.Update(ctx, sale)
 }
 
 func (s *saleService) Delete(ctx context.Context, id int) error {
+	if err := s.CanDelete(ctx, id); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *saleService) CanDelete(ctx context.Context, id int) error {
 	if id <= 0 {
 		return saleErrors.ErrInvalidSaleID
 	}
@@ -120,7 +182,13 @@ func (s *saleService) Delete(ctx context.Context, id int) error {
 		return saleErrors.ErrSaleNotFound
 	}
 
-	return s.repo.Delete(ctx, id)
+	// Returned parts are accounted for on their return documents, so the
+	// sale they point at has to stay as it was
+	if hasReturns(existing) {
+		return saleErrors.ErrSaleHasReturns
+	}
+
+	return nil
 }
 
 func (s *saleService) GetByTransactionNumber(ctx context.Context, transactionNumber string) (*models.Sale, error) {
@@ -128,7 +196,15 @@ func (s *saleService) GetByTransactionNumber(ctx context.Context, transactionNum
 		return nil, errors.New("transaction number is required")
 	}
 
-	return s.repo.GetByTransactionNumber(ctx, transactionNumber)
+	sale, err := s.repo.GetByTransactionNumber(ctx, transactionNumber)
+	if err != nil {
+		return nil, err
+	}
+	if sale == nil {
+		return nil, saleErrors.ErrSaleNotFound
+	}
+
+	return sale, nil
 }
 
 func (s *saleService) GetItemSales(ctx context.Context, itemID int) ([]*models.Sale, error) {
@@ -147,16 +223,40 @@ func (s *saleService) GetCustomerSales(ctx context.Context, customerEmail string
 	return s.repo.GetCustomerSales(ctx, customerEmail)
 }
 
+func (s *saleService) GetByCustomerID(ctx context.Context, customerID int) ([]*models.Sale, error) {
+	if customerID <= 0 {
+		return nil, saleErrors.ErrInvalidCustomerID
+	}
+
+	return s.repo.GetByCustomerID(ctx, customerID)
+}
+
 // Helper functions
 func (s *saleService) validateSale(sale *models.Sale) error {
-	if sale.ItemID <= 0 {
-		return saleErrors.ErrInvalidItemID
+	if len(sale.Lines) == 0 {
+		return saleErrors.ErrNoSaleLines
 	}
-	if sale.Quantity <= 0 {
-		return saleErrors.ErrInvalidQuantity
+	for _, line := range sale.Lines {
+		if err := s.validateSaleLine(line); err != nil {
+			return err
+		}
 	}
-	if sale.PricePerUnit <= 0 {
-		return saleErrors.ErrInvalidPricePerUnit
+
+	if sale.PaymentMethod == "" {
+		sale.PaymentMethod = models.PaymentMethodCash
+	}
+	switch sale.PaymentMethod {
+	case models.PaymentMethodCash, models.PaymentMethodCard,
+		models.PaymentMethodBankTransfer, models.PaymentMethodAccount:
+	default:
+		return saleErrors.ErrInvalidPaymentMethod
+	}
+
+	if sale.DiscountAmount < 0 || sale.TaxAmount < 0 || (sale.AmountPaid != nil && *sale.AmountPaid < 0) {
+		return saleErrors.ErrInvalidAmount
+	}
+	if sale.CustomerID != nil && *sale.CustomerID <= 0 {
+		return saleErrors.ErrInvalidCustomerID
 	}
 	if !sale.Date.IsZero() && sale.Date.After(time.Now()) {
 		return saleErrors.ErrInvalidDate
@@ -164,9 +264,59 @@ func (s *saleService) validateSale(sale *models.Sale) error {
 
 	// Additional validations could be added here:
 	// - Check if item exists
-	// - Check if item has sufficient stock
 	// - Validate email format if provided
 	// - etc.
 
 	return nil
 }
+
+func (s *saleService) validateSaleLine(line *models.SaleLine) error {
+	if line.ItemID <= 0 {
+		return saleErrors.ErrInvalidItemID
+	}
+	if line.Quantity <= 0 {
+		return saleErrors.ErrInvalidQuantity
+	}
+	if line.PricePerUnit <= 0 {
+		return saleErrors.ErrInvalidPricePerUnit
+	}
+	return nil
+}
+
+// calculateTotals computes line totals and the header subtotal and total
+func (s *saleService) calculateTotals(sale *models.Sale) error {
+	subtotal := 0.0
+	for _, line := range sale.Lines {
+		line.TotalPrice = roundCurrency(float64(line.Quantity) * line.PricePerUnit)
+		subtotal += line.TotalPrice
+	}
+	sale.Subtotal = roundCurrency(subtotal)
+
+	if sale.DiscountAmount > sale.Subtotal {
+		return saleErrors.ErrDiscountExceedsSubtotal
+	}
+
+	sale.TotalAmount = roundCurrency(sale.Subtotal - sale.DiscountAmount + sale.TaxAmount)
+	return nil
+}
+
+func hasReturns(sale *models.Sale) bool {
+	for _, line := range sale.Lines {
+		if line.QuantityReturned > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func generateDocumentNumber(prefix string) (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(10000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%s-%04d", prefix, time.Now().Format("060102150405"), n.Int64()), nil
+}
@@ -0,0 +1,14 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hsrvms/fixparts/internal/modules/sales/models"
+)
+
+type SaleReturnService interface {
+	GetAll(ctx context.Context, filter *models.SaleReturnFilter) ([]*models.SaleReturn, error)
+	GetByID(ctx context.Context, id int) (*models.SaleReturn, error)
+	GetSaleReturns(ctx context.Context, saleID int) ([]*models.SaleReturn, error)
+	Create(ctx context.Context, saleReturn *models.SaleReturn) (int, error)
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	saleErrors "github.com/hsrvms/fixparts/internal/modules/sales/errors"
+	"github.com/hsrvms/fixparts/internal/modules/sales/models"
+	"github.com/hsrvms/fixparts/internal/modules/sales/repositories"
+)
+
+type saleReturnService struct {
+	repo repositories.SaleReturnRepository
+}
+
+func NewSaleReturnService(repo repositories.SaleReturnRepository) SaleReturnService {
+	return &saleReturnService{
+		repo: repo,
+	}
+}
+
+func (s *saleReturnService) GetAll(ctx context.Context, filter *models.SaleReturnFilter) ([]*models.SaleReturn, error) {
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *saleReturnService) GetByID(ctx context.Context, id int) (*models.SaleReturn, error) {
+	if id <= 0 {
+		return nil, saleErrors.ErrInvalidReturnID
+	}
+
+	saleReturn, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if saleReturn == nil {
+		return nil, saleErrors.ErrReturnNotFound
+	}
+
+	return saleReturn, nil
+}
+
+func (s *saleReturnService) GetSaleReturns(ctx context.Context, saleID int) ([]*models.SaleReturn, error) {
+	if saleID <= 0 {
+		return nil, saleErrors.ErrInvalidSaleID
+	}
+
+	return s.repo.GetAll(ctx, &models.SaleReturnFilter{SaleID: &saleID})
+}
+
+func (s *saleReturnService) Create(ctx context.Context, saleReturn *models.SaleReturn) (int, error) {
+	if err := s.validateReturn(saleReturn); err != nil {
+		return 0, err
+	}
+
+	returnNumber, err := generateDocumentNumber("RET")
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate return number: %w", err)
+	}
+	saleReturn.ReturnNumber = returnNumber
+
+	if saleReturn.Date.IsZero() {
+		saleReturn.Date = time.Now()
+	}
+
+	return s.repo.Create(ctx, saleReturn)
+}
+
+// Helper functions
+func (s *saleReturnService) validateReturn(saleReturn *models.SaleReturn) error {
+	if saleReturn.SaleID <= 0 {
+		return saleErrors.ErrInvalidSaleID
+	}
+	if len(saleReturn.Lines) == 0 {
+		return saleErrors.ErrNoReturnLines
+	}
+
+	switch saleReturn.ReasonCode {
+	case models.ReturnReasonDefective, models.ReturnReasonWrongPart,
+		models.ReturnReasonNotNeeded, models.ReturnReasonWarranty,
+		models.ReturnReasonOther:
+	default:
+		return saleErrors.ErrInvalidReasonCode
+	}
+
+	if !saleReturn.Date.IsZero() && saleReturn.Date.After(time.Now()) {
+		return saleErrors.ErrInvalidDate
+	}
+
+	seen := make(map[int]bool, len(saleReturn.Lines))
+	for _, line := range saleReturn.Lines {
+		if line.SaleLineID <= 0 {
+			return saleErrors.ErrSaleLineNotFound
+		}
+		if seen[line.SaleLineID] {
+			return saleErrors.ErrDuplicateReturnLine
+		}
+		seen[line.SaleLineID] = true
+
+		if line.Quantity <= 0 {
+			return saleErrors.ErrInvalidQuantity
+		}
+		if line.RefundAmount < 0 {
+			return saleErrors.ErrInvalidRefundAmount
+		}
+		line.RefundAmount = roundCurrency(line.RefundAmount)
+
+		// Staff must decide what happens to each part; a defective part put
+		// back on the shelf would be sold again
+		if line.Disposition != models.DispositionRestock && line.Disposition != models.DispositionScrap {
+			return saleErrors.ErrInvalidDisposition
+		}
+	}
+
+	return nil
+}
@@ -7,4 +7,5 @@ var (
 	ErrInvalidSupplierID     = errors.New("invalid supplier ID")
 	ErrDuplicateSupplierName = errors.New("supplier name already exists")
 	ErrSupplierHasItems      = errors.New("cannot delete supplier with associated items")
+	ErrInvalidLeadTime       = errors.New("lead time cannot be negative")
 )
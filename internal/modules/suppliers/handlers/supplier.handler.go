@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"strconv"
 
+	auditMiddleware "github.com/hsrvms/fixparts/internal/modules/audit/middleware"
+	auditModels "github.com/hsrvms/fixparts/internal/modules/audit/models"
 	supplierErrors "github.com/hsrvms/fixparts/internal/modules/suppliers/errors"
 	"github.com/hsrvms/fixparts/internal/modules/suppliers/models"
 	"github.com/hsrvms/fixparts/internal/modules/suppliers/services"
@@ -75,6 +77,8 @@ func (h *SupplierHandler) CreateSupplier(c echo.Context) error {
 	id, err := h.service.Create(ctx, supplier)
 	if err != nil {
 		switch err {
+		case supplierErrors.ErrInvalidLeadTime:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		case supplierErrors.ErrDuplicateSupplierName:
 			return echo.NewHTTPError(http.StatusConflict, err.Error())
 		default:
@@ -83,6 +87,13 @@ func (h *SupplierHandler) CreateSupplier(c echo.Context) error {
 	}
 
 	supplier.SupplierID = id
+
+	created, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordCreate(c, auditModels.EntitySupplier, id, created)
+
 	return c.JSON(http.StatusCreated, supplier)
 }
 
@@ -100,11 +111,23 @@ func (h *SupplierHandler) UpdateSupplier(c echo.Context) error {
 	supplier.SupplierID = id
 
 	ctx := c.Request().Context()
+	existing, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		switch err {
+		case supplierErrors.ErrSupplierNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
 	err = h.service.Update(ctx, supplier)
 	if err != nil {
 		switch err {
 		case supplierErrors.ErrSupplierNotFound:
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case supplierErrors.ErrInvalidLeadTime:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		case supplierErrors.ErrDuplicateSupplierName:
 			return echo.NewHTTPError(http.StatusConflict, err.Error())
 		default:
@@ -112,6 +135,12 @@ func (h *SupplierHandler) UpdateSupplier(c echo.Context) error {
 		}
 	}
 
+	updated, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordUpdate(c, auditModels.EntitySupplier, id, existing, updated)
+
 	return c.JSON(http.StatusOK, supplier)
 }
 
@@ -123,6 +152,16 @@ func (h *SupplierHandler) DeleteSupplier(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
+	existing, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		switch err {
+		case supplierErrors.ErrSupplierNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
 	err = h.service.Delete(ctx, id)
 	if err != nil {
 		switch err {
@@ -134,6 +173,7 @@ func (h *SupplierHandler) DeleteSupplier(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 	}
+	auditMiddleware.RecordDelete(c, auditModels.EntitySupplier, id, existing)
 
 	return c.NoContent(http.StatusNoContent)
 }
@@ -23,7 +23,7 @@ func NewPostgresSupplierRepository(database *db.Database) SupplierRepository {
 func (r *PostgresSupplierRepository) GetAll(ctx context.Context, filter *models.SupplierFilter) ([]*models.Supplier, error) {
 	query := `
         SELECT DISTINCT s.supplier_id, s.name, s.contact_person, s.phone, s.email,
-               s.address, s.tax_id, s.payment_terms, s.notes, s.created_at, s.updated_at
+               s.address, s.tax_id, s.payment_terms, s.lead_time_days, s.notes, s.created_at, s.updated_at
         FROM suppliers s
     `
 	params := []interface{}{}
@@ -67,6 +67,7 @@ func (r *PostgresSupplierRepository) GetAll(ctx context.Context, filter *models.
 			&supplier.Address,
 			&supplier.TaxID,
 			&supplier.PaymentTerms,
+			&supplier.LeadTimeDays,
 			&supplier.Notes,
 			&supplier.CreatedAt,
 			&supplier.UpdatedAt,
@@ -83,7 +84,7 @@ func (r *PostgresSupplierRepository) GetAll(ctx context.Context, filter *models.
 func (r *PostgresSupplierRepository) GetByID(ctx context.Context, id int) (*models.Supplier, error) {
 	query := `
         SELECT supplier_id, name, contact_person, phone, email,
-               address, tax_id, payment_terms, notes, created_at, updated_at
+               address, tax_id, payment_terms, lead_time_days, notes, created_at, updated_at
         FROM suppliers
         WHERE supplier_id = $1
     `
@@ -98,6 +99,7 @@ func (r *PostgresSupplierRepository) GetByID(ctx context.Context, id int) (*mode
 		&supplier.Address,
 		&supplier.TaxID,
 		&supplier.PaymentTerms,
+		&supplier.LeadTimeDays,
 		&supplier.Notes,
 		&supplier.CreatedAt,
 		&supplier.UpdatedAt,
@@ -117,8 +119,8 @@ func (r *PostgresSupplierRepository) Create(ctx context.Context, supplier *model
 	query := `
         INSERT INTO suppliers (
             name, contact_person, phone, email, address,
-            tax_id, payment_terms, notes
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+            tax_id, payment_terms, lead_time_days, notes
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
         RETURNING supplier_id
     `
 
@@ -132,6 +134,7 @@ func (r *PostgresSupplierRepository) Create(ctx context.Context, supplier *model
 		supplier.Address,
 		supplier.TaxID,
 		supplier.PaymentTerms,
+		supplier.LeadTimeDays,
 		supplier.Notes,
 	).Scan(&id)
 
@@ -152,7 +155,8 @@ func (r *PostgresSupplierRepository) Update(ctx context.Context, supplier *model
             address = $6,
             tax_id = $7,
             payment_terms = $8,
-            notes = $9
+            lead_time_days = $9,
+            notes = $10
         WHERE supplier_id = $1
     `
 
@@ -166,6 +170,7 @@ func (r *PostgresSupplierRepository) Update(ctx context.Context, supplier *model
 		supplier.Address,
 		supplier.TaxID,
 		supplier.PaymentTerms,
+		supplier.LeadTimeDays,
 		supplier.Notes,
 	)
 
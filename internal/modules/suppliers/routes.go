@@ -1,6 +1,8 @@
 package suppliers
 
 import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
 	"github.com/hsrvms/fixparts/internal/modules/suppliers/handlers"
 	"github.com/hsrvms/fixparts/internal/modules/suppliers/repositories"
 	"github.com/hsrvms/fixparts/internal/modules/suppliers/services"
@@ -13,7 +15,7 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
 	service := services.NewSupplierService(repo)
 	handler := handlers.NewSupplierHandler(service)
 
-	suppliers := api.Group("/suppliers")
+	suppliers := api.Group("/suppliers", authMiddleware.Guard(authModels.PermSuppliersRead, authModels.PermSuppliersWrite))
 	suppliers.GET("", handler.GetSuppliers)
 	suppliers.GET("/:id", handler.GetSupplierByID)
 	suppliers.POST("", handler.CreateSupplier)
@@ -130,6 +130,9 @@ func (s *supplierService) validateSupplier(supplier *models.Supplier) error {
 	if supplier.Name == "" {
 		return errors.New("supplier name is required")
 	}
+	if supplier.LeadTimeDays < 0 {
+		return supplierErrors.ErrInvalidLeadTime
+	}
 	// Add additional validations as needed
 	return nil
 }
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"strconv"
 
+	auditMiddleware "github.com/hsrvms/fixparts/internal/modules/audit/middleware"
+	auditModels "github.com/hsrvms/fixparts/internal/modules/audit/models"
 	"github.com/hsrvms/fixparts/internal/modules/vehicles/makes/models"
 	"github.com/hsrvms/fixparts/internal/modules/vehicles/makes/services"
 	"github.com/labstack/echo/v4"
@@ -62,6 +64,13 @@ func (h *VehicleMakeHandler) CreateMake(c echo.Context) error {
 	}
 
 	make.MakeID = id
+
+	created, err := h.service.GetMakeByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordCreate(c, auditModels.EntityVehicleMake, id, created)
+
 	return c.JSON(http.StatusCreated, make)
 }
 
@@ -78,6 +87,14 @@ func (h *VehicleMakeHandler) UpdateMake(c echo.Context) error {
 	make.MakeID = id
 
 	ctx := c.Request().Context()
+	existing, err := h.service.GetMakeByID(ctx, id)
+	if err != nil {
+		if err == vehicleErrors.ErrMakeNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	err = h.service.UpdateMake(ctx, make)
 	if err != nil {
 		switch err {
@@ -88,6 +105,12 @@ func (h *VehicleMakeHandler) UpdateMake(c echo.Context) error {
 		}
 	}
 
+	updated, err := h.service.GetMakeByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordUpdate(c, auditModels.EntityVehicleMake, id, existing, updated)
+
 	return c.JSON(http.StatusOK, make)
 }
 
@@ -98,6 +121,14 @@ func (h *VehicleMakeHandler) DeleteMake(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
+	existing, err := h.service.GetMakeByID(ctx, id)
+	if err != nil {
+		if err == vehicleErrors.ErrMakeNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	err = h.service.DeleteMake(ctx, id)
 	if err != nil {
 		switch err {
@@ -107,6 +138,7 @@ func (h *VehicleMakeHandler) DeleteMake(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 	}
+	auditMiddleware.RecordDelete(c, auditModels.EntityVehicleMake, id, existing)
 
 	return c.NoContent(http.StatusNoContent)
 }
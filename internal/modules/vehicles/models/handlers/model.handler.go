@@ -8,6 +8,8 @@ import (
 	"github.com/hsrvms/fixparts/internal/modules/vehicles/models/models"
 	"github.com/hsrvms/fixparts/internal/modules/vehicles/models/services"
 
+	auditMiddleware "github.com/hsrvms/fixparts/internal/modules/audit/middleware"
+	auditModels "github.com/hsrvms/fixparts/internal/modules/audit/models"
 	"github.com/labstack/echo/v4"
 )
 
@@ -64,6 +66,13 @@ func (h *VehicleModelHandler) CreateModel(c echo.Context) error {
 	}
 
 	model.ModelID = id
+
+	created, err := h.service.GetModelByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordCreate(c, auditModels.EntityVehicleModel, id, created)
+
 	return c.JSON(http.StatusCreated, model)
 }
 
@@ -80,6 +89,14 @@ func (h *VehicleModelHandler) UpdateModel(c echo.Context) error {
 	model.ModelID = id
 
 	ctx := c.Request().Context()
+	existing, err := h.service.GetModelByID(ctx, id)
+	if err != nil {
+		if err == vehicleErrors.ErrModelNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	err = h.service.UpdateModel(ctx, model)
 	if err != nil {
 		switch err {
@@ -92,6 +109,12 @@ func (h *VehicleModelHandler) UpdateModel(c echo.Context) error {
 		}
 	}
 
+	updated, err := h.service.GetModelByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordUpdate(c, auditModels.EntityVehicleModel, id, existing, updated)
+
 	return c.JSON(http.StatusOK, model)
 }
 
@@ -102,6 +125,14 @@ func (h *VehicleModelHandler) DeleteModel(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
+	existing, err := h.service.GetModelByID(ctx, id)
+	if err != nil {
+		if err == vehicleErrors.ErrModelNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	err = h.service.DeleteModel(ctx, id)
 	if err != nil {
 		switch err {
@@ -111,6 +142,7 @@ func (h *VehicleModelHandler) DeleteModel(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 	}
+	auditMiddleware.RecordDelete(c, auditModels.EntityVehicleModel, id, existing)
 
 	return c.NoContent(http.StatusNoContent)
 }
@@ -1,6 +1,8 @@
 package vehicles
 
 import (
+	authMiddleware "github.com/hsrvms/fixparts/internal/modules/auth/middleware"
+	authModels "github.com/hsrvms/fixparts/internal/modules/auth/models"
 	vehicleMakes "github.com/hsrvms/fixparts/internal/modules/vehicles/makes"
 	vehicleModels "github.com/hsrvms/fixparts/internal/modules/vehicles/models"
 	vehicleSubmodels "github.com/hsrvms/fixparts/internal/modules/vehicles/submodels"
@@ -11,7 +13,7 @@ import (
 
 func RegisterRoutes(e *echo.Echo, api *echo.Group, database *db.Database) {
 
-	vehicleGroup := api.Group("/vehicles")
+	vehicleGroup := api.Group("/vehicles", authMiddleware.Guard(authModels.PermVehiclesRead, authModels.PermVehiclesWrite))
 
 	vehicleMakes.RegisterRoutes(e, vehicleGroup, database)
 	vehicleModels.RegisterRoutes(e, vehicleGroup, database)
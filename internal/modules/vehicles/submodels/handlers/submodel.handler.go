@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"strconv"
 
+	auditMiddleware "github.com/hsrvms/fixparts/internal/modules/audit/middleware"
+	auditModels "github.com/hsrvms/fixparts/internal/modules/audit/models"
 	vehicleErrors "github.com/hsrvms/fixparts/internal/modules/vehicles/errors"
 	"github.com/hsrvms/fixparts/internal/modules/vehicles/submodels/models"
 	"github.com/hsrvms/fixparts/internal/modules/vehicles/submodels/services"
@@ -63,6 +65,13 @@ func (h *VehicleSubmodelHandler) CreateSubmodel(c echo.Context) error {
 	}
 
 	submodel.SubmodelID = id
+
+	created, err := h.service.GetSubmodelByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordCreate(c, auditModels.EntityVehicleSubmodel, id, created)
+
 	return c.JSON(http.StatusCreated, submodel)
 }
 
@@ -79,6 +88,14 @@ func (h *VehicleSubmodelHandler) UpdateSubmodel(c echo.Context) error {
 	submodel.SubmodelID = id
 
 	ctx := c.Request().Context()
+	existing, err := h.service.GetSubmodelByID(ctx, id)
+	if err != nil {
+		if err == vehicleErrors.ErrSubmodelNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	err = h.service.UpdateSubmodel(ctx, submodel)
 	if err != nil {
 		switch err {
@@ -91,6 +108,12 @@ func (h *VehicleSubmodelHandler) UpdateSubmodel(c echo.Context) error {
 		}
 	}
 
+	updated, err := h.service.GetSubmodelByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	auditMiddleware.RecordUpdate(c, auditModels.EntityVehicleSubmodel, id, existing, updated)
+
 	return c.JSON(http.StatusOK, submodel)
 }
 
@@ -101,6 +124,14 @@ func (h *VehicleSubmodelHandler) DeleteSubmodel(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
+	existing, err := h.service.GetSubmodelByID(ctx, id)
+	if err != nil {
+		if err == vehicleErrors.ErrSubmodelNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	err = h.service.DeleteSubmodel(ctx, id)
 	if err != nil {
 		switch err {
@@ -110,6 +141,7 @@ func (h *VehicleSubmodelHandler) DeleteSubmodel(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 	}
+	auditMiddleware.RecordDelete(c, auditModels.EntityVehicleSubmodel, id, existing)
 
 	return c.NoContent(http.StatusNoContent)
 }
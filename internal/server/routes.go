@@ -3,9 +3,15 @@ package server
 import (
 	"net/http"
 
+	"github.com/hsrvms/fixparts/internal/modules/analytics"
+	"github.com/hsrvms/fixparts/internal/modules/audit"
+	"github.com/hsrvms/fixparts/internal/modules/auth"
+	"github.com/hsrvms/fixparts/internal/modules/customers"
 	"github.com/hsrvms/fixparts/internal/modules/dashboard"
+	"github.com/hsrvms/fixparts/internal/modules/forecasting"
 	"github.com/hsrvms/fixparts/internal/modules/inventory"
 	"github.com/hsrvms/fixparts/internal/modules/purchases"
+	"github.com/hsrvms/fixparts/internal/modules/reports"
 	"github.com/hsrvms/fixparts/internal/modules/sales"
 	"github.com/hsrvms/fixparts/internal/modules/suppliers"
 	"github.com/hsrvms/fixparts/internal/modules/vehicles"
@@ -19,11 +25,17 @@ func (s *Server) initRoutes() {
 		return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
 	})
 
+	auth.RegisterRoutes(s.Echo, api, s.DB, &s.Config.Auth)
+	audit.RegisterRoutes(s.Echo, api, s.DB)
 	dashboard.RegisterRoutes(s.Echo, api, s.DB)
 	inventory.RegisterRoutes(s.Echo, api, s.DB)
 	vehicles.RegisterRoutes(s.Echo, api, s.DB)
 	suppliers.RegisterRoutes(api, s.DB)
 	purchases.RegisterRoutes(api, s.DB)
 	sales.RegisterRoutes(api, s.DB)
+	customers.RegisterRoutes(api, s.DB)
+	forecasting.RegisterRoutes(api, s.DB)
+	reports.RegisterRoutes(api, s.DB)
+	analytics.RegisterRoutes(api, s.DB)
 
 }
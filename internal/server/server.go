@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -24,6 +25,7 @@ type Server struct {
 
 func New(cfg *config.Config, database *db.Database) *Server {
 	e := echo.New()
+	e.IPExtractor = ipExtractor(cfg.Server.TrustedProxies)
 
 	// e.Use(middleware.Logger())
 	// e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
@@ -47,6 +49,31 @@ func New(cfg *config.Config, database *db.Database) *Server {
 	return server
 }
 
+// ipExtractor takes the client IP from X-Forwarded-For only when the request
+// comes through one of the trusted proxies, so sessions, API key usage and
+// the audit log record an address the client cannot forge
+func ipExtractor(trustedProxies []string) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range trustedProxies {
+		_, ipRange, err := net.ParseCIDR(proxy)
+		if err != nil {
+			log.Printf("Ignoring invalid trusted proxy range %q: %v", proxy, err)
+			continue
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
 func (s *Server) Start() {
 	addr := fmt.Sprintf(":%d", s.Config.Server.Port)
 
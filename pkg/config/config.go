@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
+	Auth     AuthConfig
 }
 
 type ServerConfig struct {
@@ -17,6 +19,9 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// TrustedProxies lists the CIDR ranges whose X-Forwarded-For header is
+	// believed. Without any, the client IP is the connection's address.
+	TrustedProxies []string
 }
 
 type DatabaseConfig struct {
@@ -28,13 +33,21 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+type AuthConfig struct {
+	SessionTTL    time.Duration
+	SecureCookie  bool
+	AdminUsername string
+	AdminPassword string
+}
+
 func New() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:         getEnvAsInt("SERVER_PORT", 8080),
-			ReadTimeout:  getEnvAsDuration("SERVER_READ_TIMEOUT", 10*time.Second),
-			WriteTimeout: getEnvAsDuration("SERVER_WRITE_TIMEOUT", 10*time.Second),
-			IdleTimeout:  getEnvAsDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
+			Port:           getEnvAsInt("SERVER_PORT", 8080),
+			ReadTimeout:    getEnvAsDuration("SERVER_READ_TIMEOUT", 10*time.Second),
+			WriteTimeout:   getEnvAsDuration("SERVER_WRITE_TIMEOUT", 10*time.Second),
+			IdleTimeout:    getEnvAsDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
+			TrustedProxies: getEnvAsSlice("SERVER_TRUSTED_PROXIES"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -44,6 +57,12 @@ func New() *Config {
 			DBName:   getEnv("DB_NAME", "fixparts"),
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
 		},
+		Auth: AuthConfig{
+			SessionTTL:    getEnvAsDuration("SESSION_TTL", 12*time.Hour),
+			SecureCookie:  getEnvAsBool("SESSION_SECURE_COOKIE", false),
+			AdminUsername: getEnv("ADMIN_USERNAME", "admin"),
+			AdminPassword: getEnv("ADMIN_PASSWORD", ""),
+		},
 	}
 }
 
@@ -82,3 +101,22 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsSlice(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -2,20 +2,46 @@
 -- MVP Version
 
 -- Drop tables if they exist (for clean reinstallation)
+DROP TABLE IF EXISTS audit_log CASCADE;
+DROP TABLE IF EXISTS manager_authorizations CASCADE;
+DROP TABLE IF EXISTS totp_recovery_codes CASCADE;
+DROP TABLE IF EXISTS user_totp CASCADE;
+DROP TABLE IF EXISTS api_keys CASCADE;
+DROP TABLE IF EXISTS sessions CASCADE;
+DROP TABLE IF EXISTS users CASCADE;
+DROP TABLE IF EXISTS stock_movements CASCADE;
+DROP TABLE IF EXISTS stock_transfer_lines CASCADE;
+DROP TABLE IF EXISTS stock_transfers CASCADE;
+DROP TABLE IF EXISTS stocktake_lines CASCADE;
+DROP TABLE IF EXISTS stocktakes CASCADE;
+DROP TABLE IF EXISTS sale_return_lines CASCADE;
+DROP TABLE IF EXISTS sale_returns CASCADE;
+DROP TABLE IF EXISTS sale_lines CASCADE;
 DROP TABLE IF EXISTS sales CASCADE;
+DROP TABLE IF EXISTS customer_vehicles CASCADE;
+DROP TABLE IF EXISTS customers CASCADE;
+DROP TABLE IF EXISTS supplier_return_lines CASCADE;
+DROP TABLE IF EXISTS supplier_returns CASCADE;
 DROP TABLE IF EXISTS purchases CASCADE;
+DROP TABLE IF EXISTS goods_receipts CASCADE;
+DROP TABLE IF EXISTS purchase_order_lines CASCADE;
+DROP TABLE IF EXISTS purchase_orders CASCADE;
 DROP TABLE IF EXISTS compatibility CASCADE;
+DROP TABLE IF EXISTS item_stock CASCADE;
 DROP TABLE IF EXISTS items CASCADE;
 DROP TABLE IF EXISTS categories CASCADE;
 DROP TABLE IF EXISTS vehicle_submodels CASCADE;
 DROP TABLE IF EXISTS vehicle_models CASCADE;
 DROP TABLE IF EXISTS vehicle_makes CASCADE;
 DROP TABLE IF EXISTS suppliers CASCADE;
+DROP TABLE IF EXISTS warehouses CASCADE;
+DROP TABLE IF EXISTS settings CASCADE;
 
 -- Create extension for UUID generation if needed
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
 -- Create sequences for IDs
+CREATE SEQUENCE IF NOT EXISTS warehouse_id_seq;
 CREATE SEQUENCE IF NOT EXISTS category_id_seq;
 CREATE SEQUENCE IF NOT EXISTS make_id_seq;
 CREATE SEQUENCE IF NOT EXISTS model_id_seq;
@@ -23,7 +49,63 @@ CREATE SEQUENCE IF NOT EXISTS submodel_id_seq;
 CREATE SEQUENCE IF NOT EXISTS item_id_seq;
 CREATE SEQUENCE IF NOT EXISTS supplier_id_seq;
 CREATE SEQUENCE IF NOT EXISTS purchase_id_seq;
+CREATE SEQUENCE IF NOT EXISTS purchase_order_id_seq;
+CREATE SEQUENCE IF NOT EXISTS purchase_order_line_id_seq;
+CREATE SEQUENCE IF NOT EXISTS goods_receipt_id_seq;
+CREATE SEQUENCE IF NOT EXISTS supplier_return_id_seq;
+CREATE SEQUENCE IF NOT EXISTS supplier_return_line_id_seq;
+CREATE SEQUENCE IF NOT EXISTS customer_id_seq;
+CREATE SEQUENCE IF NOT EXISTS customer_vehicle_id_seq;
 CREATE SEQUENCE IF NOT EXISTS sale_id_seq;
+CREATE SEQUENCE IF NOT EXISTS sale_line_id_seq;
+CREATE SEQUENCE IF NOT EXISTS sale_return_id_seq;
+CREATE SEQUENCE IF NOT EXISTS sale_return_line_id_seq;
+CREATE SEQUENCE IF NOT EXISTS stocktake_id_seq;
+CREATE SEQUENCE IF NOT EXISTS stocktake_line_id_seq;
+CREATE SEQUENCE IF NOT EXISTS stock_transfer_id_seq;
+CREATE SEQUENCE IF NOT EXISTS stock_transfer_line_id_seq;
+CREATE SEQUENCE IF NOT EXISTS stock_movement_id_seq;
+CREATE SEQUENCE IF NOT EXISTS cost_layer_id_seq;
+CREATE SEQUENCE IF NOT EXISTS user_id_seq;
+CREATE SEQUENCE IF NOT EXISTS api_key_id_seq;
+CREATE SEQUENCE IF NOT EXISTS recovery_code_id_seq;
+CREATE SEQUENCE IF NOT EXISTS authorization_id_seq;
+CREATE SEQUENCE IF NOT EXISTS audit_id_seq;
+
+-- Application settings (key/value)
+CREATE TABLE settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO settings (key, value) VALUES ('negative_stock_policy', 'disallow');
+INSERT INTO settings (key, value) VALUES ('costing_method', 'fifo');
+
+-- Warehouses (branches and storerooms holding stock). Documents that do not
+-- name a warehouse use the default one.
+CREATE TABLE warehouses (
+    warehouse_id INTEGER PRIMARY KEY DEFAULT nextval('warehouse_id_seq'),
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    address TEXT,
+    phone VARCHAR(50),
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_warehouse_code UNIQUE (code)
+);
+
+CREATE UNIQUE INDEX idx_warehouses_default ON warehouses(is_default) WHERE is_default;
+
+INSERT INTO warehouses (code, name, is_default) VALUES ('MAIN', 'Ana Depo', true);
+
+CREATE OR REPLACE FUNCTION default_warehouse_id()
+RETURNS INTEGER AS $$
+    SELECT warehouse_id FROM warehouses WHERE is_default;
+$$ LANGUAGE sql STABLE;
 
 -- Categories table with hierarchical structure
 CREATE TABLE categories (
@@ -31,9 +113,11 @@ CREATE TABLE categories (
     category_name VARCHAR(100) NOT NULL,
     description TEXT,
     parent_category_id INTEGER REFERENCES categories(category_id) ON DELETE SET NULL,
+    negative_stock_policy VARCHAR(20), -- NULL falls back to the global setting
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    CONSTRAINT unique_category_name UNIQUE (category_name)
+    CONSTRAINT unique_category_name UNIQUE (category_name),
+    CONSTRAINT valid_negative_stock_policy CHECK (negative_stock_policy IN ('disallow', 'allow_with_warning', 'allow'))
 );
 
 -- Vehicle Makes
@@ -84,10 +168,12 @@ CREATE TABLE suppliers (
     address TEXT,
     tax_id VARCHAR(100),
     payment_terms VARCHAR(100),
+    lead_time_days INTEGER NOT NULL DEFAULT 7, -- days from ordering to delivery
     notes TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    CONSTRAINT unique_supplier_name UNIQUE (name)
+    CONSTRAINT unique_supplier_name UNIQUE (name),
+    CONSTRAINT positive_lead_time CHECK (lead_time_days >= 0)
 );
 
 -- Items (Auto Parts)
@@ -99,8 +185,10 @@ CREATE TABLE items (
     category_id INTEGER REFERENCES categories(category_id) ON DELETE SET NULL,
     buy_price DECIMAL(10,2) NOT NULL,
     sell_price DECIMAL(10,2) NOT NULL,
+    average_cost DECIMAL(12,4) NOT NULL DEFAULT 0, -- moving weighted average, kept by the stock ledger
     current_stock INTEGER NOT NULL DEFAULT 0,
     minimum_stock INTEGER NOT NULL DEFAULT 5,
+    reorder_up_to INTEGER, -- NULL orders up to twice the minimum stock
     barcode VARCHAR(100) UNIQUE,
     supplier_id INTEGER REFERENCES suppliers(supplier_id) ON DELETE SET NULL,
     location_aisle VARCHAR(50),
@@ -112,13 +200,34 @@ CREATE TABLE items (
     image_url VARCHAR(255),
     is_active BOOLEAN DEFAULT TRUE,
     notes TEXT,
+    negative_stock_policy VARCHAR(20), -- NULL falls back to the category, then the global setting
+    abc_class CHAR(1), -- set by the ABC/XYZ classification report
+    xyz_class CHAR(1),
+    classified_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     CONSTRAINT unique_part_number UNIQUE (part_number),
     CONSTRAINT unique_item_name UNIQUE (item_name),
     CONSTRAINT positive_buy_price CHECK (buy_price >= 0),
     CONSTRAINT positive_sell_price CHECK (sell_price >= 0),
-    CONSTRAINT non_negative_stock CHECK (current_stock >= 0)
+    CONSTRAINT valid_negative_stock_policy CHECK (negative_stock_policy IN ('disallow', 'allow_with_warning', 'allow')),
+    CONSTRAINT positive_reorder_up_to CHECK (reorder_up_to >= 0),
+    CONSTRAINT valid_abc_class CHECK (abc_class IN ('A', 'B', 'C')),
+    CONSTRAINT valid_xyz_class CHECK (xyz_class IN ('X', 'Y', 'Z'))
+);
+
+-- Item Stock (quantity and bin location of an item in each warehouse;
+-- items.current_stock is the total over all warehouses)
+CREATE TABLE item_stock (
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    location_aisle VARCHAR(50),
+    location_shelf VARCHAR(50),
+    location_bin VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (item_id, warehouse_id)
 );
 
 -- Compatibility mapping between parts and vehicle submodels
@@ -131,17 +240,66 @@ CREATE TABLE compatibility (
     CONSTRAINT unique_item_submodel UNIQUE (item_id, submodel_id)
 );
 
--- Purchases
+-- Purchase Orders (what we ordered from a supplier, does not affect stock)
+CREATE TABLE purchase_orders (
+    po_id INTEGER PRIMARY KEY DEFAULT nextval('purchase_order_id_seq'),
+    po_number VARCHAR(100) NOT NULL,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(supplier_id) ON DELETE RESTRICT,
+    status VARCHAR(30) NOT NULL DEFAULT 'draft',
+    order_date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expected_date TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_po_number UNIQUE (po_number),
+    CONSTRAINT valid_po_status CHECK (status IN ('draft', 'sent', 'partially_received', 'received', 'cancelled'))
+);
+
+-- Purchase Order Lines
+CREATE TABLE purchase_order_lines (
+    po_line_id INTEGER PRIMARY KEY DEFAULT nextval('purchase_order_line_id_seq'),
+    po_id INTEGER NOT NULL REFERENCES purchase_orders(po_id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity_ordered INTEGER NOT NULL,
+    cost_per_unit DECIMAL(10,2) NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_quantity_ordered CHECK (quantity_ordered > 0),
+    CONSTRAINT positive_po_cost_per_unit CHECK (cost_per_unit >= 0),
+    CONSTRAINT unique_po_item UNIQUE (po_id, item_id)
+);
+
+-- Goods Receipts (a delivery received against a purchase order)
+CREATE TABLE goods_receipts (
+    receipt_id INTEGER PRIMARY KEY DEFAULT nextval('goods_receipt_id_seq'),
+    receipt_number VARCHAR(100) NOT NULL,
+    po_id INTEGER NOT NULL REFERENCES purchase_orders(po_id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL DEFAULT default_warehouse_id() REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
+    date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    invoice_number VARCHAR(100),
+    received_by VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_receipt_number UNIQUE (receipt_number)
+);
+
+-- Purchases (goods received into stock, either directly or as a goods receipt line)
 CREATE TABLE purchases (
     purchase_id INTEGER PRIMARY KEY DEFAULT nextval('purchase_id_seq'),
     date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     supplier_id INTEGER NOT NULL REFERENCES suppliers(supplier_id) ON DELETE RESTRICT,
     item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL DEFAULT default_warehouse_id() REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
     quantity INTEGER NOT NULL,
     cost_per_unit DECIMAL(10,2) NOT NULL,
     total_cost DECIMAL(10,2) NOT NULL,
     invoice_number VARCHAR(100),
     received_by VARCHAR(100),
+    receipt_id INTEGER REFERENCES goods_receipts(receipt_id) ON DELETE RESTRICT,
+    po_line_id INTEGER REFERENCES purchase_order_lines(po_line_id) ON DELETE RESTRICT,
     notes TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
@@ -150,19 +308,110 @@ CREATE TABLE purchases (
     CONSTRAINT positive_total_cost CHECK (total_cost >= 0)
 );
 
--- Sales
+-- Supplier Returns (RMA: parts sent back to a supplier)
+CREATE TABLE supplier_returns (
+    supplier_return_id INTEGER PRIMARY KEY DEFAULT nextval('supplier_return_id_seq'),
+    return_number VARCHAR(100) NOT NULL,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(supplier_id) ON DELETE RESTRICT,
+    invoice_number VARCHAR(100),
+    rma_number VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'requested',
+    reason_code VARCHAR(50) NOT NULL,
+    requested_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    shipped_at TIMESTAMP WITH TIME ZONE,
+    credited_at TIMESTAMP WITH TIME ZONE,
+    credit_note_number VARCHAR(100),
+    credited_amount DECIMAL(10,2),
+    requested_by VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_supplier_return_number UNIQUE (return_number),
+    CONSTRAINT valid_supplier_return_status CHECK (status IN ('requested', 'shipped', 'credited', 'rejected')),
+    CONSTRAINT valid_supplier_return_reason CHECK (reason_code IN ('defective', 'wrong_part', 'damaged', 'other')),
+    CONSTRAINT positive_credited_amount CHECK (credited_amount >= 0)
+);
+
+-- Supplier Return Lines (quantity of a purchase sent back)
+CREATE TABLE supplier_return_lines (
+    line_id INTEGER PRIMARY KEY DEFAULT nextval('supplier_return_line_id_seq'),
+    supplier_return_id INTEGER NOT NULL REFERENCES supplier_returns(supplier_return_id) ON DELETE CASCADE,
+    purchase_id INTEGER NOT NULL REFERENCES purchases(purchase_id) ON DELETE RESTRICT,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    cost_per_unit DECIMAL(10,2) NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_supplier_return_quantity CHECK (quantity > 0),
+    CONSTRAINT positive_supplier_return_cost CHECK (cost_per_unit >= 0)
+);
+
+-- Customers
+CREATE TABLE customers (
+    customer_id INTEGER PRIMARY KEY DEFAULT nextval('customer_id_seq'),
+    name VARCHAR(200) NOT NULL,
+    phone VARCHAR(50),
+    email VARCHAR(200),
+    address TEXT,
+    tax_id VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Customer Vehicles (the cars a customer drives)
+CREATE TABLE customer_vehicles (
+    vehicle_id INTEGER PRIMARY KEY DEFAULT nextval('customer_vehicle_id_seq'),
+    customer_id INTEGER NOT NULL REFERENCES customers(customer_id) ON DELETE CASCADE,
+    submodel_id INTEGER NOT NULL REFERENCES vehicle_submodels(submodel_id),
+    year INTEGER NOT NULL,
+    vin VARCHAR(17),
+    plate VARCHAR(20),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Sales (transaction header: one row per ticket). The customer_* columns keep
+-- the contact details as written on the ticket; customer_id links the ticket
+-- to a customer record when one is known.
 CREATE TABLE sales (
     sale_id INTEGER PRIMARY KEY DEFAULT nextval('sale_id_seq'),
     date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
-    quantity INTEGER NOT NULL,
-    price_per_unit DECIMAL(10,2) NOT NULL,
-    total_price DECIMAL(10,2) NOT NULL,
-    transaction_number VARCHAR(100),
+    transaction_number VARCHAR(100) NOT NULL,
+    customer_id INTEGER REFERENCES customers(customer_id) ON DELETE SET NULL,
+    warehouse_id INTEGER NOT NULL DEFAULT default_warehouse_id() REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
     customer_name VARCHAR(200),
     customer_phone VARCHAR(50),
     customer_email VARCHAR(200),
     sold_by VARCHAR(100),
+    payment_method VARCHAR(50) NOT NULL DEFAULT 'cash',
+    subtotal DECIMAL(10,2) NOT NULL DEFAULT 0,
+    discount_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    tax_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    total_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    amount_paid DECIMAL(10,2) NOT NULL DEFAULT 0,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_transaction_number UNIQUE (transaction_number),
+    CONSTRAINT valid_payment_method CHECK (payment_method IN ('cash', 'card', 'bank_transfer', 'account')),
+    CONSTRAINT positive_subtotal CHECK (subtotal >= 0),
+    CONSTRAINT positive_discount_amount CHECK (discount_amount >= 0),
+    CONSTRAINT positive_tax_amount CHECK (tax_amount >= 0),
+    CONSTRAINT positive_total_amount CHECK (total_amount >= 0),
+    CONSTRAINT positive_amount_paid CHECK (amount_paid >= 0)
+);
+
+-- Sale Lines (one row per item on a ticket)
+CREATE TABLE sale_lines (
+    sale_line_id INTEGER PRIMARY KEY DEFAULT nextval('sale_line_id_seq'),
+    sale_id INTEGER NOT NULL REFERENCES sales(sale_id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    price_per_unit DECIMAL(10,2) NOT NULL,
+    total_price DECIMAL(10,2) NOT NULL,
+    cost_of_goods DECIMAL(12,2) NOT NULL DEFAULT 0,
     notes TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
@@ -171,19 +420,316 @@ CREATE TABLE sales (
     CONSTRAINT positive_total_price CHECK (total_price >= 0)
 );
 
+-- Sale Returns (parts taken back from a customer against a sale)
+CREATE TABLE sale_returns (
+    return_id INTEGER PRIMARY KEY DEFAULT nextval('sale_return_id_seq'),
+    return_number VARCHAR(100) NOT NULL,
+    sale_id INTEGER NOT NULL REFERENCES sales(sale_id) ON DELETE RESTRICT,
+    date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    reason_code VARCHAR(50) NOT NULL,
+    refund_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    processed_by VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_return_number UNIQUE (return_number),
+    CONSTRAINT valid_reason_code CHECK (reason_code IN ('defective', 'wrong_part', 'not_needed', 'warranty', 'other')),
+    CONSTRAINT positive_refund_amount CHECK (refund_amount >= 0)
+);
+
+-- Sale Return Lines (quantity of a sale line taken back)
+CREATE TABLE sale_return_lines (
+    return_line_id INTEGER PRIMARY KEY DEFAULT nextval('sale_return_line_id_seq'),
+    return_id INTEGER NOT NULL REFERENCES sale_returns(return_id) ON DELETE CASCADE,
+    sale_line_id INTEGER NOT NULL REFERENCES sale_lines(sale_line_id) ON DELETE RESTRICT,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    disposition VARCHAR(20) NOT NULL,
+    refund_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_return_quantity CHECK (quantity > 0),
+    CONSTRAINT valid_disposition CHECK (disposition IN ('restock', 'scrap')),
+    CONSTRAINT positive_line_refund_amount CHECK (refund_amount >= 0)
+);
+
+-- Stocktakes (cycle counts of the items in a category, of a supplier or at
+-- a location). Expected quantities are snapshotted when the count starts.
+CREATE TABLE stocktakes (
+    stocktake_id INTEGER PRIMARY KEY DEFAULT nextval('stocktake_id_seq'),
+    stocktake_number VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    warehouse_id INTEGER NOT NULL DEFAULT default_warehouse_id() REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
+    category_id INTEGER REFERENCES categories(category_id) ON DELETE SET NULL,
+    supplier_id INTEGER REFERENCES suppliers(supplier_id) ON DELETE SET NULL,
+    location_aisle VARCHAR(50),
+    location_shelf VARCHAR(50),
+    location_bin VARCHAR(50),
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    started_by VARCHAR(100),
+    approved_at TIMESTAMP WITH TIME ZONE,
+    approved_by VARCHAR(100),
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    reason TEXT,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_stocktake_number UNIQUE (stocktake_number),
+    CONSTRAINT valid_stocktake_status CHECK (status IN ('open', 'approved', 'cancelled'))
+);
+
+-- Stocktake Lines (expected and counted quantity of one item)
+CREATE TABLE stocktake_lines (
+    line_id INTEGER PRIMARY KEY DEFAULT nextval('stocktake_line_id_seq'),
+    stocktake_id INTEGER NOT NULL REFERENCES stocktakes(stocktake_id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    expected_quantity INTEGER NOT NULL,
+    counted_quantity INTEGER,
+    unit_cost DECIMAL(10,2) NOT NULL,
+    counted_at TIMESTAMP WITH TIME ZONE,
+    counted_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_stocktake_item UNIQUE (stocktake_id, item_id),
+    CONSTRAINT positive_counted_quantity CHECK (counted_quantity >= 0)
+);
+
+-- Stock transfers (stock sent from one warehouse to another). Shipping takes
+-- the stock out of the source; what has been shipped but not received yet is
+-- in transit until it is received at the destination.
+CREATE TABLE stock_transfers (
+    transfer_id INTEGER PRIMARY KEY DEFAULT nextval('stock_transfer_id_seq'),
+    transfer_number VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'requested',
+    source_warehouse_id INTEGER NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
+    destination_warehouse_id INTEGER NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
+    requested_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    requested_by VARCHAR(100),
+    shipped_at TIMESTAMP WITH TIME ZONE,
+    shipped_by VARCHAR(100),
+    received_at TIMESTAMP WITH TIME ZONE,
+    received_by VARCHAR(100),
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_transfer_number UNIQUE (transfer_number),
+    CONSTRAINT valid_transfer_status CHECK (status IN (
+        'requested', 'shipped', 'partially_received', 'received', 'cancelled'
+    )),
+    CONSTRAINT different_transfer_warehouses CHECK (source_warehouse_id <> destination_warehouse_id)
+);
+
+-- Stock Transfer Lines (quantity of one item sent and received so far)
+CREATE TABLE stock_transfer_lines (
+    line_id INTEGER PRIMARY KEY DEFAULT nextval('stock_transfer_line_id_seq'),
+    transfer_id INTEGER NOT NULL REFERENCES stock_transfers(transfer_id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    quantity_received INTEGER NOT NULL DEFAULT 0,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_transfer_item UNIQUE (transfer_id, item_id),
+    CONSTRAINT positive_transfer_quantity CHECK (quantity > 0),
+    CONSTRAINT valid_transfer_received CHECK (quantity_received BETWEEN 0 AND quantity)
+);
+
+-- Stock movements (append-only ledger; items.current_stock is its running balance)
+CREATE TABLE stock_movements (
+    movement_id INTEGER PRIMARY KEY DEFAULT nextval('stock_movement_id_seq'),
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL DEFAULT default_warehouse_id() REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
+    movement_type VARCHAR(30) NOT NULL,
+    quantity INTEGER NOT NULL,
+    balance_after INTEGER NOT NULL,
+    reference_type VARCHAR(30),
+    reference_id INTEGER,
+    reference_line_id INTEGER,
+    performed_by VARCHAR(100),
+    notes TEXT,
+    cost DECIMAL(12,2) NOT NULL DEFAULT 0, -- signed value under the costing method in use
+    cost_fifo DECIMAL(12,2) NOT NULL DEFAULT 0,
+    cost_average DECIMAL(12,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_movement_type CHECK (movement_type IN (
+        'opening', 'sale', 'sale_correction', 'purchase', 'purchase_correction',
+        'adjustment', 'sale_return', 'supplier_return', 'stocktake',
+        'transfer_out', 'transfer_in'
+    )),
+    CONSTRAINT non_zero_quantity CHECK (quantity <> 0)
+);
+
+-- Cost layers (stock received at one unit cost, consumed oldest first by
+-- FIFO costing)
+CREATE TABLE cost_layers (
+    layer_id INTEGER PRIMARY KEY DEFAULT nextval('cost_layer_id_seq'),
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    movement_id INTEGER REFERENCES stock_movements(movement_id) ON DELETE SET NULL,
+    unit_cost DECIMAL(12,4) NOT NULL,
+    quantity INTEGER NOT NULL,
+    remaining INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_layer_quantity CHECK (quantity > 0),
+    CONSTRAINT valid_layer_remaining CHECK (remaining BETWEEN 0 AND quantity),
+    CONSTRAINT positive_unit_cost CHECK (unit_cost >= 0)
+);
+
+-- Users signing in to the application. The username is what documents
+-- record in sold_by, received_by and the other actor columns.
+CREATE TABLE users (
+    user_id INTEGER PRIMARY KEY DEFAULT nextval('user_id_seq'),
+    username VARCHAR(100) NOT NULL,
+    full_name VARCHAR(100) NOT NULL,
+    password_hash VARCHAR(100) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_user_role CHECK (role IN ('admin', 'manager', 'cashier', 'warehouse'))
+);
+
+-- Login sessions. Only the SHA-256 of the cookie token is stored.
+CREATE TABLE sessions (
+    token_hash CHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- API keys of machine clients. Only the SHA-256 of the key is stored; the
+-- prefix identifies it in listings. Scopes are the permissions it grants.
+CREATE TABLE api_keys (
+    api_key_id INTEGER PRIMARY KEY DEFAULT nextval('api_key_id_seq'),
+    name VARCHAR(90) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    scopes TEXT[] NOT NULL,
+    created_by VARCHAR(100),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    replaced_by INTEGER REFERENCES api_keys(api_key_id) ON DELETE SET NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    last_used_ip VARCHAR(45),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_api_key_hash UNIQUE (key_hash),
+    CONSTRAINT api_key_has_scopes CHECK (cardinality(scopes) > 0)
+);
+
+-- TOTP authenticators of the managers approving destructive actions. The
+-- secret is pending until confirmed with a first code; last_step stops a
+-- code from being used twice.
+CREATE TABLE user_totp (
+    user_id INTEGER PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    last_step BIGINT,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One-time recovery codes for a lost authenticator, stored as SHA-256
+CREATE TABLE totp_recovery_codes (
+    recovery_code_id INTEGER PRIMARY KEY DEFAULT nextval('recovery_code_id_seq'),
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Who approved each deletion or price decrease. Names are copied so the
+-- record outlives the user and the deleted document.
+CREATE TABLE manager_authorizations (
+    authorization_id INTEGER PRIMARY KEY DEFAULT nextval('authorization_id_seq'),
+    action VARCHAR(50) NOT NULL,
+    resource_id INTEGER NOT NULL,
+    details TEXT,
+    requested_by VARCHAR(100),
+    authorized_by VARCHAR(100) NOT NULL,
+    authorizer_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    method VARCHAR(20) NOT NULL,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_authorization_method CHECK (method IN ('totp', 'recovery_code'))
+);
+
+-- Every create, update and delete of the audited entities, with the fields
+-- it changed as {"field": {"old": ..., "new": ...}}
+CREATE TABLE audit_log (
+    audit_id BIGINT PRIMARY KEY DEFAULT nextval('audit_id_seq'),
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    actor VARCHAR(100),
+    authorized_by VARCHAR(100),
+    changes JSONB NOT NULL DEFAULT '{}',
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_audit_action CHECK (action IN ('create', 'update', 'delete'))
+);
+
 -- Create indexes for performance
 CREATE INDEX idx_categories_parent ON categories(parent_category_id);
 CREATE INDEX idx_vehicle_models_make ON vehicle_models(make_id);
 CREATE INDEX idx_items_category ON items(category_id);
 CREATE INDEX idx_items_supplier ON items(supplier_id);
 CREATE INDEX idx_items_barcode ON items(barcode);
+CREATE INDEX idx_items_classes ON items(abc_class, xyz_class);
+CREATE INDEX idx_item_stock_warehouse ON item_stock(warehouse_id);
 CREATE INDEX idx_compatibility_item ON compatibility(item_id);
 CREATE INDEX idx_compatibility_submodel ON compatibility(submodel_id);
 CREATE INDEX idx_purchases_supplier ON purchases(supplier_id);
 CREATE INDEX idx_purchases_item ON purchases(item_id);
 CREATE INDEX idx_purchases_date ON purchases(date);
-CREATE INDEX idx_sales_item ON sales(item_id);
+CREATE INDEX idx_purchases_warehouse ON purchases(warehouse_id);
+CREATE INDEX idx_purchases_receipt ON purchases(receipt_id);
+CREATE INDEX idx_purchases_po_line ON purchases(po_line_id);
+CREATE INDEX idx_supplier_returns_supplier ON supplier_returns(supplier_id);
+CREATE INDEX idx_supplier_returns_status ON supplier_returns(status);
+CREATE INDEX idx_supplier_return_lines_return ON supplier_return_lines(supplier_return_id);
+CREATE INDEX idx_supplier_return_lines_purchase ON supplier_return_lines(purchase_id);
+CREATE INDEX idx_purchase_orders_supplier ON purchase_orders(supplier_id);
+CREATE INDEX idx_purchase_orders_status ON purchase_orders(status);
+CREATE INDEX idx_purchase_order_lines_po ON purchase_order_lines(po_id);
+CREATE INDEX idx_goods_receipts_po ON goods_receipts(po_id);
+CREATE INDEX idx_customers_email ON customers(LOWER(email));
+CREATE INDEX idx_customers_phone ON customers(regexp_replace(phone, '\D', '', 'g'));
+CREATE INDEX idx_customer_vehicles_customer ON customer_vehicles(customer_id);
 CREATE INDEX idx_sales_date ON sales(date);
+CREATE INDEX idx_sales_customer ON sales(customer_id);
+CREATE INDEX idx_sales_warehouse ON sales(warehouse_id);
+CREATE INDEX idx_sale_lines_sale ON sale_lines(sale_id);
+CREATE INDEX idx_sale_lines_item ON sale_lines(item_id);
+CREATE INDEX idx_sale_returns_sale ON sale_returns(sale_id);
+CREATE INDEX idx_sale_return_lines_return ON sale_return_lines(return_id);
+CREATE INDEX idx_sale_return_lines_sale_line ON sale_return_lines(sale_line_id);
+CREATE INDEX idx_stocktakes_status ON stocktakes(status);
+CREATE INDEX idx_stocktake_lines_item ON stocktake_lines(item_id);
+CREATE INDEX idx_stock_transfers_status ON stock_transfers(status);
+CREATE INDEX idx_stock_transfers_source ON stock_transfers(source_warehouse_id);
+CREATE INDEX idx_stock_transfers_destination ON stock_transfers(destination_warehouse_id);
+CREATE INDEX idx_stock_transfer_lines_transfer ON stock_transfer_lines(transfer_id);
+CREATE INDEX idx_stock_transfer_lines_item ON stock_transfer_lines(item_id);
+CREATE INDEX idx_stock_movements_item ON stock_movements(item_id, created_at);
+CREATE INDEX idx_stock_movements_warehouse ON stock_movements(warehouse_id, item_id);
+CREATE INDEX idx_stock_movements_reference ON stock_movements(reference_type, reference_id);
+CREATE INDEX idx_stock_movements_reference_line ON stock_movements(reference_type, reference_line_id);
+CREATE INDEX idx_cost_layers_open ON cost_layers(item_id, layer_id) WHERE remaining > 0;
+CREATE UNIQUE INDEX idx_users_username ON users(LOWER(username));
+CREATE INDEX idx_sessions_user ON sessions(user_id);
+CREATE INDEX idx_sessions_expires ON sessions(expires_at);
+CREATE INDEX idx_recovery_codes_user ON totp_recovery_codes(user_id);
+CREATE INDEX idx_authorizations_action ON manager_authorizations(action, resource_id);
+CREATE INDEX idx_authorizations_created ON manager_authorizations(created_at);
+CREATE INDEX idx_audit_entity ON audit_log(entity_type, entity_id);
+CREATE INDEX idx_audit_actor ON audit_log(LOWER(actor));
+CREATE INDEX idx_audit_created ON audit_log(created_at);
 
 -- Create triggers for updated_at timestamp
 CREATE OR REPLACE FUNCTION update_timestamp()
@@ -194,6 +740,14 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
+CREATE TRIGGER update_settings_timestamp
+BEFORE UPDATE ON settings
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_warehouses_timestamp
+BEFORE UPDATE ON warehouses
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
 CREATE TRIGGER update_categories_timestamp
 BEFORE UPDATE ON categories
 FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
@@ -218,45 +772,105 @@ CREATE TRIGGER update_items_timestamp
 BEFORE UPDATE ON items
 FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
 
+CREATE TRIGGER update_item_stock_timestamp
+BEFORE UPDATE ON item_stock
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
 CREATE TRIGGER update_purchases_timestamp
 BEFORE UPDATE ON purchases
 FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
 
+CREATE TRIGGER update_purchase_orders_timestamp
+BEFORE UPDATE ON purchase_orders
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_purchase_order_lines_timestamp
+BEFORE UPDATE ON purchase_order_lines
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_goods_receipts_timestamp
+BEFORE UPDATE ON goods_receipts
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_supplier_returns_timestamp
+BEFORE UPDATE ON supplier_returns
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_customers_timestamp
+BEFORE UPDATE ON customers
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_customer_vehicles_timestamp
+BEFORE UPDATE ON customer_vehicles
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
 CREATE TRIGGER update_sales_timestamp
 BEFORE UPDATE ON sales
 FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
 
--- Create a trigger to update inventory on purchase
-CREATE OR REPLACE FUNCTION update_inventory_on_purchase()
-RETURNS TRIGGER AS $$
-BEGIN
-   UPDATE items
-   SET current_stock = current_stock + NEW.quantity,
-       updated_at = CURRENT_TIMESTAMP
-   WHERE item_id = NEW.item_id;
-   RETURN NEW;
-END;
-$$ LANGUAGE plpgsql;
+CREATE TRIGGER update_sale_lines_timestamp
+BEFORE UPDATE ON sale_lines
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
 
-CREATE TRIGGER trigger_update_inventory_on_purchase
-AFTER INSERT ON purchases
-FOR EACH ROW EXECUTE PROCEDURE update_inventory_on_purchase();
+CREATE TRIGGER update_sale_returns_timestamp
+BEFORE UPDATE ON sale_returns
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_stocktakes_timestamp
+BEFORE UPDATE ON stocktakes
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
 
--- Create a trigger to update inventory on sale
-CREATE OR REPLACE FUNCTION update_inventory_on_sale()
+CREATE TRIGGER update_stocktake_lines_timestamp
+BEFORE UPDATE ON stocktake_lines
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_stock_transfers_timestamp
+BEFORE UPDATE ON stock_transfers
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_stock_transfer_lines_timestamp
+BEFORE UPDATE ON stock_transfer_lines
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_users_timestamp
+BEFORE UPDATE ON users
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_api_keys_timestamp
+BEFORE UPDATE ON api_keys
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_user_totp_timestamp
+BEFORE UPDATE ON user_totp
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+-- Stock is changed by the application posting to stock_movements inside the
+-- document's transaction; ledger rows are never rewritten or deleted
+CREATE OR REPLACE FUNCTION prevent_stock_movement_update()
 RETURNS TRIGGER AS $$
 BEGIN
-   UPDATE items
-   SET current_stock = current_stock - NEW.quantity,
-       updated_at = CURRENT_TIMESTAMP
-   WHERE item_id = NEW.item_id;
-   RETURN NEW;
+   RAISE EXCEPTION 'stock_movements is append-only; post a correcting movement instead';
 END;
 $$ LANGUAGE plpgsql;
 
-CREATE TRIGGER trigger_update_inventory_on_sale
-AFTER INSERT ON sales
-FOR EACH ROW EXECUTE PROCEDURE update_inventory_on_sale();
+CREATE TRIGGER trigger_prevent_stock_movement_update
+BEFORE UPDATE OR DELETE ON stock_movements
+FOR EACH ROW EXECUTE PROCEDURE prevent_stock_movement_update();
+
+-- Resolve the negative stock policy of an item: its own override, then its
+-- category's, then the global setting
+CREATE OR REPLACE FUNCTION item_negative_stock_policy(p_item_id INTEGER)
+RETURNS VARCHAR AS $$
+    SELECT COALESCE(
+        i.negative_stock_policy,
+        c.negative_stock_policy,
+        (SELECT value FROM settings WHERE key = 'negative_stock_policy'),
+        'disallow'
+    )
+    FROM items i
+    LEFT JOIN categories c ON i.category_id = c.category_id
+    WHERE i.item_id = p_item_id;
+$$ LANGUAGE sql STABLE;
 
 -- Create view for low stock alerts
 CREATE OR REPLACE VIEW low_stock_items AS
@@ -266,6 +880,7 @@ SELECT
     i.description,
     i.current_stock,
     i.minimum_stock,
+    i.current_stock < 0 as negative_stock,
     c.category_name,
     s.name as supplier_name,
     s.phone as supplier_phone,
@@ -281,32 +896,6 @@ WHERE
 ORDER BY
     (i.current_stock::float / i.minimum_stock) ASC;
 
--- Create view for item sales velocity (items sold per day)
-CREATE OR REPLACE VIEW item_sales_velocity AS
-SELECT
-    i.item_id,
-    i.part_number,
-    i.description,
-    COUNT(s.sale_id) as total_sales,
-    SUM(s.quantity) as total_quantity_sold,
-    (CURRENT_DATE - DATE '2023-01-01') as days_since_jan1,
-    ROUND(SUM(s.quantity)::numeric / ((CURRENT_DATE - DATE '2023-01-01')::numeric), 2) as daily_sales_rate,
-    CASE
-        WHEN i.current_stock > 0 AND (SUM(s.quantity)::numeric / ((CURRENT_DATE - DATE '2023-01-01')::numeric)) > 0
-        THEN ROUND(i.current_stock / (SUM(s.quantity)::numeric / ((CURRENT_DATE - DATE '2023-01-01')::numeric)))
-        ELSE NULL
-    END as estimated_days_until_stockout
-FROM
-    items i
-LEFT JOIN
-    sales s ON i.item_id = s.item_id
-WHERE
-    s.date >= '2023-01-01'
-GROUP BY
-    i.item_id, i.part_number, i.description, i.current_stock
-ORDER BY
-    daily_sales_rate DESC;
-
 -- Create view for top selling items
 CREATE OR REPLACE VIEW top_selling_items AS
 SELECT
@@ -314,18 +903,18 @@ SELECT
     i.part_number,
     i.description,
     c.category_name,
-    COUNT(s.sale_id) as number_of_sales,
-    SUM(s.quantity) as total_quantity_sold,
-    SUM(s.total_price) as total_revenue,
-    SUM(s.total_price) - (SUM(s.quantity) * i.buy_price) as estimated_profit
+    COUNT(DISTINCT sl.sale_id) as number_of_sales,
+    SUM(sl.quantity) as total_quantity_sold,
+    SUM(sl.total_price) as total_revenue,
+    SUM(sl.total_price) - SUM(sl.cost_of_goods) as estimated_profit
 FROM
     items i
 JOIN
-    sales s ON i.item_id = s.item_id
+    sale_lines sl ON i.item_id = sl.item_id
 JOIN
     categories c ON i.category_id = c.category_id
 GROUP BY
-    i.item_id, i.part_number, i.description, i.buy_price, c.category_name
+    i.item_id, i.part_number, i.description, c.category_name
 ORDER BY
     total_revenue DESC;
 
@@ -0,0 +1,146 @@
+-- Splits sales into transaction headers and sale lines. Every sale recorded
+-- before the split becomes a ticket of its own with a single line, paid in
+-- full in cash. Safe to run more than once: the lines are only backfilled
+-- while sales still has its item columns.
+
+BEGIN;
+
+CREATE SEQUENCE IF NOT EXISTS sale_line_id_seq;
+
+ALTER TABLE sales
+    ADD COLUMN IF NOT EXISTS payment_method VARCHAR(50) NOT NULL DEFAULT 'cash',
+    ADD COLUMN IF NOT EXISTS subtotal DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS total_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS amount_paid DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS sale_lines (
+    sale_line_id INTEGER PRIMARY KEY DEFAULT nextval('sale_line_id_seq'),
+    sale_id INTEGER NOT NULL REFERENCES sales(sale_id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    price_per_unit DECIMAL(10,2) NOT NULL,
+    total_price DECIMAL(10,2) NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_quantity CHECK (quantity > 0),
+    CONSTRAINT positive_price_per_unit CHECK (price_per_unit >= 0),
+    CONSTRAINT positive_total_price CHECK (total_price >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sale_lines_sale ON sale_lines(sale_id);
+CREATE INDEX IF NOT EXISTS idx_sale_lines_item ON sale_lines(item_id);
+
+DROP TRIGGER IF EXISTS update_sale_lines_timestamp ON sale_lines;
+CREATE TRIGGER update_sale_lines_timestamp
+BEFORE UPDATE ON sale_lines
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'sales' AND column_name = 'item_id'
+    ) THEN
+        -- The old stock trigger and views read the item columns of sales
+        DROP TRIGGER IF EXISTS trigger_update_inventory_on_sale ON sales;
+        DROP VIEW IF EXISTS item_sales_velocity;
+        DROP VIEW IF EXISTS top_selling_items;
+
+        INSERT INTO sale_lines (sale_id, item_id, quantity, price_per_unit, total_price, created_at, updated_at)
+        SELECT sale_id, item_id, quantity, price_per_unit, total_price, created_at, updated_at
+        FROM sales
+        ORDER BY sale_id;
+
+        UPDATE sales SET
+            subtotal = total_price,
+            total_amount = total_price,
+            amount_paid = total_price;
+
+        -- Every ticket needs a transaction number of its own
+        UPDATE sales SET transaction_number = 'TXN-' || sale_id
+        WHERE NULLIF(TRIM(transaction_number), '') IS NULL;
+
+        UPDATE sales s SET transaction_number = s.transaction_number || '-' || s.sale_id
+        WHERE EXISTS (
+            SELECT 1 FROM sales o
+            WHERE o.transaction_number = s.transaction_number AND o.sale_id < s.sale_id
+        );
+
+        ALTER TABLE sales
+            DROP COLUMN item_id,
+            DROP COLUMN quantity,
+            DROP COLUMN price_per_unit,
+            DROP COLUMN total_price;
+
+        CREATE VIEW item_sales_velocity AS
+        SELECT
+            i.item_id,
+            i.part_number,
+            i.description,
+            COUNT(DISTINCT s.sale_id) as total_sales,
+            SUM(sl.quantity) as total_quantity_sold,
+            (CURRENT_DATE - DATE '2023-01-01') as days_since_jan1,
+            ROUND(SUM(sl.quantity)::numeric / ((CURRENT_DATE - DATE '2023-01-01')::numeric), 2) as daily_sales_rate,
+            CASE
+                WHEN i.current_stock > 0 AND (SUM(sl.quantity)::numeric / ((CURRENT_DATE - DATE '2023-01-01')::numeric)) > 0
+                THEN ROUND(i.current_stock / (SUM(sl.quantity)::numeric / ((CURRENT_DATE - DATE '2023-01-01')::numeric)))
+                ELSE NULL
+            END as estimated_days_until_stockout
+        FROM
+            items i
+        LEFT JOIN
+            sale_lines sl ON i.item_id = sl.item_id
+        LEFT JOIN
+            sales s ON sl.sale_id = s.sale_id
+        WHERE
+            s.date >= '2023-01-01'
+        GROUP BY
+            i.item_id, i.part_number, i.description, i.current_stock
+        ORDER BY
+            daily_sales_rate DESC;
+
+        CREATE VIEW top_selling_items AS
+        SELECT
+            i.item_id,
+            i.part_number,
+            i.description,
+            c.category_name,
+            COUNT(DISTINCT sl.sale_id) as number_of_sales,
+            SUM(sl.quantity) as total_quantity_sold,
+            SUM(sl.total_price) as total_revenue,
+            SUM(sl.total_price) - (SUM(sl.quantity) * i.buy_price) as estimated_profit
+        FROM
+            items i
+        JOIN
+            sale_lines sl ON i.item_id = sl.item_id
+        JOIN
+            categories c ON i.category_id = c.category_id
+        GROUP BY
+            i.item_id, i.part_number, i.description, i.buy_price, c.category_name
+        ORDER BY
+            total_revenue DESC;
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_sales_item;
+
+ALTER TABLE sales ALTER COLUMN transaction_number SET NOT NULL;
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS unique_transaction_number;
+ALTER TABLE sales ADD CONSTRAINT unique_transaction_number UNIQUE (transaction_number);
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS valid_payment_method;
+ALTER TABLE sales ADD CONSTRAINT valid_payment_method CHECK (payment_method IN ('cash', 'card', 'bank_transfer', 'account'));
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS positive_subtotal;
+ALTER TABLE sales ADD CONSTRAINT positive_subtotal CHECK (subtotal >= 0);
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS positive_discount_amount;
+ALTER TABLE sales ADD CONSTRAINT positive_discount_amount CHECK (discount_amount >= 0);
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS positive_tax_amount;
+ALTER TABLE sales ADD CONSTRAINT positive_tax_amount CHECK (tax_amount >= 0);
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS positive_total_amount;
+ALTER TABLE sales ADD CONSTRAINT positive_total_amount CHECK (total_amount >= 0);
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS positive_amount_paid;
+ALTER TABLE sales ADD CONSTRAINT positive_amount_paid CHECK (amount_paid >= 0);
+
+COMMIT;
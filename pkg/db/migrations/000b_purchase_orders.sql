@@ -0,0 +1,80 @@
+-- Adds purchase orders and the goods receipts posted against them, and
+-- links purchases to the receipt and order line they were received on.
+-- Purchases recorded before are direct purchases and keep both links empty.
+-- Safe to run more than once.
+
+BEGIN;
+
+CREATE SEQUENCE IF NOT EXISTS purchase_order_id_seq;
+CREATE SEQUENCE IF NOT EXISTS purchase_order_line_id_seq;
+CREATE SEQUENCE IF NOT EXISTS goods_receipt_id_seq;
+
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    po_id INTEGER PRIMARY KEY DEFAULT nextval('purchase_order_id_seq'),
+    po_number VARCHAR(100) NOT NULL,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(supplier_id) ON DELETE RESTRICT,
+    status VARCHAR(30) NOT NULL DEFAULT 'draft',
+    order_date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expected_date TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_po_number UNIQUE (po_number),
+    CONSTRAINT valid_po_status CHECK (status IN ('draft', 'sent', 'partially_received', 'received', 'cancelled'))
+);
+
+CREATE TABLE IF NOT EXISTS purchase_order_lines (
+    po_line_id INTEGER PRIMARY KEY DEFAULT nextval('purchase_order_line_id_seq'),
+    po_id INTEGER NOT NULL REFERENCES purchase_orders(po_id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity_ordered INTEGER NOT NULL,
+    cost_per_unit DECIMAL(10,2) NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_quantity_ordered CHECK (quantity_ordered > 0),
+    CONSTRAINT positive_po_cost_per_unit CHECK (cost_per_unit >= 0),
+    CONSTRAINT unique_po_item UNIQUE (po_id, item_id)
+);
+
+CREATE TABLE IF NOT EXISTS goods_receipts (
+    receipt_id INTEGER PRIMARY KEY DEFAULT nextval('goods_receipt_id_seq'),
+    receipt_number VARCHAR(100) NOT NULL,
+    po_id INTEGER NOT NULL REFERENCES purchase_orders(po_id) ON DELETE RESTRICT,
+    date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    invoice_number VARCHAR(100),
+    received_by VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_receipt_number UNIQUE (receipt_number)
+);
+
+ALTER TABLE purchases
+    ADD COLUMN IF NOT EXISTS receipt_id INTEGER REFERENCES goods_receipts(receipt_id) ON DELETE RESTRICT,
+    ADD COLUMN IF NOT EXISTS po_line_id INTEGER REFERENCES purchase_order_lines(po_line_id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_purchases_receipt ON purchases(receipt_id);
+CREATE INDEX IF NOT EXISTS idx_purchases_po_line ON purchases(po_line_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_supplier ON purchase_orders(supplier_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_status ON purchase_orders(status);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_lines_po ON purchase_order_lines(po_id);
+CREATE INDEX IF NOT EXISTS idx_goods_receipts_po ON goods_receipts(po_id);
+
+DROP TRIGGER IF EXISTS update_purchase_orders_timestamp ON purchase_orders;
+CREATE TRIGGER update_purchase_orders_timestamp
+BEFORE UPDATE ON purchase_orders
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+DROP TRIGGER IF EXISTS update_purchase_order_lines_timestamp ON purchase_order_lines;
+CREATE TRIGGER update_purchase_order_lines_timestamp
+BEFORE UPDATE ON purchase_order_lines
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+DROP TRIGGER IF EXISTS update_goods_receipts_timestamp ON goods_receipts;
+CREATE TRIGGER update_goods_receipts_timestamp
+BEFORE UPDATE ON goods_receipts
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+COMMIT;
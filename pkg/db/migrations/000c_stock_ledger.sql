@@ -0,0 +1,62 @@
+-- Adds the stock movement ledger, which replaces the triggers that kept
+-- items.current_stock in step with purchases and sales. The stock of every
+-- item without movements is posted as its opening balance, so the ledger
+-- adds up to current_stock. Safe to run more than once.
+
+BEGIN;
+
+CREATE SEQUENCE IF NOT EXISTS stock_movement_id_seq;
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    movement_id INTEGER PRIMARY KEY DEFAULT nextval('stock_movement_id_seq'),
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    movement_type VARCHAR(30) NOT NULL,
+    quantity INTEGER NOT NULL,
+    balance_after INTEGER NOT NULL,
+    reference_type VARCHAR(30),
+    reference_id INTEGER,
+    reference_line_id INTEGER,
+    performed_by VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_movement_type CHECK (movement_type IN (
+        'opening', 'sale', 'sale_correction', 'purchase', 'purchase_correction',
+        'adjustment', 'sale_return', 'supplier_return', 'stocktake'
+    )),
+    CONSTRAINT non_zero_quantity CHECK (quantity <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_item ON stock_movements(item_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_reference ON stock_movements(reference_type, reference_id);
+
+-- Stock is posted to the ledger by the application, inside the transaction
+-- of the document that moves it
+DROP TRIGGER IF EXISTS trigger_update_inventory_on_purchase ON purchases;
+DROP TRIGGER IF EXISTS trigger_update_inventory_on_sale ON sales;
+DROP TRIGGER IF EXISTS trigger_update_inventory_on_sale ON sale_lines;
+DROP FUNCTION IF EXISTS update_inventory_on_purchase();
+DROP FUNCTION IF EXISTS update_inventory_on_sale();
+
+CREATE OR REPLACE FUNCTION prevent_stock_movement_update()
+RETURNS TRIGGER AS $$
+BEGIN
+   RAISE EXCEPTION 'stock_movements is append-only; post a correcting movement instead';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_prevent_stock_movement_update ON stock_movements;
+CREATE TRIGGER trigger_prevent_stock_movement_update
+BEFORE UPDATE OR DELETE ON stock_movements
+FOR EACH ROW EXECUTE PROCEDURE prevent_stock_movement_update();
+
+INSERT INTO stock_movements (
+    item_id, movement_type, quantity, balance_after,
+    reference_type, reference_id, notes
+)
+SELECT item_id, 'opening', current_stock, current_stock, 'item', item_id, 'Opening balance'
+FROM items i
+WHERE current_stock <> 0
+  AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.item_id = i.item_id)
+ORDER BY item_id;
+
+COMMIT;
@@ -0,0 +1,74 @@
+-- Adds the settings table and the negative stock policy of items,
+-- categories and the shop. Stock may now go below zero where the policy
+-- allows it. Safe to run more than once.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO settings (key, value) VALUES ('negative_stock_policy', 'disallow')
+ON CONFLICT (key) DO NOTHING;
+
+DROP TRIGGER IF EXISTS update_settings_timestamp ON settings;
+CREATE TRIGGER update_settings_timestamp
+BEFORE UPDATE ON settings
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS negative_stock_policy VARCHAR(20);
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS valid_negative_stock_policy;
+ALTER TABLE categories ADD CONSTRAINT valid_negative_stock_policy
+    CHECK (negative_stock_policy IN ('disallow', 'allow_with_warning', 'allow'));
+
+ALTER TABLE items
+    ADD COLUMN IF NOT EXISTS negative_stock_policy VARCHAR(20);
+ALTER TABLE items DROP CONSTRAINT IF EXISTS valid_negative_stock_policy;
+ALTER TABLE items ADD CONSTRAINT valid_negative_stock_policy
+    CHECK (negative_stock_policy IN ('disallow', 'allow_with_warning', 'allow'));
+ALTER TABLE items DROP CONSTRAINT IF EXISTS non_negative_stock;
+
+-- Resolve the negative stock policy of an item: its own override, then its
+-- category's, then the global setting
+CREATE OR REPLACE FUNCTION item_negative_stock_policy(p_item_id INTEGER)
+RETURNS VARCHAR AS $$
+    SELECT COALESCE(
+        i.negative_stock_policy,
+        c.negative_stock_policy,
+        (SELECT value FROM settings WHERE key = 'negative_stock_policy'),
+        'disallow'
+    )
+    FROM items i
+    LEFT JOIN categories c ON i.category_id = c.category_id
+    WHERE i.item_id = p_item_id;
+$$ LANGUAGE sql STABLE;
+
+-- The view gains a column in the middle, which CREATE OR REPLACE cannot do
+DROP VIEW IF EXISTS low_stock_items;
+CREATE VIEW low_stock_items AS
+SELECT
+    i.item_id,
+    i.part_number,
+    i.description,
+    i.current_stock,
+    i.minimum_stock,
+    i.current_stock < 0 as negative_stock,
+    c.category_name,
+    s.name as supplier_name,
+    s.phone as supplier_phone,
+    s.email as supplier_email
+FROM
+    items i
+JOIN
+    categories c ON i.category_id = c.category_id
+JOIN
+    suppliers s ON i.supplier_id = s.supplier_id
+WHERE
+    i.current_stock <= i.minimum_stock
+ORDER BY
+    (i.current_stock::float / i.minimum_stock) ASC;
+
+COMMIT;
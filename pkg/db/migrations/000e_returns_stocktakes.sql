@@ -0,0 +1,147 @@
+-- Adds sale returns, supplier returns and stocktakes. Safe to run more than
+-- once.
+
+BEGIN;
+
+CREATE SEQUENCE IF NOT EXISTS sale_return_id_seq;
+CREATE SEQUENCE IF NOT EXISTS sale_return_line_id_seq;
+CREATE SEQUENCE IF NOT EXISTS supplier_return_id_seq;
+CREATE SEQUENCE IF NOT EXISTS supplier_return_line_id_seq;
+CREATE SEQUENCE IF NOT EXISTS stocktake_id_seq;
+CREATE SEQUENCE IF NOT EXISTS stocktake_line_id_seq;
+
+CREATE TABLE IF NOT EXISTS sale_returns (
+    return_id INTEGER PRIMARY KEY DEFAULT nextval('sale_return_id_seq'),
+    return_number VARCHAR(100) NOT NULL,
+    sale_id INTEGER NOT NULL REFERENCES sales(sale_id) ON DELETE RESTRICT,
+    date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    reason_code VARCHAR(50) NOT NULL,
+    refund_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    processed_by VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_return_number UNIQUE (return_number),
+    CONSTRAINT valid_reason_code CHECK (reason_code IN ('defective', 'wrong_part', 'not_needed', 'warranty', 'other')),
+    CONSTRAINT positive_refund_amount CHECK (refund_amount >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS sale_return_lines (
+    return_line_id INTEGER PRIMARY KEY DEFAULT nextval('sale_return_line_id_seq'),
+    return_id INTEGER NOT NULL REFERENCES sale_returns(return_id) ON DELETE CASCADE,
+    sale_line_id INTEGER NOT NULL REFERENCES sale_lines(sale_line_id) ON DELETE RESTRICT,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    disposition VARCHAR(20) NOT NULL,
+    refund_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_return_quantity CHECK (quantity > 0),
+    CONSTRAINT valid_disposition CHECK (disposition IN ('restock', 'scrap')),
+    CONSTRAINT positive_line_refund_amount CHECK (refund_amount >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS supplier_returns (
+    supplier_return_id INTEGER PRIMARY KEY DEFAULT nextval('supplier_return_id_seq'),
+    return_number VARCHAR(100) NOT NULL,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(supplier_id) ON DELETE RESTRICT,
+    invoice_number VARCHAR(100),
+    rma_number VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'requested',
+    reason_code VARCHAR(50) NOT NULL,
+    requested_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    shipped_at TIMESTAMP WITH TIME ZONE,
+    credited_at TIMESTAMP WITH TIME ZONE,
+    credit_note_number VARCHAR(100),
+    credited_amount DECIMAL(10,2),
+    requested_by VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_supplier_return_number UNIQUE (return_number),
+    CONSTRAINT valid_supplier_return_status CHECK (status IN ('requested', 'shipped', 'credited', 'rejected')),
+    CONSTRAINT valid_supplier_return_reason CHECK (reason_code IN ('defective', 'wrong_part', 'damaged', 'other')),
+    CONSTRAINT positive_credited_amount CHECK (credited_amount >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS supplier_return_lines (
+    line_id INTEGER PRIMARY KEY DEFAULT nextval('supplier_return_line_id_seq'),
+    supplier_return_id INTEGER NOT NULL REFERENCES supplier_returns(supplier_return_id) ON DELETE CASCADE,
+    purchase_id INTEGER NOT NULL REFERENCES purchases(purchase_id) ON DELETE RESTRICT,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    cost_per_unit DECIMAL(10,2) NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_supplier_return_quantity CHECK (quantity > 0),
+    CONSTRAINT positive_supplier_return_cost CHECK (cost_per_unit >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS stocktakes (
+    stocktake_id INTEGER PRIMARY KEY DEFAULT nextval('stocktake_id_seq'),
+    stocktake_number VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    category_id INTEGER REFERENCES categories(category_id) ON DELETE SET NULL,
+    supplier_id INTEGER REFERENCES suppliers(supplier_id) ON DELETE SET NULL,
+    location_aisle VARCHAR(50),
+    location_shelf VARCHAR(50),
+    location_bin VARCHAR(50),
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    started_by VARCHAR(100),
+    approved_at TIMESTAMP WITH TIME ZONE,
+    approved_by VARCHAR(100),
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    reason TEXT,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_stocktake_number UNIQUE (stocktake_number),
+    CONSTRAINT valid_stocktake_status CHECK (status IN ('open', 'approved', 'cancelled'))
+);
+
+CREATE TABLE IF NOT EXISTS stocktake_lines (
+    line_id INTEGER PRIMARY KEY DEFAULT nextval('stocktake_line_id_seq'),
+    stocktake_id INTEGER NOT NULL REFERENCES stocktakes(stocktake_id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    expected_quantity INTEGER NOT NULL,
+    counted_quantity INTEGER,
+    unit_cost DECIMAL(10,2) NOT NULL,
+    counted_at TIMESTAMP WITH TIME ZONE,
+    counted_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_stocktake_item UNIQUE (stocktake_id, item_id),
+    CONSTRAINT positive_counted_quantity CHECK (counted_quantity >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sale_returns_sale ON sale_returns(sale_id);
+CREATE INDEX IF NOT EXISTS idx_sale_return_lines_return ON sale_return_lines(return_id);
+CREATE INDEX IF NOT EXISTS idx_sale_return_lines_sale_line ON sale_return_lines(sale_line_id);
+CREATE INDEX IF NOT EXISTS idx_supplier_returns_supplier ON supplier_returns(supplier_id);
+CREATE INDEX IF NOT EXISTS idx_supplier_returns_status ON supplier_returns(status);
+CREATE INDEX IF NOT EXISTS idx_supplier_return_lines_return ON supplier_return_lines(supplier_return_id);
+CREATE INDEX IF NOT EXISTS idx_supplier_return_lines_purchase ON supplier_return_lines(purchase_id);
+CREATE INDEX IF NOT EXISTS idx_stocktakes_status ON stocktakes(status);
+CREATE INDEX IF NOT EXISTS idx_stocktake_lines_item ON stocktake_lines(item_id);
+
+DROP TRIGGER IF EXISTS update_sale_returns_timestamp ON sale_returns;
+CREATE TRIGGER update_sale_returns_timestamp
+BEFORE UPDATE ON sale_returns
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+DROP TRIGGER IF EXISTS update_supplier_returns_timestamp ON supplier_returns;
+CREATE TRIGGER update_supplier_returns_timestamp
+BEFORE UPDATE ON supplier_returns
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+DROP TRIGGER IF EXISTS update_stocktakes_timestamp ON stocktakes;
+CREATE TRIGGER update_stocktakes_timestamp
+BEFORE UPDATE ON stocktakes
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+DROP TRIGGER IF EXISTS update_stocktake_lines_timestamp ON stocktake_lines;
+CREATE TRIGGER update_stocktake_lines_timestamp
+BEFORE UPDATE ON stocktake_lines
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+COMMIT;
@@ -0,0 +1,68 @@
+-- Creates customer records from the contact details typed on existing sales
+-- and links every sale to its customer. Safe to run more than once: only
+-- missing customers are created and only unlinked sales are updated.
+
+BEGIN;
+
+CREATE SEQUENCE IF NOT EXISTS customer_id_seq;
+
+CREATE TABLE IF NOT EXISTS customers (
+    customer_id INTEGER PRIMARY KEY DEFAULT nextval('customer_id_seq'),
+    name VARCHAR(200) NOT NULL,
+    phone VARCHAR(50),
+    email VARCHAR(200),
+    address TEXT,
+    tax_id VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE sales
+    ADD COLUMN IF NOT EXISTS customer_id INTEGER REFERENCES customers(customer_id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_customers_email ON customers(LOWER(email));
+CREATE INDEX IF NOT EXISTS idx_customers_phone ON customers(regexp_replace(phone, '\D', '', 'g'));
+CREATE INDEX IF NOT EXISTS idx_sales_customer ON sales(customer_id);
+
+DROP TRIGGER IF EXISTS update_customers_timestamp ON customers;
+CREATE TRIGGER update_customers_timestamp
+BEFORE UPDATE ON customers
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+-- One customer per distinct name/phone/email combination found on sales.
+-- Tickets without any contact details are walk-in sales and stay unlinked.
+WITH contacts AS (
+    SELECT DISTINCT
+        NULLIF(TRIM(customer_name), '') as name,
+        NULLIF(TRIM(customer_phone), '') as phone,
+        NULLIF(TRIM(customer_email), '') as email
+    FROM sales
+    WHERE customer_id IS NULL
+)
+INSERT INTO customers (name, phone, email)
+SELECT COALESCE(c.name, c.email, c.phone), c.phone, c.email
+FROM contacts c
+WHERE COALESCE(c.name, c.email, c.phone) IS NOT NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM customers e
+      WHERE e.name = COALESCE(c.name, c.email, c.phone)
+        AND e.phone IS NOT DISTINCT FROM c.phone
+        AND e.email IS NOT DISTINCT FROM c.email
+  );
+
+UPDATE sales s
+SET customer_id = (
+    SELECT MIN(e.customer_id) FROM customers e
+    WHERE e.name = COALESCE(NULLIF(TRIM(s.customer_name), ''),
+                            NULLIF(TRIM(s.customer_email), ''),
+                            NULLIF(TRIM(s.customer_phone), ''))
+      AND e.phone IS NOT DISTINCT FROM NULLIF(TRIM(s.customer_phone), '')
+      AND e.email IS NOT DISTINCT FROM NULLIF(TRIM(s.customer_email), '')
+)
+WHERE s.customer_id IS NULL
+  AND COALESCE(NULLIF(TRIM(s.customer_name), ''),
+               NULLIF(TRIM(s.customer_email), ''),
+               NULLIF(TRIM(s.customer_phone), '')) IS NOT NULL;
+
+COMMIT;
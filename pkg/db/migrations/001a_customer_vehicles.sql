@@ -0,0 +1,26 @@
+-- Adds the vehicles saved on customer records. Safe to run more than once.
+
+BEGIN;
+
+CREATE SEQUENCE IF NOT EXISTS customer_vehicle_id_seq;
+
+CREATE TABLE IF NOT EXISTS customer_vehicles (
+    vehicle_id INTEGER PRIMARY KEY DEFAULT nextval('customer_vehicle_id_seq'),
+    customer_id INTEGER NOT NULL REFERENCES customers(customer_id) ON DELETE CASCADE,
+    submodel_id INTEGER NOT NULL REFERENCES vehicle_submodels(submodel_id),
+    year INTEGER NOT NULL,
+    vin VARCHAR(17),
+    plate VARCHAR(20),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_vehicles_customer ON customer_vehicles(customer_id);
+
+DROP TRIGGER IF EXISTS update_customer_vehicles_timestamp ON customer_vehicles;
+CREATE TRIGGER update_customer_vehicles_timestamp
+BEFORE UPDATE ON customer_vehicles
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+COMMIT;
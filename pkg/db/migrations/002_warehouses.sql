@@ -0,0 +1,84 @@
+-- Introduces warehouses and per-warehouse stock. Existing stock, documents
+-- and ledger entries are assigned to a default warehouse created here, and
+-- the item locations become the bin locations in that warehouse. Safe to run
+-- more than once.
+
+BEGIN;
+
+CREATE SEQUENCE IF NOT EXISTS warehouse_id_seq;
+
+CREATE TABLE IF NOT EXISTS warehouses (
+    warehouse_id INTEGER PRIMARY KEY DEFAULT nextval('warehouse_id_seq'),
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    address TEXT,
+    phone VARCHAR(50),
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_warehouse_code UNIQUE (code)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_default ON warehouses(is_default) WHERE is_default;
+
+INSERT INTO warehouses (code, name, is_default)
+SELECT 'MAIN', 'Ana Depo', true
+WHERE NOT EXISTS (SELECT 1 FROM warehouses WHERE is_default);
+
+CREATE OR REPLACE FUNCTION default_warehouse_id()
+RETURNS INTEGER AS $$
+    SELECT warehouse_id FROM warehouses WHERE is_default;
+$$ LANGUAGE sql STABLE;
+
+CREATE TABLE IF NOT EXISTS item_stock (
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    location_aisle VARCHAR(50),
+    location_shelf VARCHAR(50),
+    location_bin VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (item_id, warehouse_id)
+);
+
+INSERT INTO item_stock (item_id, warehouse_id, quantity, location_aisle, location_shelf, location_bin)
+SELECT item_id, default_warehouse_id(), current_stock, location_aisle, location_shelf, location_bin
+FROM items
+ON CONFLICT (item_id, warehouse_id) DO NOTHING;
+
+ALTER TABLE sales
+    ADD COLUMN IF NOT EXISTS warehouse_id INTEGER NOT NULL DEFAULT default_warehouse_id()
+    REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT;
+ALTER TABLE purchases
+    ADD COLUMN IF NOT EXISTS warehouse_id INTEGER NOT NULL DEFAULT default_warehouse_id()
+    REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT;
+ALTER TABLE goods_receipts
+    ADD COLUMN IF NOT EXISTS warehouse_id INTEGER NOT NULL DEFAULT default_warehouse_id()
+    REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT;
+ALTER TABLE IF EXISTS stocktakes
+    ADD COLUMN IF NOT EXISTS warehouse_id INTEGER NOT NULL DEFAULT default_warehouse_id()
+    REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT;
+
+ALTER TABLE stock_movements
+    ADD COLUMN IF NOT EXISTS warehouse_id INTEGER NOT NULL DEFAULT default_warehouse_id()
+    REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_item_stock_warehouse ON item_stock(warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_purchases_warehouse ON purchases(warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_sales_warehouse ON sales(warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_warehouse ON stock_movements(warehouse_id, item_id);
+
+DROP TRIGGER IF EXISTS update_warehouses_timestamp ON warehouses;
+CREATE TRIGGER update_warehouses_timestamp
+BEFORE UPDATE ON warehouses
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+DROP TRIGGER IF EXISTS update_item_stock_timestamp ON item_stock;
+CREATE TRIGGER update_item_stock_timestamp
+BEFORE UPDATE ON item_stock
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+COMMIT;
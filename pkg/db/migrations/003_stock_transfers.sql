@@ -0,0 +1,69 @@
+-- Adds stock transfers between warehouses and the transfer movement types of
+-- the stock ledger. Safe to run more than once.
+
+BEGIN;
+
+CREATE SEQUENCE IF NOT EXISTS stock_transfer_id_seq;
+CREATE SEQUENCE IF NOT EXISTS stock_transfer_line_id_seq;
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    transfer_id INTEGER PRIMARY KEY DEFAULT nextval('stock_transfer_id_seq'),
+    transfer_number VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'requested',
+    source_warehouse_id INTEGER NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
+    destination_warehouse_id INTEGER NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
+    requested_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    requested_by VARCHAR(100),
+    shipped_at TIMESTAMP WITH TIME ZONE,
+    shipped_by VARCHAR(100),
+    received_at TIMESTAMP WITH TIME ZONE,
+    received_by VARCHAR(100),
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_transfer_number UNIQUE (transfer_number),
+    CONSTRAINT valid_transfer_status CHECK (status IN (
+        'requested', 'shipped', 'partially_received', 'received', 'cancelled'
+    )),
+    CONSTRAINT different_transfer_warehouses CHECK (source_warehouse_id <> destination_warehouse_id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_lines (
+    line_id INTEGER PRIMARY KEY DEFAULT nextval('stock_transfer_line_id_seq'),
+    transfer_id INTEGER NOT NULL REFERENCES stock_transfers(transfer_id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    quantity_received INTEGER NOT NULL DEFAULT 0,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_transfer_item UNIQUE (transfer_id, item_id),
+    CONSTRAINT positive_transfer_quantity CHECK (quantity > 0),
+    CONSTRAINT valid_transfer_received CHECK (quantity_received BETWEEN 0 AND quantity)
+);
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS valid_movement_type;
+ALTER TABLE stock_movements ADD CONSTRAINT valid_movement_type CHECK (movement_type IN (
+    'opening', 'sale', 'sale_correction', 'purchase', 'purchase_correction',
+    'adjustment', 'sale_return', 'supplier_return', 'stocktake',
+    'transfer_out', 'transfer_in'
+));
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_status ON stock_transfers(status);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_source ON stock_transfers(source_warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_destination ON stock_transfers(destination_warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_lines_transfer ON stock_transfer_lines(transfer_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_lines_item ON stock_transfer_lines(item_id);
+
+DROP TRIGGER IF EXISTS update_stock_transfers_timestamp ON stock_transfers;
+CREATE TRIGGER update_stock_transfers_timestamp
+BEFORE UPDATE ON stock_transfers
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+DROP TRIGGER IF EXISTS update_stock_transfer_lines_timestamp ON stock_transfer_lines;
+CREATE TRIGGER update_stock_transfer_lines_timestamp
+BEFORE UPDATE ON stock_transfer_lines
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+COMMIT;
@@ -0,0 +1,16 @@
+-- Adds the supplier lead time and the item reorder-up-to level used by the
+-- reorder suggestions. Safe to run more than once.
+
+BEGIN;
+
+ALTER TABLE suppliers
+    ADD COLUMN IF NOT EXISTS lead_time_days INTEGER NOT NULL DEFAULT 7;
+ALTER TABLE suppliers DROP CONSTRAINT IF EXISTS positive_lead_time;
+ALTER TABLE suppliers ADD CONSTRAINT positive_lead_time CHECK (lead_time_days >= 0);
+
+ALTER TABLE items
+    ADD COLUMN IF NOT EXISTS reorder_up_to INTEGER;
+ALTER TABLE items DROP CONSTRAINT IF EXISTS positive_reorder_up_to;
+ALTER TABLE items ADD CONSTRAINT positive_reorder_up_to CHECK (reorder_up_to >= 0);
+
+COMMIT;
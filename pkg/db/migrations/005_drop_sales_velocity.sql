@@ -0,0 +1,9 @@
+-- Drops the item_sales_velocity view. Sales velocity and stockout forecasts
+-- are computed by the forecasting service instead. Safe to run more than
+-- once.
+
+BEGIN;
+
+DROP VIEW IF EXISTS item_sales_velocity;
+
+COMMIT;
@@ -0,0 +1,18 @@
+-- Adds the ABC/XYZ classes set on items by the classification report. Safe
+-- to run more than once.
+
+BEGIN;
+
+ALTER TABLE items
+    ADD COLUMN IF NOT EXISTS abc_class CHAR(1),
+    ADD COLUMN IF NOT EXISTS xyz_class CHAR(1),
+    ADD COLUMN IF NOT EXISTS classified_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS valid_abc_class;
+ALTER TABLE items ADD CONSTRAINT valid_abc_class CHECK (abc_class IN ('A', 'B', 'C'));
+ALTER TABLE items DROP CONSTRAINT IF EXISTS valid_xyz_class;
+ALTER TABLE items ADD CONSTRAINT valid_xyz_class CHECK (xyz_class IN ('X', 'Y', 'Z'));
+
+CREATE INDEX IF NOT EXISTS idx_items_classes ON items(abc_class, xyz_class);
+
+COMMIT;
@@ -0,0 +1,91 @@
+-- Adds FIFO cost layers, the moving average cost of items, the value of
+-- every stock movement and the cost of goods sold on sale lines. Existing
+-- stock is costed at the item's buy price, as one layer per item. Safe to
+-- run more than once.
+
+BEGIN;
+
+CREATE SEQUENCE IF NOT EXISTS cost_layer_id_seq;
+
+INSERT INTO settings (key, value) VALUES ('costing_method', 'fifo')
+ON CONFLICT (key) DO NOTHING;
+
+ALTER TABLE items
+    ADD COLUMN IF NOT EXISTS average_cost DECIMAL(12,4) NOT NULL DEFAULT 0;
+
+ALTER TABLE sale_lines
+    ADD COLUMN IF NOT EXISTS cost_of_goods DECIMAL(12,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE stock_movements
+    ADD COLUMN IF NOT EXISTS cost DECIMAL(12,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cost_fifo DECIMAL(12,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cost_average DECIMAL(12,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS cost_layers (
+    layer_id INTEGER PRIMARY KEY DEFAULT nextval('cost_layer_id_seq'),
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    movement_id INTEGER REFERENCES stock_movements(movement_id) ON DELETE SET NULL,
+    unit_cost DECIMAL(12,4) NOT NULL,
+    quantity INTEGER NOT NULL,
+    remaining INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_layer_quantity CHECK (quantity > 0),
+    CONSTRAINT valid_layer_remaining CHECK (remaining BETWEEN 0 AND quantity),
+    CONSTRAINT positive_unit_cost CHECK (unit_cost >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_reference_line ON stock_movements(reference_type, reference_line_id);
+CREATE INDEX IF NOT EXISTS idx_cost_layers_open ON cost_layers(item_id, layer_id) WHERE remaining > 0;
+
+-- The backfill only runs before the ledger has opened any layer. The ledger
+-- is append-only, so its guard is lifted for this one rewrite.
+ALTER TABLE stock_movements DISABLE TRIGGER trigger_prevent_stock_movement_update;
+
+UPDATE stock_movements m SET
+    cost = ROUND(m.quantity * i.buy_price, 2),
+    cost_fifo = ROUND(m.quantity * i.buy_price, 2),
+    cost_average = ROUND(m.quantity * i.buy_price, 2)
+FROM items i
+WHERE i.item_id = m.item_id
+  AND m.movement_type NOT IN ('transfer_out', 'transfer_in')
+  AND NOT EXISTS (SELECT 1 FROM cost_layers);
+
+ALTER TABLE stock_movements ENABLE TRIGGER trigger_prevent_stock_movement_update;
+
+UPDATE sale_lines sl SET cost_of_goods = COALESCE((
+    SELECT -SUM(m.cost) FROM stock_movements m
+    WHERE m.reference_type = 'sale' AND m.reference_line_id = sl.sale_line_id
+), 0)
+WHERE NOT EXISTS (SELECT 1 FROM cost_layers);
+
+UPDATE items SET average_cost = buy_price
+WHERE NOT EXISTS (SELECT 1 FROM cost_layers);
+
+INSERT INTO cost_layers (item_id, unit_cost, quantity, remaining)
+SELECT item_id, buy_price, current_stock, current_stock
+FROM items
+WHERE current_stock > 0
+  AND NOT EXISTS (SELECT 1 FROM cost_layers);
+
+CREATE OR REPLACE VIEW top_selling_items AS
+SELECT
+    i.item_id,
+    i.part_number,
+    i.description,
+    c.category_name,
+    COUNT(DISTINCT sl.sale_id) as number_of_sales,
+    SUM(sl.quantity) as total_quantity_sold,
+    SUM(sl.total_price) as total_revenue,
+    SUM(sl.total_price) - SUM(sl.cost_of_goods) as estimated_profit
+FROM
+    items i
+JOIN
+    sale_lines sl ON i.item_id = sl.item_id
+JOIN
+    categories c ON i.category_id = c.category_id
+GROUP BY
+    i.item_id, i.part_number, i.description, c.category_name
+ORDER BY
+    total_revenue DESC;
+
+COMMIT;
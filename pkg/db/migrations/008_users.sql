@@ -0,0 +1,40 @@
+-- Adds user accounts and login sessions. The first admin is created by the
+-- application from ADMIN_USERNAME and ADMIN_PASSWORD while the users table
+-- is empty. Safe to run more than once.
+
+BEGIN;
+
+CREATE SEQUENCE IF NOT EXISTS user_id_seq;
+
+CREATE TABLE IF NOT EXISTS users (
+    user_id INTEGER PRIMARY KEY DEFAULT nextval('user_id_seq'),
+    username VARCHAR(100) NOT NULL,
+    full_name VARCHAR(100) NOT NULL,
+    password_hash VARCHAR(100) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_user_role CHECK (role IN ('admin', 'manager', 'cashier', 'warehouse'))
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    token_hash CHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users(LOWER(username));
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires ON sessions(expires_at);
+
+DROP TRIGGER IF EXISTS update_users_timestamp ON users;
+CREATE TRIGGER update_users_timestamp
+BEFORE UPDATE ON users
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+COMMIT;
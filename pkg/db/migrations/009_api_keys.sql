@@ -0,0 +1,31 @@
+-- Adds API keys for machine clients calling the API without a browser
+-- session. Safe to run more than once.
+
+BEGIN;
+
+CREATE SEQUENCE IF NOT EXISTS api_key_id_seq;
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    api_key_id INTEGER PRIMARY KEY DEFAULT nextval('api_key_id_seq'),
+    name VARCHAR(90) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    scopes TEXT[] NOT NULL,
+    created_by VARCHAR(100),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    replaced_by INTEGER REFERENCES api_keys(api_key_id) ON DELETE SET NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    last_used_ip VARCHAR(45),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_api_key_hash UNIQUE (key_hash),
+    CONSTRAINT api_key_has_scopes CHECK (cardinality(scopes) > 0)
+);
+
+DROP TRIGGER IF EXISTS update_api_keys_timestamp ON api_keys;
+CREATE TRIGGER update_api_keys_timestamp
+BEFORE UPDATE ON api_keys
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+COMMIT;
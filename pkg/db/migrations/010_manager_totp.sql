@@ -0,0 +1,51 @@
+-- Adds manager TOTP authenticators, their recovery codes and the record of
+-- who approved each deletion or price decrease. Safe to run more than once.
+
+BEGIN;
+
+CREATE SEQUENCE IF NOT EXISTS recovery_code_id_seq;
+CREATE SEQUENCE IF NOT EXISTS authorization_id_seq;
+
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id INTEGER PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    last_step BIGINT,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS totp_recovery_codes (
+    recovery_code_id INTEGER PRIMARY KEY DEFAULT nextval('recovery_code_id_seq'),
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS manager_authorizations (
+    authorization_id INTEGER PRIMARY KEY DEFAULT nextval('authorization_id_seq'),
+    action VARCHAR(50) NOT NULL,
+    resource_id INTEGER NOT NULL,
+    details TEXT,
+    requested_by VARCHAR(100),
+    authorized_by VARCHAR(100) NOT NULL,
+    authorizer_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    method VARCHAR(20) NOT NULL,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_authorization_method CHECK (method IN ('totp', 'recovery_code'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user ON totp_recovery_codes(user_id);
+CREATE INDEX IF NOT EXISTS idx_authorizations_action ON manager_authorizations(action, resource_id);
+CREATE INDEX IF NOT EXISTS idx_authorizations_created ON manager_authorizations(created_at);
+
+DROP TRIGGER IF EXISTS update_user_totp_timestamp ON user_totp;
+CREATE TRIGGER update_user_totp_timestamp
+BEFORE UPDATE ON user_totp
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+COMMIT;
@@ -0,0 +1,25 @@
+-- Adds the audit log of changes to items, categories, suppliers, vehicles,
+-- compatibility, sales and purchases. Safe to run more than once.
+
+BEGIN;
+
+CREATE SEQUENCE IF NOT EXISTS audit_id_seq;
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    audit_id BIGINT PRIMARY KEY DEFAULT nextval('audit_id_seq'),
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    actor VARCHAR(100),
+    authorized_by VARCHAR(100),
+    changes JSONB NOT NULL DEFAULT '{}',
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_audit_action CHECK (action IN ('create', 'update', 'delete'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_entity ON audit_log(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_actor ON audit_log(LOWER(actor));
+CREATE INDEX IF NOT EXISTS idx_audit_created ON audit_log(created_at);
+
+COMMIT;
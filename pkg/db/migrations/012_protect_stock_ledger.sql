@@ -0,0 +1,15 @@
+-- Keeps the stock ledger append-only: movements can no longer be deleted,
+-- directly or by deleting their item. Safe to run more than once.
+
+BEGIN;
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_item_id_fkey;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_item_id_fkey
+    FOREIGN KEY (item_id) REFERENCES items(item_id) ON DELETE RESTRICT;
+
+DROP TRIGGER IF EXISTS trigger_prevent_stock_movement_update ON stock_movements;
+CREATE TRIGGER trigger_prevent_stock_movement_update
+BEFORE UPDATE OR DELETE ON stock_movements
+FOR EACH ROW EXECUTE PROCEDURE prevent_stock_movement_update();
+
+COMMIT;
@@ -720,126 +720,312 @@ VALUES
         'Seat covers for stock'
     );
 
--- Insert sample sales
+-- Insert sample sales (transaction headers)
 INSERT INTO
     sales (
-        item_id,
-        quantity,
-        price_per_unit,
-        total_price,
         transaction_number,
         customer_name,
         customer_phone,
         customer_email,
         sold_by,
+        payment_method,
+        subtotal,
+        total_amount,
+        amount_paid,
         notes
     )
 VALUES
     (
-        1,
-        10,
-        35.00,
-        350.00,
         'TXN-1001',
         'Alice Johnson',
         '111-222-3333',
         'alice@example.com',
         'John Doe',
+        'cash',
+        350.00,
+        350.00,
+        350.00,
         'Customer purchase'
     ),
     (
-        2,
-        5,
-        15.00,
-        75.00,
         'TXN-1002',
         'Bob Smith',
         '222-333-4444',
         'bob@example.com',
         'Jane Smith',
+        'cash',
+        75.00,
+        75.00,
+        75.00,
         'Regular customer'
     ),
     (
-        3,
-        2,
-        50.00,
-        100.00,
         'TXN-1003',
         'Charlie Brown',
         '333-444-5555',
         'charlie@example.com',
         'Mike Johnson',
+        'cash',
+        100.00,
+        100.00,
+        100.00,
         'Brake pads replacement'
     ),
     (
-        4,
-        1,
-        80.00,
-        80.00,
         'TXN-1004',
         'David Lee',
         '444-555-6666',
         'david@example.com',
         'Sarah Lee',
+        'cash',
+        80.00,
+        80.00,
+        80.00,
         'Shock absorber replacement'
     ),
     (
-        5,
-        1,
-        150.00,
-        150.00,
         'TXN-1005',
         'Eve White',
         '555-666-7777',
         'eve@example.com',
         'Chris Brown',
+        'cash',
+        150.00,
+        150.00,
+        150.00,
         'Battery replacement'
     ),
     (
-        6,
-        10,
-        10.00,
-        100.00,
         'TXN-1006',
         'Frank Harris',
         '666-777-8888',
         'frank@example.com',
         'John Doe',
+        'cash',
+        100.00,
+        100.00,
+        100.00,
         'Spark plugs bulk purchase'
     ),
     (
-        7,
-        1,
-        120.00,
-        120.00,
         'TXN-1007',
         'Grace Taylor',
         '777-888-9999',
         'grace@example.com',
         'Jane Smith',
+        'cash',
+        120.00,
+        120.00,
+        120.00,
         'Muffler replacement'
     ),
     (
-        8,
-        1,
-        250.00,
-        250.00,
         'TXN-1008',
         'Henry Clark',
         '888-999-0000',
         'henry@example.com',
         'Mike Johnson',
+        'cash',
+        250.00,
+        250.00,
+        250.00,
         'Front bumper replacement'
     ),
     (
-        9,
-        2,
-        100.00,
-        200.00,
         'TXN-1009',
         'Ivy Lewis',
         '999-000-1111',
         'ivy@example.com',
         'Sarah Lee',
+        'cash',
+        200.00,
+        200.00,
+        200.00,
         'Seat covers purchase'
     );
+
+-- Insert sample sale lines
+INSERT INTO
+    sale_lines (
+        sale_id,
+        item_id,
+        quantity,
+        price_per_unit,
+        total_price
+    )
+VALUES
+    (
+        1,
+        1,
+        10,
+        35.00,
+        350.00
+    ),
+    (
+        2,
+        2,
+        5,
+        15.00,
+        75.00
+    ),
+    (
+        3,
+        3,
+        2,
+        50.00,
+        100.00
+    ),
+    (
+        4,
+        4,
+        1,
+        80.00,
+        80.00
+    ),
+    (
+        5,
+        5,
+        1,
+        150.00,
+        150.00
+    ),
+    (
+        6,
+        6,
+        10,
+        10.00,
+        100.00
+    ),
+    (
+        7,
+        7,
+        1,
+        120.00,
+        120.00
+    ),
+    (
+        8,
+        8,
+        1,
+        250.00,
+        250.00
+    ),
+    (
+        9,
+        9,
+        2,
+        100.00,
+        200.00
+    );
+
+-- Post the sample data to the stock ledger: the item balances inserted above
+-- are the opening stock, followed by the sample purchases and sales
+INSERT INTO
+    stock_movements (
+        item_id,
+        movement_type,
+        quantity,
+        balance_after,
+        reference_type,
+        reference_id,
+        reference_line_id,
+        performed_by,
+        notes,
+        created_at
+    )
+SELECT
+    item_id,
+    movement_type,
+    quantity,
+    SUM(quantity) OVER (
+        PARTITION BY item_id
+        ORDER BY created_at, seq, reference_id
+    )::INTEGER,
+    reference_type,
+    reference_id,
+    reference_line_id,
+    performed_by,
+    notes,
+    created_at
+FROM
+    (
+        SELECT
+            item_id,
+            'opening' AS movement_type,
+            current_stock AS quantity,
+            'item' AS reference_type,
+            item_id AS reference_id,
+            NULL::INTEGER AS reference_line_id,
+            NULL::VARCHAR AS performed_by,
+            'Opening balance'::TEXT AS notes,
+            created_at,
+            0 AS seq
+        FROM items
+        WHERE current_stock <> 0
+        UNION ALL
+        SELECT
+            item_id,
+            'purchase',
+            quantity,
+            'purchase',
+            purchase_id,
+            NULL,
+            received_by,
+            NULL,
+            date,
+            1
+        FROM purchases
+        UNION ALL
+        SELECT
+            sl.item_id,
+            'sale',
+            -sl.quantity,
+            'sale',
+            sl.sale_id,
+            sl.sale_line_id,
+            s.sold_by,
+            NULL,
+            s.date,
+            2
+        FROM sale_lines sl
+        JOIN sales s ON sl.sale_id = s.sale_id
+    ) m
+ORDER BY item_id, created_at, seq, reference_id;
+
+-- Bring the item balances in line with the ledger
+UPDATE items i
+SET current_stock = l.ledger_stock
+FROM
+    (
+        SELECT item_id, SUM(quantity)::INTEGER AS ledger_stock
+        FROM stock_movements
+        GROUP BY item_id
+    ) l
+WHERE i.item_id = l.item_id;
+
+-- The sample stock is all held in the default warehouse, at the location
+-- recorded on the item
+INSERT INTO
+    item_stock (
+        item_id,
+        warehouse_id,
+        quantity,
+        location_aisle,
+        location_shelf,
+        location_bin
+    )
+SELECT
+    item_id,
+    default_warehouse_id(),
+    current_stock,
+    location_aisle,
+    location_shelf,
+    location_bin
+FROM items;
+
+-- Insert sample warehouses besides the default one
+INSERT INTO
+    warehouses (code, name, address, phone)
+VALUES
+    ('SUBE2', 'Şube 2', 'Sanayi Sitesi 4. Blok No: 12', '0212 555 01 02'),
+    ('ARKA', 'Arka Depo', NULL, NULL);
@@ -0,0 +1,119 @@
+package charts
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Size of the chart's SVG viewBox and the margins around the plot area,
+// which hold the axis labels
+const (
+	Width        = 640
+	Height       = 220
+	marginLeft   = 56
+	marginRight  = 12
+	marginTop    = 12
+	marginBottom = 28
+
+	yTicks    = 4
+	maxXTicks = 6
+)
+
+// Chart is a line chart laid out in SVG coordinates, ready to render
+type Chart struct {
+	Title  string
+	Line   string
+	Area   string
+	Points []*Point
+	YTicks []*Tick
+	XTicks []*Tick
+}
+
+// Point is a data point and its tooltip
+type Point struct {
+	X, Y    float64
+	Tooltip string
+}
+
+// Tick is an axis label at a position along its axis
+type Tick struct {
+	Pos   float64
+	Label string
+}
+
+// NewLineChart lays out the values over the plot area, the first at the
+// left edge and the last at the right. Labels name each value on the x axis
+// and tooltips; decimals is the precision of the tooltips.
+func NewLineChart(title string, labels []string, values []float64, decimals int) *Chart {
+	chart := &Chart{Title: title}
+	if len(values) == 0 {
+		return chart
+	}
+
+	low, high := 0.0, 0.0
+	for _, value := range values {
+		low = math.Min(low, value)
+		high = math.Max(high, value)
+	}
+	if high == low {
+		high = low + 1
+	}
+
+	plotWidth := float64(Width - marginLeft - marginRight)
+	plotHeight := float64(Height - marginTop - marginBottom)
+	y := func(value float64) float64 {
+		return marginTop + plotHeight*(high-value)/(high-low)
+	}
+	x := func(i int) float64 {
+		if len(values) == 1 {
+			return marginLeft + plotWidth/2
+		}
+		return marginLeft + plotWidth*float64(i)/float64(len(values)-1)
+	}
+
+	line := make([]string, 0, len(values))
+	for i, value := range values {
+		point := &Point{
+			X:       x(i),
+			Y:       y(value),
+			Tooltip: fmt.Sprintf("%s: %.*f", labels[i], decimals, value),
+		}
+		chart.Points = append(chart.Points, point)
+		line = append(line, Coord(point.X)+","+Coord(point.Y))
+	}
+	chart.Line = strings.Join(line, " ")
+
+	// The area is closed along the zero line
+	zero := Coord(y(0))
+	chart.Area = Coord(x(0)) + "," + zero + " " + chart.Line + " " + Coord(x(len(values)-1)) + "," + zero
+
+	for i := 0; i <= yTicks; i++ {
+		value := low + (high-low)*float64(i)/yTicks
+		chart.YTicks = append(chart.YTicks, &Tick{Pos: y(value), Label: compact(value)})
+	}
+
+	step := int(math.Ceil(float64(len(values)) / maxXTicks))
+	for i := 0; i < len(values); i += step {
+		chart.XTicks = append(chart.XTicks, &Tick{Pos: x(i), Label: labels[i]})
+	}
+
+	return chart
+}
+
+// Coord formats an SVG coordinate
+func Coord(value float64) string {
+	return fmt.Sprintf("%.1f", value)
+}
+
+// compact shortens large axis values, 12500 to 12.5K
+func compact(value float64) string {
+	switch abs := math.Abs(value); {
+	case abs >= 1e6:
+		return fmt.Sprintf("%.1fM", value/1e6)
+	case abs >= 1e3:
+		return fmt.Sprintf("%.1fK", value/1e3)
+	default:
+		return fmt.Sprintf("%.0f", value)
+	}
+}
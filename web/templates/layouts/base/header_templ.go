@@ -8,7 +8,7 @@ package layouts
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
-func Header() templ.Component {
+func Header(userName string) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -29,7 +29,20 @@ func Header() templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<header class=\"bg-white shadow rounded-lg p-4 mb-6\"><div class=\"flex justify-between items-center\"><h1 class=\"text-2xl font-bold\">Dashboard Overview</h1><div class=\"flex items-center space-x-4\"><button class=\"bg-gray-800 text-white px-4 py-2 rounded hover:bg-gray-700\">Refresh</button><div x-data=\"{ open: false }\" class=\"relative\"><button @click=\"open = !open\" class=\"flex items-center space-x-2\"><span>John Doe</span></button><div x-show=\"open\" @click.away=\"open = false\" class=\"absolute right-0 mt-2 w-48 bg-white rounded shadow-lg py-2\"><a href=\"#\" class=\"block px-4 py-2 hover:bg-gray-100\">Profile</a> <a href=\"#\" class=\"block px-4 py-2 hover:bg-gray-100\">Settings</a> <a href=\"#\" class=\"block px-4 py-2 hover:bg-gray-100\">Logout</a></div></div></div></div></header>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<header class=\"bg-white shadow rounded-lg p-4 mb-6\"><div class=\"flex justify-between items-center\"><h1 class=\"text-2xl font-bold\">Dashboard Overview</h1><div class=\"flex items-center space-x-4\"><button class=\"bg-gray-800 text-white px-4 py-2 rounded hover:bg-gray-700\">Refresh</button><div x-data=\"{ open: false }\" class=\"relative\"><button @click=\"open = !open\" class=\"flex items-center space-x-2\"><span>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var2 string
+		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(userName)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/layouts/base/header.templ`, Line: 13, Col: 22}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</span></button><div x-show=\"open\" @click.away=\"open = false\" class=\"absolute right-0 mt-2 w-48 bg-white rounded shadow-lg py-2\"><a href=\"#\" class=\"block px-4 py-2 hover:bg-gray-100\">Profile</a> <a href=\"#\" class=\"block px-4 py-2 hover:bg-gray-100\">Settings</a> <a href=\"/account/totp\" class=\"block px-4 py-2 hover:bg-gray-100\">Two-Factor</a><form method=\"post\" action=\"/logout\"><button type=\"submit\" class=\"block w-full text-left px-4 py-2 hover:bg-gray-100\">Logout</button></form></div></div></div></div></header>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
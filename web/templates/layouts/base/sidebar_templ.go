@@ -29,7 +29,7 @@ func Sidebar() templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"bg-gray-800 text-white w-64 py-6 px-4 flex flex-col\"><div class=\"text-2xl font-bold mb-8\">Dashboard</div><nav class=\"flex-1\"><ul class=\"space-y-2\"><li><a href=\"/\" class=\"flex items-center space-x-2 p-2 rounded hover:bg-gray-700\"><span>Overview</span></a></li><li><a href=\"/inventory\" class=\"flex items-center space-x-2 p-2 rounded hover:bg-gray-700\"><span>Inventory</span></a></li><li><a href=\"/sales\" class=\"flex items-center space-x-2 p-2 rounded hover:bg-gray-700\"><span>Sales</span></a></li><li><a href=\"/purchases\" class=\"flex items-center space-x-2 p-2 rounded hover:bg-gray-700\"><span>Purchases</span></a></li></ul></nav></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"bg-gray-800 text-white w-64 py-6 px-4 flex flex-col\"><div class=\"text-2xl font-bold mb-8\">Dashboard</div><nav class=\"flex-1\"><ul class=\"space-y-2\"><li><a href=\"/\" class=\"flex items-center space-x-2 p-2 rounded hover:bg-gray-700\"><span>Overview</span></a></li><li><a href=\"/inventory\" class=\"flex items-center space-x-2 p-2 rounded hover:bg-gray-700\"><span>Inventory</span></a></li><li><a href=\"/sales\" class=\"flex items-center space-x-2 p-2 rounded hover:bg-gray-700\"><span>Sales</span></a></li><li><a href=\"/purchases\" class=\"flex items-center space-x-2 p-2 rounded hover:bg-gray-700\"><span>Purchases</span></a></li><li><a href=\"/audit\" class=\"flex items-center space-x-2 p-2 rounded hover:bg-gray-700\"><span>Audit Log</span></a></li></ul></nav></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -0,0 +1,52 @@
+package auditview
+
+// Entry is an audit entry laid out for the history table
+type Entry struct {
+	Time         string
+	EntityType   string
+	EntityID     int
+	Action       string
+	Actor        string
+	AuthorizedBy string
+	Changes      []Change
+}
+
+// Change is a changed field with its values formatted for display
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+var entityLabels = map[string]string{
+	"item":             "Ürünler",
+	"category":         "Kategoriler",
+	"supplier":         "Tedarikçiler",
+	"vehicle_make":     "Markalar",
+	"vehicle_model":    "Modeller",
+	"vehicle_submodel": "Alt Modeller",
+	"compatibility":    "Uyumluluk",
+	"sale":             "Satışlar",
+	"purchase":         "Alımlar",
+}
+
+var actionLabels = map[string]string{
+	"create": "Oluşturma",
+	"update": "Güncelleme",
+	"delete": "Silme",
+}
+
+// EntityLabel is the tab title of an entity type
+func EntityLabel(entityType string) string {
+	if label, ok := entityLabels[entityType]; ok {
+		return label
+	}
+	return entityType
+}
+
+func actionLabel(action string) string {
+	if label, ok := actionLabels[action]; ok {
+		return label
+	}
+	return action
+}
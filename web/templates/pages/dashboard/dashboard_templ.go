@@ -8,7 +8,10 @@ package dashboardview
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
-func Dashboard() templ.Component {
+// Dashboard renders the dashboard for the range from start to end, both
+// formatted as date input values, with a chart of each metric. Widgets
+// covering a period read the range form and reload when it changes.
+func Dashboard(start, end string, metrics []string) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -29,7 +32,56 @@ func Dashboard() templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"space-y-6\"><!-- Key Metrics --><div class=\"grid grid-cols-1 md:grid-cols-2 lg:grid-cols-4 gap-6\"><div class=\"bg-white p-6 rounded-lg shadow\"><h2 class=\"text-lg font-semibold text-gray-600\">Düşük Stok Ürünleri</h2><div class=\"mt-2\"><div class=\"text-3xl font-bold text-red-600\" hx-get=\"/api/inventory/low-stock-count\" hx-trigger=\"load\"><div class=\"animate-pulse h-8 bg-gray-200 rounded w-16\"></div></div><div class=\"text-sm text-gray-500\">Minimum Altında</div></div></div><div class=\"bg-white p-6 rounded-lg shadow\"><h2 class=\"text-lg font-semibold text-gray-600\">Günlük Gelir</h2><div class=\"mt-2\"><div class=\"text-3xl font-bold text-green-600\" hx-get=\"/api/sales/today\" hx-trigger=\"load\"><div class=\"animate-pulse h-8 bg-gray-200 rounded w-24\"></div></div><div class=\"text-sm text-gray-500\">Satışlardan</div></div></div><div class=\"bg-white p-6 rounded-lg shadow\"><h2 class=\"text-lg font-semibold text-gray-600\">Stokta Parçalar</h2><div class=\"mt-2\"><div class=\"text-3xl font-bold text-blue-600\" hx-get=\"/api/inventory/total-count\" hx-trigger=\"load\"><div class=\"animate-pulse h-8 bg-gray-200 rounded w-20\"></div></div><div class=\"text-sm text-gray-500\">Toplam Ürün</div></div></div><div class=\"bg-white p-6 rounded-lg shadow\"><h2 class=\"text-lg font-semibold text-gray-600\">Araç Kapsamı</h2><div class=\"mt-2\"><div class=\"text-3xl font-bold text-purple-600\" hx-get=\"/api/compatibility/vehicle-count\" hx-trigger=\"load\"><div class=\"animate-pulse h-8 bg-gray-200 rounded w-16\"></div></div><div class=\"text-sm text-gray-500\">Uyumlu Modeller</div></div></div></div><!-- Low Stock & Recent Sales --><div class=\"grid grid-cols-1 lg:grid-cols-2 gap-6\"><div class=\"bg-white rounded-lg shadow\"><div class=\"p-6\"><h2 class=\"text-xl font-semibold mb-4\">Düşük Stok Uyarıları</h2><div class=\"overflow-x-auto\"><table class=\"min-w-full\" hx-get=\"/api/inventory/low-stock\" hx-trigger=\"load every 5m\"><thead class=\"bg-gray-50\"><tr><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Parça No</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">İsim</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Mevcut</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Minimum</th></tr></thead> <tbody class=\"bg-white divide-y divide-gray-200\"><tr class=\"animate-pulse\"><td colspan=\"4\" class=\"px-6 py-4\"><div class=\"h-4 bg-gray-200 rounded\"></div></td></tr></tbody></table></div></div></div><div class=\"bg-white rounded-lg shadow\"><div class=\"p-6\"><h2 class=\"text-xl font-semibold mb-4\">Son Satışlar</h2><div class=\"overflow-x-auto\"><table class=\"min-w-full\" hx-get=\"/api/sales/recent\" hx-trigger=\"load every 30s\"><thead class=\"bg-gray-50\"><tr><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Tarih</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Parça</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Müşteri</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Toplam</th></tr></thead> <tbody class=\"bg-white divide-y divide-gray-200\"><tr class=\"animate-pulse\"><td colspan=\"4\" class=\"px-6 py-4\"><div class=\"h-4 bg-gray-200 rounded\"></div></td></tr></tbody></table></div></div></div></div><!-- Top Sellers & Recent Purchases --><div class=\"grid grid-cols-1 lg:grid-cols-2 gap-6\"><div class=\"bg-white rounded-lg shadow\"><div class=\"p-6\"><h2 class=\"text-xl font-semibold mb-4\">En Çok Satan Parçalar</h2><div class=\"overflow-x-auto\"><table class=\"min-w-full\" hx-get=\"/api/sales/top-sellers\" hx-trigger=\"load\"><thead class=\"bg-gray-50\"><tr><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Parça No</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">İsim</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Satılan</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Gelir</th></tr></thead> <tbody class=\"bg-white divide-y divide-gray-200\"><tr class=\"animate-pulse\"><td colspan=\"4\" class=\"px-6 py-4\"><div class=\"h-4 bg-gray-200 rounded\"></div></td></tr></tbody></table></div></div></div><div class=\"bg-white rounded-lg shadow\"><div class=\"p-6\"><h2 class=\"text-xl font-semibold mb-4\">Son Alımlar</h2><div class=\"overflow-x-auto\"><table class=\"min-w-full\" hx-get=\"/api/purchases/recent\" hx-trigger=\"load\"><thead class=\"bg-gray-50\"><tr><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Tarih</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Parça No</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Tedarikçi</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Maliyet</th></tr></thead> <tbody class=\"bg-white divide-y divide-gray-200\"><tr class=\"animate-pulse\"><td colspan=\"4\" class=\"px-6 py-4\"><div class=\"h-4 bg-gray-200 rounded\"></div></td></tr></tbody></table></div></div></div></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"space-y-6\"><!-- Date Range --><form id=\"dashboard-range\" class=\"bg-white p-4 rounded-lg shadow flex flex-wrap items-end gap-4\"><label class=\"text-sm text-gray-600\">Başlangıç <input type=\"date\" name=\"start\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var2 string
+		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(start)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/pages/dashboard/dashboard.templ`, Line: 12, Col: 49}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "\" class=\"block mt-1 border rounded px-2 py-1\"></label> <label class=\"text-sm text-gray-600\">Bitiş <input type=\"date\" name=\"end\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var3 string
+		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(end)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/pages/dashboard/dashboard.templ`, Line: 16, Col: 45}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "\" class=\"block mt-1 border rounded px-2 py-1\"></label> <label class=\"text-sm text-gray-600\">Aralık <select name=\"interval\" class=\"block mt-1 border rounded px-2 py-1\"><option value=\"\">Otomatik</option> <option value=\"day\">Günlük</option> <option value=\"month\">Aylık</option></select></label></form><!-- Key Metrics --><div class=\"grid grid-cols-1 md:grid-cols-2 lg:grid-cols-4 gap-6\"><div class=\"bg-white p-6 rounded-lg shadow\"><h2 class=\"text-lg font-semibold text-gray-600\">Düşük Stok Ürünleri</h2><div class=\"mt-2\"><div class=\"text-3xl font-bold text-red-600\" hx-get=\"/api/inventory/low-stock-count\" hx-trigger=\"load\"><div class=\"animate-pulse h-8 bg-gray-200 rounded w-16\"></div></div><div class=\"text-sm text-gray-500\">Minimum Altında</div></div></div><div class=\"bg-white p-6 rounded-lg shadow\"><h2 class=\"text-lg font-semibold text-gray-600\">Dönem Geliri</h2><div class=\"mt-2\"><div class=\"text-3xl font-bold text-green-600\" hx-get=\"/api/sales/revenue\" hx-include=\"#dashboard-range\" hx-trigger=\"load, change from:#dashboard-range\"><div class=\"animate-pulse h-8 bg-gray-200 rounded w-24\"></div></div><div class=\"text-sm text-gray-500\">Satışlardan</div></div></div><div class=\"bg-white p-6 rounded-lg shadow\"><h2 class=\"text-lg font-semibold text-gray-600\">Stokta Parçalar</h2><div class=\"mt-2\"><div class=\"text-3xl font-bold text-blue-600\" hx-get=\"/api/inventory/total-count\" hx-trigger=\"load\"><div class=\"animate-pulse h-8 bg-gray-200 rounded w-20\"></div></div><div class=\"text-sm text-gray-500\">Toplam Ürün</div></div></div><div class=\"bg-white p-6 rounded-lg shadow\"><h2 class=\"text-lg font-semibold text-gray-600\">Araç Kapsamı</h2><div class=\"mt-2\"><div class=\"text-3xl font-bold text-purple-600\" hx-get=\"/api/compatibility/vehicle-count\" hx-trigger=\"load\"><div class=\"animate-pulse h-8 bg-gray-200 rounded w-16\"></div></div><div class=\"text-sm text-gray-500\">Uyumlu Modeller</div></div></div></div><!-- Charts --><div class=\"grid grid-cols-1 lg:grid-cols-2 gap-6\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		for _, metric := range metrics {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<div class=\"bg-white p-6 rounded-lg shadow\" hx-get=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var4 string
+			templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs("/api/dashboard/charts/" + metric)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/pages/dashboard/dashboard.templ`, Line: 69, Col: 90}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "\" hx-include=\"#dashboard-range\" hx-trigger=\"load, change from:#dashboard-range\"><div class=\"animate-pulse h-48 bg-gray-200 rounded\"></div></div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</div><!-- Low Stock & Recent Sales --><div class=\"grid grid-cols-1 lg:grid-cols-2 gap-6\"><div class=\"bg-white rounded-lg shadow\"><div class=\"p-6\"><h2 class=\"text-xl font-semibold mb-4\">Düşük Stok Uyarıları</h2><div class=\"overflow-x-auto\"><table class=\"min-w-full\" hx-get=\"/api/inventory/low-stock\" hx-trigger=\"load every 5m\"><thead class=\"bg-gray-50\"><tr><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Parça No</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">İsim</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Mevcut</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Minimum</th></tr></thead> <tbody class=\"bg-white divide-y divide-gray-200\"><tr class=\"animate-pulse\"><td colspan=\"4\" class=\"px-6 py-4\"><div class=\"h-4 bg-gray-200 rounded\"></div></td></tr></tbody></table></div></div></div><div class=\"bg-white rounded-lg shadow\"><div class=\"p-6\"><h2 class=\"text-xl font-semibold mb-4\">Son Satışlar</h2><div class=\"overflow-x-auto\"><table class=\"min-w-full\" hx-get=\"/api/sales/recent\" hx-include=\"#dashboard-range\" hx-trigger=\"load, every 30s, change from:#dashboard-range\"><thead class=\"bg-gray-50\"><tr><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Tarih</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Parça</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Müşteri</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Toplam</th></tr></thead> <tbody class=\"bg-white divide-y divide-gray-200\"><tr class=\"animate-pulse\"><td colspan=\"4\" class=\"px-6 py-4\"><div class=\"h-4 bg-gray-200 rounded\"></div></td></tr></tbody></table></div></div></div></div><!-- Stockout Forecast --><div class=\"bg-white rounded-lg shadow\"><div class=\"p-6\"><h2 class=\"text-xl font-semibold mb-4\">Stok Tükenme Tahmini</h2><p class=\"text-sm text-gray-500 mb-4\">Son 30 günün satışlarına göre 30 gün içinde tükenmesi beklenen ürünler</p><div class=\"overflow-x-auto\"><table class=\"min-w-full\" hx-get=\"/api/inventory/stockout-forecast\" hx-trigger=\"load every 5m\"><thead class=\"bg-gray-50\"><tr><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Parça No</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">İsim</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Mevcut</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Günlük Satış</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Kalan Gün</th></tr></thead> <tbody class=\"bg-white divide-y divide-gray-200\"><tr class=\"animate-pulse\"><td colspan=\"5\" class=\"px-6 py-4\"><div class=\"h-4 bg-gray-200 rounded\"></div></td></tr></tbody></table></div></div></div><!-- Top Sellers & Recent Purchases --><div class=\"grid grid-cols-1 lg:grid-cols-2 gap-6\"><div class=\"bg-white rounded-lg shadow\"><div class=\"p-6\"><h2 class=\"text-xl font-semibold mb-4\">En Çok Satan Parçalar</h2><div class=\"overflow-x-auto\"><table class=\"min-w-full\" hx-get=\"/api/sales/top-sellers\" hx-include=\"#dashboard-range\" hx-trigger=\"load, change from:#dashboard-range\"><thead class=\"bg-gray-50\"><tr><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Parça No</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">İsim</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Satılan</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Gelir</th></tr></thead> <tbody class=\"bg-white divide-y divide-gray-200\"><tr class=\"animate-pulse\"><td colspan=\"4\" class=\"px-6 py-4\"><div class=\"h-4 bg-gray-200 rounded\"></div></td></tr></tbody></table></div></div></div><div class=\"bg-white rounded-lg shadow\"><div class=\"p-6\"><h2 class=\"text-xl font-semibold mb-4\">Son Alımlar</h2><div class=\"overflow-x-auto\"><table class=\"min-w-full\" hx-get=\"/api/purchases/recent\" hx-include=\"#dashboard-range\" hx-trigger=\"load, change from:#dashboard-range\"><thead class=\"bg-gray-50\"><tr><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Tarih</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Parça No</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Tedarikçi</th><th class=\"px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase\">Maliyet</th></tr></thead> <tbody class=\"bg-white divide-y divide-gray-200\"><tr class=\"animate-pulse\"><td colspan=\"4\" class=\"px-6 py-4\"><div class=\"h-4 bg-gray-200 rounded\"></div></td></tr></tbody></table></div></div></div></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}